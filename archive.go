@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- アーカイブ用の MKA 出力 ---
+// FLACの音声を Matroska (.mka) に入れ、カバー画像・同期歌詞 (.lrc)・メタデータのJSONを添付ファイルとして
+// 一緒に格納する。1つのファイルにダウンロードで得たものがすべて残る。
+const (
+	outputMKA              = "mka"
+	archiveManifestVersion = 1
+)
+
+// archiveManifest is the JSON attached to an archival file: everything the tags hold plus what
+// they can't (credits split by role, both lyric variants, where the cover came from).
+type archiveManifest struct {
+	Version       int          `json:"version"`
+	Created       time.Time    `json:"created"`
+	Title         string       `json:"title"`
+	Artist        string       `json:"artist"`
+	AlbumArtist   string       `json:"album_artist,omitempty"`
+	Album         string       `json:"album,omitempty"`
+	Date          string       `json:"date,omitempty"`
+	OriginalDate  string       `json:"original_date,omitempty"`
+	TrackNumber   string       `json:"track_number,omitempty"`
+	TrackTotal    int          `json:"track_total,omitempty"`
+	DiscNumber    int          `json:"disc_number,omitempty"`
+	DiscTotal     int          `json:"disc_total,omitempty"`
+	Genre         string       `json:"genre,omitempty"`
+	ISRC          string       `json:"isrc,omitempty"`
+	Label         string       `json:"label,omitempty"`
+	CatalogNumber string       `json:"catalog_number,omitempty"`
+	RecordingID   string       `json:"recording_id,omitempty"`
+	Credits       workCredits  `json:"credits"`
+	Lyrics        lyricsResult `json:"lyrics"`
+	CoverSource   coverSource  `json:"cover_source,omitempty"`
+	Segment       audioSegment `json:"segment"`
+	Chapters      []chapter    `json:"chapters,omitempty"`
+}
+
+func newArchiveManifest(job convertJob) archiveManifest {
+	t := job.tags
+	return archiveManifest{
+		Version: archiveManifestVersion, Created: time.Now(),
+		Title: t.Title, Artist: t.Artist, AlbumArtist: t.AlbumArtist, Album: t.Album,
+		Date: t.Date, OriginalDate: t.OriginalDate,
+		TrackNumber: t.TrackNumber, TrackTotal: t.TrackTotal, DiscNumber: t.DiscNumber, DiscTotal: t.DiscTotal,
+		Genre: t.Genre, ISRC: t.ISRC, Label: t.Label, CatalogNumber: t.CatalogNumber, RecordingID: t.RecordingID,
+		Credits: job.credits, Lyrics: job.lyrics, CoverSource: job.coverSrc,
+		Segment: job.segment, Chapters: job.chapters,
+	}
+}
+
+// archiveAttachArgs writes the manifest and .lrc next to the temporary audio and returns the ffmpeg
+// arguments that attach them (and the cover) to the Matroska output.
+func archiveAttachArgs(job convertJob) ([]string, error) {
+	dir := filepath.Dir(job.audioPath)
+	var args []string
+	n := 0
+	attach := func(path, name, mime string) {
+		args = append(args, "-attach", path,
+			fmt.Sprintf("-metadata:s:t:%d", n), "filename="+name,
+			fmt.Sprintf("-metadata:s:t:%d", n), "mimetype="+mime)
+		n++
+	}
+	if job.coverPath != "" {
+		data, err := os.ReadFile(job.coverPath)
+		if err != nil {
+			return nil, err
+		}
+		// Matroska の仕様で cover.jpg / cover.png という名前の添付がカバー画像として扱われる
+		name := "cover.jpg"
+		mime := http.DetectContentType(data)
+		if mime == "image/png" {
+			name = "cover.png"
+		}
+		attach(job.coverPath, name, mime)
+	}
+	if job.lyrics.Synced != "" {
+		lrcPath := filepath.Join(dir, "lyrics.lrc")
+		if err := os.WriteFile(lrcPath, []byte(strings.TrimSpace(job.lyrics.Synced)+"\n"), 0o644); err != nil {
+			return nil, err
+		}
+		attach(lrcPath, "lyrics.lrc", "text/plain")
+	}
+	data, err := json.MarshalIndent(newArchiveManifest(job), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	manifestPath := filepath.Join(dir, "metadata.json")
+	if err := os.WriteFile(manifestPath, data, 0o644); err != nil {
+		return nil, err
+	}
+	attach(manifestPath, "metadata.json", "application/json")
+	return args, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- 音声解析 ---
+var ffmpegDurationRe = regexp.MustCompile(`Duration: (\d+):(\d+):(\d+(?:\.\d+)?)`)
+
+// probeDuration reads the container duration (in seconds) from ffmpeg's input banner.
+func probeDuration(ffmpegPath, path string) (float64, error) {
+	// ffmpeg exits non-zero without an output file; the banner is still printed.
+	out, _ := exec.Command(ffmpegPath, "-hide_banner", "-i", path).CombinedOutput()
+	m := ffmpegDurationRe.FindStringSubmatch(string(out))
+	if m == nil {
+		return 0, fmt.Errorf("duration not found in ffmpeg output")
+	}
+	h, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.ParseFloat(m[3], 64)
+	return float64(h*3600+mins*60) + sec, nil
+}
+
+const (
+	stretchMinDelta = 0.03 // 3%未満は誤差として扱う
+	stretchMaxDelta = 0.40
+)
+
+// detectTimeStretch compares the downloaded length with the MusicBrainz length and returns a warning
+// when the ratio looks like a sped-up/slowed-down reupload. Returns "" when nothing is suspicious.
+func detectTimeStretch(actualSec float64, expectedSec int) string {
+	if actualSec <= 0 || expectedSec <= 0 {
+		return ""
+	}
+	ratio := float64(expectedSec) / actualSec
+	delta := math.Abs(ratio - 1)
+	if delta < stretchMinDelta || delta > stretchMaxDelta {
+		return ""
+	}
+	direction := tr("速く")
+	if ratio < 1 {
+		direction = tr("遅く")
+	}
+	semitones := 12 * math.Log2(ratio)
+	return tr("⚠ 音源の長さ (%s) がMusicBrainzの記録 (%s) と%.1f%%ずれています。\n再生速度が%.2f倍%sされている可能性があります (ピッチ変化の目安: %+.1f半音)。",
+		formatDuration(int(math.Round(actualSec))), formatDuration(expectedSec), delta*100, ratio, direction, semitones)
+}
+
+// durationMismatchWarning flags a YouTube source whose length is far from the MusicBrainz track, which
+// usually means an MV edit, an extended version or a wrong match.
+func durationMismatchWarning(yt item, track item) string {
+	info, ok := yt.meta.(ytDlpVideoInfo)
+	trackInfo, ok2 := track.meta.(MBTrack)
+	limit := cfg.Warnings.DurationMismatchSec
+	if !ok || !ok2 || limit <= 0 || info.Duration <= 0 || trackInfo.Length <= 0 {
+		return ""
+	}
+	ytSec, mbSec := int(math.Round(info.Duration)), trackInfo.Length/1000
+	diff := ytSec - mbSec
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff <= limit {
+		return ""
+	}
+	return tr("⚠ 長さが一致しません: YouTube %s / MusicBrainz %s (差 %d秒)\nMV版・ロングバージョン・別の曲の可能性があります。",
+		formatDuration(ytSec), formatDuration(mbSec), diff)
+}
+
+func formatDuration(sec int) string {
+	return fmt.Sprintf("%d:%02d", sec/60, sec%60)
+}
+
+// --- 複数音声トラック ---
+type ytFormat struct {
+	FormatID           string `json:"format_id"`
+	FormatNote         string `json:"format_note"`
+	Language           string `json:"language"`
+	LanguagePreference int    `json:"language_preference"`
+	ACodec             string `json:"acodec"`
+	VCodec             string `json:"vcodec"`
+}
+
+type audioTrack struct {
+	lang, label string
+	isDefault   bool
+}
+
+// audioTracks lists the distinct audio languages among the audio-only formats, in yt-dlp's order.
+// Videos with a single audio track return at most one entry.
+func (info ytDlpVideoInfo) audioTracks() []audioTrack {
+	var tracks []audioTrack
+	seen := map[string]bool{}
+	for _, f := range info.Formats {
+		if f.VCodec != "none" || f.ACodec == "none" || f.Language == "" || seen[f.Language] {
+			continue
+		}
+		seen[f.Language] = true
+		label, _, _ := strings.Cut(f.FormatNote, ",")
+		if label == "" {
+			label = f.Language
+		}
+		tracks = append(tracks, audioTrack{
+			lang:      f.Language,
+			label:     strings.TrimSpace(label),
+			isDefault: f.LanguagePreference > 0 || strings.Contains(f.FormatNote, "default"),
+		})
+	}
+	return tracks
+}
+
+// currentAudioTrack is the explicitly chosen track, or the default one when nothing was chosen.
+func (info ytDlpVideoInfo) currentAudioTrack() audioTrack {
+	tracks := info.audioTracks()
+	for _, t := range tracks {
+		if (info.audioLang == "" && t.isDefault) || (info.audioLang != "" && t.lang == info.audioLang) {
+			return t
+		}
+	}
+	if len(tracks) > 0 {
+		return tracks[0]
+	}
+	return audioTrack{}
+}
+
+// cycleAudioTrack returns the item with its next audio track selected.
+func cycleAudioTrack(yt item) item {
+	info, ok := yt.meta.(ytDlpVideoInfo)
+	if !ok {
+		return yt
+	}
+	tracks := info.audioTracks()
+	if len(tracks) < 2 {
+		return yt
+	}
+	cur := info.currentAudioTrack()
+	for i, t := range tracks {
+		if t.lang == cur.lang {
+			info.audioLang = tracks[(i+1)%len(tracks)].lang
+			break
+		}
+	}
+	yt.meta = info
+	return yt
+}
+
+// audioFormat is the yt-dlp format selector for the item's chosen audio track.
+func audioFormat(yt item) string {
+	if info, ok := yt.meta.(ytDlpVideoInfo); ok && info.audioLang != "" {
+		return fmt.Sprintf("bestaudio[language=%s]/bestaudio", info.audioLang)
+	}
+	return "bestaudio"
+}
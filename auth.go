@@ -0,0 +1,233 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- API の認証 ---
+// daemon -listen の API は Bearer トークン (Authorization: Bearer <トークン>) か Basic 認証で守る。
+// トークンは token サブコマンドで発行して api_tokens.json にハッシュだけを保存するか、server.tokens に直接書く。
+// ブラウザの EventSource はヘッダーを付けられないので、?access_token= でも受け付ける。
+// 認証の設定がないときはループバックのアドレス (127.0.0.1 など) でしか待ち受けない。
+const (
+	apiTokensFile  = "api_tokens.json"
+	apiTokenPrefix = "gmd_"
+)
+
+type serverConfig struct {
+	// Bearer トークンとして受け付ける文字列 (token サブコマンドで発行したものとあわせて使える)
+	Tokens []string `json:"tokens"`
+	// Basic 認証のユーザー名とパスワード (トークンを送れないクライアント向け)。空なら使わない
+	BasicAuth struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"basic_auth"`
+}
+
+// apiToken is an issued token; only its SHA-256 is kept, so the token itself is shown once.
+type apiToken struct {
+	Name    string    `json:"name"`
+	Hash    string    `json:"hash"`
+	Hint    string    `json:"hint"`
+	Created time.Time `json:"created"`
+}
+
+var apiTokensMu sync.Mutex
+
+func apiTokensPath() string { return filepath.Join(mainDir, apiTokensFile) }
+
+func loadAPITokens() ([]apiToken, error) {
+	data, err := os.ReadFile(apiTokensPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tokens []apiToken
+	return tokens, json.Unmarshal(data, &tokens)
+}
+
+// updateAPITokens applies fn to the stored tokens under the lock and saves them, readable by the
+// owner only.
+func updateAPITokens(fn func(tokens []apiToken) ([]apiToken, error)) error {
+	apiTokensMu.Lock()
+	defer apiTokensMu.Unlock()
+	tokens, err := loadAPITokens()
+	if err != nil {
+		return err
+	}
+	if tokens, err = fn(tokens); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(apiTokensPath(), data, 0o600)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + hex.EncodeToString(b), nil
+}
+
+// authConfigured reports whether any way of authenticating is set up.
+func authConfigured() bool {
+	tokens, err := loadAPITokens()
+	return len(tokens) > 0 || err != nil || len(cfg.Server.Tokens) > 0 || cfg.Server.BasicAuth.User != ""
+}
+
+// apiAuth is decided once when the server starts listening: once authentication is required it stays
+// required, so removing the last token locks the API instead of opening it.
+type apiAuth struct {
+	required bool
+}
+
+// checkListenAuth decides whether the API needs authentication and refuses to serve a non-loopback
+// address without it.
+func checkListenAuth(addr string) (apiAuth, error) {
+	if authConfigured() {
+		return apiAuth{required: true}, nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return apiAuth{}, err
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return apiAuth{}, nil
+	}
+	return apiAuth{}, errorf("%s で待ち受けるには認証が必要です。token add <名前> でトークンを発行するか、server.tokens / server.basic_auth を設定してください", addr)
+}
+
+// authorized checks the request's bearer token (or access_token parameter) and basic auth. The
+// token file is read on every request so revoked tokens stop working without a restart; with no
+// credentials left every request is refused.
+func (a apiAuth) authorized(r *http.Request) bool {
+	if !a.required {
+		return true
+	}
+	if user, pass, ok := r.BasicAuth(); ok && cfg.Server.BasicAuth.User != "" {
+		return subtle.ConstantTimeCompare([]byte(user), []byte(cfg.Server.BasicAuth.User)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.Server.BasicAuth.Password)) == 1
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token = strings.TrimSpace(token); token == "" {
+		return false
+	}
+	hash := []byte(hashToken(token))
+	match := false
+	for _, t := range cfg.Server.Tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(hashToken(t))) == 1 {
+			match = true
+		}
+	}
+	stored, _ := loadAPITokens()
+	for _, t := range stored {
+		if subtle.ConstantTimeCompare(hash, []byte(t.Hash)) == 1 {
+			match = true
+		}
+	}
+	return match
+}
+
+func (a apiAuth) require(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.authorized(r) {
+			if cfg.Server.BasicAuth.User != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="GoMusicDownloader"`)
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+func runToken(args []string) error {
+	if len(args) == 0 {
+		return errorf("使い方: token add <名前> | token remove <名前> | token list")
+	}
+	name := strings.TrimSpace(strings.Join(args[1:], " "))
+	switch args[0] {
+	case "add":
+		if name == "" {
+			return errorf("使い方: token add <名前>")
+		}
+		token, err := newAPIToken()
+		if err != nil {
+			return err
+		}
+		err = updateAPITokens(func(tokens []apiToken) ([]apiToken, error) {
+			for _, t := range tokens {
+				if t.Name == name {
+					return nil, errorf("%s という名前のトークンはすでにあります", name)
+				}
+			}
+			return append(tokens, apiToken{Name: name, Hash: hashToken(token), Hint: token[len(token)-4:], Created: time.Now()}), nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(tr("トークン %s を発行しました。この値は二度と表示されないので控えてください:\n%s\n", name, token))
+		return nil
+	case "remove":
+		removed := false
+		err := updateAPITokens(func(tokens []apiToken) ([]apiToken, error) {
+			kept := tokens[:0]
+			for _, t := range tokens {
+				if t.Name == name {
+					removed = true
+					continue
+				}
+				kept = append(kept, t)
+			}
+			return kept, nil
+		})
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return errorf("%s という名前のトークンはありません", name)
+		}
+		fmt.Print(tr("トークン %s を削除しました\n", name))
+		return nil
+	case "list":
+		tokens, err := loadAPITokens()
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			fmt.Println(tr("発行したトークンはありません"))
+		}
+		for _, t := range tokens {
+			fmt.Printf("%-20s …%s  %s\n", t.Name, t.Hint, t.Created.Format("2006-01-02"))
+		}
+		return nil
+	}
+	return errorf("不明な操作です: %s (add / remove / list)", args[0])
+}
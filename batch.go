@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 複数トラックの一括ダウンロード ---
+func markedItems(items []list.Item) []item {
+	var marked []item
+	for _, li := range items {
+		if i, ok := li.(item); ok && i.marked {
+			marked = append(marked, i)
+		}
+	}
+	return marked
+}
+
+// splitCandidates returns the marked tracks, or every track when none are marked.
+func splitCandidates(items []list.Item) []item {
+	if marked := markedItems(items); len(marked) > 0 {
+		return marked
+	}
+	var all []item
+	for _, li := range items {
+		if i, ok := li.(item); ok {
+			all = append(all, i)
+		}
+	}
+	return all
+}
+
+// buildTags fills the tags for a track straight from the MusicBrainz release data.
+func buildTags(releaseInfo MBRelease, track item) finalTags {
+	trackInfo := track.meta.(MBTrack)
+	label, catalogNumber := releaseLabel(releaseInfo)
+	tags := finalTags{
+		Title:         trackInfo.Title,
+		Artist:        track.artist,
+		Album:         releaseInfo.Title,
+		Date:          releaseInfo.Date,
+		OriginalDate:  releaseInfo.ReleaseGroup.FirstReleaseDate,
+		TrackNumber:   trackInfo.Number,
+		AlbumArtist:   track.artist,
+		RecordingID:   trackInfo.Recording.ID,
+		Label:         label,
+		CatalogNumber: catalogNumber,
+		DurationSec:   trackInfo.Length / 1000,
+		Romanize:      cfg.Romanize.Enabled,
+	}
+	if track.artist == joinArtistCredits(releaseInfo.ArtistCredit) {
+		tags.ArtistSort = artistSortName(releaseInfo.ArtistCredit)
+	}
+	tags.DiscNumber, tags.DiscTotal, tags.TrackTotal = trackDiscInfo(releaseInfo, trackInfo.ID)
+	if len(trackInfo.Recording.Genres) > 0 {
+		tags.Genre = trackInfo.Recording.Genres[0].Name
+	}
+	if len(trackInfo.Recording.ISRCs) > 0 {
+		tags.ISRC = trackInfo.Recording.ISRCs[0]
+	}
+	return tags
+}
+
+func (m model) batchActive() bool { return m.batchRunning }
+
+func (m *model) searchBatchTrack() tea.Cmd {
+	track := m.batch[m.batchIndex].track
+	m.selectedMB = m.batch[m.batchIndex].release
+	m.batch[m.batchIndex].status = queueActive
+	if source := m.batch[m.batchIndex].source; source.id != "" {
+		return m.startBatchDownload(source)
+	}
+	m.state = stateSearching
+	m.statusMsg = tr("(%d/%d) 「%s」の音源をYouTubeで検索中です...", m.batchIndex+1, len(m.batch), track.title)
+	return tea.Batch(m.spinner.Tick, searchYouTubeCmd(m.ytDlpPath, fmt.Sprintf("%s %s", track.artist, track.title)))
+}
+
+func (m *model) startBatchDownload(source item) tea.Cmd {
+	track := m.batch[m.batchIndex].track
+	m.selectedYT, m.selectedTrack = source, track
+	m.state = stateDownloading
+	m.statusMsg = tr("(%d/%d) 「%s」をダウンロード中です...", m.batchIndex+1, len(m.batch), track.title)
+	tags := buildTags(m.selectedMB.meta.(MBRelease), track)
+	if t := m.batch[m.batchIndex].tags; t != nil {
+		tags = *t
+	}
+	return tea.Batch(m.spinner.Tick, downloadCmd(m.ytDlpPath, m.ffmpegPath, source, m.selectedMB, tags))
+}
+
+// advanceBatch moves on to the next pending queue item, or shows the summary once the queue is drained.
+func (m *model) advanceBatch() tea.Cmd {
+	if next := m.nextPending(m.batchIndex + 1); next >= 0 {
+		m.batchIndex = next
+		return m.searchBatchTrack()
+	}
+	if cfg.Output.Playlist {
+		if path, err := batchPlaylist(m.batch); err != nil {
+			log.Printf("Playlist: %v", err)
+		} else if path != "" {
+			m.batchLog = append(m.batchLog, "📃 "+path)
+		}
+	}
+	m.state = stateShowSuccess
+	m.lastFile = strings.Join(m.batchLog, "\n")
+	notify := m.desktopNotifyCmd(tr("キューの処理が完了しました"), batchNotice(m.batch))
+	m.batch, m.batchIndex, m.batchRunning = nil, 0, false
+	return notify
+}
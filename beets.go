@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- beets との連携 ---
+// ライブラリを beets で管理している場合に、完成したファイルを beets の取り込み待ちフォルダへ移し、
+// MBIDを書いたマニフェストを添える。import を有効にすると、続けて `beet import -A` も実行する。
+const beetsManifestFile = "yt-music-manifest.jsonl"
+
+type beetsConfig struct {
+	// 完成したファイルを移すフォルダ (beets の取り込み待ち)。空なら移さない
+	StagingDir string `json:"staging_dir"`
+	// ダウンロードのたびに `beet import -A` で取り込む (タグは付け直さない)
+	Import bool `json:"import"`
+	// beet コマンドのパス。空なら PATH から探す
+	BeetPath string `json:"beet_path"`
+}
+
+// beetsManifestEntry is one line of the staging manifest, so a beets plugin or script can match the
+// staged file to its MusicBrainz release and recording without re-identifying it.
+type beetsManifestEntry struct {
+	Path        string    `json:"path"`
+	Time        time.Time `json:"time"`
+	Title       string    `json:"title"`
+	Artist      string    `json:"artist"`
+	Album       string    `json:"album,omitempty"`
+	ReleaseID   string    `json:"mb_albumid,omitempty"`
+	RecordingID string    `json:"mb_trackid,omitempty"`
+	ISRC        string    `json:"isrc,omitempty"`
+	VideoID     string    `json:"video_id,omitempty"`
+}
+
+func (c beetsConfig) enabled() bool { return c.StagingDir != "" || c.Import }
+
+// handOffToBeets stages and/or imports a finished file and returns where it now lives. Files outside
+// the downloads folder (retagged in place by the tagger) are left alone. Failures are logged and
+// leave the file where it was.
+func handOffToBeets(finalPath string, job convertJob, selectedYT, selectedMB item) string {
+	if !cfg.Beets.enabled() || !inLibrary(finalPath) {
+		return finalPath
+	}
+	path := finalPath
+	if cfg.Beets.StagingDir != "" {
+		staged, err := stageForBeets(finalPath)
+		if err != nil {
+			log.Printf("Beets: failed to stage %s: %v", finalPath, err)
+			return finalPath
+		}
+		path = staged
+		entry := beetsManifestEntry{
+			Path: staged, Time: time.Now(), Title: job.tags.Title, Artist: job.tags.Artist, Album: job.tags.Album,
+			ReleaseID: mbReleaseID(selectedMB.id), RecordingID: job.tags.RecordingID, ISRC: job.tags.ISRC, VideoID: selectedYT.id,
+		}
+		if err := appendBeetsManifest(entry); err != nil {
+			log.Printf("Beets: failed to write manifest: %v", err)
+		}
+	}
+	if cfg.Beets.Import {
+		if err := beetImport(path); err != nil {
+			log.Printf("Beets: %v", err)
+		}
+	}
+	return path
+}
+
+// stageForBeets moves the file (and its .lrc) into the staging folder, keeping the artist/album
+// folders from the naming template so beets sees albums together.
+func stageForBeets(finalPath string) (string, error) {
+	rel, err := filepath.Rel(filepath.Join(mainDir, downloadsDir), finalPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(finalPath)
+	}
+	staged := filepath.Join(cfg.Beets.StagingDir, rel)
+	if err := os.MkdirAll(filepath.Dir(staged), os.ModePerm); err != nil {
+		return "", err
+	}
+	if err := moveFile(finalPath, staged); err != nil {
+		return "", err
+	}
+	lrc := strings.TrimSuffix(finalPath, filepath.Ext(finalPath)) + ".lrc"
+	if _, err := os.Stat(lrc); err == nil {
+		if err := moveFile(lrc, strings.TrimSuffix(staged, filepath.Ext(staged))+".lrc"); err != nil {
+			log.Printf("Beets: failed to stage %s: %v", lrc, err)
+		}
+	}
+	return staged, nil
+}
+
+// moveFile renames, falling back to copy and delete when the staging folder is on another drive.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+func appendBeetsManifest(e beetsManifestEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(cfg.Beets.StagingDir, beetsManifestFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// beetImport runs `beet import -A -q`: the file is added as tagged here, without beets' own matching.
+func beetImport(path string) error {
+	beet := cfg.Beets.BeetPath
+	if beet == "" {
+		var err error
+		if beet, err = exec.LookPath("beet"); err != nil {
+			return fmt.Errorf("beet not found: %w", err)
+		}
+	}
+	out, err := exec.Command(beet, "import", "-A", "-q", path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("beet import failed: %v\n%s", err, out)
+	}
+	log.Printf("Beets: imported %s", path)
+	return nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- YouTube字幕からの歌詞 ---
+// どのプロバイダにも歌詞が無い場合に、動画の字幕 (無ければ自動生成字幕) をLRCに変換して使う。
+const captionSource = "youtube-captions"
+
+type captionLyricsMsg struct {
+	result lyricsResult
+	err    error
+}
+
+var (
+	vttCueTime = regexp.MustCompile(`^(?:(\d+):)?(\d{2}):(\d{2})\.(\d{3}) --> (?:(\d+):)?(\d{2}):(\d{2})\.(\d{3})`)
+	vttTag     = regexp.MustCompile(`<[^>]*>`)
+	// 字幕に入る [音楽] や ♪ だけの行は歌詞ではない
+	captionNoise = regexp.MustCompile(`^[\[(（［][^\])）］]*[\])）］]$|^[♪♫\s]*$`)
+)
+
+// captionLangs lists subtitle languages to try: the chosen audio track's language first, then Japanese
+// and English. "-orig" is what yt-dlp calls the untranslated auto-captions.
+func captionLangs(yt item) []string {
+	var langs []string
+	if info, ok := yt.meta.(ytDlpVideoInfo); ok {
+		if lang := info.currentAudioTrack().lang; lang != "" {
+			langs = append(langs, lang)
+		}
+	}
+	var out []string
+	for _, l := range append(langs, "ja", "en") {
+		if !containsString(out, l) {
+			out = append(out, l, l+"-orig")
+		}
+	}
+	return out
+}
+
+func fetchCaptionLyricsCmd(ytDlpPath string, yt item) tea.Cmd {
+	return func() tea.Msg {
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return captionLyricsMsg{err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+
+		langs := captionLangs(yt)
+		ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+		defer cancel()
+		cmd := ytDlpCommand(ctx, ytDlpPath, "--skip-download", "--write-subs", "--write-auto-subs",
+			"--sub-langs", strings.Join(langs, ","), "--sub-format", "vtt",
+			"-o", filepath.Join(tmpDir, "captions.%(ext)s"), yt.url)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return captionLyricsMsg{err: errorf("字幕の取得失敗:\n%s", string(out))}
+		}
+		for _, lang := range langs {
+			data, err := os.ReadFile(filepath.Join(tmpDir, "captions."+lang+".vtt"))
+			if err != nil {
+				continue
+			}
+			lrc := vttToLRC(string(data))
+			if lrc == "" {
+				continue
+			}
+			log.Printf("Lyrics: using %s captions of %s", lang, yt.url)
+			res := newLyricsResult(lrc)
+			res.Source = captionSource
+			return captionLyricsMsg{result: res}
+		}
+		return captionLyricsMsg{err: errorf("この動画には字幕がありません")}
+	}
+}
+
+// vttToLRC converts WebVTT cues to LRC lines at each cue's start time. Auto-captions repeat the previous
+// line in every cue as they scroll, so lines that were just emitted are skipped.
+func vttToLRC(vtt string) string {
+	var lines []string
+	var recent []string
+	sc := bufio.NewScanner(strings.NewReader(vtt))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	stamp := ""
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if m := vttCueTime.FindStringSubmatch(line); m != nil {
+			stamp = lrcStamp(m[1], m[2], m[3], m[4])
+			continue
+		}
+		if line == "" {
+			stamp = ""
+			continue
+		}
+		if stamp == "" {
+			continue // WEBVTT ヘッダやキューID
+		}
+		text := strings.TrimSpace(vttTag.ReplaceAllString(line, ""))
+		text = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&nbsp;", " ").Replace(text)
+		if text == "" || captionNoise.MatchString(text) || containsString(recent, text) {
+			continue
+		}
+		lines = append(lines, stamp+text)
+		recent = append(recent, text)
+		if len(recent) > 2 {
+			recent = recent[1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func lrcStamp(h, m, s, ms string) string {
+	var hours, mins, secs, millis int
+	fmt.Sscanf(h, "%d", &hours)
+	fmt.Sscanf(m, "%d", &mins)
+	fmt.Sscanf(s, "%d", &secs)
+	fmt.Sscanf(ms, "%d", &millis)
+	return fmt.Sprintf("[%02d:%02d.%02d]", hours*60+mins, secs, millis/10)
+}
+
+// offerCaptions reports whether the compare screen should offer the captions fallback.
+func (m model) offerCaptions() bool {
+	l := m.pendingTags.Lyrics
+	return l != nil && !l.found() && !l.Instrumental && m.tagFile == ""
+}
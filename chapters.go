@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- チャプター (ライブ・ミックスなどを1ファイルで保存) ---
+// チャプターは ffmpeg のメタデータとして渡し (FLACでは CHAPTERxxx タグ、MP4ではチャプター)、
+// FLACには加えて CUESHEET ブロックを書き込む。output.cue_sheet が有効なら、アルバムを
+// 「1ファイル + .cue」で保管する人向けに、同じ名前の .cue ファイルも書き出す。
+const (
+	flacBlockCuesheet    = 5
+	cuesheetMaxTracks    = 99
+	cuesheetLeadOutNonCD = 255
+	cueFramesPerSec      = 75
+)
+
+type chapter struct {
+	Start, End float64
+	Title      string
+}
+
+// planChapters prefers the video's own chapters and otherwise places the tracks the same way the
+// splitter does. Chapter titles come from MusicBrainz whenever the counts line up.
+func planChapters(ffmpegPath, audioPath string, info ytDlpVideoInfo, tracks []item) ([]chapter, string) {
+	duration, err := probeDuration(ffmpegPath, audioPath)
+	if err != nil {
+		duration = info.Duration
+	}
+	var chapters []chapter
+	method := tr("YouTubeチャプター")
+	if len(info.Chapters) > 1 {
+		for i, c := range info.Chapters {
+			title := c.Title
+			if len(info.Chapters) == len(tracks) {
+				title = tracks[i].meta.(MBTrack).Title
+			}
+			chapters = append(chapters, chapter{Start: c.StartTime, End: c.EndTime, Title: title})
+		}
+	} else {
+		trackInfos := make([]MBTrack, len(tracks))
+		for i, t := range tracks {
+			trackInfos[i] = t.meta.(MBTrack)
+		}
+		var segs []audioSegment
+		segs, method = planSegments(ffmpegPath, audioPath, info, trackInfos)
+		for i, s := range segs {
+			chapters = append(chapters, chapter{Start: s.Start, End: s.End, Title: trackInfos[i].Title})
+		}
+	}
+	if n := len(chapters); n > 0 && (chapters[n-1].End <= 0 || chapters[n-1].End > duration) && duration > 0 {
+		chapters[n-1].End = duration
+	}
+	return chapters, method
+}
+
+// writeFFMetadata writes the chapters in ffmpeg's FFMETADATA format, to be passed with -map_chapters.
+func writeFFMetadata(path string, chapters []chapter) error {
+	esc := strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n")
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	for _, c := range chapters {
+		fmt.Fprintf(&b, "[CHAPTER]\nTIMEBASE=1/1000\nSTART=%d\nEND=%d\ntitle=%s\n",
+			int64(c.Start*1000), int64(c.End*1000), esc.Replace(c.Title))
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// streamInfo reads the sample rate and total sample count from a FLAC STREAMINFO block.
+func streamInfo(data []byte) (sampleRate int, totalSamples uint64, err error) {
+	if len(data) < 18 {
+		return 0, 0, errors.New("short STREAMINFO block")
+	}
+	sampleRate = int(data[10])<<12 | int(data[11])<<4 | int(data[12])>>4
+	totalSamples = uint64(data[13]&0x0f)<<32 | uint64(binary.BigEndian.Uint32(data[14:18]))
+	return sampleRate, totalSamples, nil
+}
+
+// encodeCuesheet builds a non-CD CUESHEET block with one track (and one index point) per chapter.
+func encodeCuesheet(chapters []chapter, sampleRate int, totalSamples uint64) []byte {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 128))                    // media catalog number
+	binary.Write(&buf, binary.BigEndian, uint64(0)) // lead-in
+	buf.Write(make([]byte, 1+258))                  // is_cd = 0 + reserved
+	buf.WriteByte(byte(len(chapters) + 1))          // + lead-out
+	writeTrack := func(offset uint64, number byte, indexed bool) {
+		binary.Write(&buf, binary.BigEndian, offset)
+		buf.WriteByte(number)
+		buf.Write(make([]byte, 12+1+13)) // ISRC, type/pre-emphasis, reserved
+		if !indexed {
+			buf.WriteByte(0)
+			return
+		}
+		buf.WriteByte(1)
+		binary.Write(&buf, binary.BigEndian, uint64(0))
+		buf.Write([]byte{1, 0, 0, 0}) // index 01 + reserved
+	}
+	for i, c := range chapters {
+		writeTrack(uint64(c.Start*float64(sampleRate)), byte(i+1), true)
+	}
+	writeTrack(totalSamples, cuesheetLeadOutNonCD, false)
+	return buf.Bytes()
+}
+
+// writeFLACCuesheet adds a CUESHEET block matching the chapters, so players that read cue sheets
+// rather than CHAPTER tags can seek by track too.
+func writeFLACCuesheet(path string, chapters []chapter) error {
+	if len(chapters) > cuesheetMaxTracks {
+		return fmt.Errorf("too many chapters for a cuesheet (%d)", len(chapters))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	blocks, _, err := readFLACMetadata(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	sampleRate, totalSamples, err := streamInfo(blocks[0].data)
+	if err != nil {
+		return err
+	}
+	if sampleRate == 0 || totalSamples == 0 {
+		return errors.New("unknown stream length")
+	}
+	return replaceFLACBlock(path, flacBlock{kind: flacBlockCuesheet, data: encodeCuesheet(chapters, sampleRate, totalSamples)})
+}
+
+// cueTime formats a position as a cue sheet MM:SS:FF (75 frames per second).
+func cueTime(sec float64) string {
+	frames := int64(sec*cueFramesPerSec + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d", frames/(60*cueFramesPerSec), frames/cueFramesPerSec%60, frames%cueFramesPerSec)
+}
+
+// cueQuote makes a value safe inside a quoted cue sheet field.
+func cueQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "'", "\r", " ", "\n", " ").Replace(s) + `"`
+}
+
+// writeCueFile writes a .cue next to the audio file with one track per chapter. Track performers and
+// ISRCs come from MusicBrainz when the chapters line up with the tracklist. The file is UTF-8 with a
+// BOM, which is how foobar2000 and most rippers tell it from the local code page.
+func writeCueFile(audioPath string, tags finalTags, chapters []chapter, tracks []item, releaseID string) (string, error) {
+	if len(chapters) > cuesheetMaxTracks {
+		return "", fmt.Errorf("too many chapters for a cue sheet (%d)", len(chapters))
+	}
+	var b strings.Builder
+	b.WriteString("\ufeff")
+	if tags.Genre != "" {
+		fmt.Fprintf(&b, "REM GENRE %s\n", cueQuote(tags.Genre))
+	}
+	if year := yearOf(firstNonEmpty(tags.Date, tags.OriginalDate)); year != "" {
+		fmt.Fprintf(&b, "REM DATE %s\n", year)
+	}
+	if releaseID != "" {
+		fmt.Fprintf(&b, "REM MUSICBRAINZ_ALBUMID %s\n", releaseID)
+	}
+	fmt.Fprintf(&b, "PERFORMER %s\nTITLE %s\n", cueQuote(firstNonEmpty(tags.AlbumArtist, tags.Artist)), cueQuote(tags.Album))
+	fmt.Fprintf(&b, "FILE %s WAVE\n", cueQuote(filepath.Base(audioPath)))
+	for i, c := range chapters {
+		fmt.Fprintf(&b, "  TRACK %02d AUDIO\n    TITLE %s\n", i+1, cueQuote(c.Title))
+		if len(chapters) == len(tracks) {
+			t := tracks[i]
+			fmt.Fprintf(&b, "    PERFORMER %s\n", cueQuote(firstNonEmpty(t.artist, tags.Artist)))
+			if isrcs := t.meta.(MBTrack).Recording.ISRCs; len(isrcs) > 0 {
+				fmt.Fprintf(&b, "    ISRC %s\n", strings.ReplaceAll(isrcs[0], "-", ""))
+			}
+		}
+		fmt.Fprintf(&b, "    INDEX 01 %s\n", cueTime(c.Start))
+	}
+	path := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".cue"
+	return path, os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// albumFileTags tags a whole-release recording as a single item named after the release.
+func albumFileTags(releaseInfo MBRelease, first item) finalTags {
+	tags := buildTags(releaseInfo, first)
+	tags.Title = releaseInfo.Title
+	tags.TrackNumber, tags.RecordingID, tags.ISRC = "", "", ""
+	tags.DiscNumber, tags.DiscTotal, tags.TrackTotal, tags.DurationSec = 0, 0, 0, 0
+	return tags
+}
+
+// chapterDownloadCmd keeps the whole upload as one file with a chapter per track.
+func chapterDownloadCmd(ytDlpPath, ffmpegPath string, selectedYT, selectedMB item, tracks []item) tea.Cmd {
+	return func() tea.Msg {
+		releaseInfo := selectedMB.meta.(MBRelease)
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+
+		var tl, coverPhase timeline
+		job := convertJob{audioPath: filepath.Join(tmpDir, "audio.tmp"), tags: albumFileTags(releaseInfo, tracks[0]), timeline: &tl}
+		var dlErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if dlErr = downloadAudio(ytDlpPath, selectedYT, job.audioPath); dlErr == nil {
+				tl.add(tr("ダウンロード"), start, fileSize(job.audioPath))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if job.coverPath, job.coverSrc = fetchCoverArt(tmpDir, releaseInfo); job.coverPath == "" {
+				job.coverPath, job.coverSrc = fetchThumbnailCover(ffmpegPath, tmpDir, selectedYT.id)
+			}
+			coverPhase.add(tr("カバー画像"), start, 0)
+		}()
+		wg.Wait()
+		tl = append(tl, coverPhase...)
+		if dlErr != nil {
+			recordFailure(selectedYT, job.tags, dlErr)
+			return downloadFinishedMsg{err: dlErr}
+		}
+
+		info, _ := selectedYT.meta.(ytDlpVideoInfo)
+		var method string
+		start := time.Now()
+		job.chapters, method = planChapters(ffmpegPath, job.audioPath, info, tracks)
+		tl.add(tr("チャプター検出"), start, 0)
+		log.Printf("Chapters: %d chapters by %s", len(job.chapters), method)
+		job.choosePassthrough(ffmpegPath)
+
+		finalPath, err := convertAudio(ffmpegPath, job)
+		if err != nil {
+			recordFailure(selectedYT, job.tags, err)
+			return downloadFinishedMsg{err: err}
+		}
+		finalPath = recordDownload(finalPath, job, selectedYT, selectedMB)
+		note := tr("%d個のチャプター付き, %s", len(job.chapters), method)
+		if cfg.Output.CueSheet {
+			if _, err := writeCueFile(finalPath, job.tags, job.chapters, tracks, mbReleaseID(selectedMB.id)); err != nil {
+				log.Printf("Chapters: failed to write .cue for %s: %v", finalPath, err)
+			} else {
+				note += tr(", .cue付き")
+			}
+		}
+		queueUpload(finalPath, job.tags)
+		log.Printf("Timeline: %s", tl)
+		return downloadFinishedMsg{filename: fmt.Sprintf("%s\n(%s)", finalPath, note), files: []string{finalPath}, warning: liveWarning(ffmpegPath, selectedYT, job.audioPath), timeline: tl}
+	}
+}
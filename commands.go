@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --- サブコマンド ---
+type subcommand struct {
+	name, usage string
+	run         func(args []string) error
+}
+
+var subcommands = []subcommand{
+	{"export-report", "ライブラリのレポートをHTML/Markdownで出力します", runExportReport},
+	{"export-manifest", "履歴から共有用のマニフェスト (音声なし) を出力します", runExportManifest},
+	{"import-manifest", "マニフェストの曲を自分の環境でダウンロードします", runImportManifest},
+	{"daemon", "inbox の検索語を自動照合してダウンロードし続けます", runDaemon},
+	{"doctor", "動作環境を診断します (-fix で自動修復)", runDoctor},
+	{"tag", "手持ちの音声ファイルをMusicBrainzの情報でタグ付けし直します", runTagFile},
+	{"import", "フォルダの音声ファイルを1曲ずつ照合・タグ付けしてライブラリに取り込みます", runImportFolder},
+	{"library", "downloads フォルダを走査して索引を更新し、統計と重複を表示します", runLibrary},
+	{"watch", "新譜を確認するアーティストを登録・削除・一覧します (add / remove / list)", runWatch},
+	{"check-new", "登録したアーティストの新譜を MusicBrainz で確認します", runCheckNew},
+	{"subscribe", "新着を自動でダウンロードするYouTubeチャンネルを登録・削除・一覧します (add / remove / list / check)", runSubscribe},
+	{"token", "daemon の API で使うトークンを発行・削除・一覧します (add / remove / list)", runToken},
+	{"upgrade-lyrics", "同期歌詞のない曲をlrclibで探し直し、見つかれば埋め込みます", runUpgradeLyrics},
+}
+
+func runSubcommand(name string, args []string) error {
+	for _, c := range subcommands {
+		if c.name == name {
+			return c.run(args)
+		}
+	}
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return nil
+	}
+	printUsage()
+	return errorf("不明なコマンドです: %s", name)
+}
+
+func printUsage() {
+	var b strings.Builder
+	b.WriteString(tr("使い方: go-music-downloader [--yt-dlp-args \"引数\"] [コマンド] [オプション]\n\n引数なしで起動するとTUIを開きます。\n\nコマンド:\n"))
+	for _, c := range subcommands {
+		b.WriteString(fmt.Sprintf("  %-16s %s\n", c.name, tr(c.usage)))
+	}
+	b.WriteString(tr("\n  --yt-dlp-args はすべての yt-dlp 呼び出しに付け加える引数です (例: --yt-dlp-args \"--limit-rate 2M\")\n"))
+	fmt.Fprint(os.Stderr, b.String())
+}
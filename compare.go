@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- YouTube音源とMusicBrainzトラックの比較画面 ---
+var compareLabelStyle, compareOKStyle, compareBadStyle lipgloss.Style
+
+type compareRow struct{ label, value string }
+
+func comparePane(title string, color lipgloss.Color, width int, rows []compareRow) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Foreground(color).Bold(true).Render(title) + "\n\n")
+	for _, r := range rows {
+		b.WriteString(compareLabelStyle.Render(r.label) + " " + lipgloss.NewStyle().Width(width-16).Render(r.value) + "\n")
+	}
+	return lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(color).Padding(0, 1).Width(width).Render(b.String())
+}
+
+// compareView shows the chosen YouTube source next to the MusicBrainz track with per-field verdicts.
+func (m model) compareView() string {
+	info, _ := m.selectedYT.meta.(ytDlpVideoInfo)
+	trackInfo, _ := m.selectedTrack.meta.(MBTrack)
+	tags := m.pendingTags
+	paneWidth := (m.width - 10) / 2
+
+	ytDuration := tr("不明")
+	if info.Duration > 0 {
+		ytDuration = formatDuration(int(math.Round(info.Duration)))
+	}
+	mbDuration := tr("不明")
+	if trackInfo.Length > 0 {
+		mbDuration = formatDuration(trackInfo.Length / 1000)
+	}
+	ytRows := []compareRow{
+		{tr("タイトル"), m.selectedYT.title},
+		{tr("チャンネル"), m.selectedYT.desc},
+		{tr("長さ"), ytDuration},
+		{"URL", m.selectedYT.url},
+	}
+	if tracks := info.audioTracks(); len(tracks) > 1 {
+		cur := info.currentAudioTrack()
+		ytRows = append(ytRows, compareRow{tr("音声"), tr("%s (%d種類中, l で切替)", cur.label, len(tracks))})
+	}
+	source := "YouTube"
+	if m.tagFile != "" {
+		source = strings.TrimSpace(tr("ファイル %s", m.importProgress()))
+	}
+	left := comparePane(source, redColor, paneWidth, ytRows)
+	right := comparePane("MusicBrainz", purpleColor, paneWidth, []compareRow{
+		{tr("タイトル"), tags.Title},
+		{tr("アーティスト"), tags.Artist},
+		{tr("アルバム"), tags.Album},
+		{tr("長さ"), mbDuration},
+		{tr("トラック"), tags.TrackNumber},
+	})
+
+	score := scoreMatch(m.selectedYT, m.selectedTrack)
+	verdict := func(label string, v float64) string {
+		style := compareOKStyle
+		mark := "✓"
+		if v < 0.6 {
+			style, mark = compareBadStyle, "✗"
+		}
+		return style.Render(fmt.Sprintf("%s %s %d%%", mark, label, pct(v)))
+	}
+	summary := strings.Join([]string{verdict(tr("タイトル"), score.Title), verdict(tr("アーティスト"), score.Artist), verdict(tr("長さ"), score.Duration)}, "   ")
+
+	var details strings.Builder
+	details.WriteString(summary + "\n")
+	if note := liveNote(m.selectedYT); note != "" {
+		details.WriteString("\n" + lipgloss.NewStyle().Foreground(yellowColor).Bold(true).Render(note) + "\n")
+	}
+	if w := durationMismatchWarning(m.selectedYT, m.selectedTrack); w != "" {
+		details.WriteString("\n" + lipgloss.NewStyle().Foreground(yellowColor).Bold(true).Render(w) + "\n")
+	}
+	if dups := m.libIndex.duplicatesOf(tags); len(dups) > 0 && (m.tagFile == "" || m.importing()) {
+		w := tr("⚠ ライブラリに同じ曲があります: %s", dups[0].Path)
+		if len(dups) > 1 {
+			w += tr(" ほか%d件", len(dups)-1)
+		}
+		details.WriteString("\n" + lipgloss.NewStyle().Foreground(yellowColor).Render(w) + "\n")
+	}
+	if tags.Romanize {
+		details.WriteString(fmt.Sprintf("\n%s %s\n", helpStyle.Render(tr("ローマ字:")), trackFilename(tags, "")))
+	}
+	if tags.Trim.active() {
+		details.WriteString(fmt.Sprintf("\n%s %s\n", helpStyle.Render(tr("トリム:")), tags.Trim))
+	}
+	if l := tags.Lyrics; l != nil {
+		details.WriteString(fmt.Sprintf("\n%s %s\n", helpStyle.Render(tr("歌詞:")), l.status()))
+		if m.lyricsNote != "" {
+			details.WriteString(lipgloss.NewStyle().Foreground(yellowColor).Render(m.lyricsNote) + "\n")
+		} else if m.offerCaptions() {
+			details.WriteString(helpStyle.Render(tr("c で動画の字幕から同期歌詞を作成できます")) + "\n")
+		}
+	}
+	details.WriteString("\n" + tr("この組み合わせでダウンロードしますか？") + "\n")
+	bottom := details.String()
+	if previewProtocol() != previewOff {
+		art := lipgloss.NewStyle().Width(previewCols).Height(previewRows).Render(m.previewNote)
+		if m.coverPreview != nil {
+			art = renderPreview(m.coverPreview)
+		}
+		cover := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(pinkColor).Render(
+			art + "\n" + helpStyle.Width(previewCols).Render(m.previewNote))
+		bottom = lipgloss.JoinHorizontal(lipgloss.Top, cover, "  ", bottom)
+	}
+	return "\n" + lipgloss.JoinHorizontal(lipgloss.Top, left, "  ", right) + "\n\n" + bottom
+}
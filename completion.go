@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- アルバムの収集率 ---
+// MusicBrainzのリリース一覧に、そのリリースから保存済みの曲の割合をバッジで表示する。
+
+// ownedIndex is read from the history (checking the files) on first use and then kept current by
+// recordDownload, so the release lists don't reread the history on every page.
+var ownedIndex struct {
+	sync.Mutex
+	built  bool
+	tracks map[string]map[string]bool // リリースID → 曲 (録音ID、無ければパス)
+}
+
+// ownedTracks counts, per release ID, the distinct tracks saved from it whose files still exist
+// (here or at a destination).
+func ownedTracks() map[string]int {
+	ownedIndex.Lock()
+	defer ownedIndex.Unlock()
+	if !ownedIndex.built {
+		entries, err := loadHistory()
+		if err != nil {
+			log.Printf("Completion: failed to load history: %v", err)
+			return nil
+		}
+		ownedIndex.tracks = map[string]map[string]bool{}
+		for _, e := range entries {
+			if _, err := os.Stat(e.Path); err == nil || len(e.Remote) > 0 {
+				addOwnedLocked(e)
+			}
+		}
+		ownedIndex.built = true
+	}
+	owned := make(map[string]int, len(ownedIndex.tracks))
+	for id, tracks := range ownedIndex.tracks {
+		owned[id] = len(tracks)
+	}
+	return owned
+}
+
+// noteOwned adds a track just saved to the counts, once they have been built.
+func noteOwned(e historyEntry) {
+	ownedIndex.Lock()
+	defer ownedIndex.Unlock()
+	if ownedIndex.built {
+		addOwnedLocked(e)
+	}
+}
+
+func addOwnedLocked(e historyEntry) {
+	if e.ReleaseID == "" || e.status() != statusOK {
+		return
+	}
+	tracks := ownedIndex.tracks[e.ReleaseID]
+	if tracks == nil {
+		tracks = map[string]bool{}
+		ownedIndex.tracks[e.ReleaseID] = tracks
+	}
+	tracks[firstNonEmpty(e.RecordingID, e.Path)] = true
+}
+
+func releaseTrackCount(r MBRelease) int {
+	total := 0
+	for _, m := range r.Media {
+		total += m.TrackCount
+	}
+	return total
+}
+
+// completionBadge renders "3/12 25%", in green once the release is complete. Releases with nothing
+// saved get no badge.
+func completionBadge(owned, total int) string {
+	if owned == 0 || total == 0 {
+		return ""
+	}
+	owned = min(owned, total)
+	style := lipgloss.NewStyle().Foreground(yellowColor)
+	mark := "◐"
+	if owned == total {
+		style, mark = lipgloss.NewStyle().Foreground(greenColor).Bold(true), "●"
+	}
+	return style.Render(fmt.Sprintf("%s %d/%d %d%%", mark, owned, total, owned*100/total))
+}
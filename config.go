@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// --- 設定ファイル ---
+const configFile = "config.json"
+
+type config struct {
+	MusicBrainz   mbConfig            `json:"musicbrainz"`
+	Library       libraryConfig       `json:"library"`
+	Warnings      warningConfig       `json:"warnings"`
+	Preview       previewConfig       `json:"preview"`
+	Cover         coverConfig         `json:"cover"`
+	Lyrics        lyricsConfig        `json:"lyrics"`
+	Naming        namingConfig        `json:"naming"`
+	Cache         cacheConfig         `json:"cache"`
+	Auto          autoConfig          `json:"auto"`
+	Network       networkConfig       `json:"network"`
+	Cookies       cookiesConfig       `json:"cookies"`
+	YtDlp         ytDlpConfig         `json:"yt_dlp"`
+	Download      downloadConfig      `json:"download"`
+	Output        outputConfig        `json:"output"`
+	Live          liveConfig          `json:"live"`
+	Beets         beetsConfig         `json:"beets"`
+	Retry         retryConfig         `json:"retry"`
+	ListenBrainz  listenBrainzConfig  `json:"listenbrainz"`
+	LastFM        lastfmConfig        `json:"lastfm"`
+	Spotify       spotifyConfig       `json:"spotify"`
+	Watch         watchConfig         `json:"watch"`
+	Subscriptions subscriptionsConfig `json:"subscriptions"`
+	Webhook       webhookConfig       `json:"webhook"`
+	Notify        notifyConfig        `json:"notify"`
+	Hooks         hooksConfig         `json:"hooks"`
+	MediaServer   mediaServerConfig   `json:"media_server"`
+	Destinations  destinationsConfig  `json:"destinations"`
+	Schedule      scheduleConfig      `json:"schedule"`
+	Server        serverConfig        `json:"server"`
+	Remote        remoteConfig        `json:"remote"`
+	UI            uiConfig            `json:"ui"`
+	Keys          keysConfig          `json:"keys"`
+	Search        searchConfig        `json:"search"`
+	Romanize      romanizeConfig      `json:"romanize"`
+}
+
+type cacheConfig struct {
+	// MusicBrainzの応答とカバー画像を cache フォルダに保存して再利用する
+	Enabled bool `json:"enabled"`
+	// キャッシュの有効期限 (時間)
+	TTLHours int `json:"ttl_hours"`
+}
+
+type namingConfig struct {
+	// downloads フォルダ内の保存先。"/" でフォルダを区切る (拡張子は自動)
+	// 使える値: {Artist} {AlbumArtist} {Album} {Title} {Track} {Disc} {Genre} {Year} {OrigYear} {Date}
+	Template string `json:"template"`
+}
+
+type lyricsConfig struct {
+	// 歌詞を探すプロバイダの順番: lrclib / genius / musixmatch / netease
+	Providers []string `json:"providers"`
+	// Genius API のアクセストークン (空なら genius はスキップ)
+	GeniusToken string `json:"genius_token"`
+	// Musixmatch API キー (空なら musixmatch はスキップ。無料プランは歌詞の一部のみ)
+	MusixmatchKey string `json:"musixmatch_key"`
+	// 同期歌詞 (タイムスタンプ付き) の .lrc ファイル出力: off / also (埋め込みと両方) / only (.lrc のみ)
+	LRCSidecar string `json:"lrc_sidecar"`
+	// 同期歌詞を埋め込むタグ名。"LYRICS" にすると通常の歌詞の代わりに同期歌詞を入れる。空なら埋め込まない
+	SyncedTag string `json:"synced_tag"`
+	// 歌詞が見つかったとき、埋め込む前に確認・編集画面を自動で開く (既定は無効。比較画面の e でいつでも開ける)
+	Review bool `json:"review"`
+}
+
+type coverConfig struct {
+	// カバー画像の取得元を試す順番: caa (Cover Art Archive) / itunes (iTunes Search API, 最大3000px)
+	Providers []string `json:"providers"`
+	// Cover Art Archive から取得するサイズ: 250 / 500 / 1200 / original
+	CAASize string `json:"caa_size"`
+	// 埋め込み前に縮小する一辺のピクセル数。0 で縮小しない
+	MaxSide int `json:"max_side"`
+	// PNGの画像をJPEGに変換して埋め込む
+	ConvertPNG bool `json:"convert_png"`
+	// 埋め込む画像の最大サイズ (KB)。超える場合は品質と解像度を下げる。0 で無制限
+	MaxEmbedKB int `json:"max_embed_kb"`
+}
+
+type previewConfig struct {
+	// カバープレビューの描画方式: auto / kitty / iterm2 / sixel / blocks / off
+	Protocol string `json:"protocol"`
+}
+
+type libraryConfig struct {
+	// ライブラリ (downloads フォルダ) の上限サイズ。0 は無制限
+	MaxSizeMB int64 `json:"max_size_mb"`
+	// 履歴で付けた評価とメモをFLACの RATING (1〜5) / COMMENT タグにも書き込む
+	RatingTags bool `json:"rating_tags"`
+	// ライブラリブラウザの並び順の言語 (例: ja, en)
+	SortLocale string `json:"sort_locale"`
+	// ライブラリブラウザのグループ分け: artist / album / year
+	GroupBy string `json:"group_by"`
+}
+
+type mbConfig struct {
+	// タグ機能ごとのON/OFF。無効な機能の inc= や追加リクエストは送信しない
+	ISRCTags    bool `json:"isrc_tags"`
+	LabelTags   bool `json:"label_tags"`
+	CreditTags  bool `json:"credit_tags"`
+	GenreTags   bool `json:"genre_tags"`
+	AliasLookup bool `json:"alias_lookup"`
+	// ARTISTSORT / TITLESORT などの並び替え用タグにローマ字の読みを書く (アーティストの別名も取得する)
+	SortTags bool `json:"sort_tags"`
+	// リリース取得時に追加する任意の inc= パラメータ (例: "url-rels")
+	ExtraIncludes []string `json:"extra_includes"`
+}
+
+type warningConfig struct {
+	// YouTubeとMusicBrainzの長さの差がこの秒数を超えたら警告する。0 で無効
+	DurationMismatchSec int `json:"duration_mismatch_sec"`
+}
+
+var cfg = defaultConfig()
+
+func defaultConfig() config {
+	return config{
+		MusicBrainz: mbConfig{
+			ISRCTags:   true,
+			LabelTags:  true,
+			CreditTags: true,
+			SortTags:   true,
+		},
+		Library:  libraryConfig{SortLocale: "ja", GroupBy: libraryGroupArtist},
+		Warnings: warningConfig{DurationMismatchSec: 10},
+		Preview:  previewConfig{Protocol: previewAuto},
+		Cover: coverConfig{
+			Providers:  []string{coverProviderCAA, coverProviderITunes},
+			CAASize:    "500",
+			MaxSide:    1200,
+			ConvertPNG: true,
+		},
+		Naming:        namingConfig{Template: defaultNamingTemplate},
+		Cache:         cacheConfig{Enabled: true, TTLHours: defaultCacheTTL},
+		Auto:          autoConfig{AcceptScore: 0.8, MinScore: matchMinScore},
+		Network:       networkConfig{TimeoutSec: defaultHTTPTimeout},
+		Download:      downloadConfig{Stream: true},
+		Output:        outputConfig{Format: outputFLAC, FLACCompression: defaultFLACCompression},
+		Live:          liveConfig{FromStart: true, MaxMinutes: 240},
+		Watch:         watchConfig{IntervalHours: defaultWatchIntervalHours, Types: []string{"album", "ep", "single"}},
+		Subscriptions: subscriptionsConfig{IntervalHours: defaultSubsInterval, Limit: defaultSubsLimit},
+		Webhook:       webhookConfig{OnSuccess: true, OnFailure: true},
+		Notify:        notifyConfig{Desktop: desktopNotifyOff},
+		Hooks:         hooksConfig{TimeoutSec: defaultHookTimeout},
+		MediaServer:   mediaServerConfig{DelaySec: defaultMediaRefreshDelay},
+		Destinations:  destinationsConfig{KeepLocal: true},
+		Retry:         retryConfig{Attempts: defaultRetryAttempts, BackoffSec: defaultRetryBackoffSec, MaxBackoffSec: defaultRetryMaxBackoffSec},
+		Lyrics: lyricsConfig{
+			Providers:  []string{"lrclib", "genius", "musixmatch", "netease"},
+			LRCSidecar: lrcSidecarAlso,
+			SyncedTag:  "SYNCEDLYRICS",
+		},
+		UI:     uiConfig{Language: langAuto, Theme: themeDark},
+		Search: searchConfig{YouTubeResults: defaultYouTubeResults, MusicBrainzResults: defaultMusicBrainzResults},
+	}
+}
+
+func configPath() string { return filepath.Join(mainDir, configFile) }
+
+// loadConfig reads the config file over the defaults, writing a default file on first run.
+func loadConfig() (config, error) {
+	c := defaultConfig()
+	data, err := os.ReadFile(configPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return c, saveConfig(c)
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+func saveConfig(c config) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath(), data, 0o644)
+}
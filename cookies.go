@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Cookie (年齢制限・メンバー限定の動画) ---
+// yt-dlp にブラウザのCookieを渡し、ログインした状態で取得する。
+type cookiesConfig struct {
+	// Cookieを読み込むブラウザ: chrome / firefox / edge / brave / safari など。"firefox:プロファイル名" も可
+	FromBrowser string `json:"from_browser"`
+	// Netscape形式の cookies.txt のパス (from_browser が空の場合に使う)
+	File string `json:"file"`
+}
+
+var cookieBrowsers = []string{"chrome", "firefox", "edge", "brave", "safari", "chromium", "opera", "vivaldi"}
+
+// cookieErrorHints are fragments of yt-dlp errors that go away when signed in.
+var cookieErrorHints = []string{
+	"sign in to confirm your age", "age-restricted", "inappropriate for some users",
+	"members-only", "join this channel", "available to this channel's members",
+	"sign in to confirm you're not a bot", "sign in to confirm you’re not a bot", "--cookies",
+}
+
+func cookieArgs() []string {
+	switch {
+	case cfg.Cookies.FromBrowser != "":
+		return []string{"--cookies-from-browser", cfg.Cookies.FromBrowser}
+	case cfg.Cookies.File != "":
+		return []string{"--cookies", cfg.Cookies.File}
+	}
+	return nil
+}
+
+func needsCookies(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, h := range cookieErrorHints {
+		if strings.Contains(msg, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable remembers cmd, started from the current state, so it can be rerun with cookies.
+func (m *model) retryable(cmd tea.Cmd) tea.Cmd {
+	m.cookieRetry, m.cookieState = cmd, m.state
+	return cmd
+}
+
+// promptCookies opens the browser picker when err is one cookies would fix.
+func (m *model) promptCookies(err error) bool {
+	if m.cookieRetry == nil || !needsCookies(err) {
+		return false
+	}
+	m.cookieErr, m.state = err, stateCookies
+	m.cookieCursor = 0
+	for i, b := range cookieBrowsers {
+		if strings.HasPrefix(cfg.Cookies.FromBrowser, b) {
+			m.cookieCursor = i
+		}
+	}
+	return true
+}
+
+// updateCookies handles keys on the cookie prompt. Enter uses the browser for this session, s also
+// writes it to config.json.
+func (m *model) updateCookies(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		m.cookieCursor = (m.cookieCursor + len(cookieBrowsers) - 1) % len(cookieBrowsers)
+	case "down", "j":
+		m.cookieCursor = (m.cookieCursor + 1) % len(cookieBrowsers)
+	case "enter", "s":
+		cfg.Cookies.FromBrowser = cookieBrowsers[m.cookieCursor]
+		if msg.String() == "s" {
+			if err := saveConfig(cfg); err != nil {
+				log.Printf("Config: failed to save cookies setting: %v", err)
+			}
+		}
+		log.Printf("Cookies: retrying with cookies from %s", cfg.Cookies.FromBrowser)
+		m.state, m.statusMsg = m.cookieState, tr("%s のCookieを使って再試行中です...", cfg.Cookies.FromBrowser)
+		return tea.Batch(m.spinner.Tick, m.cookieRetry)
+	case "esc":
+		m.state, m.error = stateError, m.cookieErr
+	}
+	return nil
+}
+
+func (m model) cookiesView() string {
+	var b strings.Builder
+	b.WriteString("\n" + listTitleStyle.Render(tr("ログインが必要な動画です")) + "\n\n")
+	b.WriteString(helpStyle.Render(tr("  年齢制限またはメンバー限定のため取得できませんでした。\n  YouTubeにログイン済みのブラウザを選ぶと、そのCookieを使って再試行します。")) + "\n\n")
+	for i, name := range cookieBrowsers {
+		cursor, style := "  ", lipgloss.NewStyle()
+		if i == m.cookieCursor {
+			cursor = lipgloss.NewStyle().Foreground(cyanColor).Render("> ")
+			style = style.Foreground(cyanColor).Bold(true)
+		}
+		b.WriteString(fmt.Sprintf("  %s%s\n", cursor, style.Render(name)))
+	}
+	if m.cookieErr != nil {
+		detail := firstLine(m.cookieErr.Error())
+		for _, line := range strings.Split(m.cookieErr.Error(), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "ERROR:") {
+				detail = strings.TrimSpace(line)
+				break
+			}
+		}
+		b.WriteString("\n" + helpStyle.Render("  "+detail) + "\n")
+	}
+	return b.String()
+}
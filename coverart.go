@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- カバー画像の処理 ---
+const (
+	coverMinSide        = 100 // これより小さい画像はエラーページ等とみなして破棄
+	coverJPEGQuality    = 90
+	coverMinJPEGQuality = 50 // 容量上限に収めるときに下げる品質の下限
+)
+
+// prepareCover validates that the downloaded file really is an image (not an HTML error page), crops it
+// to a centered square, downsizes it to cover.max_side and re-encodes it. PNGs stay PNG only when
+// cover.convert_png is off. The returned path has the extension matching the final format.
+func prepareCover(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	ct := http.DetectContentType(data)
+	if ct != "image/jpeg" && ct != "image/png" {
+		return "", fmt.Errorf("cover is %s, not an image", ct)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("cover decode failed: %w", err)
+	}
+	b := img.Bounds()
+	if b.Dx() < coverMinSide || b.Dy() < coverMinSide {
+		return "", fmt.Errorf("cover too small: %dx%d", b.Dx(), b.Dy())
+	}
+	if cfg.Cover.MaxSide > 0 {
+		img = resizeSquare(cropSquare(img), cfg.Cover.MaxSide)
+	} else {
+		img = cropSquare(img)
+	}
+
+	out, asPNG, err := encodeCover(img, ct == "image/png" && !cfg.Cover.ConvertPNG, cfg.Cover.MaxEmbedKB*1024)
+	if err != nil {
+		return "", err
+	}
+	finalPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".jpg"
+	if asPNG {
+		finalPath = strings.TrimSuffix(path, filepath.Ext(path)) + ".png"
+	}
+	if finalPath != path {
+		os.Remove(path)
+	}
+	return finalPath, os.WriteFile(finalPath, out, 0o644)
+}
+
+// encodeCover encodes the image, shrinking it until it fits maxBytes (0 = no limit): first by lowering the
+// JPEG quality, then by halving the resolution. A PNG that doesn't fit is converted to JPEG.
+func encodeCover(img image.Image, asPNG bool, maxBytes int) ([]byte, bool, error) {
+	var buf bytes.Buffer
+	if asPNG {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, false, err
+		}
+		if maxBytes <= 0 || buf.Len() <= maxBytes {
+			return buf.Bytes(), true, nil
+		}
+		log.Printf("Cover: PNG is %d bytes, over the %d byte limit; converting to JPEG", buf.Len(), maxBytes)
+	}
+	for {
+		for quality := coverJPEGQuality; ; quality -= 10 {
+			buf.Reset()
+			if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, false, err
+			}
+			if maxBytes <= 0 || buf.Len() <= maxBytes || quality <= coverMinJPEGQuality {
+				break
+			}
+		}
+		side := img.Bounds().Dx()
+		if maxBytes <= 0 || buf.Len() <= maxBytes || side/2 < coverMinSide {
+			return buf.Bytes(), false, nil
+		}
+		img = resizeSquare(img, side/2)
+	}
+}
+
+func cropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	rect := image.Rect(x0, y0, x0+side, y0+side)
+	if si, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return si.SubImage(rect)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			dst.Set(x, y, img.At(x0+x, y0+y))
+		}
+	}
+	return dst
+}
+
+// resizeSquare downsamples a square image with box filtering; images already small enough are returned as-is.
+func resizeSquare(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	side := b.Dx()
+	if side <= maxSide {
+		return img
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, maxSide, maxSide))
+	scale := float64(side) / float64(maxSide)
+	for dy := 0; dy < maxSide; dy++ {
+		sy0, sy1 := int(float64(dy)*scale), int(float64(dy+1)*scale)
+		for dx := 0; dx < maxSide; dx++ {
+			sx0, sx1 := int(float64(dx)*scale), int(float64(dx+1)*scale)
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < max(sy1, sy0+1); sy++ {
+				for sx := sx0; sx < max(sx1, sx0+1); sx++ {
+					cr, cg, cb, ca := img.At(b.Min.X+sx, b.Min.Y+sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.Set(dx, dy, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// --- YouTubeサムネイルによるフォールバック ---
+var cropdetectRe = regexp.MustCompile(`crop=(\d+):(\d+):(\d+):(\d+)`)
+
+// fetchThumbnailCover is the last resort when Cover Art Archive has nothing: it grabs the video's
+// thumbnail, strips letterbox bars with ffmpeg's cropdetect and crops the remaining picture square.
+func fetchThumbnailCover(ffmpegPath, tmpDir, videoID string) (string, coverSource) {
+	if videoID == "" {
+		return "", coverSourceNone
+	}
+	rawPath, coverPath := filepath.Join(tmpDir, "thumbnail.jpg"), filepath.Join(tmpDir, "cover.jpg")
+	fetched := false
+	for _, name := range []string{"maxresdefault.jpg", "sddefault.jpg", "hqdefault.jpg"} {
+		if downloadFile(fmt.Sprintf("https://i.ytimg.com/vi/%s/%s", videoID, name), rawPath) {
+			fetched = true
+			break
+		}
+	}
+	if !fetched {
+		return "", coverSourceNone
+	}
+	filter := "crop='min(iw,ih)':'min(iw,ih)'"
+	if box := detectCrop(ffmpegPath, rawPath); box != "" {
+		filter = "crop=" + box + "," + filter
+	}
+	out, err := exec.Command(ffmpegPath, "-y", "-i", rawPath, "-vf", filter, "-q:v", "2", coverPath).CombinedOutput()
+	if err != nil {
+		log.Printf("Cover: thumbnail crop failed: %v\n%s", err, out)
+		return "", coverSourceNone
+	}
+	coverPath, err = prepareCover(coverPath)
+	if err != nil {
+		log.Printf("Cover: rejected thumbnail for %s: %v", videoID, err)
+		return "", coverSourceNone
+	}
+	return coverPath, coverSourceYTThumbnail
+}
+
+// detectCrop returns the w:h:x:y box of the non-black area, or "" when there are no bars to remove.
+func detectCrop(ffmpegPath, path string) string {
+	out, _ := exec.Command(ffmpegPath, "-i", path, "-vf", "cropdetect=limit=24:round=2:reset=0", "-f", "null", "-").CombinedOutput()
+	matches := cropdetectRe.FindAllStringSubmatch(string(out), -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	last := matches[len(matches)-1]
+	w, _ := strconv.Atoi(last[1])
+	h, _ := strconv.Atoi(last[2])
+	if w < coverMinSide || h < coverMinSide {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", last[1], last[2], last[3], last[4])
+}
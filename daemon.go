@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// --- daemon (ヘッドレスの自動処理) ---
+// inbox.txt に1行ずつ書かれた検索語やURLを自動照合し、一致度が auto.accept_score 以上ならそのまま
+// ダウンロード、それ未満なら要確認キューに回す。登録したチャンネルの新着も同じように処理する。
+// 受け取った検索語はジョブキュー (jobs.json) に保存してから処理し、SIGTERM / Ctrl+C では処理中の1件を
+// 終えてから止まる (残りは次の起動時に処理する)。schedule でキューの処理や確認の時刻を決められる。
+const inboxFile = "inbox.txt"
+
+type autoConfig struct {
+	// この一致度 (0〜1) 以上の候補は確認なしでダウンロードする
+	AcceptScore float64 `json:"accept_score"`
+	// 要確認キューに候補として残す最低の一致度。これ未満は候補なしとして登録する
+	MinScore float64 `json:"min_score"`
+}
+
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	inbox := fs.String("inbox", filepath.Join(mainDir, inboxFile), tr("検索語・URLを1行ずつ書くファイル"))
+	interval := fs.Duration("interval", 30*time.Second, tr("inbox を確認する間隔"))
+	once := fs.Bool("once", false, tr("inbox を1回処理して終了する"))
+	listen := fs.String("listen", "", tr("進捗イベントを配信するアドレス (例: 127.0.0.1:8080)"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ytCheck := checkYtDlpCmd().(ytDlpCheckResultMsg)
+	if ytCheck.err != nil {
+		return ytCheck.err
+	}
+	ffmpegPath, err := findFfmpeg()
+	if err != nil {
+		return errorf("ffmpegが見つかりません: %w", err)
+	}
+	tasks, err := daemonSchedules(time.Now())
+	if err != nil {
+		return err
+	}
+	if *listen != "" {
+		if err := startProgressServer(*listen, ytCheck.path); err != nil {
+			return err
+		}
+		daemonLog("進捗イベントを http://%s/events で配信します", *listen)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	daemonLog("開始しました (inbox: %s, 自動ダウンロード: 一致度 %d%% 以上)", *inbox, pct(cfg.Auto.AcceptScore))
+	for _, t := range []*scheduledTask{tasks.queue, tasks.subscriptions, tasks.newReleases} {
+		if t != nil {
+			daemonLog("スケジュール %s", t)
+		}
+	}
+	scanCrashedDownloads() // 中断したジョブの一時フォルダを片付ける (ジョブ自体は jobs.json から再開する)
+	if n, err := resumeJobs(); err != nil {
+		return errorf("ジョブキューの読み込みに失敗: %w", err)
+	} else if n > 0 {
+		daemonLog("中断されていたジョブ %d 件を再開します", n)
+	}
+	for {
+		if err := takeInbox(*inbox, addJobs); err != nil {
+			daemonLog("inbox の読み込みに失敗: %v", err)
+		}
+		now := time.Now()
+		if tasks.subscriptions == nil || tasks.subscriptions.due(now) {
+			if err := queueSubscriptionJobs(ytCheck.path, tasks.subscriptions != nil); err != nil {
+				daemonLog("チャンネルの新着の登録に失敗: %v", err)
+			}
+		}
+		if tasks.newReleases != nil && tasks.newReleases.due(now) {
+			if err := queueNewReleaseJobs(); err != nil {
+				daemonLog("新譜の確認に失敗: %v", err)
+			}
+		}
+		// -once ではスケジュールに関係なくすぐ処理する
+		if *once || tasks.queue == nil || tasks.queue.due(now) {
+			runJobs(ctx, ytCheck.path, ffmpegPath)
+			if tasks.queue != nil {
+				daemonLog("スケジュール %s", tasks.queue)
+			}
+		}
+		if *once || ctx.Err() != nil {
+			daemonLog("終了します")
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+		case <-jobsAdded:
+		case <-time.After(*interval):
+		}
+	}
+}
+
+type daemonTasks struct {
+	queue, subscriptions, newReleases *scheduledTask
+}
+
+func daemonSchedules(now time.Time) (daemonTasks, error) {
+	var t daemonTasks
+	var err error
+	s := cfg.Schedule
+	if t.queue, err = newScheduledTask(tr("ジョブキューの処理"), s.Queue, now); err != nil {
+		return t, err
+	}
+	if t.subscriptions, err = newScheduledTask(tr("チャンネルの新着の確認"), s.Subscriptions, now); err != nil {
+		return t, err
+	}
+	t.newReleases, err = newScheduledTask(tr("新譜の確認"), s.NewReleases, now)
+	return t, err
+}
+
+// runJobs works through the queue until it is empty or a stop signal arrives; the job in progress
+// is always finished first.
+func runJobs(ctx context.Context, ytDlpPath, ffmpegPath string) {
+	for ctx.Err() == nil {
+		job, ok, err := nextJob()
+		if err != nil {
+			daemonLog("ジョブキューの更新に失敗: %v", err)
+			return
+		}
+		if !ok {
+			return
+		}
+		setProgressJob(job)
+		if job.Download != nil {
+			processRemoteDownload(ytDlpPath, ffmpegPath, job)
+		} else {
+			processAutoQuery(ytDlpPath, ffmpegPath, job.Query)
+		}
+		setProgressJob(daemonJob{})
+		if err := finishJob(job.ID); err != nil {
+			daemonLog("ジョブキューの更新に失敗: %v", err)
+		}
+		if ctx.Err() != nil {
+			daemonLog("停止の要求を受けました。残りのジョブは次の起動時に処理します")
+		}
+	}
+}
+
+func daemonLog(format string, args ...interface{}) {
+	fmt.Printf("%s %s\n", time.Now().Format("2006-01-02 15:04:05"), tr(format, args...))
+}
+
+// takeInbox hands the pending lines to keep and empties the file once they are kept. The file is
+// renamed first so lines appended while we're reading end up in a fresh inbox instead of being lost;
+// a work file left by a crash is picked up again.
+func takeInbox(path string, keep func([]string) error) error {
+	work := path + ".processing"
+	if _, err := os.Stat(work); err != nil {
+		if err := os.Rename(path, work); err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+	}
+	data, err := os.ReadFile(work)
+	if err != nil {
+		return err
+	}
+	var queries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			queries = append(queries, line)
+		}
+	}
+	if err := keep(queries); err != nil {
+		return err
+	}
+	return os.Remove(work)
+}
+
+// processAutoQuery searches, auto-matches and either downloads the result or files it for review.
+func processAutoQuery(ytDlpPath, ffmpegPath, query string) {
+	var ytItems, mbItems []list.Item
+	var err error
+	reportPhase(phaseSearch, "")
+	if strings.HasPrefix(query, "http") {
+		info := getURLInfoCmd(ytDlpPath, query)().(urlInfoFetchedMsg)
+		if err = info.err; err == nil {
+			ytItems = []list.Item{info.ytItem}
+			mbItems, err = doMusicBrainzSearch(mbQueryFor(info.ytItem))
+		}
+	} else if ytItems, err = doYouTubeSearch(ytDlpPath, query); err == nil {
+		mbItems, err = doMusicBrainzSearch(query)
+	}
+	if err != nil {
+		fileForReview(reviewEntry{Query: query, Reason: firstLine(err.Error())})
+		return
+	}
+	if len(ytItems) == 0 || len(mbItems) == 0 {
+		fileForReview(reviewEntry{Query: query, Reason: tr("検索結果がありません")})
+		return
+	}
+
+	reportPhase(phaseMatch, "")
+	match := autoMatchCmd(ytItems, mbItems)().(autoMatchFinishedMsg)
+	if match.track.meta == nil {
+		reason := tr("候補が見つかりません")
+		if match.err != nil {
+			reason = firstLine(match.err.Error())
+		}
+		fileForReview(reviewEntry{Query: query, Reason: reason})
+		return
+	}
+	if match.score.Total < cfg.Auto.AcceptScore {
+		reason := tr("一致度が低い (%d%%)", pct(match.score.Total))
+		if match.score.Total >= cfg.Auto.MinScore {
+			fileForReview(candidateReview(query, reason, match))
+		} else {
+			fileForReview(reviewEntry{Query: query, Reason: reason})
+		}
+		return
+	}
+
+	tags := buildTags(match.release.meta.(MBRelease), match.track)
+	reportPhase(phaseDownload, match.yt.title)
+	res := downloadCmd(ytDlpPath, ffmpegPath, match.yt, match.release, tags)().(downloadFinishedMsg)
+	if res.err != nil {
+		// 失敗した曲は照合済みの候補ごと要確認キューに残し、TUI からそのままやり直せるようにする
+		reportPhase(phaseFailed, firstLine(res.err.Error()))
+		daemonLog("失敗: %s: %s", query, firstLine(res.err.Error()))
+		fileForReview(candidateReview(query, tr("ダウンロード失敗: %s", firstLine(res.err.Error())), match))
+		return
+	}
+	reportPhase(phaseDone, res.filename)
+	daemonLog("完了: %s → %s (%d%%)", query, res.filename, pct(match.score.Total))
+}
+
+// candidateReview files a query together with its matched video and track, so the review screen
+// can open the candidate directly.
+func candidateReview(query, reason string, match autoMatchFinishedMsg) reviewEntry {
+	trackInfo := match.track.meta.(MBTrack)
+	return reviewEntry{
+		Query: query, Reason: reason, Score: match.score.Total,
+		VideoID: match.yt.id, VideoURL: match.yt.url, VideoTitle: match.yt.title,
+		ReleaseID: match.release.id, ReleaseTitle: match.release.title,
+		RecordingID: trackInfo.Recording.ID, TrackTitle: trackInfo.Title,
+	}
+}
+
+func fileForReview(e reviewEntry) {
+	if err := appendReview(e); err != nil {
+		daemonLog("要確認キューへの登録に失敗: %s: %v", e.Query, err)
+		return
+	}
+	reportPhase(phaseReview, e.Reason)
+	daemonLog("要確認: %s (%s)", e.Query, e.Reason)
+}
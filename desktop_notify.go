@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- デスクトップ通知 ---
+// ダウンロードやキューの処理が終わったときに OS の通知を出す。変換に時間がかかる間に端末を裏に回していても
+// 終わったことに気付けるようにする。Linux は notify-send、macOS は osascript、Windows は PowerShell のトーストを使う。
+const (
+	desktopNotifyAlways    = "always"
+	desktopNotifyUnfocused = "unfocused"
+	desktopNotifyOff       = "off"
+	// Windows のトーストは登録済みのアプリIDが必要なので PowerShell のものを借りる
+	windowsToastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+)
+
+type notifyConfig struct {
+	// デスクトップ通知: off (既定) / always (常に) / unfocused (端末が裏にあるときだけ。端末がフォーカスの報告に対応している場合)
+	Desktop string `json:"desktop"`
+}
+
+// desktopNotifyCmd shows the notification when the settings ask for it (and, for unfocused, the
+// terminal is in the background). It reports nothing back; a missing notifier is only logged.
+func (m model) desktopNotifyCmd(title, body string) tea.Cmd {
+	switch cfg.Notify.Desktop {
+	case desktopNotifyAlways:
+	case desktopNotifyUnfocused:
+		if !m.unfocused {
+			return nil
+		}
+	default:
+		return nil // off (既定)
+	}
+	return func() tea.Msg {
+		if err := desktopNotify(title, body); err != nil {
+			log.Printf("Notify: %v", err)
+		}
+		return nil
+	}
+}
+
+func desktopNotify(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(body), appleScriptQuote(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript(title, body))
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=GoMusicDownloader", title, body)
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v %s", cmd.Args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func appleScriptQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func windowsToastScript(title, body string) string {
+	return strings.Join([]string{
+		"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+		"$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+		"$x = $t.GetElementsByTagName('text')",
+		"$x.Item(0).AppendChild($t.CreateTextNode(" + powerShellQuote(title) + ")) > $null",
+		"$x.Item(1).AppendChild($t.CreateTextNode(" + powerShellQuote(body) + ")) > $null",
+		"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier(" + powerShellQuote(windowsToastAppID) + ").Show([Windows.UI.Notifications.ToastNotification]::new($t))",
+	}, "; ")
+}
+
+// batchNotice summarises a finished queue for the notification.
+func batchNotice(items []queueItem) string {
+	var done, failed int
+	for _, q := range items {
+		switch q.status {
+		case queueDone:
+			done++
+		case queueFailed:
+			failed++
+		}
+	}
+	if failed > 0 {
+		return tr("%d曲完了 / %d曲失敗", done, failed)
+	}
+	return tr("%d曲完了", done)
+}
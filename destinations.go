@@ -0,0 +1,400 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- 送り先 (リモート) ---
+// 完成したファイルを downloads フォルダのほかに rclone のリモート・SFTPサーバー・WebDAV の共有・S3 のバケットへ送る。
+// 送り先でのパスは downloads フォルダからの相対パス (命名テンプレートのフォルダ構成) をそのまま使う。
+// keep_local を false にすると、すべての送り先に送れたファイルは手元から消す。
+// 送信は歌詞・.cue・フックなど手元での処理がすべて終わってから裏で順に行い、送り先での場所を履歴に残す。
+const (
+	destRclone = "rclone"
+	destSFTP   = "sftp"
+	destWebDAV = "webdav"
+	// 送信の制限時間 (retry.timeout_sec の rclone / sftp / webdav / s3 で変えられる)
+	defaultUploadTimeout = 30 * time.Minute
+)
+
+type destinationsConfig struct {
+	// ダウンロード後にファイルを送る先。複数指定するとすべてに送る
+	Targets []destinationConfig `json:"targets"`
+	// 送ったあとも downloads フォルダにファイルを残す
+	KeepLocal bool `json:"keep_local"`
+}
+
+type destinationConfig struct {
+	// rclone / sftp / webdav / s3
+	Type string `json:"type"`
+	// rclone: リモートとフォルダ (例: "gdrive:Music")
+	Remote string `json:"remote,omitempty"`
+	// sftp: 接続先 (例: "me@nas.local")。認証は ssh の鍵で行う
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	// sftp: 送り先のフォルダ / s3: キーの先頭に付けるフォルダ
+	Path string `json:"path,omitempty"`
+	// webdav: フォルダのURL (例: "https://cloud.example.com/remote.php/dav/files/me/Music")
+	URL      string `json:"url,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+	// s3: バケット・リージョン・アクセスキー
+	Bucket    string `json:"bucket,omitempty"`
+	Region    string `json:"region,omitempty"`
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key,omitempty"`
+	// s3: AWS 以外 (MinIO など) のエンドポイント (例: "http://nas.local:9000")。空なら AWS
+	Endpoint string `json:"endpoint,omitempty"`
+	// s3: バケット名をホスト名ではなくパスに入れる (エンドポイントを指定した場合は常にそうする)
+	PathStyle bool `json:"path_style,omitempty"`
+	// s3: オブジェクトのキーのテンプレート (naming.template と同じプレースホルダー、拡張子は自動)。空なら downloads フォルダからの相対パス
+	KeyTemplate string `json:"key_template,omitempty"`
+}
+
+// destination is somewhere finished files are copied to.
+type destination interface {
+	String() string
+	upload(f uploadFile) error
+	// location is where the file ends up, for the history
+	location(f uploadFile) string
+}
+
+// uploadFile is one file to send: rel is its path below the downloads folder, with forward slashes,
+// and tags are the track's, for destinations that name files themselves.
+type uploadFile struct {
+	local, rel string
+	tags       finalTags
+}
+
+func newDestination(c destinationConfig) (destination, error) {
+	switch c.Type {
+	case destRclone:
+		if c.Remote == "" {
+			return nil, errorf("rclone の送り先には remote が必要です")
+		}
+		return rcloneDestination{remote: c.Remote}, nil
+	case destSFTP:
+		if c.Host == "" {
+			return nil, errorf("sftp の送り先には host が必要です")
+		}
+		return sftpDestination{host: c.Host, port: c.Port, dir: c.Path}, nil
+	case destWebDAV:
+		if c.URL == "" {
+			return nil, errorf("webdav の送り先には url が必要です")
+		}
+		return webdavDestination{baseURL: strings.TrimRight(c.URL, "/"), user: c.User, password: c.Password}, nil
+	case destS3:
+		return newS3Destination(c)
+	}
+	return nil, errorf("不明な送り先の種類です: %q (rclone / sftp / webdav / s3)", c.Type)
+}
+
+// uploadFiles lists the audio file and the sidecars written next to it.
+func uploadFiles(finalPath string) []string {
+	files := []string{finalPath}
+	stem := strings.TrimSuffix(finalPath, filepath.Ext(finalPath))
+	for _, ext := range []string{".lrc", ".cue"} {
+		if _, err := os.Stat(stem + ext); err == nil {
+			files = append(files, stem+ext)
+		}
+	}
+	return files
+}
+
+// destinationRel is the file's path below the downloads folder, or just its name when it lives
+// elsewhere (a beets staging folder).
+func destinationRel(localPath string) string {
+	rel, err := filepath.Rel(filepath.Join(mainDir, downloadsDir), localPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(localPath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func uploadTimeout(kind string) time.Duration {
+	if t := cfg.Retry.timeout(kind); t > 0 {
+		return t
+	}
+	return defaultUploadTimeout
+}
+
+// uploads is the queue of finished files waiting to be sent. One worker sends them in order, so a
+// slow destination holds up neither the next download nor the TUI.
+var uploads struct {
+	sync.Mutex
+	queue   []uploadJob
+	running bool
+	pending sync.WaitGroup
+}
+
+type uploadJob struct {
+	finalPath string
+	tags      finalTags
+}
+
+// queueUpload sends a finished file in the background. Call it once everything local is done with the
+// file, since it may be removed afterwards.
+func queueUpload(finalPath string, tags finalTags) {
+	if len(cfg.Destinations.Targets) == 0 {
+		return
+	}
+	uploads.Lock()
+	defer uploads.Unlock()
+	uploads.queue = append(uploads.queue, uploadJob{finalPath: finalPath, tags: tags})
+	uploads.pending.Add(1)
+	if !uploads.running {
+		uploads.running = true
+		go runUploads()
+	}
+}
+
+func runUploads() {
+	for {
+		uploads.Lock()
+		if len(uploads.queue) == 0 {
+			uploads.running = false
+			uploads.Unlock()
+			return
+		}
+		job := uploads.queue[0]
+		uploads.queue = uploads.queue[1:]
+		uploads.Unlock()
+		pushToDestinations(job.finalPath, job.tags)
+		uploads.pending.Done()
+	}
+}
+
+// flushUploads waits for the queued uploads, so none is lost when the program exits.
+func flushUploads() {
+	uploads.Lock()
+	waiting := len(uploads.queue)
+	if uploads.running {
+		waiting++
+	}
+	uploads.Unlock()
+	if waiting > 0 {
+		fmt.Print(tr("送り先へのアップロードを待っています (%d件)...\n", waiting))
+	}
+	uploads.pending.Wait()
+}
+
+// pushToDestinations copies a finished download to every configured destination and records where
+// it went. Failures are logged; the local copy is only removed when every upload went through.
+func pushToDestinations(finalPath string, tags finalTags) {
+	targets := cfg.Destinations.Targets
+	if len(targets) == 0 {
+		return
+	}
+	files := uploadFiles(finalPath)
+	var remote []string
+	ok := true
+	for _, c := range targets {
+		d, err := newDestination(c)
+		if err != nil {
+			log.Printf("Destination: %v", err)
+			ok = false
+			continue
+		}
+		uploaded := true
+		for _, f := range files {
+			if err := d.upload(uploadFile{local: f, rel: destinationRel(f), tags: tags}); err != nil {
+				log.Printf("Destination: failed to upload %s to %s: %v", filepath.Base(f), d, err)
+				uploaded = false
+			}
+		}
+		if uploaded {
+			log.Printf("Destination: uploaded %s to %s", filepath.Base(finalPath), d)
+			remote = append(remote, d.location(uploadFile{local: finalPath, rel: destinationRel(finalPath), tags: tags}))
+		}
+		ok = ok && uploaded
+	}
+	if len(remote) > 0 {
+		if err := setHistoryRemote(finalPath, remote); err != nil {
+			log.Printf("History: failed to record the destinations of %s: %v", finalPath, err)
+		}
+	}
+	if !ok || cfg.Destinations.KeepLocal {
+		return
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			log.Printf("Destination: failed to remove %s: %v", f, err)
+		}
+	}
+}
+
+// setHistoryRemote records the destinations of the latest history entry for path.
+func setHistoryRemote(path string, remote []string) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Path == path {
+			entries[i].Remote = remote
+			return saveHistory(entries)
+		}
+	}
+	return nil
+}
+
+type rcloneDestination struct{ remote string }
+
+func (d rcloneDestination) String() string { return d.remote }
+
+func (d rcloneDestination) location(f uploadFile) string {
+	if strings.HasSuffix(d.remote, ":") || strings.HasSuffix(d.remote, "/") {
+		return d.remote + f.rel
+	}
+	return d.remote + "/" + f.rel
+}
+
+func (d rcloneDestination) upload(f uploadFile) error {
+	target := d.location(f)
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout(destRclone))
+	defer cancel()
+	if output, err := exec.CommandContext(ctx, "rclone", "copyto", f.local, target).CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+type sftpDestination struct {
+	host string
+	port int
+	dir  string
+}
+
+func (d sftpDestination) String() string {
+	return "sftp://" + d.host + "/" + strings.TrimPrefix(d.dir, "/")
+}
+
+func (d sftpDestination) remotePath(rel string) string {
+	if d.dir == "" {
+		return rel
+	}
+	return path.Join(d.dir, rel)
+}
+
+func (d sftpDestination) location(f uploadFile) string {
+	return "sftp://" + d.host + "/" + strings.TrimPrefix(d.remotePath(f.rel), "/")
+}
+
+// upload runs sftp in batch mode, creating the folders first ("-" lets mkdir fail when they exist).
+func (d sftpDestination) upload(f uploadFile) error {
+	remote := d.remotePath(f.rel)
+	var script strings.Builder
+	dir := path.Dir(remote)
+	var parents []string
+	for ; dir != "." && dir != "/" && dir != ""; dir = path.Dir(dir) {
+		parents = append([]string{dir}, parents...)
+	}
+	for _, p := range parents {
+		fmt.Fprintf(&script, "-mkdir %s\n", strconv.Quote(p))
+	}
+	fmt.Fprintf(&script, "put %s %s\n", strconv.Quote(f.local), strconv.Quote(remote))
+	args := []string{"-b", "-", "-o", "BatchMode=yes"}
+	if d.port != 0 {
+		args = append(args, "-P", strconv.Itoa(d.port))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout(destSFTP))
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sftp", append(args, d.host)...)
+	cmd.Stdin = strings.NewReader(script.String())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+type webdavDestination struct{ baseURL, user, password string }
+
+func (d webdavDestination) String() string { return d.baseURL }
+
+func (d webdavDestination) location(f uploadFile) string { return d.fileURL(f.rel) }
+
+func (d webdavDestination) fileURL(rel string) string {
+	parts := strings.Split(rel, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return d.baseURL + "/" + strings.Join(parts, "/")
+}
+
+// upload creates the folders with MKCOL and PUTs the file. The request isn't retried, since the body
+// is streamed from disk.
+func (d webdavDestination) upload(f uploadFile) error {
+	client := *httpClient
+	client.Timeout = uploadTimeout(destWebDAV)
+	segments := strings.Split(f.rel, "/")
+	for i := 1; i < len(segments); i++ {
+		req, err := d.request("MKCOL", d.fileURL(strings.Join(segments[:i], "/")), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 はフォルダが既にある
+		if resp.StatusCode >= 300 && resp.StatusCode != http.StatusMethodNotAllowed {
+			return fmt.Errorf("MKCOL %s: %s", strings.Join(segments[:i], "/"), resp.Status)
+		}
+	}
+	file, err := os.Open(f.local)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := d.request("PUT", d.fileURL(f.rel), file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("PUT %s: %s", f.rel, resp.Status)
+	}
+	return nil
+}
+
+func (d webdavDestination) request(method, rawURL string, body *os.File) (*http.Request, error) {
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, rawURL, body)
+	} else {
+		req, err = http.NewRequest(method, rawURL, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	if d.user != "" {
+		req.SetBasicAuth(d.user, d.password)
+	}
+	return req, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- 診断 ---
+type diagCheck struct {
+	label, detail string
+	ok            bool
+	hint          string                 // 失敗時の対処方法
+	fix           func() (string, error) // doctor -fix で実行する修復 (nil なら自動修復不可)
+}
+
+type diagReport struct {
+	tools, network, files []diagCheck
+}
+
+type diagnosticsMsg struct{ report diagReport }
+
+var diagEndpoints = []struct{ label, url string }{
+	{"MusicBrainz", "https://musicbrainz.org/ws/2/"},
+	{"Cover Art Archive", "https://coverartarchive.org/"},
+	{"lrclib", "https://lrclib.net/api/search?q=test"},
+}
+
+func (m *model) openDiagnostics() tea.Cmd {
+	m.state, m.diag = stateDiagnostics, nil
+	return tea.Batch(m.spinner.Tick, runDiagnosticsCmd)
+}
+
+func runDiagnosticsCmd() tea.Msg { return diagnosticsMsg{report: runDiagnostics()} }
+
+func runDiagnostics() diagReport {
+	var r diagReport
+	var wg sync.WaitGroup
+	r.network = make([]diagCheck, len(diagEndpoints))
+	for i, ep := range diagEndpoints {
+		wg.Add(1)
+		go func(i int, label, url string) {
+			defer wg.Done()
+			r.network[i] = checkEndpoint(label, url)
+		}(i, ep.label, ep.url)
+	}
+
+	ytPath := ""
+	if msg, ok := checkYtDlpCmd().(ytDlpCheckResultMsg); ok && msg.err == nil {
+		ytPath = msg.path
+	}
+	ytCheck := checkTool("yt-dlp", ytPath, "--version")
+	if ytPath == "" {
+		ytCheck.hint, ytCheck.fix = tr("pip install -U yt-dlp などでインストールするか、実行ファイルと同じフォルダに配置してください"), installYtDlp
+	} else if !ytCheck.ok {
+		ytCheck.hint, ytCheck.fix = tr("yt-dlp を最新版に更新してください (yt-dlp -U)"), updateYtDlp(ytPath)
+	}
+	r.tools = append(r.tools, ytCheck)
+	ffPath, _ := findFfmpeg()
+	ffCheck := checkTool("ffmpeg", ffPath, "-version")
+	ffCheck.hint = tr("ffmpeg をインストールしてください (例: brew install ffmpeg / winget install ffmpeg / sudo apt-get install ffmpeg)")
+	r.tools = append(r.tools, ffCheck)
+
+	if p := proxySetting(); p != "" {
+		detail := p
+		if u, err := url.Parse(p); err == nil {
+			detail = u.Scheme + "://" + u.Host // 認証情報は表示しない
+		}
+		r.network = append(r.network, diagCheck{label: tr("プロキシ"), detail: detail, ok: true})
+	}
+	if args := ytDlpExtraArgs(); len(args) > 0 {
+		r.tools = append(r.tools, diagCheck{label: tr("yt-dlp 追加引数"), detail: strings.Join(args, " "), ok: true})
+	}
+	if args := cookieArgs(); args != nil {
+		r.network = append(r.network, diagCheck{label: "Cookie", detail: strings.Join(args, " "), ok: true})
+	}
+	if ca := cfg.Network.CAFile; ca != "" {
+		c := diagCheck{label: tr("CA証明書"), detail: ca, ok: true}
+		if _, err := loadCAPool(ca); err != nil {
+			c.detail, c.ok = tr("%s (読み込めません: %v)", ca, err), false
+			c.hint = tr("network.ca_file にPEM形式の証明書のパスを指定してください")
+		}
+		r.network = append(r.network, c)
+	}
+
+	cfgCheck := diagCheck{label: tr("設定ファイル"), ok: true}
+	if abs, err := filepath.Abs(configPath()); err == nil {
+		cfgCheck.detail = abs
+	} else {
+		cfgCheck.detail = configPath()
+	}
+	if _, err := os.Stat(configPath()); err != nil {
+		cfgCheck.ok, cfgCheck.detail = false, tr("%s (見つかりません)", cfgCheck.detail)
+		cfgCheck.fix = func() (string, error) { return tr("既定の設定で作成しました"), saveConfig(cfg) }
+	}
+	r.files = append(r.files, cfgCheck)
+	for _, dir := range []string{mainDir, filepath.Join(mainDir, downloadsDir), filepath.Join(mainDir, tempDir), filepath.Join(mainDir, logsDir)} {
+		r.files = append(r.files, checkWritable(dir))
+	}
+	r.files = append(r.files, checkHistoryFile(), checkStaleTemp())
+
+	wg.Wait()
+	return r
+}
+
+func checkTool(name, path string, versionFlag string) diagCheck {
+	if path == "" {
+		return diagCheck{label: name, detail: tr("見つかりません")}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, versionFlag).Output()
+	if err != nil {
+		log.Printf("Diagnostics: %s %s failed: %v", path, versionFlag, err)
+		return diagCheck{label: name, detail: tr("%s (バージョン取得に失敗: %v)", path, err)}
+	}
+	version := firstLine(strings.TrimSpace(string(out)))
+	return diagCheck{label: name, detail: fmt.Sprintf("%s — %s", path, version), ok: true}
+}
+
+// checkEndpoint counts any non-5xx response as reachable; rate limits and 404s still prove the host is up.
+func checkEndpoint(label, url string) diagCheck {
+	req, err := newGetRequest(url)
+	if err != nil {
+		return diagCheck{label: label, detail: err.Error()}
+	}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		log.Printf("Diagnostics: %s unreachable: %v", url, err)
+		return diagCheck{label: label, detail: tr("接続できません (%v)", err), hint: tr("ネットワーク接続とプロキシ設定 (network.proxy) を確認してください")}
+	}
+	resp.Body.Close()
+	latency := time.Since(start).Round(time.Millisecond)
+	return diagCheck{label: label, detail: fmt.Sprintf("%s — %s", latency, resp.Status), ok: resp.StatusCode < 500}
+}
+
+func checkWritable(dir string) diagCheck {
+	c := diagCheck{label: tr("書き込み"), detail: dir}
+	if abs, err := filepath.Abs(dir); err == nil {
+		c.detail = abs
+	}
+	f, err := os.CreateTemp(dir, ".diag-*")
+	if err != nil {
+		c.detail += tr(" (書き込めません: %v)", err)
+		c.hint = tr("フォルダの権限と空き容量を確認してください")
+		if os.IsNotExist(err) {
+			c.fix = func() (string, error) { return tr("フォルダを作成しました"), os.MkdirAll(dir, os.ModePerm) }
+		}
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+	c.ok = true
+	return c
+}
+
+func (m model) diagnosticsView() string {
+	if m.diag == nil {
+		return tr("\n %s 診断を実行中です...\n", m.spinner.View())
+	}
+	okStyle, badStyle := lipgloss.NewStyle().Foreground(greenColor), lipgloss.NewStyle().Foreground(redColor).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(cyanColor).Width(20)
+	var b strings.Builder
+	section := func(title string, checks []diagCheck) {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(pinkColor).Bold(true).Render(title) + "\n")
+		for _, c := range checks {
+			mark := okStyle.Render("✓")
+			if !c.ok {
+				mark = badStyle.Render("✗")
+			}
+			b.WriteString(fmt.Sprintf("  %s %s %s\n", mark, labelStyle.Render(c.label), c.detail))
+			if !c.ok && c.hint != "" {
+				b.WriteString(helpStyle.Render("      → "+c.hint) + "\n")
+			}
+		}
+	}
+	section(tr("外部ツール"), m.diag.tools)
+	section(tr("ネットワーク"), m.diag.network)
+	section(tr("ファイル"), m.diag.files)
+	return b.String()
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- doctor (診断と自動修復) ---
+// 診断画面と同じチェックをターミナルに出力する。-fix を付けると直せる問題を直してから再チェックする。
+const staleTempAge = time.Hour
+
+const ytDlpReleaseURL = "https://github.com/yt-dlp/yt-dlp/releases/latest/download/"
+
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fix := fs.Bool("fix", false, tr("自動で直せる問題を修復する"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := runDiagnostics()
+	if *fix {
+		fixed := 0
+		for _, c := range r.all() {
+			if c.ok || c.fix == nil {
+				continue
+			}
+			note, err := c.fix()
+			if err != nil {
+				fmt.Print(tr("✗ %s の修復に失敗しました: %v\n", c.label, err))
+				continue
+			}
+			fmt.Printf("🔧 %s: %s\n", c.label, note)
+			fixed++
+		}
+		if fixed > 0 {
+			fmt.Println()
+			r = runDiagnostics()
+		}
+	}
+	failed := printDiagReport(r, *fix)
+	if failed > 0 {
+		return errorf("%d件の問題があります", failed)
+	}
+	fmt.Println(tr("\n問題は見つかりませんでした。"))
+	return nil
+}
+
+func (r diagReport) all() []diagCheck {
+	return append(append(append([]diagCheck(nil), r.tools...), r.network...), r.files...)
+}
+
+// printDiagReport writes the report as plain text and returns the number of failed checks.
+func printDiagReport(r diagReport, fixed bool) int {
+	failed := 0
+	section := func(title string, checks []diagCheck) {
+		fmt.Printf("[%s]\n", title)
+		for _, c := range checks {
+			mark := "✓"
+			if !c.ok {
+				mark = "✗"
+				failed++
+			}
+			pad := strings.Repeat(" ", max(18-lipgloss.Width(c.label), 1)) // 全角文字は2桁で数える
+			fmt.Printf("  %s %s%s%s\n", mark, c.label, pad, c.detail)
+			if !c.ok && c.hint != "" {
+				fmt.Printf("      → %s\n", c.hint)
+			}
+			if !c.ok && c.fix != nil && !fixed {
+				fmt.Println(tr("      → doctor -fix で自動修復できます"))
+			}
+		}
+		fmt.Println()
+	}
+	section(tr("外部ツール"), r.tools)
+	section(tr("ネットワーク"), r.network)
+	section(tr("ファイル"), r.files)
+	return failed
+}
+
+// ytDlpAsset is the standalone yt-dlp build for this platform.
+func ytDlpAsset() (asset, local string) {
+	switch runtime.GOOS {
+	case "windows":
+		return "yt-dlp.exe", "yt-dlp.exe"
+	case "darwin":
+		return "yt-dlp_macos", "yt-dlp"
+	}
+	if runtime.GOARCH == "arm64" {
+		return "yt-dlp_linux_aarch64", "yt-dlp"
+	}
+	return "yt-dlp_linux", "yt-dlp"
+}
+
+// installYtDlp downloads the latest standalone yt-dlp next to the working directory, where
+// checkYtDlpCmd looks for it.
+func installYtDlp() (string, error) {
+	asset, local := ytDlpAsset()
+	req, err := newGetRequest(ytDlpReleaseURL + asset)
+	if err != nil {
+		return "", err
+	}
+	client := *httpClient
+	client.Timeout = 0 // 数十MBあるので全体の制限時間は付けない
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: %s", asset, resp.Status)
+	}
+	tmp := local + ".download"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, local); err != nil {
+		return "", err
+	}
+	abs, _ := filepath.Abs(local)
+	return tr("%s をダウンロードしました (%s)", abs, formatBytes(n)), nil
+}
+
+func updateYtDlp(path string) func() (string, error) {
+	return func() (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, path, "-U").CombinedOutput()
+		if err != nil {
+			return "", fmt.Errorf("%v: %s", err, firstLine(string(out)))
+		}
+		return lastLine(string(out)), nil
+	}
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// checkHistoryFile reports whether history.json can be parsed.
+func checkHistoryFile() diagCheck {
+	c := diagCheck{label: tr("履歴"), detail: historyPath()}
+	entries, err := loadHistory()
+	if err != nil {
+		c.detail = tr("%s (読み込めません: %v)", historyPath(), err)
+		c.hint = tr("読み込める項目だけを残して修復します (元のファイルは .broken として残します)")
+		c.fix = repairHistory
+		return c
+	}
+	c.detail, c.ok = tr("%s — %d件", historyPath(), len(entries)), true
+	return c
+}
+
+// repairHistory keeps every entry that still decodes. Truncated files (e.g. after a crash mid-write)
+// lose only the entries after the damage.
+func repairHistory() (string, error) {
+	data, err := os.ReadFile(historyPath())
+	if err != nil {
+		return "", err
+	}
+	var entries []historyEntry
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err == nil && tok == json.Delim('[') {
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				break
+			}
+			var e historyEntry
+			if json.Unmarshal(raw, &e) == nil {
+				entries = append(entries, e)
+			}
+		}
+	}
+	backup := fmt.Sprintf("%s.broken-%s", historyPath(), time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0o644); err != nil {
+		return "", err
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if err := saveHistory(entries); err != nil {
+		return "", err
+	}
+	return tr("%d件を復元しました (元のファイル: %s)", len(entries), backup), nil
+}
+
+// staleTempDirs lists leftovers of interrupted downloads in the temp folder.
+func staleTempDirs() ([]string, int64) {
+	dir := filepath.Join(mainDir, tempDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, 0
+	}
+	var stale []string
+	var size int64
+	for _, e := range entries {
+		info, err := e.Info()
+		path := filepath.Join(dir, e.Name())
+		if err != nil || !tempDirAbandoned(path, info) {
+			continue
+		}
+		stale = append(stale, path)
+		filepath.Walk(path, func(_ string, fi os.FileInfo, err error) error {
+			if err == nil && !fi.IsDir() {
+				size += fi.Size()
+			}
+			return nil
+		})
+	}
+	return stale, size
+}
+
+func checkStaleTemp() diagCheck {
+	stale, size := staleTempDirs()
+	c := diagCheck{label: tr("一時ファイル"), detail: tr("残っていません"), ok: len(stale) == 0}
+	if len(stale) > 0 {
+		c.detail = tr("中断されたダウンロードの一時ファイルが%d件 (%s)", len(stale), formatBytes(size))
+		c.fix = func() (string, error) {
+			for _, p := range stale {
+				if err := os.RemoveAll(p); err != nil {
+					return "", err
+				}
+			}
+			return tr("%d件 (%s) を削除しました", len(stale), formatBytes(size)), nil
+		}
+	}
+	return c
+}
@@ -0,0 +1,550 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- ダウンロードと変換 ---
+
+// audioSegment selects part of the source audio in seconds; a zero End means "until the end".
+type audioSegment struct{ Start, End float64 }
+
+type convertJob struct {
+	audioPath, coverPath string
+	coverSrc             coverSource
+	tags                 finalTags
+	lyrics               lyricsResult
+	credits              workCredits
+	segment              audioSegment
+	chapters             []chapter
+	stream               *exec.Cmd // 設定されていれば audioPath の代わりにこの出力を変換する
+	codec                string    // 設定されていれば再エンコードせずにこのコーデックのまま保存する
+	timeline             *timeline // 各段階の処理時間を記録する (nil なら記録しない)
+	outputPath           string    // 設定されていればダウンロードフォルダではなくこのパスに書き出す
+	pending              string    // 設定されていれば変換の出力先をこの pending.json に書き留める
+}
+
+// newTempDir makes a work folder marked with our pid, so a later start can tell it was abandoned.
+func newTempDir() (string, error) {
+	dir, err := os.MkdirTemp(filepath.Join(mainDir, tempDir), "gomusicdl_*")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, tempPIDFile), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		log.Printf("Recovery: failed to mark %s: %v", dir, err)
+	}
+	return dir, nil
+}
+
+func downloadAudio(ytDlpPath string, yt item, audioPath string) error {
+	if isLiveStream(yt) {
+		return recordLive(ytDlpPath, yt, audioPath)
+	}
+	for retry := 1; ; retry++ {
+		err := downloadAudioOnce(ytDlpPath, yt, audioPath)
+		// ログインが必要な動画は何度やっても同じなので、すぐにCookieの選択に回す
+		if err == nil || needsCookies(err) || retry > cfg.Retry.Attempts {
+			return err
+		}
+		cause := "yt-dlp failed"
+		for _, line := range strings.Split(err.Error(), "\n") {
+			if strings.HasPrefix(line, "ERROR: ") {
+				cause = strings.TrimPrefix(line, "ERROR: ")
+			}
+		}
+		wait := cfg.Retry.backoff(retry)
+		notifyRetry("youtube", retry, wait, cause)
+		time.Sleep(wait)
+	}
+}
+
+func downloadAudioOnce(ytDlpPath string, yt item, audioPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout(yt)) // ダウンロードは長めに
+	defer cancel()
+	dlCmd := ytDlpCommand(ctx, ytDlpPath, append(progressArgs(), "-f", audioFormat(yt), "-o", audioPath, yt.url)...)
+	var out bytes.Buffer
+	dlCmd.Stdout = progressOutput(&out)
+	dlCmd.Stderr = dlCmd.Stdout
+	if err := dlCmd.Run(); err != nil {
+		return errorf("音声のダウンロード失敗:\n%s", out.String())
+	}
+	return nil
+}
+
+func simpleDownloadCmd(ytDlpPath, ffmpegPath string, selectedYT item) tea.Cmd {
+	if remoteEnabled() {
+		return remoteDownloadCmd(selectedYT, item{}, guessTags(selectedYT))
+	}
+	tags := guessTags(selectedYT)
+	tags.Romanize = cfg.Romanize.Enabled
+	return func() tea.Msg {
+		job := convertJob{tags: tags}
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+		if tuiMode {
+			job.pending = filepath.Join(tmpDir, pendingFile)
+			if err := writePending(job.pending, pendingDownload{downloadRequest: newDownloadRequest(selectedYT, item{}, tags), Started: time.Now()}); err != nil {
+				log.Printf("Recovery: failed to record download: %v", err)
+			}
+		}
+		// 取得 (download.stream)・形式 (output.format)・ファイル名 (命名テンプレート) はタグ付きのダウンロードと同じ
+		finalPath, err := fetchAndConvert(ytDlpPath, ffmpegPath, selectedYT, job, tmpDir)
+		if err != nil {
+			recordFailure(selectedYT, tags, err)
+			return downloadFinishedMsg{err: err}
+		}
+		if job.pending != "" {
+			os.Remove(job.pending)
+		}
+		// タグ付きのダウンロードと同じく、履歴・アーカイブ・フック・通知・メディアサーバーの更新を通す
+		finalPath = recordDownload(finalPath, job, selectedYT, item{})
+		queueUpload(finalPath, tags)
+		return downloadFinishedMsg{filename: finalPath, files: []string{finalPath}}
+	}
+}
+
+// fetchTrackExtras looks up the per-track lyrics and work credits in parallel.
+func fetchTrackExtras(tags finalTags) (lyrics lyricsResult, credits workCredits) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if tags.Lyrics != nil {
+			lyrics = *tags.Lyrics
+			return
+		}
+		lyrics = getLyrics(tags.Artist, tags.Title, tags.Album, tags.DurationSec)
+	}()
+	go func() {
+		defer wg.Done()
+		var err error
+		if credits, err = fetchWorkCredits(tags.RecordingID); err != nil {
+			log.Printf("Credits: failed to fetch work relationships: %v", err)
+		}
+	}()
+	wg.Wait()
+	return lyrics, credits
+}
+
+func downloadCmd(ytDlpPath, ffmpegPath string, selectedYT, selectedMB item, tags finalTags) tea.Cmd {
+	if remoteEnabled() {
+		return remoteDownloadCmd(selectedYT, selectedMB, tags)
+	}
+	return func() tea.Msg {
+		var wg sync.WaitGroup
+		wg.Add(2)
+		var tl timeline
+		job := convertJob{tags: tags, timeline: &tl}
+
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+		// daemon のジョブは jobs.json から再開するので、中断に備えて書き留めるのは TUI だけ
+		if tuiMode {
+			job.pending = filepath.Join(tmpDir, pendingFile)
+			if err := writePending(job.pending, pendingDownload{downloadRequest: newDownloadRequest(selectedYT, selectedMB, tags), Started: time.Now()}); err != nil {
+				log.Printf("Recovery: failed to record download: %v", err)
+			}
+		}
+
+		// 並行して取得するので、それぞれの段階を別々に記録してから並べる
+		var coverPhase, extrasPhase timeline
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			if job.coverPath, job.coverSrc = fetchCoverArt(tmpDir, selectedMB.meta.(MBRelease)); job.coverPath == "" {
+				job.coverPath, job.coverSrc = fetchThumbnailCover(ffmpegPath, tmpDir, selectedYT.id)
+			}
+			coverPhase.add(tr("カバー画像"), start, 0)
+		}()
+
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			job.lyrics, job.credits = fetchTrackExtras(tags)
+			extrasPhase.add(tr("歌詞・クレジット"), start, 0)
+		}()
+
+		wg.Wait()
+		tl = append(append(tl, coverPhase...), extrasPhase...)
+
+		finalPath, err := fetchAndConvert(ytDlpPath, ffmpegPath, selectedYT, job, tmpDir)
+		if err != nil {
+			recordFailure(selectedYT, tags, err)
+			return downloadFinishedMsg{err: err}
+		}
+		if job.pending != "" {
+			os.Remove(job.pending) // 変換が終わったので、ここから先で落ちても出力は消さない
+		}
+		finalPath = recordDownload(finalPath, job, selectedYT, selectedMB)
+
+		// 変換後のファイルの長さ (トリム後) で再生速度の違いを確認する。配信の録音は上限で切れたかだけを見る
+		var warning string
+		if isLiveStream(selectedYT) {
+			warning = liveWarning(ffmpegPath, selectedYT, finalPath)
+		} else if actual, err := probeDuration(ffmpegPath, finalPath); err != nil {
+			log.Printf("Duration: failed to probe %s: %v", finalPath, err)
+		} else {
+			warning = detectTimeStretch(actual, tags.DurationSec)
+		}
+		queueUpload(finalPath, job.tags)
+
+		log.Printf("Timeline: %s", tl)
+		finalMsg := finalPath
+		if job.lyrics.Instrumental {
+			finalMsg += tr(" (インストゥルメンタル)")
+		} else if job.lyrics.found() {
+			finalMsg += tr(" (歌詞付き)")
+		}
+		return downloadFinishedMsg{filename: finalMsg, warning: warning, files: []string{finalPath}, timeline: tl}
+	}
+}
+
+// fetchAndConvert downloads and converts the audio, streaming it into ffmpeg when download.stream is
+// on and falling back to a temp file when ffmpeg can't read it from the pipe.
+func fetchAndConvert(ytDlpPath, ffmpegPath string, yt item, job convertJob, tmpDir string) (string, error) {
+	// コーデックを調べる場合と配信を録音する場合は一時ファイルが要る
+	if !cfg.Download.Stream || cfg.Output.Format == outputOriginal || isLiveStream(yt) {
+		return downloadThenConvert(ytDlpPath, ffmpegPath, yt, job, tmpDir)
+	}
+	finalPath, err := streamToFlac(ytDlpPath, ffmpegPath, yt, job)
+	var convErr streamConvertError
+	if errors.As(err, &convErr) {
+		log.Printf("Stream: ffmpeg could not convert from the pipe, retrying via a temp file: %s", firstLine(convErr.out))
+		return downloadThenConvert(ytDlpPath, ffmpegPath, yt, job, tmpDir)
+	}
+	return finalPath, err
+}
+
+// streamToFlac converts yt-dlp's output as it downloads. The source length isn't known up front, so a
+// tail trim is placed using the video's reported duration.
+func streamToFlac(ytDlpPath, ffmpegPath string, yt item, job convertJob) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout(yt))
+	defer cancel()
+	job.stream = streamAudioCmd(ctx, ytDlpPath, yt)
+	if job.tags.Trim.active() {
+		job.segment = job.tags.Trim.segment(videoDuration(yt))
+	}
+	return convertAudio(ffmpegPath, job)
+}
+
+// downloadThenConvert is the non-streaming path: the audio is saved to tmpDir first.
+func downloadThenConvert(ytDlpPath, ffmpegPath string, yt item, job convertJob, tmpDir string) (string, error) {
+	job.audioPath = filepath.Join(tmpDir, "audio.tmp")
+	start := time.Now()
+	if err := downloadAudio(ytDlpPath, yt, job.audioPath); err != nil {
+		return "", err
+	}
+	job.timeline.add(tr("ダウンロード"), start, fileSize(job.audioPath))
+	reportPhase(phaseConvert, "")
+	if job.tags.Trim.active() {
+		actual, err := probeDuration(ffmpegPath, job.audioPath)
+		if err != nil {
+			log.Printf("Duration: failed to probe %s: %v", job.audioPath, err)
+		}
+		job.segment = job.tags.Trim.segment(actual)
+	}
+	job.choosePassthrough(ffmpegPath)
+	return convertAudio(ffmpegPath, job)
+}
+
+func trackFilename(tags finalTags, ext string) string {
+	tmpl := cfg.Naming.Template
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = defaultNamingTemplate
+	}
+	if tags.Romanize {
+		tags = asciiFields(tags)
+	}
+	return expandTemplate(tmpl, tags) + ext
+}
+
+// convertAudio encodes the job's audio (or the requested segment of it) to FLAC, or remuxes it when
+// job.codec is set, with all tags and art embedded.
+func convertAudio(ffmpegPath string, job convertJob) (string, error) {
+	tags := job.tags
+	ext := job.outputExt()
+	finalPath := job.outputPath
+	if finalPath == "" {
+		finalPath = filepath.Join(mainDir, downloadsDir, trackFilename(tags, ext))
+	}
+	outPath := longPath(finalPath) // 記録には finalPath、書き込みには outPath を使う
+	if err := os.MkdirAll(filepath.Dir(outPath), os.ModePerm); err != nil {
+		return "", err
+	}
+	notePendingOutput(job.pending, finalPath)
+
+	ffmpegArgs := []string{"-y"}
+	if job.segment.Start > 0 {
+		ffmpegArgs = append(ffmpegArgs, "-ss", fmt.Sprintf("%.3f", job.segment.Start))
+	}
+	if job.segment.End > 0 {
+		ffmpegArgs = append(ffmpegArgs, "-to", fmt.Sprintf("%.3f", job.segment.End))
+	}
+	input := job.audioPath
+	if job.stream != nil {
+		input = "pipe:0"
+	}
+	ffmpegArgs = append(ffmpegArgs, "-i", input)
+	nextInput := 1
+	oggCover := ext == ".opus" || ext == ".ogg"
+	archive := ext == ".mka"
+	if job.coverPath != "" && !oggCover && !archive {
+		// -c:v copy keeps the image prepareCover produced; otherwise the FLAC muxer re-encodes it to PNG
+		ffmpegArgs = append(ffmpegArgs, "-i", job.coverPath, "-map", "0:a:0", "-map", "1:v:0", "-c:v", "copy", "-disposition:v", "attached_pic")
+		nextInput++
+	}
+	if len(job.chapters) > 0 {
+		metaPath := filepath.Join(filepath.Dir(job.audioPath), "chapters.txt")
+		if err := writeFFMetadata(metaPath, job.chapters); err != nil {
+			return "", err
+		}
+		ffmpegArgs = append(ffmpegArgs, "-i", metaPath, "-map_chapters", fmt.Sprint(nextInput))
+		nextInput++
+	}
+	if job.coverPath != "" && oggCover {
+		// Ogg には画像ストリームを入れられないので、METADATA_BLOCK_PICTURE タグとして埋め込む
+		if kv, err := opusCoverTag(job.coverPath); err != nil {
+			log.Printf("Passthrough: failed to embed cover: %v", err)
+		} else {
+			metaPath := filepath.Join(filepath.Dir(job.audioPath), "cover.txt")
+			if err := writeFFMetadataTags(metaPath, [][2]string{kv}); err != nil {
+				return "", err
+			}
+			ffmpegArgs = append(ffmpegArgs, "-i", metaPath, "-map", "0:a:0", "-map_metadata", fmt.Sprint(nextInput))
+		}
+	}
+	audioArgs := append([]string{"-c:a", "flac"}, flacEncodeArgs()...)
+	if job.codec != "" {
+		audioArgs = []string{"-c:a", "copy"}
+	}
+	if ext == ".m4a" {
+		ffmpegArgs = append(ffmpegArgs, "-movflags", "+use_metadata_tags") // MP4 の標準以外のタグも残す
+	}
+	if archive {
+		attachArgs, err := archiveAttachArgs(job)
+		if err != nil {
+			return "", err
+		}
+		ffmpegArgs = append(ffmpegArgs, attachArgs...)
+	}
+	ffmpegArgs = append(ffmpegArgs, audioArgs...)
+	for _, kv := range job.metadata() {
+		ffmpegArgs = append(ffmpegArgs, "-metadata", fmt.Sprintf("%s=%s", kv[0], kv[1]))
+	}
+	ffmpegArgs = append(ffmpegArgs, outPath)
+
+	convCmd := exec.Command(ffmpegPath, ffmpegArgs...)
+	start := time.Now()
+	if job.stream != nil {
+		n, err := runStreamed(job.stream, convCmd, job.segment.End > 0)
+		if err != nil {
+			os.Remove(outPath) // 途中で切れた音声が残らないように
+			return "", err
+		}
+		job.timeline.add(tr("ダウンロード+変換"), start, n)
+	} else if out, err := convCmd.CombinedOutput(); err != nil {
+		return "", errorf("ffmpegでの変換失敗:\n%s", string(out))
+	} else {
+		job.timeline.add(tr("変換"), start, 0)
+	}
+	if len(job.chapters) > 0 && ext == ".flac" {
+		if err := writeFLACCuesheet(outPath, job.chapters); err != nil {
+			log.Printf("Chapters: failed to write cuesheet for %s: %v", finalPath, err)
+		}
+	}
+	if job.lyrics.Synced != "" && cfg.Lyrics.LRCSidecar != lrcSidecarOff && !archive {
+		if err := writeLRCSidecar(outPath, job.lyrics.Synced); err != nil {
+			log.Printf("Lyrics: failed to write .lrc for %s: %v", finalPath, err)
+		}
+	}
+	return finalPath, nil
+}
+
+// metadata lists the tags written to the output, keyed by ffmpeg's generic names (which its muxers
+// map to each container's own fields). The basic fields are always set so stale values get cleared.
+func (job convertJob) metadata() [][2]string {
+	tags := romanizedTags(job.tags)
+	out := [][2]string{
+		{"title", tags.Title}, {"artist", tags.Artist}, {"album_artist", tags.AlbumArtist},
+		{"album", tags.Album}, {"track", tags.TrackNumber}, {"date", tags.Date},
+	}
+	if job.coverSrc != coverSourceNone {
+		out = append(out, [2]string{"COVERART_SOURCE", string(job.coverSrc)}, [2]string{"COVERART_NOTE", job.coverSrc.licenseNote()})
+	}
+	optional := [][2]string{
+		{"ORIGINALDATE", tags.OriginalDate}, {"GENRE", tags.Genre}, {"ISRC", tags.ISRC}, {"LABEL", tags.Label}, {"CATALOGNUMBER", tags.CatalogNumber},
+		{"TRACKTOTAL", optionalInt(tags.TrackTotal)}, {"DISCNUMBER", optionalInt(tags.DiscNumber)}, {"DISCTOTAL", optionalInt(tags.DiscTotal)},
+	}
+	optional = append(optional, sortTags(job.tags)...) // 読みは併記する前の表記から作る
+	for _, kv := range append(optional, job.credits.tags()...) {
+		if kv[1] != "" {
+			out = append(out, kv)
+		}
+	}
+	if job.lyrics.Instrumental {
+		out = append(out, [2]string{"INSTRUMENTAL", "1"})
+	}
+	return append(out, job.lyrics.tags()...)
+}
+
+// recordDownload hands the file to beets if configured and adds it to the history, returning the path
+// the file ended up at.
+func recordDownload(finalPath string, job convertJob, selectedYT, selectedMB item) string {
+	finalPath = handOffToBeets(finalPath, job, selectedYT, selectedMB)
+	entry := historyEntry{
+		Path:        finalPath,
+		Title:       job.tags.Title,
+		Artist:      job.tags.Artist,
+		Album:       job.tags.Album,
+		Date:        job.tags.Date,
+		VideoID:     selectedYT.id,
+		VideoURL:    selectedYT.url,
+		ReleaseID:   mbReleaseID(selectedMB.id),
+		RecordingID: job.tags.RecordingID,
+		CoverSource: job.coverSrc,
+		Lyrics:      job.lyrics.kind(),
+	}
+	if err := appendHistory(entry); err != nil {
+		log.Printf("History: failed to record download: %v", err)
+	}
+	noteOwned(entry)
+	submitToListenBrainz(job, selectedYT, selectedMB)
+	archiveDownload(selectedYT.id)
+	runPostDownloadHook(finalPath, job, selectedYT, selectedMB)
+	scheduleMediaRefresh()
+	notifyDownloaded(finalPath, job.tags, selectedYT, selectedMB)
+	return finalPath
+}
+
+func recordFailure(selectedYT item, tags finalTags, cause error) {
+	if err := appendHistory(historyEntry{
+		Title:    tags.Title,
+		Artist:   tags.Artist,
+		Album:    tags.Album,
+		VideoID:  selectedYT.id,
+		VideoURL: selectedYT.url,
+		Status:   statusFailed,
+		Error:    cause.Error(),
+	}); err != nil {
+		log.Printf("History: failed to record failure: %v", err)
+	}
+	notifyFailed(selectedYT, tags, cause)
+}
+
+const (
+	coverProviderCAA    = "caa"
+	coverProviderITunes = "itunes"
+)
+
+// fetchCoverArt tries the configured providers in order. For CAA the release's front cover is tried
+// first, then the release group's.
+func fetchCoverArt(tmpDir string, releaseInfo MBRelease) (string, coverSource) {
+	localPath := filepath.Join(tmpDir, "cover.jpg")
+	for _, provider := range cfg.Cover.Providers {
+		switch provider {
+		case coverProviderCAA:
+			if releaseInfo.ID == "" {
+				continue
+			}
+			coverURL := fmt.Sprintf("https://coverartarchive.org/release/%s/%s", releaseInfo.ID, caaImageName())
+			if path, ok := downloadCover(coverURL, localPath); ok {
+				return path, coverSourceCAARelease
+			}
+			if releaseInfo.ReleaseGroup.ID != "" {
+				coverGroupURL := fmt.Sprintf("https://coverartarchive.org/release-group/%s/%s", releaseInfo.ReleaseGroup.ID, caaImageName())
+				if path, ok := downloadCover(coverGroupURL, localPath); ok {
+					return path, coverSourceCAAGroup
+				}
+			}
+		case coverProviderITunes:
+			if path, ok := fetchITunesCover(localPath, releaseInfo); ok {
+				return path, coverSourceITunes
+			}
+		default:
+			log.Printf("Cover: unknown provider %q in config", provider)
+		}
+	}
+	return "", coverSourceNone
+}
+
+// downloadCover fetches an image and runs it through prepareCover, discarding anything that isn't usable art.
+// The returned path may differ from localPath when the image is kept as PNG.
+func downloadCover(coverURL, localPath string) (string, bool) {
+	if !downloadFile(coverURL, localPath) {
+		return "", false
+	}
+	path, err := prepareCover(localPath)
+	if err != nil {
+		log.Printf("Cover: rejected %s: %v", coverURL, err)
+		os.Remove(localPath)
+		return "", false
+	}
+	return path, true
+}
+
+// caaImageName maps cover.caa_size to the Cover Art Archive front image name.
+func caaImageName() string {
+	switch cfg.Cover.CAASize {
+	case "250", "500", "1200":
+		return "front-" + cfg.Cover.CAASize
+	case "original":
+		return "front"
+	}
+	return "front-500"
+}
+
+// downloadFile saves fileURL to localPath, serving it from the HTTP cache when possible.
+func downloadFile(fileURL, localPath string) bool {
+	if body, missing, ok := cacheGet(fileURL); ok {
+		return !missing && os.WriteFile(localPath, body, 0o644) == nil
+	}
+	req, err := newGetRequest(fileURL)
+	if err != nil {
+		return false
+	}
+	resp, err := doWithRetry("cover", req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		cachePutMissing(fileURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false
+	}
+	cachePut(fileURL, body)
+	return os.WriteFile(localPath, body, 0o644) == nil
+}
+
+func optionalInt(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log"
+)
+
+// --- FLACのエンコード設定 ---
+// output.flac_compression / sample_rate / bit_depth を ffmpeg の引数にする。パススルー時は再エンコードしないので使わない。
+const (
+	defaultFLACCompression = 5
+	maxFLACCompression     = 12
+)
+
+// flacEncodeArgs returns the ffmpeg output options for the FLAC encoder. Out-of-range values are
+// logged and left at the encoder default rather than failing the download.
+func flacEncodeArgs() []string {
+	o := cfg.Output
+	var args []string
+	if o.FLACCompression >= 0 && o.FLACCompression <= maxFLACCompression {
+		args = append(args, "-compression_level", fmt.Sprint(o.FLACCompression))
+	} else {
+		log.Printf("Encode: ignoring flac_compression %d (expected 0-%d)", o.FLACCompression, maxFLACCompression)
+	}
+	switch {
+	case o.SampleRate == 0:
+	case o.SampleRate >= 8000 && o.SampleRate <= 384000:
+		args = append(args, "-ar", fmt.Sprint(o.SampleRate))
+	default:
+		log.Printf("Encode: ignoring sample_rate %d", o.SampleRate)
+	}
+	switch o.BitDepth {
+	case 0:
+	case 16:
+		// 深度を下げるのでディザをかける
+		args = append(args, "-sample_fmt", "s16", "-af", "aresample=osf=s16:dither_method=triangular")
+	case 24:
+		// FLACエンコーダは24bitを s32 で受け取り、実際のビット数を別に指定する
+		args = append(args, "-sample_fmt", "s32", "-bits_per_raw_sample", "24")
+	default:
+		log.Printf("Encode: ignoring bit_depth %d (expected 16 or 24)", o.BitDepth)
+	}
+	return args
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- 進捗イベント (API) ---
+// daemon -listen で起動すると、ジョブの状態と進み具合 (段階・ダウンロードの割合・速度) を
+// Server-Sent Events (GET /events) で配信する。外部のUIはポーリングせずに進捗を表示できる。
+// GET /jobs はジョブキューの今の内容を返し、POST /jobs で検索語やURLをジョブキューに追加できる。
+// 同じ API の gRPC 版の定義は api/downloader.proto にある (型付きのクライアントを生成するための契約)。
+const (
+	phaseQueued   = "queued"
+	phaseSearch   = "search"
+	phaseMatch    = "match"
+	phaseDownload = "download"
+	phaseConvert  = "convert"
+	phaseDone     = "done"
+	phaseReview   = "review"
+	phaseFailed   = "failed"
+	// yt-dlp の進捗行を他の出力と見分けるための印
+	progressMarker = "gmd-progress:"
+)
+
+type progressEvent struct {
+	Job     string    `json:"job"`
+	Query   string    `json:"query,omitempty"`
+	Phase   string    `json:"phase"`
+	Percent float64   `json:"percent,omitempty"`
+	Speed   string    `json:"speed,omitempty"`
+	ETA     string    `json:"eta,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// progress fans events out to the connected clients. Sends never block: a client that falls behind
+// misses events rather than holding up the download. last is the running job's latest event, sent
+// to clients as they connect.
+var progress struct {
+	sync.Mutex
+	enabled bool
+	job     daemonJob
+	last    *progressEvent
+	subs    map[chan progressEvent]struct{}
+}
+
+func progressEnabled() bool {
+	progress.Lock()
+	defer progress.Unlock()
+	return progress.enabled
+}
+
+func publishProgress(e progressEvent) {
+	progress.Lock()
+	defer progress.Unlock()
+	if !progress.enabled {
+		return
+	}
+	e.Time = time.Now()
+	if e.Job == progress.job.ID && e.Job != "" {
+		progress.last = &e
+	}
+	for ch := range progress.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// setProgressJob makes job the one phase and download events belong to.
+func setProgressJob(job daemonJob) {
+	progress.Lock()
+	progress.job, progress.last = job, nil
+	progress.Unlock()
+}
+
+// reportPhase publishes a phase change of the running job. Outside the daemon it does nothing.
+func reportPhase(phase, message string) {
+	progress.Lock()
+	job := progress.job
+	progress.Unlock()
+	if job.ID == "" {
+		return
+	}
+	publishProgress(progressEvent{Job: job.ID, Query: job.Query, Phase: phase, Message: message})
+}
+
+func subscribeProgress() (chan progressEvent, func()) {
+	ch := make(chan progressEvent, 64)
+	progress.Lock()
+	progress.subs[ch] = struct{}{}
+	if progress.last != nil {
+		ch <- *progress.last
+	}
+	progress.Unlock()
+	return ch, func() {
+		progress.Lock()
+		delete(progress.subs, ch)
+		progress.Unlock()
+	}
+}
+
+// progressArgs asks yt-dlp for one machine-readable progress line per update, only when someone may
+// be listening.
+func progressArgs() []string {
+	if !progressEnabled() {
+		return nil
+	}
+	return []string{"--progress", "--newline", "--progress-template",
+		"download:" + progressMarker + "%(progress._percent_str)s|%(progress._speed_str)s|%(progress._eta_str)s"}
+}
+
+// progressOutput wraps yt-dlp's output so progress lines become events and everything else still
+// reaches w (which the error messages are built from).
+func progressOutput(w io.Writer) io.Writer {
+	if !progressEnabled() {
+		return w
+	}
+	return &progressWriter{out: w, lastPct: -1}
+}
+
+type progressWriter struct {
+	out     io.Writer
+	buf     []byte
+	lastPct int
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexAny(p.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		line := string(p.buf[:i+1])
+		p.buf = p.buf[i+1:]
+		if !p.parse(line) {
+			if _, err := io.WriteString(p.out, line); err != nil {
+				return len(b), err
+			}
+		}
+	}
+	return len(b), nil
+}
+
+// parse reports whether line was a progress line. Events are published once per whole percent.
+func (p *progressWriter) parse(line string) bool {
+	_, rest, ok := strings.Cut(line, progressMarker)
+	if !ok {
+		return false
+	}
+	fields := strings.Split(strings.TrimSpace(rest), "|")
+	if len(fields) != 3 {
+		return true
+	}
+	pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(fields[0]), "%"), 64)
+	if err != nil || int(pct) == p.lastPct {
+		return true
+	}
+	p.lastPct = int(pct)
+	progress.Lock()
+	job := progress.job
+	progress.Unlock()
+	if job.ID != "" {
+		publishProgress(progressEvent{Job: job.ID, Query: job.Query, Phase: phaseDownload, Percent: pct,
+			Speed: strings.TrimSpace(fields[1]), ETA: strings.TrimSpace(fields[2])})
+	}
+	return true
+}
+
+// startProgressServer serves the event stream, the job list and the remote TUI's endpoints on addr
+// in the background.
+func startProgressServer(addr, ytDlpPath string) error {
+	auth, err := checkListenAuth(addr)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errorf("%s で待ち受けできません: %w", addr, err)
+	}
+	progress.Lock()
+	progress.enabled = true
+	progress.subs = map[chan progressEvent]struct{}{}
+	progress.Unlock()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", auth.require(serveEvents))
+	mux.HandleFunc("GET /jobs", auth.require(serveJobs))
+	mux.HandleFunc("POST /jobs", auth.require(submitJobs))
+	mux.HandleFunc("GET /youtube/search", auth.require(serveYouTubeSearch(ytDlpPath)))
+	mux.HandleFunc("GET /youtube/info", auth.require(serveURLInfo(ytDlpPath)))
+	mux.HandleFunc("POST /downloads", auth.require(submitDownload))
+	mux.HandleFunc("OPTIONS /", allowCORS)
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Printf("Server: %v", err)
+		}
+	}()
+	return nil
+}
+
+// serveEvents streams progress as Server-Sent Events, with a comment every so often to keep proxies
+// from closing an idle connection.
+func serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	ch, unsubscribe := subscribeProgress()
+	defer unsubscribe()
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case e := <-ch:
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Phase, data)
+		}
+		flusher.Flush()
+	}
+}
+
+// allowCORS answers browsers' preflight requests, which carry no credentials.
+func allowCORS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func serveJobs(w http.ResponseWriter, r *http.Request) {
+	jobsMu.Lock()
+	jobs, err := loadJobs()
+	jobsMu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if jobs == nil {
+		jobs = []daemonJob{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// submitJobsRequest accepts a single query or a list of them.
+type submitJobsRequest struct {
+	Query   string   `json:"query"`
+	Queries []string `json:"queries"`
+}
+
+func submitJobs(w http.ResponseWriter, r *http.Request) {
+	var req submitJobsRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var queries []string
+	for _, q := range append(req.Queries, req.Query) {
+		if q = strings.TrimSpace(q); q != "" {
+			queries = append(queries, q)
+		}
+	}
+	if len(queries) == 0 {
+		http.Error(w, "query or queries is required", http.StatusBadRequest)
+		return
+	}
+	added, err := enqueueJobs(queries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(added)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// --- ファイル名の整形 ---
+// macOS から来た濁点の分かれた仮名 (NFD) を NFC にまとめ、ファイル名に使えない文字を置き換える。
+// 多くのファイルシステムは1つの名前を255バイトまでに制限していて、日本語は1文字3バイトなので長いタイトルは
+// 作成に失敗する。拡張子 (.lrc / .cue に付け替える分も含む) の余裕を残してバイト数で切り詰める。
+// exFAT / NTFS は末尾のドットと空白を黙って削るため、書いた名前と実際の名前がずれないよう先に取り除く。
+// Windows では CON / PRN / AUX / NUL / COM1 / LPT1 などの名前 (拡張子付きも) がデバイス扱いになり、
+// エクスプローラーで開くことも消すこともできないファイルになるので、末尾に「_」を付ける。
+// MAX_PATH (260文字) を超える保存先は \\?\ 付きの絶対パスにして ffmpeg に渡す。
+const (
+	maxNameBytes   = 255
+	maxExtBytes    = 8   // ".flac" ".opus" ".lrc" ".cue" など
+	windowsMaxPath = 248 // フォルダの作成は MAX_PATH より短い長さで失敗する
+)
+
+var (
+	filenameReplacer = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "'", "<", "-", ">", "-", "|", "-")
+	windowsReserved  = regexp.MustCompile(`(?i)^(con|prn|aux|nul|conin\$|conout\$|com[0-9¹²³]|lpt[0-9¹²³])$`)
+)
+
+// sanitizeFilename makes one path element safe to create. A short extension is kept as it is while the
+// rest is shortened; a name without one leaves room for the extension the caller adds.
+func sanitizeFilename(name string) string {
+	name = filenameReplacer.Replace(norm.NFC.String(name))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	stem, ext := name, filepath.Ext(name)
+	if len(ext) < 2 || len(ext) > maxExtBytes || strings.ContainsRune(ext, ' ') {
+		ext = ""
+	}
+	stem = strings.TrimSuffix(stem, ext)
+	stem = strings.TrimRight(truncateBytes(strings.TrimSpace(stem), maxNameBytes-maxExtBytes), " .")
+	if stem == "" {
+		stem = "Unknown"
+	}
+	if device, rest, _ := strings.Cut(stem, "."); windowsReserved.MatchString(strings.TrimSpace(device)) {
+		stem = strings.TrimSpace(device) + "_"
+		if rest != "" {
+			stem += "." + rest
+		}
+	}
+	return stem + ext
+}
+
+// longPath turns a Windows path of MAX_PATH or more into the \\?\ form, which lifts the limit for ffmpeg
+// and the file APIs. Other systems and short paths are returned unchanged.
+func longPath(p string) string {
+	if runtime.GOOS != "windows" || strings.HasPrefix(p, `\\?\`) {
+		return p
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil || len(abs) < windowsMaxPath {
+		return p
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:] // \\server\share
+	}
+	return `\\?\` + abs
+}
+
+// truncateBytes cuts s to at most n bytes without splitting a character.
+func truncateBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
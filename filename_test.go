@@ -0,0 +1,93 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "Lemon.flac", "Lemon.flac"},
+		{"separators", "AC/DC: Back in Black?.flac", "AC-DC- Back in Black-.flac"},
+		{"quotes and pipes", `Say "Hi" <live> | *.flac`, "Say 'Hi' -live- - -.flac"},
+		{"control characters", "Tab\tNew\nline.flac", "TabNewline.flac"},
+		{"nfd kana", "か\u3099くえん.flac", "がくえん.flac"},
+		{"nfd latin", "Cafe\u0301.flac", "Caf\u00e9.flac"},
+		{"trailing dots", "Song....flac", "Song.flac"},
+		{"trailing dots without extension", "Song...", "Song"},
+		{"trailing space and dot", "Song . ", "Song"},
+		{"long extension kept in stem", "Song.extension", "Song.extension"},
+		{"empty", "", "Unknown"},
+		{"only dots", "...", "Unknown"},
+		{"reserved", "CON", "CON_"},
+		{"reserved lowercase", "nul.flac", "nul_.flac"},
+		{"reserved with inner dot", "Aux.live.flac", "Aux_.live.flac"},
+		{"reserved com port", "COM1.flac", "COM1_.flac"},
+		{"reserved superscript port", "LPT¹.flac", "LPT¹_.flac"},
+		{"reserved console", "CONIN$", "CONIN$_"},
+		{"reserved with trailing space", "PRN .flac", "PRN_.flac"},
+		{"not reserved", "CONSOLE.flac", "CONSOLE.flac"},
+		{"not reserved prefix", "Nullset.flac", "Nullset.flac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeFilename(tt.in); got != tt.want {
+				t.Errorf("sanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeFilenameLength(t *testing.T) {
+	tests := []struct {
+		name, in, ext string
+	}{
+		{"ascii", strings.Repeat("a", 400) + ".flac", ".flac"},
+		{"kana", strings.Repeat("あ", 300) + ".flac", ".flac"},
+		{"mixed", strings.Repeat("aあ", 150) + ".opus", ".opus"},
+		{"emoji", strings.Repeat("🎵", 100), ""},
+		{"no extension", strings.Repeat("か", 200), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeFilename(tt.in)
+			if !utf8.ValidString(got) {
+				t.Fatalf("sanitizeFilename split a character: %q", got)
+			}
+			if !strings.HasSuffix(got, tt.ext) {
+				t.Errorf("sanitizeFilename(...) = %q, want extension %q", got, tt.ext)
+			}
+			stem := strings.TrimSuffix(got, tt.ext)
+			if len(stem) > maxNameBytes-maxExtBytes {
+				t.Errorf("stem is %d bytes, want at most %d", len(stem), maxNameBytes-maxExtBytes)
+			}
+			if len(got)+maxExtBytes-len(tt.ext) > maxNameBytes {
+				t.Errorf("%d bytes leave no room for an extension within %d", len(got), maxNameBytes)
+			}
+		})
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"abc", 5, "abc"},
+		{"abc", 2, "ab"},
+		{"あいう", 9, "あいう"},
+		{"あいう", 8, "あい"},
+		{"あいう", 4, "あ"},
+		{"あいう", 2, ""},
+		{"aあ", 3, "a"},
+	}
+	for _, tt := range tests {
+		if got := truncateBytes(tt.in, tt.n); got != tt.want {
+			t.Errorf("truncateBytes(%q, %d) = %q, want %q", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
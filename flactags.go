@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- FLAC タグ (Vorbis comment / PICTURE) の直接読み書き ---
+// ffmpeg を通さずにタグだけを書き換えるため、音声データは再エンコードもコピーもしない
+// (パディングに収まらない場合のみファイル全体を書き直す)。
+
+const (
+	flacBlockPadding       = 1
+	flacBlockVorbisComment = 4
+	flacBlockPicture       = 6
+	flacMaxBlockLen        = 1<<24 - 1
+)
+
+type flacBlock struct {
+	kind byte
+	data []byte
+}
+
+type flacTags struct {
+	vendor   string
+	comments []string // "KEY=value"
+}
+
+// readFLACMetadata returns the metadata blocks and the offset where the audio frames begin.
+func readFLACMetadata(r io.Reader) ([]flacBlock, int64, error) {
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, 0, err
+	}
+	if string(magic) != "fLaC" {
+		return nil, 0, errors.New("not a FLAC file")
+	}
+	offset := int64(4)
+	var blocks []flacBlock
+	for {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, 0, err
+		}
+		length := int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, 0, err
+		}
+		blocks = append(blocks, flacBlock{kind: header[0] & 0x7f, data: data})
+		offset += 4 + int64(length)
+		if header[0]&0x80 != 0 {
+			return blocks, offset, nil
+		}
+	}
+}
+
+func readFLACTags(path string) (*flacTags, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	blocks, _, err := readFLACMetadata(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	for _, b := range blocks {
+		if b.kind == flacBlockVorbisComment {
+			return parseVorbisComment(b.data)
+		}
+	}
+	return &flacTags{}, nil
+}
+
+func parseVorbisComment(data []byte) (*flacTags, error) {
+	r := bytes.NewReader(data)
+	readString := func() (string, error) {
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return "", err
+		}
+		if int64(n) > int64(r.Len()) {
+			return "", errors.New("corrupt vorbis comment")
+		}
+		buf := make([]byte, n)
+		_, err := io.ReadFull(r, buf)
+		return string(buf), err
+	}
+	vendor, err := readString()
+	if err != nil {
+		return nil, err
+	}
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	t := &flacTags{vendor: vendor}
+	for i := uint32(0); i < count; i++ {
+		c, err := readString()
+		if err != nil {
+			return nil, err
+		}
+		t.comments = append(t.comments, c)
+	}
+	return t, nil
+}
+
+func (t *flacTags) encode() []byte {
+	var buf bytes.Buffer
+	writeString := func(s string) {
+		binary.Write(&buf, binary.LittleEndian, uint32(len(s)))
+		buf.WriteString(s)
+	}
+	writeString(t.vendor)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(t.comments)))
+	for _, c := range t.comments {
+		writeString(c)
+	}
+	return buf.Bytes()
+}
+
+func encodeFLACMetadata(blocks []flacBlock) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("fLaC")
+	for i, b := range blocks {
+		kind := b.kind
+		if i == len(blocks)-1 {
+			kind |= 0x80
+		}
+		n := len(b.data)
+		buf.Write([]byte{kind, byte(n >> 16), byte(n >> 8), byte(n)})
+		buf.Write(b.data)
+	}
+	return buf.Bytes()
+}
+
+// writeFLACTags replaces the Vorbis comment block.
+func writeFLACTags(path string, t *flacTags) error {
+	comment := t.encode()
+	if len(comment) > flacMaxBlockLen {
+		return errors.New("tags too large for a FLAC metadata block")
+	}
+	return replaceFLACBlock(path, flacBlock{kind: flacBlockVorbisComment, data: comment})
+}
+
+// replaceFLACBlock swaps in a block of the given kind, adding it after STREAMINFO when the file has none.
+func replaceFLACBlock(path string, nb flacBlock) error {
+	return rewriteFLACMetadata(path, func(blocks []flacBlock) []flacBlock {
+		var kept []flacBlock
+		replaced := false
+		for _, b := range blocks {
+			switch {
+			case b.kind == nb.kind:
+				if !replaced {
+					kept = append(kept, nb)
+					replaced = true
+				}
+			default:
+				kept = append(kept, b)
+			}
+		}
+		if !replaced {
+			// STREAMINFO must stay first
+			kept = append(kept[:1], append([]flacBlock{nb}, kept[1:]...)...)
+		}
+		return kept
+	})
+}
+
+// rewriteFLACMetadata applies edit to the metadata blocks (padding excluded). When the existing padding
+// can absorb the size change the metadata is overwritten in place; otherwise the file is rewritten via
+// a temp file.
+func rewriteFLACMetadata(path string, edit func([]flacBlock) []flacBlock) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	blocks, audioOffset, err := readFLACMetadata(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	var unpadded []flacBlock
+	for _, b := range blocks {
+		if b.kind != flacBlockPadding {
+			unpadded = append(unpadded, b)
+		}
+	}
+	// 編集する側は先頭の STREAMINFO を前提にしている
+	if len(unpadded) == 0 {
+		return fmt.Errorf("%s: no metadata blocks besides padding", filepath.Base(path))
+	}
+	kept := edit(unpadded)
+
+	used := int64(len(encodeFLACMetadata(kept)))
+	if spare := audioOffset - used - 4; spare >= 0 && spare <= flacMaxBlockLen {
+		meta := encodeFLACMetadata(append(kept, flacBlock{kind: flacBlockPadding, data: make([]byte, spare)}))
+		_, err := f.WriteAt(meta, 0)
+		return err
+	}
+
+	const newPadding = 4096
+	meta := encodeFLACMetadata(append(kept, flacBlock{kind: flacBlockPadding, data: make([]byte, newPadding)}))
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".retag-*.flac")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	// CreateTemp は 0600 で作るので、元のファイルの権限 (メディアサーバーが読めるように) を引き継ぐ
+	info, err := f.Stat()
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(meta); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := f.Seek(audioOffset, io.SeekStart); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := io.Copy(tmp, f); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	f.Close()
+	return os.Rename(tmp.Name(), path)
+}
+
+// vorbisCommentKeys are the Vorbis comment names of ffmpeg's generic keys; the rest are just upper-cased.
+var vorbisCommentKeys = map[string]string{"album_artist": "ALBUMARTIST", "track": "TRACKNUMBER"}
+
+// writeFLACJob rewrites the tags (and the cover, when the job has one) of an existing FLAC file with
+// the same values convertAudio would write, without re-muxing the audio. Tags not written by this app
+// (ReplayGain, notes, ...) are kept; an existing cover is kept if the job has none.
+func writeFLACJob(path string, job convertJob) error {
+	t, err := readFLACTags(path)
+	if err != nil {
+		return err
+	}
+	if t.vendor == "" {
+		t.vendor = "yt-music"
+	}
+	var fresh []string
+	replace := map[string]bool{}
+	for _, kv := range job.metadata() {
+		key := vorbisCommentKeys[kv[0]]
+		if key == "" {
+			key = strings.ToUpper(kv[0])
+		}
+		replace[key] = true
+		if kv[1] != "" {
+			fresh = append(fresh, key+"="+kv[1])
+		}
+	}
+	// 歌詞・クレジットなど、今回の値が空のときも前回書き込んだものを残さない
+	for _, key := range []string{"LYRICS", "UNSYNCEDLYRICS", strings.ToUpper(cfg.Lyrics.SyncedTag), "INSTRUMENTAL", "COMPOSER", "LYRICIST", "ARRANGER", "COVERART_SOURCE", "COVERART_NOTE"} {
+		replace[key] = true
+	}
+	var kept []string
+	for _, c := range t.comments {
+		key, _, _ := strings.Cut(c, "=")
+		if !replace[strings.ToUpper(key)] {
+			kept = append(kept, c)
+		}
+	}
+	t.comments = append(kept, fresh...)
+	comment := t.encode()
+	if len(comment) > flacMaxBlockLen {
+		return errors.New("tags too large for a FLAC metadata block")
+	}
+	var picture []byte
+	if job.coverPath != "" {
+		if picture, err = pictureBlock(job.coverPath); err != nil {
+			return err
+		}
+		if len(picture) > flacMaxBlockLen {
+			return errors.New("cover too large for a FLAC metadata block")
+		}
+	}
+
+	return rewriteFLACMetadata(path, func(blocks []flacBlock) []flacBlock {
+		kept := []flacBlock{blocks[0], {kind: flacBlockVorbisComment, data: comment}}
+		if picture != nil {
+			kept = append(kept, flacBlock{kind: flacBlockPicture, data: picture})
+		}
+		for _, b := range blocks[1:] {
+			if b.kind == flacBlockVorbisComment || (picture != nil && b.kind == flacBlockPicture) {
+				continue
+			}
+			kept = append(kept, b)
+		}
+		return kept
+	})
+}
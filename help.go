@@ -0,0 +1,308 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- ヘルプオーバーレイ ---
+type helpEntry struct{ key, desc string }
+
+var helpKeyStyle lipgloss.Style
+
+// textEntry reports whether printable keys are currently consumed by a text input.
+func (m model) textEntry() bool {
+	return m.state == stateInput || m.state == stateEditTags || m.state == stateLyrics || (m.state == stateHistory && (m.historyTyping || m.noteEditing)) ||
+		((m.state == stateSelectYT || m.state == stateSelectMB || m.state == stateSelectTrack) && m.activeList().FilterState() == list.Filtering) ||
+		(m.state == stateReview && m.reviewList.FilterState() == list.Filtering) ||
+		(m.state == stateYTMusic && m.ytmList.FilterState() == list.Filtering) ||
+		(m.state == stateNewReleases && m.releaseList.FilterState() == list.Filtering) ||
+		(m.state == stateReplace && m.replPlan == nil)
+}
+
+func (m model) isHelpKey(msg tea.KeyMsg) bool {
+	if msg.Type == tea.KeyF1 {
+		return true
+	}
+	return msg.String() == "?" && !m.textEntry()
+}
+
+func stateName(s state) string {
+	switch s {
+	case stateCheckingDeps:
+		return tr("依存関係の確認")
+	case stateInput:
+		return tr("検索ワード入力")
+	case stateFetchingURLInfo, stateSearching:
+		return tr("検索中")
+	case stateSelectYT:
+		return tr("YouTube音源の選択")
+	case stateSelectMB:
+		return tr("MusicBrainzリリースの選択")
+	case stateSelectTrack:
+		return tr("トラックの選択")
+	case stateHistory:
+		return tr("ダウンロード履歴")
+	case stateEditTags:
+		return tr("タグの確認・編集")
+	case stateLyrics:
+		return tr("歌詞の確認・編集")
+	case stateCompare:
+		return tr("音源とトラックの比較")
+	case stateDownloading:
+		return tr("ダウンロード中")
+	case stateShowSuccess:
+		return tr("完了")
+	case stateConfirmSkipMB:
+		return tr("タグ無しダウンロードの確認")
+	case stateReplace:
+		return tr("タグの一括置換")
+	case stateQueue:
+		return tr("ダウンロードキュー")
+	case stateDiagnostics:
+		return tr("診断")
+	case stateTrim:
+		return tr("前後のトリム")
+	case stateReview:
+		return tr("要確認キュー")
+	case stateCookies:
+		return tr("Cookieの選択")
+	case stateLibrary:
+		return tr("ライブラリ")
+	case stateYTMusic:
+		return tr("YouTube Music のプレイリスト")
+	case stateNewReleases:
+		return tr("新譜")
+	case stateRecover:
+		return tr("中断したダウンロード")
+	case stateError:
+		return tr("エラー")
+	}
+	return ""
+}
+
+func stateHelp(s state) (keys []helpEntry, tips []string) {
+	ok, back, queue := keyName(keymap.confirm), keyName(keymap.back), keyName(keymap.queue)
+	listKeys := []helpEntry{{"↑/↓, k/j", tr("カーソル移動")}, {"←/→, PgUp/PgDn", tr("ページ切り替え")}, {"Home/End", tr("先頭/末尾へ")}, {"/", tr("絞り込み")}}
+	switch s {
+	case stateInput:
+		keys = []helpEntry{{ok, tr("検索を開始")}, {"Ctrl+R", tr("ダウンロード履歴を開く")}, {"Ctrl+L", tr("ライブラリを開く")}, {queue, tr("ダウンロードキューを開く")}, {"Ctrl+Y", tr("YouTube Music のプレイリストを開く")}, {"Ctrl+N", tr("ウォッチ中のアーティストの新譜を開く")}, {"Ctrl+O", tr("要確認キューを開く")}, {"Ctrl+D", tr("診断画面を開く")}}
+		tips = []string{
+			tr("「アーティスト 曲名」の形で入力すると、YouTubeとMusicBrainzを同時に検索します。"),
+			tr("YouTubeのURLを貼り付けると、その動画を音源として直接使用します。"),
+			tr("手持ちの音声ファイルのパスを入力すると、MusicBrainzの情報でタグ付けし直します。"),
+			tr("Spotify のプレイリストのURLを貼ると、全曲をキューに追加します (config.json に spotify の client_id / client_secret が必要)。"),
+			tr("フォルダのパスを入力すると、中の音声ファイルを1曲ずつ照合して downloads に取り込みます (Esc でその曲をスキップ)。"),
+			tr("subscribe add で登録したチャンネルの新着動画は、起動時に自動でキューに追加されます。"),
+		}
+	case stateSelectYT:
+		keys = append([]helpEntry{{ok, tr("この音源でMusicBrainzを検索 (一括処理中はダウンロード)")}, {"a", tr("音源とトラックを自動で照合")}, {"m", tr("続きの検索結果を読み込む")}, {back, tr("入力画面に戻る (一括処理中はこの曲をスキップ)")}, {queue, tr("キューを開く (一括処理中)")}}, listKeys...)
+		tips = []string{
+			tr("公式チャンネルや「- Topic」チャンネルの音源は音質・長さが正確なことが多いです。"),
+			tr("a を押すと、タイトル・長さ・アーティストの一致度から最適な音源とトラックを選び、タグ編集画面に進みます。"),
+		}
+	case stateSelectMB:
+		keys = append([]helpEntry{{ok, tr("このリリースのトラックを表示")}, {"a", tr("トラックを自動で照合")}, {"m", tr("続きの検索結果を読み込む")}, {"c", tr("発売国で絞り込む (もう一度押すと解除)")}, {"o", tr("公式リリースだけにする (切り替え)")}, {"t", tr("形態を切り替える (CD / Digital Media / すべて)")}, {"r", tr("録音 (recording) の検索に切り替える")}, {"g", tr("同じリリースグループの版 (再発・地域違いなど) をすべて表示")}, {keyName(keymap.skipMB), tr("タグ付けをスキップ")}, {back, tr("YouTube結果に戻る")}}, listKeys...)
+		tips = []string{
+			tr("同じアルバムでも複数の版 (CD/デジタル/地域違い) が表示されることがあります。c / o / t で国・公式・形態を絞り込むと探しやすくなります。"),
+			tr("目的のリリースが無い場合は s でYouTubeのタイトルのままダウンロードできます。"),
+			tr("◐ 3/12 25% のバッジは、そのリリースから保存済みの曲数です (● はすべて保存済み)。"),
+			tr("候補が多いときは / を押して文字を入力すると、タイトルや年・レーベルのあいまい一致で絞り込めます (もう一度 Esc で解除)。"),
+		}
+	case stateSelectTrack:
+		keys = append([]helpEntry{{ok, tr("このトラックのタグを編集 (選択中があれば一括処理)")}, {"Space", tr("トラックの選択/解除")}, {"q", tr("選択中のトラックをキューに追加")}, {queue, tr("キューを開く")}, {"x", tr("動画を複数曲に分割して保存")}, {"w", tr("動画を1ファイルのままチャプター付きで保存")}, {back, tr("リリース一覧に戻る")}}, listKeys...)
+		tips = []string{
+			tr("Space で複数のトラックに ✓ を付けて Enter を押すと、1曲ずつYouTube音源を選んで連続ダウンロードできます。"),
+			tr("シングル+カップリングのように2〜3曲が1本の動画に入っている場合、x でチャプターや無音区間から分割し、曲ごとにタグ付けして保存します。"),
+			tr("ライブやミックスなど1本のまま残したい動画は w で保存すると、トラックごとのチャプター (FLACではCUESHEETも) が埋め込まれ、プレーヤーで曲単位に移動できます。"),
+			tr("複数枚組のリリースでは Disc 番号も表示されます。"),
+		}
+	case stateEditTags:
+		keys = []helpEntry{{"↑/↓", tr("項目の移動")}, {ok, tr("次の項目へ / 最後の項目で決定")}, {"Ctrl+T", tr("Last.fm の補正・ジャンルを採用")}, {back, tr("トラック選択に戻る")}}
+		tips = []string{
+			tr("ISRC・レーベル・ディスク番号などはMusicBrainzの情報から自動で書き込まれます。"),
+			tr("「 - Topic」チャンネルの自動生成の動画では、概要欄の曲名・アーティスト・アルバム・発売日を使います (戻ると音源の選択へ)。"),
+			tr("lastfm.api_key を設定すると、ジャンルやリリース日が無い曲では Last.fm の表記補正とジャンルの候補が表示されます。"),
+			tr("歌詞はlrclib.netなど config.json の lyrics.providers の順に探し、見つかった場合のみ埋め込まれます。インストゥルメンタル曲は歌詞の代わりにINSTRUMENTALタグが付きます。"),
+		}
+	case stateHistory:
+		keys = append([]helpEntry{
+			{"f", tr("フィルタを入力")}, {"t", tr("今日のみ")}, {"w", tr("今週のみ")}, {"x", tr("失敗のみ")},
+			{"1〜5", tr("★の評価を付ける (同じ数字でもう一度押すと解除)")}, {"n", tr("メモを編集")},
+			{"c", tr("整理候補 (サイズ順) の表示切替")}, {"r", tr("表示中のファイルのタグを一括置換")}, {"Esc", tr("入力画面に戻る")},
+		}, listKeys...)
+		tips = []string{
+			tr("フィルタ例: artist:YOASOBI format:flac from:2024-01-01 to:2024-03-31 status:failed (スペース区切りで組み合わせ可)"),
+			tr("rating:4 で★4以上、note:語 でメモの内容を絞り込めます。通常の検索語もメモに一致します。"),
+			tr("t/w/x をもう一度押すとクイックフィルタを解除します。"), tr("config.json の library.max_size_mb でライブラリの上限を設定すると、超過時に警告と整理候補を表示します。")}
+	case stateCompare:
+		keys = []helpEntry{{"y, " + ok, tr("この組み合わせでダウンロード")}, {"l", tr("音声トラックの切り替え (複数ある動画のみ)")}, {"e", tr("歌詞の確認・編集")}, {"c", tr("動画の字幕から同期歌詞を作成 (歌詞が見つからない場合)")}, {"t", tr("前後のトリム")}, {"r", tr("ローマ字化の切り替え (ファイル名・タグ)")}, {"n, " + back, tr("タグ編集に戻る")}}
+		tips = []string{
+			tr("✗ が付いた項目は一致度が低い項目です。長さの差が大きい場合はMV版や別バージョンの可能性があります。"),
+			tr("吹き替えなど複数の音声トラックを持つ動画では、l で抽出するトラックを選べます。"),
+			tr("字幕から作った歌詞は自動生成字幕の場合誤りが多いので、編集画面で確認してください。"),
+		}
+	case stateReview:
+		keys = append([]helpEntry{
+			{"Enter", tr("候補をタグ編集画面で開く (候補が無い場合は検索)")}, {"s", tr("検索語で手動検索")}, {"d", tr("キューから削除")}, {"Esc", tr("入力画面に戻る")},
+		}, listKeys...)
+		tips = []string{
+			tr("daemon コマンドが一致度の低い曲をここに登録します。開いた項目はキューから消えます。"),
+			tr("自動でダウンロードする一致度は config.json の auto.accept_score (0〜1) で調整できます。"),
+		}
+	case stateNewReleases:
+		keys = append([]helpEntry{{"Enter", tr("最初の公式リリースの全曲をキューに追加")}, {"d", tr("一覧から消す")}, {"Esc", tr("入力画面に戻る")}}, listKeys...)
+		tips = []string{
+			tr("アーティストは watch add <名前> で登録します。起動時に watch.interval_hours ごとに MusicBrainz を確認します。"),
+			tr("不要な曲はキュー画面で d を押して消してから開始してください。"),
+		}
+	case stateYTMusic:
+		keys = append([]helpEntry{{"Enter", tr("プレイリストの曲をキューに追加")}, {"Esc", tr("入力画面に戻る")}}, listKeys...)
+		tips = []string{
+			tr("ログインには cookies の設定 (ブラウザのCookie) を使います。未設定ならブラウザの選択画面が開きます。"),
+			tr("追加した曲は動画が決まっているので、キューの実行時にYouTubeを検索せずにダウンロードします。"),
+		}
+	case stateCookies:
+		keys = []helpEntry{{"↑/↓, k/j", tr("ブラウザの選択")}, {"Enter", tr("このブラウザのCookieで再試行")}, {"s", tr("config.json に保存して再試行")}, {"Esc", tr("エラー画面へ")}}
+		tips = []string{
+			tr("年齢制限やメンバー限定の動画は、YouTubeにログインしたブラウザのCookieがあれば取得できます。"),
+			tr("ブラウザの起動中はCookieを読めないことがあります (特にChrome系)。失敗する場合はブラウザを閉じてから再試行してください。"),
+			tr("cookies.txt を使う場合は config.json の cookies.file にパスを指定してください。"),
+		}
+	case stateTrim:
+		keys = []helpEntry{
+			{"←/→, h/l", tr("0.5秒ずつ調整")}, {"Shift+←/→, H/L", tr("5秒ずつ調整")}, {"Tab, ↑/↓", tr("先頭/末尾の切り替え")},
+			{"p, Space", tr("切り取り位置から試聴")}, {"0", tr("トリムを解除")}, {"Enter", tr("決定して比較画面へ")}, {"Esc", tr("変更を破棄して比較画面へ")},
+		}
+		tips = []string{
+			tr("曲の前のトークや無音・黒画面を削るための画面です。トリムは変換時に適用され、元の動画には影響しません。"),
+			tr("試聴には ffplay (ffmpegに同梱) が必要です。先頭は切り取り位置から、末尾は切り取り位置の直前を再生します。"),
+			tr("MusicBrainzの長さと比べながら調整すると、曲の境目を合わせやすくなります。"),
+		}
+	case stateLyrics:
+		keys = []helpEntry{{"↑/↓, PgUp/PgDn", tr("スクロール")}, {"Ctrl+S", tr("編集内容を保存して比較画面へ")}, {"Esc", tr("編集を破棄して比較画面へ")}}
+		tips = []string{
+			tr("別の曲の歌詞が取得された場合や、翻訳者のクレジットなど不要な行がある場合はここで修正できます。"),
+			tr("すべて削除して保存すると歌詞は埋め込まれません。"),
+			tr("同期歌詞は [mm:ss.xx] のタイムスタンプごと編集できます。通常の歌詞はタイムスタンプを除いたものが自動で作られます。"),
+			tr("この画面を出さない場合は config.json の lyrics.review を false にしてください。"),
+		}
+	case stateConfirmSkipMB:
+		keys = []helpEntry{{"y, " + ok, tr("タグ無しでダウンロード")}, {"n, " + back, tr("YouTube結果に戻る")}, {"r", tr("リリースではなく録音 (recording) で検索し直す")}}
+	case stateReplace:
+		keys = []helpEntry{
+			{"↑/↓, Tab", tr("項目の移動")}, {"Enter", tr("次の項目へ / 最後の項目で変更をプレビュー")}, {"Ctrl+T", tr("正規表現のON/OFF")},
+			{"y, Enter", tr("プレビュー後に書き換えを実行")}, {"Esc", tr("戻る")},
+		}
+		tips = []string{
+			tr("対象は履歴画面で表示中 (フィルタ適用後) の、現存するFLACファイルです。先に artist:名前 などで絞り込んでおくと安全です。"),
+			tr("タグ名はカンマ区切りで複数指定できます (例: artist, albumartist)。空にすると歌詞以外のすべてのタグが対象です。"),
+			tr("タグだけを直接書き換えるため、音声は再エンコードされません。"),
+		}
+	case stateQueue:
+		keys = []helpEntry{
+			{"↑/↓, k/j", tr("カーソル移動")}, {"Space", tr("アルバムの折りたたみ切替")}, {"←/→, h/l", tr("折りたたむ / 展開する")},
+			{"d", tr("曲またはアルバムをキューから削除 (実行前のみ)")}, {"Enter", tr("キューのダウンロードを開始")}, {"Esc", tr("元の画面に戻る")},
+		}
+		tips = []string{
+			tr("複数のアルバムからトラックを q で追加し、まとめてダウンロードできます。曲はアルバムごとにまとめて表示されます。"),
+			tr("ダウンロード中の画面では処理中のアルバムだけが展開され、他のアルバムは進捗のみ表示されます。"),
+		}
+	case stateLibrary:
+		keys = []helpEntry{
+			{"↑/↓, k/j", tr("カーソル移動")}, {"PgUp/PgDn, Home/End", tr("ページ移動 / 先頭・末尾へ")}, {"Space, Enter", tr("グループの折りたたみ切替")}, {"←/→, h/l", tr("折りたたむ / 展開する")},
+			{"g", tr("グループ分けの切替 (アーティスト → アルバム → 年)")}, {"A〜Z, #", tr("その頭文字の見出しへ移動")}, {"[ / ]", tr("前 / 次の見出し (あ行・か行…) へ移動")}, {"Esc", tr("入力画面に戻る")},
+		}
+		tips = []string{
+			tr("並び順は config.json の library.sort_locale (既定は ja) に従います。日本語ではかなが五十音順に、全角・半角や大文字・小文字の違いは無視して並びます。"),
+			tr("漢字で始まる名前は読みが分からないため「他」の見出しにまとめられます。"),
+			tr("起動時のグループ分けは library.group_by (artist / album / year) で変更できます。"),
+		}
+	case stateDiagnostics:
+		keys = []helpEntry{{"r", tr("診断を再実行")}, {"Esc, q", tr("戻る")}}
+		tips = []string{
+			tr("ツールが ✗ の場合はインストールとPATHを確認してください。yt-dlp は実行ファイルと同じフォルダに置いても認識されます。"),
+			tr("ネットワークが ✗ の場合はファイアウォールやプロキシ設定を確認してください。"),
+			tr("不具合を報告する際はこの画面の内容を添えてください。"),
+		}
+	case stateError:
+		keys = []helpEntry{{"Ctrl+D", tr("診断画面を開く")}, {tr("任意のキー"), tr("最初の画面に戻る")}}
+		tips = []string{tr("詳細なログは %s に出力されます。", mainDir+"/"+logsDir+"/debug.log")}
+	case stateShowSuccess:
+		keys = []helpEntry{{tr("任意のキー"), tr("最初の画面に戻る")}}
+		tips = []string{tr("詳細なログは %s に出力されます。", mainDir+"/"+logsDir+"/debug.log")}
+	default:
+		tips = []string{tr("処理が終わるまでお待ちください。")}
+	}
+	keys = append(keys, helpEntry{"?, F1", tr("ヘルプの表示/非表示")}, helpEntry{keyName(keymap.quit), tr("終了")})
+	return keys, tips
+}
+
+// pipelineStages are the steps from a search to a saved file, shown at the top of the overlay.
+var pipelineStages = []struct {
+	name   string
+	states []state
+}{
+	{"検索", []state{stateInput, stateFetchingURLInfo, stateSearching}},
+	{"音源", []state{stateSelectYT}},
+	{"リリース", []state{stateSelectMB, stateConfirmSkipMB}},
+	{"トラック", []state{stateSelectTrack}},
+	{"タグ", []state{stateEditTags, stateLyrics}},
+	{"確認", []state{stateCompare, stateTrim}},
+	{"ダウンロード", []state{stateDownloading}},
+	{"完了", []state{stateShowSuccess}},
+}
+
+// pipelineView shows where the current screen sits in the download steps, with the queue position
+// and the running task. Screens outside the steps (history, library...) get nothing.
+func (m model) pipelineView() string {
+	current := -1
+	for i, st := range pipelineStages {
+		if slices.Contains(st.states, m.state) {
+			current = i
+		}
+	}
+	if current < 0 {
+		return ""
+	}
+	steps := make([]string, len(pipelineStages))
+	for i, st := range pipelineStages {
+		style := lipgloss.NewStyle().Foreground(commentColor)
+		if i == current {
+			style = lipgloss.NewStyle().Foreground(pinkColor).Bold(true).Underline(true)
+		}
+		steps[i] = style.Render(tr(st.name))
+	}
+	line := "  " + strings.Join(steps, helpStyle.Render(" → "))
+	if m.batchActive() && m.batchIndex < len(m.batch) {
+		line += helpStyle.Render(tr("  (キュー %d/%d: %s)", m.batchIndex+1, len(m.batch), m.batch[m.batchIndex].track.title))
+	}
+	if busy := m.state == stateSearching || m.state == stateFetchingURLInfo || m.state == stateDownloading; busy && m.statusMsg != "" {
+		line += "\n  " + helpStyle.Render(m.statusMsg)
+	}
+	return line + "\n\n"
+}
+
+func (m model) helpView() string {
+	keys, tips := stateHelp(m.state)
+	var b strings.Builder
+	b.WriteString(listTitleStyle.Render(tr("ヘルプ: %s", stateName(m.state))) + "\n\n")
+	b.WriteString(m.pipelineView())
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf("  %s %s\n", helpKeyStyle.Render(k.key), k.desc))
+	}
+	if len(tips) > 0 {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(pinkColor).Render(tr("ヒント")) + "\n")
+		for _, t := range tips {
+			b.WriteString(lipgloss.NewStyle().Foreground(fgColor).Width(m.width-12).Render("  • "+t) + "\n")
+		}
+	}
+	box := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(pinkColor).Padding(1, 2).Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, box, helpStyle.Render(tr("何かキーを押すとヘルプを閉じます"))))
+}
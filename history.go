@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// --- ダウンロード履歴 ---
+const historyFile = "history.json"
+
+type coverSource string
+
+const (
+	coverSourceNone        coverSource = ""
+	coverSourceCAARelease  coverSource = "caa-release"
+	coverSourceCAAGroup    coverSource = "caa-release-group"
+	coverSourceITunes      coverSource = "itunes"
+	coverSourceYTThumbnail coverSource = "youtube-thumbnail"
+)
+
+// isFallback reports whether the file has no art or only a low-quality fallback that a re-art pass should replace.
+func (s coverSource) isFallback() bool {
+	return s == coverSourceNone || s == coverSourceYTThumbnail
+}
+
+func (s coverSource) licenseNote() string {
+	switch s {
+	case coverSourceCAARelease, coverSourceCAAGroup:
+		return "Cover Art Archive (https://coverartarchive.org); image rights belong to their respective owners"
+	case coverSourceITunes:
+		return "iTunes Search API artwork; image rights belong to their respective owners"
+	case coverSourceYTThumbnail:
+		return "YouTube video thumbnail; image rights belong to the uploader"
+	}
+	return ""
+}
+
+type historyEntry struct {
+	Time        time.Time   `json:"time"`
+	Path        string      `json:"path"`
+	Title       string      `json:"title"`
+	Artist      string      `json:"artist"`
+	Album       string      `json:"album,omitempty"`
+	Date        string      `json:"date,omitempty"`
+	VideoID     string      `json:"video_id,omitempty"`
+	VideoURL    string      `json:"video_url,omitempty"`
+	ReleaseID   string      `json:"release_id,omitempty"`
+	RecordingID string      `json:"recording_id,omitempty"`
+	CoverSource coverSource `json:"cover_source,omitempty"`
+	Status      string      `json:"status,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Rating      int         `json:"rating,omitempty"` // ★1〜5 (0 は未評価)
+	Note        string      `json:"note,omitempty"`
+	// 埋め込んだ歌詞の種類 (synced / plain / instrumental / none)。空は記録前の履歴
+	Lyrics        string     `json:"lyrics,omitempty"`
+	LyricsChecked *time.Time `json:"lyrics_checked,omitempty"` // upgrade-lyrics で最後に確認した日時
+	// 送り先 (destinations) に送ったファイルの場所。keep_local が false ならローカルの Path は消えている
+	Remote []string `json:"remote,omitempty"`
+}
+
+const (
+	statusOK     = "ok"
+	statusFailed = "failed"
+)
+
+// status treats entries written before statuses were recorded as successful.
+func (e historyEntry) status() string {
+	if e.Status == "" {
+		return statusOK
+	}
+	return e.Status
+}
+
+var historyMu sync.Mutex
+
+func historyPath() string { return filepath.Join(mainDir, historyFile) }
+
+func loadHistory() ([]historyEntry, error) {
+	data, err := os.ReadFile(historyPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []historyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func saveHistory(entries []historyEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := historyPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, historyPath())
+}
+
+func appendHistory(e historyEntry) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Status == "" {
+		e.Status = statusOK
+	}
+	return saveHistory(append(entries, e))
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 履歴ブラウザ ---
+type historyRow struct {
+	entry     historyEntry
+	sizeBytes int64
+	exists    bool
+}
+
+type historyLoadedMsg struct {
+	rows []historyRow
+	err  error
+}
+
+func newHistoryFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = tr("today / week / failed / artist:名前 / format:flac / from:2024-01-01 to:2024-12-31")
+	ti.Width = 60
+	return ti
+}
+
+func loadHistoryCmd() tea.Msg {
+	entries, err := loadHistory()
+	if err != nil {
+		return historyLoadedMsg{err: err}
+	}
+	rows := make([]historyRow, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		row := historyRow{entry: entries[i]}
+		if info, err := os.Stat(entries[i].Path); err == nil {
+			row.sizeBytes, row.exists = info.Size(), true
+		}
+		rows = append(rows, row)
+	}
+	return historyLoadedMsg{rows: rows}
+}
+
+func historyItems(rows []historyRow, now time.Time) []list.Item {
+	items := make([]list.Item, 0, len(rows))
+	for _, r := range rows {
+		e := r.entry
+		desc := relativeTime(e.Time, now)
+		switch {
+		case e.status() == statusFailed:
+			desc += tr(" · ❌ 失敗: %s", firstLine(e.Error))
+		case r.exists:
+			desc += " · " + formatBytes(r.sizeBytes)
+		case len(e.Remote) > 0:
+			desc += " · ☁ " + e.Remote[0]
+		default:
+			desc += tr(" · ファイルなし")
+		}
+		if e.Album != "" {
+			desc += " · " + e.Album
+		}
+		if e.Note != "" {
+			desc += " · 📝 " + e.Note
+		}
+		items = append(items, item{title: fmt.Sprintf("%s - %s", e.Artist, e.Title), desc: desc, url: e.Path, meta: r, badge: ratingBadge(e.Rating)})
+	}
+	return items
+}
+
+// relativeTime renders a timestamp as "3分前" / "2日前", falling back to the date for older entries.
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return tr("たった今")
+	case d < time.Hour:
+		return tr("%d分前", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return tr("%d時間前", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		return tr("%d日前", int(d.Hours()/24))
+	case d < 30*24*time.Hour:
+		return tr("%d週間前", int(d.Hours()/24/7))
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// historyFilter narrows the history view. Query syntax (space separated, all must match):
+//
+//	today / week / failed        quick filters
+//	from:2024-01-01 to:2024-12-31 date range (inclusive)
+//	artist:名前 format:flac status:ok|failed
+//	rating:4                     rated 4 stars or more
+//	note:語                      note contains the word
+//	other words                  matched against artist, title, album and note
+type historyFilter struct {
+	from, to              time.Time
+	artist, format, state string
+	note                  string
+	minRating             int
+	words                 []string
+}
+
+func parseHistoryFilter(query string, now time.Time) (historyFilter, error) {
+	var f historyFilter
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, tok := range strings.Fields(query) {
+		key, value, hasValue := strings.Cut(tok, ":")
+		switch {
+		case tok == "today":
+			f.from = today
+		case tok == "week":
+			f.from = today.AddDate(0, 0, -int((today.Weekday()+6)%7)) // 月曜始まり
+		case tok == "failed":
+			f.state = statusFailed
+		case hasValue && key == "from", hasValue && key == "to":
+			d, err := time.ParseInLocation("2006-01-02", value, now.Location())
+			if err != nil {
+				return f, errorf("日付の形式が正しくありません: %s", value)
+			}
+			if key == "from" {
+				f.from = d
+			} else {
+				f.to = d.AddDate(0, 0, 1)
+			}
+		case hasValue && key == "artist":
+			f.artist = strings.ToLower(value)
+		case hasValue && key == "format":
+			f.format = strings.TrimPrefix(strings.ToLower(value), ".")
+		case hasValue && key == "status":
+			f.state = strings.ToLower(value)
+		case hasValue && key == "rating":
+			n, err := strconv.Atoi(strings.TrimSuffix(value, "+"))
+			if err != nil || n < 0 || n > maxRating {
+				return f, errorf("評価は0〜%dで指定してください: %s", maxRating, value)
+			}
+			f.minRating = n
+		case hasValue && key == "note":
+			f.note = strings.ToLower(value)
+		default:
+			f.words = append(f.words, strings.ToLower(tok))
+		}
+	}
+	return f, nil
+}
+
+func (f historyFilter) match(r historyRow) bool {
+	e := r.entry
+	if !f.from.IsZero() && e.Time.Before(f.from) {
+		return false
+	}
+	if !f.to.IsZero() && !e.Time.Before(f.to) {
+		return false
+	}
+	if f.artist != "" && !strings.Contains(strings.ToLower(e.Artist), f.artist) {
+		return false
+	}
+	if f.format != "" && strings.TrimPrefix(strings.ToLower(filepath.Ext(e.Path)), ".") != f.format {
+		return false
+	}
+	if f.state != "" && e.status() != f.state {
+		return false
+	}
+	if e.Rating < f.minRating || (f.note != "" && !strings.Contains(strings.ToLower(e.Note), f.note)) {
+		return false
+	}
+	haystack := strings.ToLower(e.Artist + " " + e.Title + " " + e.Album + " " + e.Note)
+	for _, w := range f.words {
+		if !strings.Contains(haystack, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func filterHistory(rows []historyRow, f historyFilter) []historyRow {
+	var out []historyRow
+	for _, r := range rows {
+		if f.match(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// cleanupCandidates lists existing files largest first with the space a lossy conversion would free.
+func cleanupCandidates(rows []historyRow) []list.Item {
+	var existing []historyRow
+	for _, r := range rows {
+		if r.exists {
+			existing = append(existing, r)
+		}
+	}
+	sort.Slice(existing, func(i, j int) bool { return existing[i].sizeBytes > existing[j].sizeBytes })
+	items := make([]list.Item, 0, len(existing))
+	for _, r := range existing {
+		e := r.entry
+		desc := tr("%s · Opus変換で約%s削減 · %s", formatBytes(r.sizeBytes), formatBytes(lossySavings(r.sizeBytes, 0)), e.Time.Local().Format("2006-01-02"))
+		items = append(items, item{title: fmt.Sprintf("%s - %s", e.Artist, e.Title), desc: desc, url: e.Path, meta: r})
+	}
+	return items
+}
+
+// visibleHistory applies the current filter query; label describes the filter or its parse error.
+func (m model) visibleHistory(now time.Time) (rows []historyRow, label string) {
+	rows, label = m.historyRows, m.historyQuery
+	if m.historyQuery != "" {
+		if f, err := parseHistoryFilter(m.historyQuery, now); err != nil {
+			label = err.Error()
+		} else {
+			rows = filterHistory(rows, f)
+		}
+	}
+	return rows, label
+}
+
+func (m *model) showHistoryList() {
+	now := time.Now()
+	rows, label := m.visibleHistory(now)
+	if m.historyPrune {
+		title := tr("整理候補 (サイズ順)")
+		if budget := cfg.Library.budgetBytes(); budget > 0 && m.libraryBytes > budget {
+			title = tr("整理候補 (サイズ順) — 上限を%s超過しています", formatBytes(m.libraryBytes-budget))
+		}
+		m.historyList = newList(title, cleanupCandidates(rows))
+	} else {
+		title := tr("ダウンロード履歴 (%d件)", len(rows))
+		if m.historyQuery != "" {
+			title = tr("ダウンロード履歴 (%d/%d件) — %s", len(rows), len(m.historyRows), label)
+		}
+		m.historyList = newList(title, historyItems(rows, now))
+	}
+	m.historyList.SetSize(m.width-4, m.height-8)
+}
+
+// setHistoryQuickFilter toggles a single-keyword filter such as "today".
+func (m *model) setHistoryQuickFilter(q string) {
+	if m.historyQuery == q {
+		q = ""
+	}
+	m.historyQuery = q
+	m.historyInput.SetValue(q)
+	m.showHistoryList()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// --- ダウンロード後のフック ---
+// ダウンロードが完了するたびに、設定したコマンドを保存先・タグ・MBIDを環境変数に入れて実行する。
+// 同期・変換・通知などを利用者のスクリプトでつなげられるようにする。コマンドは sh -c (Windows は cmd /C) で実行する。
+const defaultHookTimeout = 300
+
+type hooksConfig struct {
+	// ダウンロード完了後に実行するコマンド。空なら実行しない
+	// 例: "rsync -a \"$GMD_PATH\" nas:/music/"
+	PostDownload string `json:"post_download"`
+	// コマンドの制限時間 (秒)
+	TimeoutSec int `json:"timeout_sec"`
+}
+
+// hookEnv lists the variables handed to the hook, in the order the README documents them.
+func hookEnv(finalPath string, job convertJob, selectedYT, selectedMB item) []string {
+	tags := job.tags
+	var releaseGroupID string
+	if release, ok := selectedMB.meta.(MBRelease); ok {
+		releaseGroupID = release.ReleaseGroup.ID
+	}
+	vars := [][2]string{
+		{"GMD_PATH", finalPath},
+		{"GMD_TITLE", tags.Title},
+		{"GMD_ARTIST", tags.Artist},
+		{"GMD_ALBUM", tags.Album},
+		{"GMD_ALBUM_ARTIST", tags.AlbumArtist},
+		{"GMD_DATE", tags.Date},
+		{"GMD_TRACK_NUMBER", tags.TrackNumber},
+		{"GMD_DISC_NUMBER", fmt.Sprint(tags.DiscNumber)},
+		{"GMD_GENRE", tags.Genre},
+		{"GMD_ISRC", tags.ISRC},
+		{"GMD_RECORDING_ID", tags.RecordingID},
+		{"GMD_RELEASE_ID", mbReleaseID(selectedMB.id)},
+		{"GMD_RELEASE_GROUP_ID", releaseGroupID},
+		{"GMD_VIDEO_ID", selectedYT.id},
+		{"GMD_VIDEO_URL", selectedYT.url},
+	}
+	env := make([]string, len(vars))
+	for i, v := range vars {
+		env[i] = v[0] + "=" + v[1]
+	}
+	return env
+}
+
+// runPostDownloadHook runs the configured command for a finished download. Its output goes to the
+// log; a failing hook never fails the download.
+func runPostDownloadHook(finalPath string, job convertJob, selectedYT, selectedMB item) {
+	command := strings.TrimSpace(cfg.Hooks.PostDownload)
+	if command == "" {
+		return
+	}
+	timeout := cfg.Hooks.TimeoutSec
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(), hookEnv(finalPath, job, selectedYT, selectedMB)...)
+	output, err := cmd.CombinedOutput()
+	if out := strings.TrimSpace(string(output)); out != "" {
+		log.Printf("Hook: %s", out)
+	}
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		log.Printf("Hook: timed out after %ds for %s", timeout, finalPath)
+	case err != nil:
+		log.Printf("Hook: failed for %s: %v", finalPath, err)
+	}
+}
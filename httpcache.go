@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// --- HTTPレスポンスのキャッシュ ---
+// MusicBrainzのJSONとカバー画像をURLごとにファイルへ保存し、TTLの間は再取得しない。
+const (
+	cacheDir        = "cache"
+	missingSuffix   = ".missing" // 404 だったURL (CAAで画像が無いリリースなど)
+	defaultCacheTTL = 7 * 24
+)
+
+func (c cacheConfig) ttl() time.Duration { return time.Duration(c.TTLHours) * time.Hour }
+
+func cacheFile(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(mainDir, cacheDir, hex.EncodeToString(sum[:]))
+}
+
+func fresh(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) < cfg.Cache.ttl()
+}
+
+// cacheGet returns the cached body of rawURL. missing is true when the URL was cached as a 404.
+func cacheGet(rawURL string) (body []byte, missing, ok bool) {
+	if !cfg.Cache.Enabled {
+		return nil, false, false
+	}
+	path := cacheFile(rawURL)
+	if fresh(path + missingSuffix) {
+		return nil, true, true
+	}
+	if !fresh(path) {
+		return nil, false, false
+	}
+	body, err := os.ReadFile(path)
+	return body, false, err == nil
+}
+
+func cachePut(rawURL string, body []byte) {
+	if !cfg.Cache.Enabled {
+		return
+	}
+	if err := writeCacheFile(cacheFile(rawURL), body); err != nil {
+		log.Printf("Cache: failed to store %s: %v", rawURL, err)
+	}
+}
+
+func cachePutMissing(rawURL string) {
+	if !cfg.Cache.Enabled {
+		return
+	}
+	if err := writeCacheFile(cacheFile(rawURL)+missingSuffix, nil); err != nil {
+		log.Printf("Cache: failed to store %s: %v", rawURL, err)
+	}
+}
+
+// writeCacheFile goes through a temp file so a concurrent reader never sees a partial body.
+func writeCacheFile(path string, body []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// pruneHTTPCache deletes expired entries so the cache doesn't grow forever.
+func pruneHTTPCache() {
+	entries, err := os.ReadDir(filepath.Join(mainDir, cacheDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Printf("Cache: failed to read cache dir: %v", err)
+		return
+	}
+	removed := 0
+	for _, e := range entries {
+		path := filepath.Join(mainDir, cacheDir, e.Name())
+		if !fresh(path) {
+			if os.Remove(path) == nil {
+				removed++
+			}
+		}
+	}
+	if removed > 0 {
+		log.Printf("Cache: removed %d expired entries", removed)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// --- HTTP クライアント ---
+// MusicBrainz・歌詞・カバー画像などすべての通信で1つのクライアントを共有し、接続を再利用する。
+const (
+	defaultUserAgent   = "GoMusicDownloader/1.7 ( your-contact-info@example.com )"
+	defaultHTTPTimeout = 30
+)
+
+type networkConfig struct {
+	// 例: http://proxy.example.com:8080 / socks5://127.0.0.1:1080 。空なら環境変数に従う
+	Proxy string `json:"proxy"`
+	// 1回のリクエスト (応答の読み込みを含む) の制限時間 (秒)
+	TimeoutSec int `json:"timeout_sec"`
+	// 追加で信頼するCA証明書 (PEM) のパス。社内のTLS検査プロキシなどで使う
+	CAFile string `json:"ca_file"`
+	// 送信する User-Agent。空なら既定値
+	UserAgent string `json:"user_agent"`
+}
+
+var httpClient = newHTTPClient()
+
+// newHTTPClient builds the shared client from the network config. A CA file that can't be used is
+// logged and the system roots are used alone, so a typo doesn't take every provider down.
+func newHTTPClient() *http.Client {
+	transport := &http.Transport{
+		Proxy:                 proxyForRequest,
+		DialContext:           (&net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          32,
+		MaxIdleConnsPerHost:   4,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	if cfg.Network.CAFile != "" {
+		if pool, err := loadCAPool(cfg.Network.CAFile); err != nil {
+			log.Printf("HTTP: ignoring ca_file: %v", err)
+		} else {
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+		}
+	}
+	timeout := cfg.Network.TimeoutSec
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	return &http.Client{Transport: transport, Timeout: time.Duration(timeout) * time.Second}
+}
+
+// loadCAPool adds the certificates in path to the system roots.
+func loadCAPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", path)
+	}
+	return pool, nil
+}
+
+// setupHTTPClient rebuilds the shared client once the config is loaded.
+func setupHTTPClient() {
+	httpClient = newHTTPClient()
+	if p := proxySetting(); p != "" {
+		if u, err := url.Parse(p); err == nil {
+			log.Printf("Proxy: using %s://%s", u.Scheme, u.Host)
+		} else {
+			log.Printf("Proxy: invalid proxy %q: %v", p, err)
+		}
+	}
+}
+
+func userAgent() string {
+	if cfg.Network.UserAgent != "" {
+		return cfg.Network.UserAgent
+	}
+	return defaultUserAgent
+}
+
+// newGetRequest builds a GET request carrying the app's User-Agent.
+func newGetRequest(rawURL string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	return req, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// --- 表示言語 ---
+// 画面の文言は日本語をそのままキーにして、英語の訳を i18n_en.go のカタログから引く。
+// 言語は ui.language ("auto" / "ja" / "en") で選び、auto なら LC_ALL / LC_MESSAGES / LANG から決める。
+// カタログにない文言は日本語のまま表示する。ログは英語のまま。
+const (
+	langAuto     = "auto"
+	langJapanese = "ja"
+	langEnglish  = "en"
+)
+
+type uiConfig struct {
+	// 表示言語: auto (環境変数 LANG などから判定) / ja / en
+	Language string `json:"language"`
+	// 配色のプリセット: dark (既定) / light / auto (端末の背景色から選ぶ)。theme.go を参照
+	Theme string `json:"theme"`
+	// プリセットの色を個別に上書きする (例: {"cyan": "#005f87"})
+	Colors map[string]string `json:"colors"`
+	// マウスでリストの選択・スクロールと操作説明のクリックをする (既定は無効。有効にすると端末での文字の選択は Shift+ドラッグになる)
+	Mouse bool `json:"mouse"`
+}
+
+// uiLang starts from the environment so messages printed before the config is read are translated
+// too; setupLanguage applies ui.language once it is.
+var uiLang = detectLanguage()
+
+// detectLanguage reads the locale variables in POSIX order. Japanese stays the default when none is
+// set (Windows, minimal containers), as it was before English existed.
+func detectLanguage() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(key)
+		if v == "" {
+			continue
+		}
+		if v == "C" || v == "POSIX" || strings.HasPrefix(v, "C.") {
+			return langJapanese
+		}
+		if strings.HasPrefix(strings.ToLower(v), "ja") {
+			return langJapanese
+		}
+		return langEnglish
+	}
+	return langJapanese
+}
+
+func setupLanguage() {
+	switch cfg.UI.Language {
+	case langJapanese, langEnglish:
+		uiLang = cfg.UI.Language
+	default:
+		uiLang = detectLanguage()
+	}
+}
+
+// lookup returns the message in the UI language, falling back to the Japanese key.
+func lookup(key string) string {
+	if uiLang == langEnglish {
+		if s, ok := enMessages[key]; ok {
+			return s
+		}
+	}
+	return key
+}
+
+// tr translates a message; with args the translation is used as the format.
+func tr(format string, args ...interface{}) string {
+	if len(args) == 0 {
+		return lookup(format)
+	}
+	return fmt.Sprintf(lookup(format), args...)
+}
+
+// errorf is fmt.Errorf with a translated format, so %w still wraps.
+func errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(lookup(format), args...)
+}
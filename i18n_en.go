@@ -0,0 +1,677 @@
+package main
+
+// enMessages is the English catalog, keyed by the Japanese message. The format verbs (%d, %s, %w)
+// must stay in the same order as in the key.
+var enMessages = map[string]string{
+	"アーティスト名と曲名、またはYouTubeのURLを入力してください...": "Enter an artist and title, or a YouTube URL...",
+	"依存関係を確認中...":                      "Checking dependencies...",
+	"⏭ %s (スキップ)":                      "⏭ %s (skipped)",
+	"最適な音源とトラックを自動で照合中です...":           "Matching the best source and track automatically...",
+	"トラックリストを取得中です...":                 "Fetching the tracklist...",
+	"最適なトラックを自動で照合中です...":              "Matching the best track automatically...",
+	"⏭ %s: スキップ":                       "⏭ %s: skipped",
+	"ファイルのタグ付けでは1曲を選んで Enter を押してください": "When tagging a file, pick one track and press Enter",
+	"動画を%d曲に分割してダウンロード中です...":          "Splitting the video into %d tracks and downloading...",
+	"分割できるのは%d〜%d曲です (Spaceで対象を選択)":    "Splitting needs %d to %d tracks (select them with Space)",
+	"%d曲分のチャプター付きで1ファイルにダウンロード中です...":  "Downloading as one file with chapters for %d tracks...",
+	"ジャケット・歌詞を取得してタグを書き換え中です...":       "Fetching cover art and lyrics, rewriting tags...",
+	"音声・ジャケット・歌詞を取得中です...":             "Fetching audio, cover art and lyrics...",
+	"YouTubeの字幕を取得中です...":              "Fetching YouTube subtitles...",
+	"依存関係を再確認中です...":                   "Checking dependencies again...",
+	"履歴を読み込み中です...":                    "Loading history...",
+	"ライブラリを読み込み中です...":                 "Loading library...",
+	"Spotifyのプレイリストを取得中です...":          "Fetching the Spotify playlist...",
+	"URLから情報を取得中です...":                 "Fetching info from the URL...",
+	"YouTubeとMusicBrainzを検索中です...":     "Searching YouTube and MusicBrainz...",
+	"ffmpegが見つかりません。\n音声変換には必須です。OSに合わせてインストールしてください。\n(例: brew install ffmpeg)": "ffmpeg was not found.\nIt is required for audio conversion. Install it for your OS.\n(e.g. brew install ffmpeg)",
+	"一致するタグはありませんでした":                     "No tags matched",
+	"%dファイル / %d項目を変更":                    "%d files / %d changes",
+	"%d件を書き換えた後にエラーが発生しました:\n%w":          "An error occurred after rewriting %d files:\n%w",
+	"%d件のファイルのタグを書き換えました":                 "Rewrote the tags of %d files",
+	"自動処理で確認が必要な曲 (%d件)":                  "Tracks that need review (%d)",
+	"プレビュー取得失敗":                           "Preview failed",
+	"配信元に画像なし (YouTubeサムネイルを使用)":          "No image from the source (using the YouTube thumbnail)",
+	"MusicBrainzでメタデータを検索中です...":          "Searching MusicBrainz for metadata...",
+	"どの音源をダウンロードしますか？":                    "Which source do you want to download?",
+	"どのリリースからタグ情報を取得しますか？":                "Which release should the tags come from?",
+	"見つかりませんでした":                          "Not found",
+	"(%d/%d) 「%s」の音源を選択してください":            "(%d/%d) Choose a source for \"%s\"",
+	"自動選択: %s\n  %s":                      "Auto-selected: %s\n  %s",
+	"YouTube Music: どのプレイリストをキューに追加しますか？": "YouTube Music: which playlist should be queued?",
+	"⏭ %s: MusicBrainzで見つかりませんでした":        "⏭ %s: not found on MusicBrainz",
+	"MusicBrainzで「%s」が見つかりませんでした。\nファイル名かタグを曲名に直してからもう一度お試しください。": "\"%s\" was not found on MusicBrainz.\nRename the file or fix its tags to the song title and try again.",
+	"選択したリリースにはトラック情報が含まれていませんでした。別のリリースを選択してください。":               "The selected release has no track information. Choose another release.",
+	"「%s」から曲を選択してください":                                                                            "Choose a track from \"%s\"",
+	" — この動画は全曲入りのようです (x: 分割 / w: 1ファイル)":                                                        " — this video seems to contain the whole release (x: split / w: one file)",
+	"ダウンロード失敗":                                                                                    "Download failed",
+	"ダウンロード完了":                                                                                    "Download complete",
+	"✅ ダウンロード完了":                                                                                  "✅ Download complete",
+	"何かキーを押すと最初の画面に戻ります...":                                                                       "Press any key to return to the start screen...",
+	"  Enter: 候補を開く | s: 手動で検索 | d: 削除 | Esc: 戻る | ?: ヘルプ":                                        "  Enter: open candidate | s: search manually | d: delete | Esc: back | ?: help",
+	"  Enter: 全曲をキューに追加 | d: 一覧から消す | /: 絞り込み | Esc: 戻る | ?: ヘルプ":                                 "  Enter: queue all tracks | d: dismiss | /: filter | Esc: back | ?: help",
+	"  y/Enter: キューに入れ直す | n/Esc: 破棄 | ?: ヘルプ":                                                    "  y/Enter: queue again | n/Esc: discard | ?: help",
+	"  Enter: キューに追加 | /: 絞り込み | Esc: 戻る | ?: ヘルプ":                                                "  Enter: add to queue | /: filter | Esc: back | ?: help",
+	"  ↑/↓: 選択 | Enter: このブラウザで再試行 | s: 設定に保存して再試行 | Esc: やめる | ?: ヘルプ":                           "  ↑/↓: select | Enter: retry with this browser | s: save to config and retry | Esc: cancel | ?: help",
+	"  ←/→: ±0.5秒 | Shift+←/→: ±5秒 | Tab: 先頭/末尾 | p: 試聴 | 0: リセット | Enter: 決定 | Esc: 戻る | ?: ヘルプ": "  ←/→: ±0.5s | Shift+←/→: ±5s | Tab: start/end | p: preview | 0: reset | Enter: confirm | Esc: back | ?: help",
+	"  Ctrl+S: 保存して次へ | Esc: 編集を破棄して次へ | F1: ヘルプ":                                                 "  Ctrl+S: save and continue | Esc: discard edits and continue | F1: help",
+	"  y/Enter: 書き換える | n/Esc: 編集に戻る | ?: ヘルプ":                                                    "  y/Enter: rewrite | n/Esc: back to editing | ?: help",
+	"  Enter: 次へ/プレビュー | ↑/↓: 移動 | Ctrl+T: 正規表現 | Esc: 履歴に戻る | F1: ヘルプ":                           "  Enter: next/preview | ↑/↓: move | Ctrl+T: regex | Esc: back to history | F1: help",
+	"  ↑/↓: 移動 | Space/←/→: 折りたたみ | g: グループ切替 | A〜Z/#: 頭文字へ | [/]: 前/次の見出し | Esc: 戻る | ?: ヘルプ":    "  ↑/↓: move | Space/←/→: fold | g: switch grouping | A–Z/#: jump to letter | [/]: prev/next heading | Esc: back | ?: help",
+	"  ↑/↓: 移動 | Space/←/→: 折りたたみ | Esc: 戻る | ?: ヘルプ":                                             "  ↑/↓: move | Space/←/→: fold | Esc: back | ?: help",
+	"  ↑/↓: 移動 | Space/←/→: 折りたたみ | d: 削除 | Enter: 開始 | Esc: 戻る | ?: ヘルプ":                         "  ↑/↓: move | Space/←/→: fold | d: delete | Enter: start | Esc: back | ?: help",
+	"自動処理で確認が必要な曲が %d 件あります (Ctrl+O で確認)":                                                         "%d tracks from automatic processing need review (Ctrl+O to review)",
+	"ウォッチ中のアーティストの新譜が %d 件あります (Ctrl+N で確認)":                                                      "%d new releases from watched artists (Ctrl+N to view)",
+	"ダウンロードはサーバー (%s) で行います":                                                                      "Downloads run on the server (%s)",
+	"MusicBrainzにデータが見つかりませんでした。":                                                                 "No data was found on MusicBrainz.",
+	"YouTubeのタイトルを元にタグ無しでダウンロードしますか？":                                                             "Download without tags, using the YouTube title?",
+	"  r: 再実行 | Esc: 戻る | ?: ヘルプ":                                                                 "  r: run again | Esc: back | ?: help",
+	"%s\n  メモ: %s":             "%s\n  Note: %s",
+	"  Enter: 保存 | Esc: キャンセル": "  Enter: save | Esc: cancel",
+	"%s\n  フィルタ: %s":           "%s\n  Filter: %s",
+	"  Enter: 適用 | Esc: キャンセル | 例: artist:名前 format:flac from:2024-01-01 rating:4 failed":          "  Enter: apply | Esc: cancel | e.g. artist:name format:flac from:2024-01-01 rating:4 failed",
+	"  f: フィルタ | t: 今日 | w: 今週 | x: 失敗のみ | 1-5: 評価 | n: メモ | c: 整理候補 | r: 一括置換 | Esc: 戻る | ?: ヘルプ": "  f: filter | t: today | w: this week | x: failed only | 1-5: rating | n: note | c: cleanup | r: replace | Esc: back | ?: help",
+	"\nメタデータを確認・編集してください:\n\n":                                                                     "\nCheck and edit the metadata:\n\n",
+	"タイトル:":        "Title:",
+	"アーティスト:":      "Artist:",
+	"アルバム:":        "Album:",
+	"リリース日:":       "Release date:",
+	"トラック番号:":      "Track number:",
+	"取得中...":       "Fetching...",
+	"歌詞:":          "Lyrics:",
+	"❌ エラーが発生しました": "❌ An error occurred",
+	"  何かキーを押すと最初の画面に戻ります...":                          "  Press any key to return to the start screen...",
+	"yt-dlpが見つかりません。パスが通っているか、実行ファイルと同じフォルダに配置してください。": "yt-dlp was not found. Make sure it is on your PATH or next to the executable.",
+	"URL情報の取得がタイムアウトしました (30s)":                        "Fetching URL info timed out (30s)",
+	"URL情報の取得に失敗:\n%s":                                 "Failed to fetch URL info:\n%s",
+	"URL情報のJSON解析に失敗:\n%v":                             "Failed to parse URL info JSON:\n%v",
+	"YouTube検索がタイムアウトしました":                             "YouTube search timed out",
+	"YouTube検索に失敗:\n%s":                                "YouTube search failed:\n%s",
+	"ディレクトリの作成に失敗しました: %v\n":                           "Failed to create directories: %v\n",
+	"ログファイルの作成に失敗しました: %v\n":                           "Failed to create the log file: %v\n",
+	"エラー: %v\n":                "Error: %v\n",
+	"アプリケーションエラー: %v":          "Application error: %v",
+	"依存関係の確認":                  "Checking dependencies",
+	"検索ワード入力":                  "Search",
+	"検索中":                      "Searching",
+	"YouTube音源の選択":             "Choose a YouTube source",
+	"MusicBrainzリリースの選択":       "Choose a MusicBrainz release",
+	"トラックの選択":                  "Choose a track",
+	"ダウンロード履歴":                 "Download history",
+	"タグの確認・編集":                 "Check and edit tags",
+	"歌詞の確認・編集":                 "Check and edit lyrics",
+	"音源とトラックの比較":               "Compare source and track",
+	"ダウンロード中":                  "Downloading",
+	"完了":                       "Done",
+	"タグ無しダウンロードの確認":            "Confirm download without tags",
+	"タグの一括置換":                  "Bulk tag replace",
+	"ダウンロードキュー":                "Download queue",
+	"診断":                       "Diagnostics",
+	"前後のトリム":                   "Trim",
+	"要確認キュー":                   "Review queue",
+	"Cookieの選択":                "Choose cookies",
+	"ライブラリ":                    "Library",
+	"YouTube Music のプレイリスト":    "YouTube Music playlists",
+	"新譜":                       "New releases",
+	"中断したダウンロード":               "Interrupted downloads",
+	"エラー":                      "Error",
+	"カーソル移動":                   "Move cursor",
+	"ページ切り替え":                  "Change page",
+	"先頭/末尾へ":                   "Go to first/last",
+	"絞り込み":                     "Filter",
+	"検索を開始":                    "Start the search",
+	"ダウンロード履歴を開く":              "Open download history",
+	"ライブラリを開く":                 "Open library",
+	"ダウンロードキューを開く":             "Open download queue",
+	"YouTube Music のプレイリストを開く": "Open YouTube Music playlists",
+	"ウォッチ中のアーティストの新譜を開く": "Open new releases from watched artists",
+	"要確認キューを開く":          "Open review queue",
+	"診断画面を開く":            "Open diagnostics",
+	"「アーティスト 曲名」の形で入力すると、YouTubeとMusicBrainzを同時に検索します。":                                             "Type \"artist title\" to search YouTube and MusicBrainz at once.",
+	"YouTubeのURLを貼り付けると、その動画を音源として直接使用します。":                                                         "Paste a YouTube URL to use that video as the source directly.",
+	"手持ちの音声ファイルのパスを入力すると、MusicBrainzの情報でタグ付けし直します。":                                                 "Enter the path of an audio file you have to retag it with MusicBrainz data.",
+	"Spotify のプレイリストのURLを貼ると、全曲をキューに追加します (config.json に spotify の client_id / client_secret が必要)。": "Paste a Spotify playlist URL to queue all its tracks (needs spotify client_id / client_secret in config.json).",
+	"フォルダのパスを入力すると、中の音声ファイルを1曲ずつ照合して downloads に取り込みます (Esc でその曲をスキップ)。":                            "Enter a folder path to match its audio files one by one and import them into downloads (Esc skips a track).",
+	"subscribe add で登録したチャンネルの新着動画は、起動時に自動でキューに追加されます。":                                             "New videos from channels added with subscribe add are queued automatically at startup.",
+	"この音源でMusicBrainzを検索 (一括処理中はダウンロード)":                                                            "Search MusicBrainz with this source (download when processing a batch)",
+	"音源とトラックを自動で照合":                               "Match source and track automatically",
+	"入力画面に戻る (一括処理中はこの曲をスキップ)":                    "Back to input (skip this track when processing a batch)",
+	"キューを開く (一括処理中)":                              "Open queue (while processing a batch)",
+	"公式チャンネルや「- Topic」チャンネルの音源は音質・長さが正確なことが多いです。": "Official channels and \"- Topic\" channels usually have accurate quality and length.",
+	"a を押すと、タイトル・長さ・アーティストの一致度から最適な音源とトラックを選び、タグ編集画面に進みます。": "Press a to pick the best source and track by title, length and artist, and go to the tag editor.",
+	"このリリースのトラックを表示": "Show this release's tracks",
+	"トラックを自動で照合":     "Match the track automatically",
+	"タグ付けをスキップ":      "Skip tagging",
+	"YouTube結果に戻る":   "Back to YouTube results",
+	"目的のリリースが無い場合は s でYouTubeのタイトルのままダウンロードできます。":      "If the release you want is missing, press s to download with the YouTube title.",
+	"◐ 3/12 25% のバッジは、そのリリースから保存済みの曲数です (● はすべて保存済み)。": "A ◐ 3/12 25% badge is how many tracks of the release you have saved (● means all of them).",
+	"このトラックのタグを編集 (選択中があれば一括処理)":                       "Edit this track's tags (batch if tracks are selected)",
+	"トラックの選択/解除":            "Select/deselect track",
+	"選択中のトラックをキューに追加":       "Add selected tracks to the queue",
+	"キューを開く":                "Open queue",
+	"動画を複数曲に分割して保存":         "Split the video into several tracks",
+	"動画を1ファイルのままチャプター付きで保存": "Save the video as one file with chapters",
+	"リリース一覧に戻る":             "Back to releases",
+	"Space で複数のトラックに ✓ を付けて Enter を押すと、1曲ずつYouTube音源を選んで連続ダウンロードできます。":                        "Mark several tracks ✓ with Space and press Enter to pick a YouTube source for each and download them in a row.",
+	"シングル+カップリングのように2〜3曲が1本の動画に入っている場合、x でチャプターや無音区間から分割し、曲ごとにタグ付けして保存します。":                   "If a video holds 2–3 songs, like a single with its B-sides, x splits it by chapters or silence and tags each track.",
+	"ライブやミックスなど1本のまま残したい動画は w で保存すると、トラックごとのチャプター (FLACではCUESHEETも) が埋め込まれ、プレーヤーで曲単位に移動できます。": "Save videos you want to keep whole, like live sets or mixes, with w: per-track chapters (and a CUESHEET for FLAC) are embedded so players can skip between tracks.",
+	"複数枚組のリリースでは Disc 番号も表示されます。": "Releases with several discs also show the disc number.",
+	"項目の移動": "Move between fields",
+	"次の項目へ / 最後の項目で決定":    "Next field / confirm on the last field",
+	"Last.fm の補正・ジャンルを採用": "Use Last.fm corrections and genres",
+	"トラック選択に戻る":           "Back to track selection",
+	"ISRC・レーベル・ディスク番号などはMusicBrainzの情報から自動で書き込まれます。":                                                                "ISRC, label, disc number and the like are written from MusicBrainz automatically.",
+	"lastfm.api_key を設定すると、ジャンルやリリース日が無い曲では Last.fm の表記補正とジャンルの候補が表示されます。":                                          "With lastfm.api_key set, tracks without a genre or release date get Last.fm spelling fixes and genre suggestions.",
+	"歌詞はlrclib.netなど config.json の lyrics.providers の順に探し、見つかった場合のみ埋め込まれます。インストゥルメンタル曲は歌詞の代わりにINSTRUMENTALタグが付きます。": "Lyrics are looked up in the order of lyrics.providers in config.json (lrclib.net and others) and embedded only when found. Instrumentals get an INSTRUMENTAL tag instead.",
+	"フィルタを入力": "Enter a filter",
+	"今日のみ":    "Today only",
+	"今週のみ":    "This week only",
+	"失敗のみ":    "Failed only",
+	"★の評価を付ける (同じ数字でもう一度押すと解除)": "Rate with ★ (press the same number again to clear)",
+	"メモを編集": "Edit note",
+	"整理候補 (サイズ順) の表示切替": "Toggle cleanup candidates (by size)",
+	"表示中のファイルのタグを一括置換":  "Bulk replace tags of the listed files",
+	"入力画面に戻る":           "Back to input",
+	"フィルタ例: artist:YOASOBI format:flac from:2024-01-01 to:2024-03-31 status:failed (スペース区切りで組み合わせ可)": "Filter example: artist:YOASOBI format:flac from:2024-01-01 to:2024-03-31 status:failed (combine with spaces)",
+	"rating:4 で★4以上、note:語 でメモの内容を絞り込めます。通常の検索語もメモに一致します。":                                           "rating:4 shows ★4 and up, note:word filters by note. Plain search words match notes too.",
+	"t/w/x をもう一度押すとクイックフィルタを解除します。":                                                                  "Press t/w/x again to clear the quick filter.",
+	"config.json の library.max_size_mb でライブラリの上限を設定すると、超過時に警告と整理候補を表示します。":                           "Set library.max_size_mb in config.json to get a warning and cleanup candidates when the library grows past it.",
+	"この組み合わせでダウンロード":               "Download this combination",
+	"音声トラックの切り替え (複数ある動画のみ)":       "Switch audio track (videos with several only)",
+	"動画の字幕から同期歌詞を作成 (歌詞が見つからない場合)": "Make synced lyrics from the video's subtitles (when no lyrics were found)",
+	"タグ編集に戻る": "Back to tags",
+	"✗ が付いた項目は一致度が低い項目です。長さの差が大きい場合はMV版や別バージョンの可能性があります。": "Items marked ✗ match poorly. A large difference in length may mean an MV or another version.",
+	"吹き替えなど複数の音声トラックを持つ動画では、l で抽出するトラックを選べます。":            "For videos with several audio tracks, such as dubs, l picks the track to extract.",
+	"字幕から作った歌詞は自動生成字幕の場合誤りが多いので、編集画面で確認してください。":           "Lyrics made from auto-generated subtitles often contain mistakes, so check them in the editor.",
+	"候補をタグ編集画面で開く (候補が無い場合は検索)":                           "Open the candidate in the tag editor (search if there is none)",
+	"検索語で手動検索": "Search manually with the query",
+	"キューから削除":  "Remove from the queue",
+	"daemon コマンドが一致度の低い曲をここに登録します。開いた項目はキューから消えます。":                  "The daemon command files poorly matching tracks here. Opened items leave the queue.",
+	"自動でダウンロードする一致度は config.json の auto.accept_score (0〜1) で調整できます。": "The score for automatic downloads is set by auto.accept_score (0–1) in config.json.",
+	"最初の公式リリースの全曲をキューに追加":                                            "Queue all tracks of the first official release",
+	"一覧から消す": "Dismiss",
+	"アーティストは watch add <名前> で登録します。起動時に watch.interval_hours ごとに MusicBrainz を確認します。": "Add artists with watch add <name>. MusicBrainz is checked at startup every watch.interval_hours.",
+	"不要な曲はキュー画面で d を押して消してから開始してください。":                                                "Remove unwanted tracks with d on the queue screen before starting.",
+	"プレイリストの曲をキューに追加":                                                                 "Queue the playlist's tracks",
+	"ログインには cookies の設定 (ブラウザのCookie) を使います。未設定ならブラウザの選択画面が開きます。":                     "Signing in uses the cookies setting (browser cookies). If it is not set, the browser picker opens.",
+	"追加した曲は動画が決まっているので、キューの実行時にYouTubeを検索せずにダウンロードします。":                               "Added tracks already have their video, so the queue downloads them without searching YouTube.",
+	"ブラウザの選択":              "Choose a browser",
+	"このブラウザのCookieで再試行":    "Retry with this browser's cookies",
+	"config.json に保存して再試行": "Save to config.json and retry",
+	"エラー画面へ":               "Go to the error screen",
+	"年齢制限やメンバー限定の動画は、YouTubeにログインしたブラウザのCookieがあれば取得できます。":                "Age-restricted and members-only videos can be fetched with cookies from a browser signed in to YouTube.",
+	"ブラウザの起動中はCookieを読めないことがあります (特にChrome系)。失敗する場合はブラウザを閉じてから再試行してください。": "Cookies may be unreadable while the browser is running (Chrome-based ones especially). If it fails, close the browser and retry.",
+	"cookies.txt を使う場合は config.json の cookies.file にパスを指定してください。":         "To use cookies.txt, set its path in cookies.file in config.json.",
+	"0.5秒ずつ調整":     "Adjust by 0.5s",
+	"5秒ずつ調整":       "Adjust by 5s",
+	"先頭/末尾の切り替え":   "Switch between start and end",
+	"切り取り位置から試聴":   "Preview from the cut point",
+	"トリムを解除":       "Clear the trim",
+	"決定して比較画面へ":    "Confirm and go to the comparison",
+	"変更を破棄して比較画面へ": "Discard changes and go to the comparison",
+	"曲の前のトークや無音・黒画面を削るための画面です。トリムは変換時に適用され、元の動画には影響しません。":           "This screen cuts talk, silence or black frames around the song. The trim is applied when converting and does not touch the video.",
+	"試聴には ffplay (ffmpegに同梱) が必要です。先頭は切り取り位置から、末尾は切り取り位置の直前を再生します。": "Previewing needs ffplay (bundled with ffmpeg). The start plays from the cut point, the end plays up to it.",
+	"MusicBrainzの長さと比べながら調整すると、曲の境目を合わせやすくなります。":                    "Comparing with the MusicBrainz length makes it easier to find where the song starts and ends.",
+	"スクロール": "Scroll",
+	"編集内容を保存して比較画面へ": "Save edits and go to the comparison",
+	"編集を破棄して比較画面へ":   "Discard edits and go to the comparison",
+	"別の曲の歌詞が取得された場合や、翻訳者のクレジットなど不要な行がある場合はここで修正できます。":                 "Fix lyrics here if another song's lyrics were fetched or there are extra lines such as translator credits.",
+	"すべて削除して保存すると歌詞は埋め込まれません。":                                        "Delete everything and save to embed no lyrics.",
+	"同期歌詞は [mm:ss.xx] のタイムスタンプごと編集できます。通常の歌詞はタイムスタンプを除いたものが自動で作られます。": "Synced lyrics can be edited with their [mm:ss.xx] timestamps. Plain lyrics are made from them without the timestamps.",
+	"この画面を出さない場合は config.json の lyrics.review を false にしてください。":       "Set lyrics.review to false in config.json to skip this screen.",
+	"タグ無しでダウンロード":            "Download without tags",
+	"次の項目へ / 最後の項目で変更をプレビュー": "Next field / preview the changes on the last field",
+	"正規表現のON/OFF":            "Toggle regular expressions",
+	"プレビュー後に書き換えを実行":         "Rewrite after the preview",
+	"戻る": "Back",
+	"対象は履歴画面で表示中 (フィルタ適用後) の、現存するFLACファイルです。先に artist:名前 などで絞り込んでおくと安全です。": "Targets are the existing FLAC files listed in history (after filtering). Narrowing down first, e.g. with artist:name, is safer.",
+	"タグ名はカンマ区切りで複数指定できます (例: artist, albumartist)。空にすると歌詞以外のすべてのタグが対象です。":  "Separate several tag names with commas (e.g. artist, albumartist). Leave empty to target every tag except lyrics.",
+	"タグだけを直接書き換えるため、音声は再エンコードされません。":                                       "Only the tags are rewritten, so the audio is not re-encoded.",
+	"アルバムの折りたたみ切替":             "Fold/unfold album",
+	"折りたたむ / 展開する":             "Fold / unfold",
+	"曲またはアルバムをキューから削除 (実行前のみ)": "Remove a track or album from the queue (before starting only)",
+	"キューのダウンロードを開始":            "Start downloading the queue",
+	"元の画面に戻る":                  "Back to the previous screen",
+	"複数のアルバムからトラックを q で追加し、まとめてダウンロードできます。曲はアルバムごとにまとめて表示されます。": "Add tracks from several albums with q and download them together. Tracks are grouped by album.",
+	"ダウンロード中の画面では処理中のアルバムだけが展開され、他のアルバムは進捗のみ表示されます。":            "While downloading, only the current album is expanded; the others show their progress only.",
+	"ページ移動 / 先頭・末尾へ":                "Page / first and last",
+	"グループの折りたたみ切替":                  "Fold/unfold group",
+	"グループ分けの切替 (アーティスト → アルバム → 年)": "Switch grouping (artist → album → year)",
+	"その頭文字の見出しへ移動":                  "Jump to the heading of that letter",
+	"前 / 次の見出し (あ行・か行…) へ移動":        "Previous / next heading",
+	"並び順は config.json の library.sort_locale (既定は ja) に従います。日本語ではかなが五十音順に、全角・半角や大文字・小文字の違いは無視して並びます。": "Sorting follows library.sort_locale in config.json (ja by default). In Japanese, kana sort in gojūon order, ignoring full/half width and case.",
+	"漢字で始まる名前は読みが分からないため「他」の見出しにまとめられます。":                                                            "Names starting with kanji go under the \"他\" (other) heading, as their reading is unknown.",
+	"起動時のグループ分けは library.group_by (artist / album / year) で変更できます。":                                  "Change the initial grouping with library.group_by (artist / album / year).",
+	"診断を再実行": "Run diagnostics again",
+	"ツールが ✗ の場合はインストールとPATHを確認してください。yt-dlp は実行ファイルと同じフォルダに置いても認識されます。": "If a tool shows ✗, check that it is installed and on your PATH. yt-dlp is also found next to the executable.",
+	"ネットワークが ✗ の場合はファイアウォールやプロキシ設定を確認してください。":                           "If the network shows ✗, check your firewall and proxy settings.",
+	"不具合を報告する際はこの画面の内容を添えてください。":                                        "Please include this screen when reporting a problem.",
+	"任意のキー":              "Any key",
+	"最初の画面に戻る":           "Back to the start screen",
+	"詳細なログは %s に出力されます。": "Detailed logs are written to %s.",
+	"処理が終わるまでお待ちください。":   "Please wait until processing finishes.",
+	"ヘルプの表示/非表示":         "Show/hide help",
+	"終了":                 "Quit",
+	"ヘルプ: %s":            "Help: %s",
+	"ヒント":                "Tips",
+	"何かキーを押すとヘルプを閉じます":   "Press any key to close the help",
+	"不明なコマンドです: %s":      "Unknown command: %s",
+	"使い方: go-music-downloader [--yt-dlp-args \"引数\"] [コマンド] [オプション]\n\n引数なしで起動するとTUIを開きます。\n\nコマンド:\n": "Usage: go-music-downloader [--yt-dlp-args \"args\"] [command] [options]\n\nRun without arguments to open the TUI.\n\nCommands:\n",
+	"\n  --yt-dlp-args はすべての yt-dlp 呼び出しに付け加える引数です (例: --yt-dlp-args \"--limit-rate 2M\")\n":           "\n  --yt-dlp-args are arguments added to every yt-dlp call (e.g. --yt-dlp-args \"--limit-rate 2M\")\n",
+	"検索語・URLを1行ずつ書くファイル":                                                   "File with one search query or URL per line",
+	"inbox を確認する間隔":                                                        "How often to check the inbox",
+	"inbox を1回処理して終了する":                                                    "Process the inbox once and exit",
+	"進捗イベントを配信するアドレス (例: 127.0.0.1:8080)":                                  "Address to serve progress events on (e.g. 127.0.0.1:8080)",
+	"自動で直せる問題を修復する":                                                        "Fix the problems that can be fixed automatically",
+	"同じ曲が複数あるファイルを一覧表示する":                                                  "List files that hold the same track",
+	"同じ曲をlrclibに問い合わせ直すまでの間隔":                                              "How long to wait before asking lrclib about the same track again",
+	"書き込まずに対象と結果だけを表示する":                                                   "Only show targets and results without writing",
+	"出力先ファイル":                                                              "Output file",
+	"履歴のフィルタ (例: \"artist:名前 from:2024-01-01\")":                           "History filter (e.g. \"artist:name from:2024-01-01\")",
+	"ダウンロードせずに対象の曲を表示する":                                                   "Show the tracks without downloading",
+	"出力形式 (html または md)":                                                   "Output format (html or md)",
+	"登録時点の動画もダウンロードの対象にする":                                                 "Also download videos that exist when subscribing",
+	"新譜の曲を inbox.txt に書き出して daemon に任せる":                                   "Write new releases' tracks to inbox.txt for the daemon",
+	"ライブラリのレポートをHTML/Markdownで出力します":                                       "Write a library report as HTML/Markdown",
+	"履歴から共有用のマニフェスト (音声なし) を出力します":                                         "Write a shareable manifest (no audio) from history",
+	"マニフェストの曲を自分の環境でダウンロードします":                                             "Download a manifest's tracks on this machine",
+	"inbox の検索語を自動照合してダウンロードし続けます":                                         "Keep matching and downloading the queries in inbox",
+	"動作環境を診断します (-fix で自動修復)":                                              "Diagnose the environment (-fix repairs)",
+	"手持ちの音声ファイルをMusicBrainzの情報でタグ付けし直します":                                  "Retag audio files you have with MusicBrainz data",
+	"フォルダの音声ファイルを1曲ずつ照合・タグ付けしてライブラリに取り込みます":                                "Match, tag and import a folder's audio files into the library one by one",
+	"downloads フォルダを走査して索引を更新し、統計と重複を表示します":                                "Scan the downloads folder, update the index and show stats and duplicates",
+	"新譜を確認するアーティストを登録・削除・一覧します (add / remove / list)":                      "Add, remove or list artists to check for new releases (add / remove / list)",
+	"登録したアーティストの新譜を MusicBrainz で確認します":                                    "Check MusicBrainz for new releases from registered artists",
+	"新着を自動でダウンロードするYouTubeチャンネルを登録・削除・一覧します (add / remove / list / check)": "Add, remove or list YouTube channels whose new videos are downloaded automatically (add / remove / list / check)",
+	"daemon の API で使うトークンを発行・削除・一覧します (add / remove / list)":               "Issue, remove or list tokens for the daemon API (add / remove / list)",
+	"同期歌詞のない曲をlrclibで探し直し、見つかれば埋め込みます":                                     "Look up lrclib again for tracks without synced lyrics and embed them when found",
+	"出力先ファイル (省略時は %s/library-report.<形式>)":                                "Output file (default: %s/library-report.<format>)",
+	"%d曲をキューに追加しました (計%d曲, %s: キューを表示)":                                    "Added %d tracks to the queue (%d in total, %s: show queue)",
+	"登録チャンネルの新着 %d 曲をキューに追加しました (%s で確認)":                                  "Queued %d new tracks from subscribed channels (%s to view)",
+	"決定":                "Choose",
+	"次へ/決定":             "Next/confirm",
+	"検索":                "Search",
+	"履歴":                "History",
+	"キュー":               "Queue",
+	"要確認":               "Review",
+	"ヘルプ":               "Help",
+	"はい":                "Yes",
+	"いいえ":               "No",
+	"ダウンロード":            "Download",
+	"取り込む":              "Import",
+	"タグを書き換える":          "Rewrite tags",
+	"トリム":               "Trim",
+	"音声トラック切替":          "Switch audio track",
+	"歌詞":                "Lyrics",
+	"字幕から歌詞を作成":         "Lyrics from subtitles",
+	"歌詞を入力":             "Enter lyrics",
+	"歌詞を編集":             "Edit lyrics",
+	"自動照合":              "Auto-match",
+	"スキップ":              "Skip",
+	"複数選択":              "Multi-select",
+	"キューに追加":            "Add to queue",
+	"1ファイルで保存":          "Save as one file",
+	"Last.fmを採用":        "Use Last.fm",
+	"  (キュー %d/%d: %s)": "  (queue %d/%d: %s)",
+	"音源":                "Source",
+	"リリース":              "Release",
+	"トラック":              "Track",
+	"タグ":                "Tags",
+	"確認":                "Review",
+	"候補が多いときは / を押して文字を入力すると、タイトルや年・レーベルのあいまい一致で絞り込めます (もう一度 Esc で解除)。": "With many candidates, press / and type to fuzzy-filter them by title, year or label (Esc again clears it).",
+	"続きを取得中です...":                         "Loading more results...",
+	"続きの取得に失敗しました: %v":                    "Could not load more results: %v",
+	"これ以上の結果はありません":                       "No more results",
+	"%d件を追加しました (計%d件)":                   "Added %d results (%d total)",
+	"続き":                                  "More",
+	"続きの検索結果を読み込む":                        "Load the next page of results",
+	"%s回視聴":                               "%s views",
+	"公式":                                  "official",
+	"条件を変えて検索し直しています...":                  "Searching again with the new filter...",
+	"検索に失敗しました: %v":                       "Search failed: %v",
+	"条件に合うリリースはありません":                     "No releases match the filter",
+	"%d件のリリース":                            "%d releases",
+	"条件":                                  "Filter",
+	"発売国で絞り込む (もう一度押すと解除)":                "Only releases from your country (press again to clear)",
+	"公式リリースだけにする (切り替え)":                  "Only official releases (toggle)",
+	"形態を切り替える (CD / Digital Media / すべて)": "Switch the medium (CD / Digital Media / any)",
+	"同じアルバムでも複数の版 (CD/デジタル/地域違い) が表示されることがあります。c / o / t で国・公式・形態を絞り込むと探しやすくなります。": "The same album may show up in several editions (CD, digital, regional); narrow them by country, status and medium with c / o / t.",
+	"録音": "recordings",
+	"MusicBrainzで録音を検索中です...": "Searching MusicBrainz recordings...",
+	"録音で検索": "Search recordings",
+	"録音「%s」のトラックを選びました":             "Picked the track of the recording \"%s\"",
+	"リリースではなく録音 (recording) で検索し直す": "Search again by recording instead of release",
+	"録音 (recording) の検索に切り替える":      "Switch to searching recordings",
+	"版の一覧": "Editions",
+	"同じリリースグループの版 (再発・地域違いなど) をすべて表示": "List every edition in the release group (reissues, regional releases, ...)",
+	"日付不明": "unknown date",
+	"このリリースのリリースグループが分かりません":                      "This release has no release group",
+	"同じリリースグループの版を取得中です...":                       "Loading the editions in the release group...",
+	"版の一覧の取得に失敗しました: %v":                          "Could not load the editions: %v",
+	"「%s」の版 (%d件)":                                "Editions of \"%s\" (%d)",
+	"版の一覧を表示中です (%s: 検索結果に戻る)":                    "Showing editions (%s: back to the search results)",
+	"MusicBrainzのタグ無しでダウンロード中です...":               "Downloading without MusicBrainz tags...",
+	"曲名: %s / アーティスト: %s":                         "Title: %s / Artist: %s",
+	"YouTubeの自動生成の情報を使っています (MusicBrainzは検索しません)": "Using YouTube's auto-generated track info (MusicBrainz is not searched)",
+	"「 - Topic」チャンネルの自動生成の動画では、概要欄の曲名・アーティスト・アルバム・発売日を使います (戻ると音源の選択へ)。": "For auto-generated videos from \" - Topic\" channels, the title, artist, album and release date come from the description (Back returns to the source list).",
+	"ローマ字化の切り替え (ファイル名・タグ)": "Toggle romanization (file name and tags)",
+	"ローマ字":                   "Romaji",
+	"    MusicBrainzの長さ: %s": "    MusicBrainz length: %s",
+	"  (同期歌詞: タイムスタンプ付き)":    "  (synced lyrics: with timestamps)",
+	"  取得元: %s":              "  Source: %s",
+	" (%d秒後)…":               " (in %ds)…",
+	" (インストゥルメンタル)":          " (instrumental)",
+	" (失敗・スキップ %d)":          " (failed/skipped %d)",
+	" (歌詞付き)":                " (with lyrics)",
+	" · ❌ 失敗: %s":            " · ❌ failed: %s",
+	" · ファイルなし":              " · file missing",
+	" ほか%d件":                 " and %d more",
+	" 中…":                    "…",
+	"%6.1f 秒":                "%6.1f s",
+	"%d個のチャプター付き, %s":        "%d chapters, %s",
+	"%d分前":                   "%d min ago",
+	"%d日前":                   "%d days ago",
+	"%d時間前":                  "%d h ago",
+	"%d曲に分割しました (%s)\n%s":    "Split into %d tracks (%s)\n%s",
+	"%d曲完了 / %d曲失敗":          "%d done / %d failed",
+	"%d曲完了":                  "%d done",
+	"%d週間前":                  "%d weeks ago",
+	"%s  候補: %s / %s (%d%%)": "%s  candidate: %s / %s (%d%%)",
+	"%s (%d種類中, l で切替)":      "%s (of %d, l to switch)",
+	"%s · Opus変換で約%s削減 · %s": "%s · about %s saved as Opus · %s",
+	"%s: %s — 再試行 %d/%d":     "%s: %s — retry %d/%d",
+	"(%d/%d) 「%s」の音源をYouTubeで検索中です...": "(%d/%d) Searching YouTube for \"%s\"...",
+	"(%d/%d) 「%s」をダウンロード中です...":        "(%d/%d) Downloading \"%s\"...",
+	", .cue付き":                 ", with .cue",
+	"Last.fm (採用済み): %s":       "Last.fm (applied): %s",
+	"Last.fm: %s (Ctrl+T: 採用)": "Last.fm: %s (Ctrl+T: apply)",
+	"YouTubeチャプター":             "YouTube chapters",
+	"c で動画の字幕から同期歌詞を作成できます":                                                           "Press c to build synced lyrics from the video's subtitles",
+	"ffmpegでの変換失敗:\n%s":                                                               "ffmpeg conversion failed:\n%s",
+	"ffplay が見つからないため試聴できません":                                                         "Can't preview: ffplay not found",
+	"today / week / failed / artist:名前 / format:flac / from:2024-01-01 to:2024-12-31": "today / week / failed / artist:name / format:flac / from:2024-01-01 to:2024-12-31",
+	"● 配信中": "● Live",
+	"⚠ このダウンロード (約%s) でライブラリの上限 %s を超えます。履歴画面 (Ctrl+R) の c で整理候補を確認できます。": "⚠ This download (about %s) takes the library over its %s limit. Press c on the history screen (Ctrl+R) to see cleanup candidates.",
+	"⚠ ライブラリに同じ曲があります: %s": "⚠ The library already has this track: %s",
+	"⚠ 録音が上限の%d分に達したため、配信の途中で打ち切りました (live.max_minutes で変更できます)。\n録音の長さ: %s":                           "⚠ The recording hit the %d-minute limit and stopped mid-stream (change it with live.max_minutes).\nRecorded: %s",
+	"⚠ 長さが一致しません: YouTube %s / MusicBrainz %s (差 %d秒)\nMV版・ロングバージョン・別の曲の可能性があります。":                     "⚠ Lengths differ: YouTube %s / MusicBrainz %s (%ds apart)\nThis may be an MV edit, an extended version or a different song.",
+	"⚠ 音源の長さ (%s) がMusicBrainzの記録 (%s) と%.1f%%ずれています。\n再生速度が%.2f倍%sされている可能性があります (ピッチ変化の目安: %+.1f半音)。": "⚠ The source length (%s) differs from MusicBrainz (%s) by %.1f%%.\nPlayback may be at %.2fx, %s (about %+.1f semitones of pitch).",
+	"✅ ダウンロード完了: %s":      "✅ Downloaded: %s",
+	"❌ ダウンロード失敗: %s":      "❌ Download failed: %s",
+	"あり (%s)":             "found (%s)",
+	"あり・同期歌詞 (%s)":        "found, synced (%s)",
+	"この動画には字幕がありません":      "This video has no subtitles",
+	"この組み合わせでダウンロードしますか？": "Download with this combination?",
+	"たった今":                "just now",
+	"アルバム":                "Album",
+	"アーティスト":              "Artist",
+	"アーティスト: %s":          "Artist: %s",
+	"インストゥルメンタル (歌詞なし)":   "instrumental (no lyrics)",
+	"カバー取得中...":           "Fetching cover...",
+	"カバー画像":               "Cover",
+	"キューに入れ直してもう一度ダウンロードしますか？ (途中まで保存されたファイルは削除済みです)": "Queue them again and retry? (partially written files were removed)",
+	"キューの処理が完了しました":                           "Queue finished",
+	"キューは空です。トラック選択画面で Space で選んで q で追加できます。": "The queue is empty. On the track list, select with Space and add with q.",
+	"ジャンル: %s":  "Genre: %s",
+	"タイトル":      "Title",
+	"ダウンロード+変換": "Download+convert",
+	"ダウンロードキュー (%d曲 / %dアルバム)": "Download queue (%d tracks / %d albums)",
+	"ダウンロード履歴 (%d/%d件) — %s":   "Download history (%d/%d) — %s",
+	"ダウンロード履歴 (%d件)":           "Download history (%d)",
+	"チャプター":                    "chapters",
+	"チャプター検出":                  "Chapter detection",
+	"チャンネル":                    "Channel",
+	"トラック長":                    "track lengths",
+	"トリム:":                     "Trim:",
+	"ファイル %s":                  "File %s",
+	"ファイル":                     "File",
+	"メモ (空にすると削除)":             "Note (empty to delete)",
+	"ライブラリ使用量: %s / %s (%d%%)": "Library usage: %s / %s (%d%%)",
+	"ライブラリ使用量: %s":             "Library usage: %s",
+	"ローマ字:":                    "Romaji:",
+	"一致度 %d%% (タイトル %d%% / 長さ %d%% / アーティスト %d%%)": "Match %d%% (title %d%% / length %d%% / artist %d%%)",
+	"上限なし": "no limit",
+	"不明":   "unknown",
+	"候補のトラックがリリースに見つかりませんでした。手動で検索してください。": "The candidate track isn't on the release. Search manually.",
+	"候補を読み込み中です...":       "Loading the candidate...",
+	"先頭 %.1f秒 / 末尾 %.1f秒": "head %.1fs / tail %.1fs",
+	"先頭から削る":              "Trim from start",
+	"再生中...":              "Playing...",
+	"処理時間":                "Timing",
+	"前回、次の %d 曲のダウンロード中にプログラムが終了しました。": "Last time the program exited while downloading these %d tracks.",
+	"前後のトリム — %s": "Trim — %s",
+	"十分に一致する候補が見つかりませんでした (最高 %d%%)。手動で選択してください。": "No candidate matched well enough (best %d%%). Pick one manually.",
+	"変換":           "Convert",
+	"字幕の取得失敗:\n%s": "Fetching subtitles failed:\n%s",
+	"履歴には保存しましたが、タグの書き込みに失敗しました: %w": "Saved to history, but writing the tags failed: %w",
+	"整理候補 (サイズ順) — 上限を%s超過しています":     "Cleanup candidates (by size) — %s over the limit",
+	"整理候補 (サイズ順)":        "Cleanup candidates (by size)",
+	"日付の形式が正しくありません: %s": "Invalid date: %s",
+	"曲名: %s":             "Title: %s",
+	"最初から":               "from the start",
+	"最大%d分":              "up to %d min",
+	"末尾から削る":             "Trim from end",
+	"歌詞の確認・編集 — %s / %s": "Review lyrics — %s / %s",
+	"歌詞・クレジット":           "Lyrics/credits",
+	"無音検出":               "silence detection",
+	"現在の位置から":            "from the current position",
+	"評価は0〜%dで指定してください: %s": "Rating must be 0–%d: %s",
+	"試聴に失敗しました: %v":        "Preview failed: %v",
+	"速く":                   "sped up",
+	"遅く":                   "slowed down",
+	"配信がまだ始まっていないため、ダウンロードできません":          "The stream hasn't started yet, so it can't be downloaded",
+	"配信がまだ始まっていません。開始後にもう一度お試しください":       "The stream hasn't started yet. Try again once it has",
+	"配信が終わったばかりでアーカイブの処理中です。%s録音します (%s)": "The stream just ended and its archive is still processing. Recording %s (%s)",
+	"配信の録音がタイムアウトしました (%s)":               "Recording the stream timed out (%s)",
+	"配信アーカイブ":            "Stream archive",
+	"配信中です。%s録音します (%s)": "The stream is live. Recording %s (%s)",
+	"配信予定":               "Upcoming stream",
+	"長さ %s の配信アーカイブです。w でチャプター付きの1ファイルにできます": "A %s stream archive. Press w to keep it as one file with chapters",
+	"長さ": "Length",
+	"音声": "Audio",
+	"音声URLの取得に失敗しました: %v": "Getting the audio URL failed: %v",
+	"音声のダウンロード失敗:\n%s":    "Audio download failed:\n%s",
+	"分割ダウンロード":            "Split download",
+	"新着":                  "New releases",
+	"keys.%s: %s は文字入力中にも使う操作なので、文字のキーは割り当てられません": "keys.%s: %s works while typing too, so it can't be a character key",
+	"keys.%s: %s は「%s」で使われています":                   "keys.%s: %s is already used for \"%s\"",
+	"keys.%s: %s はリストの移動で使われています":                 "keys.%s: %s is used to move around lists",
+	"keys.%s: %s は keys.%s にも割り当てられています":          "keys.%s: %s is also bound to keys.%s",
+	"送り先へのアップロードを待っています (%d件)...\n":               "Waiting for %d upload(s) to destinations...\n",
+	"      → doctor -fix で自動修復できます":               "      → doctor -fix can repair this",
+	"  … 他 %d 項目": "  … %d more",
+	"  年齢制限またはメンバー限定のため取得できませんでした。\n  YouTubeにログイン済みのブラウザを選ぶと、そのCookieを使って再試行します。": "  The video is age-restricted or members-only.\n  Pick a browser that is signed in to YouTube to retry with its cookies.",
+	" (フィルタ: %s)":                " (filter: %s)",
+	" (書き込めません: %v)":             " (not writable: %v)",
+	"%d件 (%s) を削除しました":           "Removed %d files (%s)",
+	"%d件のアルバムをレポートに出力しました: %s\n": "Wrote %d albums to the report: %s\n",
+	"%d件の問題があります":                "%d problems found",
+	"%d件を復元しました (元のファイル: %s)":    "Recovered %d entries (original kept as %s)",
+	"%d件中%d件を取り込みました\n%s":        "Imported %[2]d of %[1]d files\n%[3]s",
+	"%d時間%02d分":                  "%dh %02dm",
+	"%d曲をマニフェストに書き出しました: %s\n":   "Wrote %d tracks to the manifest: %s\n",
+	"%q は %d〜%d の範囲外です":          "%q is outside %d-%d",
+	"%s %d曲":                     "%s %d tracks",
+	"%s %s を読み込み中です...":          "%s Loading %s...",
+	"%s (%s) を登録しました。既存の動画 %d 本はダウンロードしません。\n":                                  "Subscribed to %s (%s). The %d existing videos will not be downloaded.\n",
+	"%s (https://musicbrainz.org/artist/%s) を登録しました。既存のリリース %d 件は新譜として扱いません。\n": "Watching %s (https://musicbrainz.org/artist/%s). The %d existing releases are not treated as new.\n",
+	"%s (バージョン取得に失敗: %v)":   "%s (could not get the version: %v)",
+	"%s (見つかりません)":          "%s (not found)",
+	"%s (読み込めません: %v)":      "%s (unreadable: %v)",
+	"%s — %d件":              "%s — %d entries",
+	"%s からチャンネルを特定できませんでした": "Could not find a channel at %s",
+	"%s で待ち受けできません: %w":     "Cannot listen on %s: %w",
+	"%s で待ち受けるには認証が必要です。token add <名前> でトークンを発行するか、server.tokens / server.basic_auth を設定してください": "Listening on %s requires authentication. Issue a token with token add <name>, or set server.tokens / server.basic_auth",
+	"%s という名前のトークンはありません":                      "There is no token named %s",
+	"%s という名前のトークンはすでにあります":                    "A token named %s already exists",
+	"%s に値がありません":                              "%s needs a value",
+	"%s のCookieを使って再試行中です...":                  "Retrying with cookies from %s...",
+	"%s の確認に失敗: %w":                            "Failed to check %s: %w",
+	"%s はすでに登録されています":                          "%s is already registered",
+	"%s は登録されていません":                            "%s is not registered",
+	"%s をダウンロードしました (%s)":                      "Downloaded %s (%s)",
+	"%s: %d曲を inbox に追加しました\n":                 "%s: added %d tracks to the inbox\n",
+	"%s: 取得済みのためスキップ\n":                        "%s: already downloaded, skipped\n",
+	"%s: 同期歌詞が見つかりました (-dry-run のため書き込みません)\n": "%s: synced lyrics found (not written because of -dry-run)\n",
+	"%s: 同期歌詞はまだありません\n":                       "%s: no synced lyrics yet\n",
+	"%s: 同期歌詞を埋め込みました\n":                       "%s: embedded synced lyrics\n",
+	"%s: 失敗 (%v)\n":                            "%s: failed (%v)\n",
+	"%s: 実行されません":                              "%s: never runs",
+	"%s: 書き込みに失敗 (%v)\n":                       "%s: write failed (%v)\n",
+	"%s: 次回 %s":                                "%s: next at %s",
+	"(%d曲)":                                    "(%d tracks)",
+	"(アルバム情報なし)":                               "(no album information)",
+	"(不明)":                                     "(unknown)",
+	"CA証明書":                                    "CA certificate",
+	"FLACのタグ書き込みに失敗: %w":                       "Failed to write the FLAC tags: %w",
+	"MusicBrainz returned %s (%d回再試行しました)":     "MusicBrainz returned %s (retried %d times)",
+	"MusicBrainzで「%s」に一致するアーティストが見つかりませんでした": "No artist matching \"%s\" was found on MusicBrainz",
+	"MusicBrainzの取得に失敗: %w":                                      "MusicBrainz lookup failed: %w",
+	"Spotifyのプレイリストを取得できませんでした: %w":                              "Could not fetch the Spotify playlist: %w",
+	"Spotifyの認証に失敗しました (%s)。client_id / client_secret を確認してください": "Spotify authentication failed (%s). Check client_id / client_secret",
+	"YouTube Musicのライブラリの取得がタイムアウトしました":                          "Fetching the YouTube Music library timed out",
+	"YouTube Musicのライブラリの取得に失敗:\n%s":                             "Failed to fetch the YouTube Music library:\n%s",
+	"YouTube Musicのライブラリを取得中です...":                               "Fetching the YouTube Music library...",
+	"YouTube Musicのライブラリを読むにはログイン (--cookies) が必要です":             "Reading the YouTube Music library requires signing in (--cookies)",
+	"\n  ライブラリに曲がありません。ダウンロードした曲がここに表示されます。\n":                   "\n  The library is empty. Downloaded tracks will show up here.\n",
+	"\n  正規表現: %s\n":                                                    "\n  Regex: %s\n",
+	"\n %s 診断を実行中です...\n":                                               "\n %s Running diagnostics...\n",
+	"\n(タグを書き換えました)":                                                    "\n(tags rewritten)",
+	"\n(ライブラリに取り込みました)":                                                 "\n(imported into the library)",
+	"\nTUIの入力画面で Ctrl+N を押すとキューに追加できます。":                                "\nPress Ctrl+N on the TUI input screen to queue them.",
+	"\n問題は見つかりませんでした。":                                                  "\nNo problems found.",
+	"\n最終確認: %s / 未処理の新譜: %d件\n":                                        "\nLast check: %s / pending new releases: %d\n",
+	"\n最終確認: %s\n":                                                      "\nLast check: %s\n",
+	"\n重複の可能性がある曲が %d組あります (-dupes で一覧表示)\n":                            "\n%d groups of possible duplicates (-dupes to list them)\n",
+	"artist, albumartist (空ならすべてのタグ)":                                   "artist, albumartist (empty for all tags)",
+	"config.json の spotify.client_id と spotify.client_secret を設定してください": "Set spotify.client_id and spotify.client_secret in config.json",
+	"ffmpeg をインストールしてください (例: brew install ffmpeg / winget install ffmpeg / sudo apt-get install ffmpeg)": "Install ffmpeg (e.g. brew install ffmpeg / winget install ffmpeg / sudo apt-get install ffmpeg)",
+	"ffmpegが見つからないため、FLAC以外のファイルはタグを読まずに登録します。":                                                           "ffmpeg was not found, so non-FLAC files are indexed without reading their tags.",
+	"ffmpegが見つかりません: %w":                                        "ffmpeg was not found: %w",
+	"media_server.plex.section_id を設定してください":                    "Set media_server.plex.section_id",
+	"network.ca_file にPEM形式の証明書のパスを指定してください":                    "Set network.ca_file to the path of a PEM certificate",
+	"pip install -U yt-dlp などでインストールするか、実行ファイルと同じフォルダに配置してください": "Install it (e.g. pip install -U yt-dlp) or put it next to the executable",
+	"rclone の送り先には remote が必要です":                                "An rclone destination needs a remote",
+	"s3 の送り先には bucket / access_key / secret_key が必要です":          "An s3 destination needs bucket / access_key / secret_key",
+	"sftp の送り先には host が必要です":                                    "An sftp destination needs a host",
+	"webdav の送り先には url が必要です":                                   "A webdav destination needs a url",
+	"yt-dlp を最新版に更新してください (yt-dlp -U)":                          "Update yt-dlp to the latest version (yt-dlp -U)",
+	"yt-dlp 追加引数":                        "Extra yt-dlp args",
+	"yt-dlpの出力のJSON解析に失敗:\n%v":           "Failed to parse the yt-dlp JSON output:\n%v",
+	"✗ %s の修復に失敗しました: %v\n":              "✗ Could not repair %s: %v\n",
+	"「%s」のトラックリストを取得中です...":              "Fetching the tracklist of \"%s\"...",
+	"「%s」の曲を取得中です...":                    "Fetching the tracks of \"%s\"...",
+	"このバージョンでは読めないマニフェストです (version %d)": "This version cannot read the manifest (version %d)",
+	"ウォッチ中のアーティストの新譜 (%d件)":              "New releases from watched artists (%d)",
+	"コピー":      "Copy",
+	"サーバー: %s": "Server: %s",
+	"サーバーでのダウンロードに失敗:\n%s":                                "The download failed on the server:\n%s",
+	"サーバーとの接続が切れました。ダウンロードはサーバーで続いている可能性があります":            "Lost the connection to the server. The download may still be running there",
+	"サーバーに接続できません: %w":                                    "Cannot connect to the server: %w",
+	"サーバーに認証されませんでした。remote.token を確認してください":              "The server rejected the credentials. Check remote.token",
+	"サーバーのエラー (%s):\n%s":                                  "Server error (%s):\n%s",
+	"サーバーの進捗を受け取れません (%s)":                                "Cannot receive progress from the server (%s)",
+	"ジョブキューの処理":                                           "Job queue",
+	"ジョブキューの読み込みに失敗: %w":                                  "Failed to load the job queue: %w",
+	"スケジュール %q: %w":                                       "Schedule %q: %w",
+	"スケジュール %q: @every には1分以上の間隔を指定してください (例: @every 6h)": "Schedule %q: @every needs an interval of at least a minute (e.g. @every 6h)",
+	"スケジュール %q: cron 形式は「分 時 日 月 曜日」の5項目です":               "Schedule %q: cron format has 5 fields: minute hour day month weekday",
+	"タグ:":           "Tag:",
+	"タグを書き換え中です...": "Rewriting tags...",
+	"タグ書き込み":        "Tagging",
+	"ダウンロード失敗: %s":  "Download failed: %s",
+	"チャンネルの取得がタイムアウトしました": "Fetching the channel timed out",
+	"チャンネルの取得に失敗:\n%s":    "Failed to fetch the channel:\n%s",
+	"チャンネルの新着の確認":         "Channel uploads check",
+	"チャンネル登録":             "Subscription",
+	"トークン %s を削除しました\n":   "Removed token %s\n",
+	"トークン %s を発行しました。この値は二度と表示されないので控えてください:\n%s\n": "Issued token %s. Save it now; it will not be shown again:\n%s\n",
+	"ネットワーク": "Network",
+	"ネットワーク接続とプロキシ設定 (network.proxy) を確認してください": "Check your network connection and proxy setting (network.proxy)",
+	"ファイルが見つかりません: %s":                          "File not found: %s",
+	"ファイルを読み込み中です...":                           "Loading the file...",
+	"フォルダが見つかりません: %s":                          "Folder not found: %s",
+	"フォルダの権限と空き容量を確認してください":                     "Check the folder permissions and free space",
+	"フォルダを作成しました":                               "Created the folder",
+	"プレイリスト":                                    "Playlist",
+	"プレイリスト「%s」にダウンロードできる曲がありません":               "The playlist \"%s\" has no downloadable tracks",
+	"プロキシ": "Proxy",
+	"マニフェストの解析に失敗: %w":                              "Failed to parse the manifest: %w",
+	"ライブラリ (%d曲 / %d%s) — %s順":                      "Library (%d tracks in %d groups) — by %[4]s",
+	"ライブラリに同じ名前のファイルがあります: %s":                      "The library already has a file with this name: %s",
+	"ライブラリの走査に失敗: %w":                               "Failed to scan the library: %w",
+	"リリースグループにまだ公式のリリースがありません":                      "The release group has no official release yet",
+	"ログインが必要な動画です":                                  "This video requires signing in",
+	"一時ファイル":                                        "Temporary files",
+	"一致度が低い (%d%%)":                                 "Low match score (%d%%)",
+	"不明な操作です: %s (add / remove / list / check)":     "Unknown action: %s (add / remove / list / check)",
+	"不明な操作です: %s (add / remove / list)":             "Unknown action: %s (add / remove / list)",
+	"不明な送り先の種類です: %q (rclone / sftp / webdav / s3)": "Unknown destination type: %q (rclone / sftp / webdav / s3)",
+	"不正な値 %q":  "Invalid value %q",
+	"不正な間隔 %q": "Invalid step %q",
+	"中断されたダウンロードの一時ファイルが%d件 (%s)":                                                                         "%d temporary files from interrupted downloads (%s)",
+	"使い方: import <フォルダ>":                                                                                  "Usage: import <folder>",
+	"使い方: import-manifest [-dry-run] <マニフェストのファイル>":                                                       "Usage: import-manifest [-dry-run] <manifest file>",
+	"使い方: subscribe add <チャンネルのURL> | subscribe remove <チャンネル名またはURL> | subscribe list | subscribe check": "Usage: subscribe add <channel URL> | subscribe remove <channel name or URL> | subscribe list | subscribe check",
+	"使い方: subscribe add [-all] <チャンネルのURL>":                                                               "Usage: subscribe add [-all] <channel URL>",
+	"使い方: tag <音声ファイル>":                                                                                   "Usage: tag <audio file>",
+	"使い方: token add <名前> | token remove <名前> | token list":                                                "Usage: token add <name> | token remove <name> | token list",
+	"使い方: token add <名前>":                                                                                 "Usage: token add <name>",
+	"使い方: watch add <アーティスト名> | watch remove <アーティスト名> | watch list":                                      "Usage: watch add <artist> | watch remove <artist> | watch list",
+	"使い方: watch add <アーティスト名>":                                                                            "Usage: watch add <artist>",
+	"候補が見つかりません":                                                                                          "No candidates found",
+	"処理中に %d 回中断されました":                                                                                    "Interrupted %d times while processing",
+	"取り込める音声ファイルがありません: %s":                                                                               "No audio files to import: %s",
+	"合計: %s / %s\n": "Total: %s / %s\n",
+	"同期歌詞を探し直す曲はありません。":                    "No tracks need a synced lyrics lookup.",
+	"変更内容を確認中です...":                        "Checking the changes...",
+	"外部ツール":                                "External tools",
+	"完了: %d曲 / スキップ: %d曲 / 失敗: %d曲\n":      "Done: %d tracks / skipped: %d / failed: %d\n",
+	"完了: 同期歌詞に更新 %d曲 / 未登録 %d曲 / 失敗 %d曲\n": "Done: %d upgraded to synced lyrics / %d not found / %d failed\n",
+	"対象: 履歴の%d件":                           "Target: %d history entries",
+	"履歴の読み込みに失敗: %w":                       "Failed to load the history: %w",
+	"年":                                    "Year",
+	"引用符が閉じられていません: %s":                    "Unclosed quote: %s",
+	"形式: %s\n":                             "Formats: %s\n",
+	"接続できません (%v)":                         "Cannot connect (%v)",
+	"新しいアップロードはありません。":                     "No new uploads.",
+	"新譜の確認":                                "New releases check",
+	"新譜はありません。":                            "No new releases.",
+	"既定の設定で作成しました":                         "Created with the default settings",
+	"曲数: %d / アーティスト: %d / アルバム: %d\n":     "Tracks: %d / artists: %d / albums: %d\n",
+	"書き出せるダウンロード履歴がありません":                  "There is no download history to export",
+	"書き込み":                                 "Writable",
+	"未対応の形式です: %s":                         "Unsupported format: %s",
+	"検索:":                                  "Find:",
+	"検索結果がありません":                           "No search results",
+	"正規表現が不正です: %w":                        "Invalid regex: %w",
+	"残っていません":                              "None left",
+	"発行したトークンはありません":                       "No tokens have been issued",
+	"置換:":           "Replace:",
+	"置換のプレビュー — %s": "Replace preview — %s",
+	"置換前の文字列":       "Text to find",
+	"置換前の文字列を入力してください":        "Enter the text to find",
+	"置換後の文字列 (正規表現では $1 で参照)": "Replacement (use $1 for regex groups)",
+	"見つかりません":                 "Not found",
+	"設定ファイル":                  "Config file",
+	"読み込める項目だけを残して修復します (元のファイルは .broken として残します)": "Repairs it by keeping the readable entries (the original is kept as .broken)",
+	"音声ファイルとして読み込めません: %s":                         "Cannot read as an audio file: %s",
+	"高く評価した曲": "Liked songs",
+	"進捗イベントを http://%s/events で配信します":           "Serving progress events at http://%s/events",
+	"開始しました (inbox: %s, 自動ダウンロード: 一致度 %d%% 以上)": "Started (inbox: %s, auto-download at %d%% match or above)",
+	"スケジュール %s":             "Schedule %s",
+	"中断されていたジョブ %d 件を再開します": "Resuming %d interrupted jobs",
+	"inbox の読み込みに失敗: %v":    "Failed to read the inbox: %v",
+	"チャンネルの新着の登録に失敗: %v":    "Failed to queue channel uploads: %v",
+	"新譜の確認に失敗: %v":          "Failed to check new releases: %v",
+	"終了します":                 "Exiting",
+	"ジョブキューの更新に失敗: %v":      "Failed to update the job queue: %v",
+	"停止の要求を受けました。残りのジョブは次の起動時に処理します": "Stop requested. The remaining jobs will run on the next start",
+	"失敗: %s: %s":                       "Failed: %s: %s",
+	"完了: %s → %s (%d%%)":               "Done: %s → %s (%d%%)",
+	"要確認キューへの登録に失敗: %s: %v":            "Failed to add to the review queue: %s: %v",
+	"要確認: %s (%s)":                     "Needs review: %s (%s)",
+	"完了: %s → %s (リモート)":               "Done: %s → %s (remote)",
+	"チャンネルの確認に失敗: %v":                  "Failed to check channels: %v",
+	"新譜「%s」の曲を取得できませんでした: %v":          "Could not fetch the tracks of the new release \"%s\": %v",
+	"新譜: %s - %s の %d 曲をジョブキューに追加しました": "New release: queued %[3]d tracks of %[1]s - %[2]s",
+}
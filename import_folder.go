@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- フォルダの取り込み ---
+// よそで入手したタグの無い・不正確な音声ファイルのフォルダを、1曲ずつ既存ファイルのタグ付けと同じ流れ
+// (MusicBrainz検索 → トラック選択 → タグ編集 → 確認) で照合し、命名テンプレートどおりの名前で
+// downloads フォルダへ移す。照合はファイルのタグ、無ければ「アーティスト - 曲名」形式のファイル名で行う。
+
+// importAudioExts are the formats accepted from outside; anything ffmpeg reads but can't be kept is
+// converted to FLAC.
+var importAudioExts = map[string]bool{
+	".flac": true, ".opus": true, ".ogg": true, ".m4a": true, ".mp3": true, ".mka": true,
+	".wav": true, ".aiff": true, ".aif": true, ".wma": true, ".aac": true, ".ape": true, ".wv": true,
+}
+
+func runImportFolder(args []string) error {
+	if len(args) != 1 {
+		return errorf("使い方: import <フォルダ>")
+	}
+	dir, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return errorf("フォルダが見つかりません: %s", args[0])
+	}
+	m := newModel()
+	m.tagFile = dir
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// isLocalDir reports whether the search input names a folder on disk.
+func isLocalDir(query string) bool {
+	fi, err := os.Stat(strings.Trim(query, `"'`))
+	return err == nil && fi.IsDir()
+}
+
+// importFiles lists the audio files under dir, in name order.
+func importFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && importAudioExts[strings.ToLower(filepath.Ext(path))] {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// startLocal opens the tagger for a file, or starts importing every file in a folder.
+func (m *model) startLocal(path string) tea.Cmd {
+	path = strings.Trim(path, `"'`)
+	if !isLocalDir(path) {
+		return m.startTagger(path)
+	}
+	files, err := importFiles(path)
+	if err != nil || len(files) == 0 {
+		if err == nil {
+			err = errorf("取り込める音声ファイルがありません: %s", path)
+		}
+		m.tagFile, m.state, m.error = "", stateError, err
+		return nil
+	}
+	m.importQueue, m.importTotal, m.importLog = files, len(files), nil
+	return m.nextImport()
+}
+
+func (m model) importing() bool { return m.importTotal > 0 }
+
+// importProgress is "(3/12)" while importing a folder.
+func (m model) importProgress() string {
+	if !m.importing() {
+		return ""
+	}
+	return fmt.Sprintf("(%d/%d)", m.importTotal-len(m.importQueue), m.importTotal)
+}
+
+// finishImportItem records the outcome of the current file and moves on to the next one.
+func (m *model) finishImportItem(line string) tea.Cmd {
+	m.importLog = append(m.importLog, line)
+	return m.nextImport()
+}
+
+// nextImport starts the next file, or shows the summary once the folder is done.
+func (m *model) nextImport() tea.Cmd {
+	if len(m.importQueue) == 0 {
+		done := 0
+		for _, l := range m.importLog {
+			if strings.HasPrefix(l, "✅") {
+				done++
+			}
+		}
+		m.state, m.lastWarning = stateShowSuccess, ""
+		m.lastFile = tr("%d件中%d件を取り込みました\n%s", m.importTotal, done, strings.Join(m.importLog, "\n"))
+		m.importTotal, m.tagFile = 0, ""
+		return scanLibraryCmd(m.ffmpegPath)
+	}
+	next := m.importQueue[0]
+	m.importQueue = m.importQueue[1:]
+	cmd := m.startTagger(next)
+	m.statusMsg = tr("%s %s を読み込み中です...", m.importProgress(), filepath.Base(next))
+	return cmd
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// --- iTunes Search API のアートワーク ---
+const (
+	itunesSearchURL    = "https://itunes.apple.com/search"
+	itunesArtworkSize  = "3000x3000bb"
+	itunesMinAlbumSim  = 0.6
+	itunesMinArtistSim = 0.5
+)
+
+type itunesSearchResponse struct {
+	Results []itunesAlbum `json:"results"`
+}
+
+type itunesAlbum struct {
+	CollectionName string `json:"collectionName"`
+	ArtistName     string `json:"artistName"`
+	ArtworkURL100  string `json:"artworkUrl100"`
+}
+
+// releaseArtist joins the release's artist credit the way MusicBrainz displays it.
+func releaseArtist(releaseInfo MBRelease) string {
+	var b strings.Builder
+	for _, a := range releaseInfo.ArtistCredit {
+		b.WriteString(a.Name + a.JoinPhrase)
+	}
+	return b.String()
+}
+
+// itunesArtworkURL finds the album on iTunes and returns its artwork URL rewritten to the largest size.
+// Results whose album or artist name doesn't resemble the release are ignored to avoid wrong covers.
+func itunesArtworkURL(releaseInfo MBRelease) (string, error) {
+	artist := releaseArtist(releaseInfo)
+	q := url.Values{}
+	q.Set("term", strings.TrimSpace(artist+" "+releaseInfo.Title))
+	q.Set("entity", "album")
+	q.Set("limit", "10")
+	req, err := newGetRequest(itunesSearchURL + "?" + q.Encode())
+	if err != nil {
+		return "", err
+	}
+	resp, err := doWithRetry("itunes", req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("iTunes API returned %s", resp.Status)
+	}
+	var data itunesSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", err
+	}
+	best, bestScore := "", 0.0
+	for _, r := range data.Results {
+		if r.ArtworkURL100 == "" {
+			continue
+		}
+		albumSim := max(similarity(r.CollectionName, releaseInfo.Title), containmentScore(r.CollectionName, releaseInfo.Title))
+		artistSim := max(similarity(r.ArtistName, artist), containmentScore(r.ArtistName, artist))
+		if albumSim < itunesMinAlbumSim || artistSim < itunesMinArtistSim {
+			continue
+		}
+		if score := albumSim + artistSim; score > bestScore {
+			best, bestScore = r.ArtworkURL100, score
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no matching album among %d results", len(data.Results))
+	}
+	return strings.Replace(best, "100x100bb", itunesArtworkSize, 1), nil
+}
+
+func fetchITunesCover(localPath string, releaseInfo MBRelease) (string, bool) {
+	artURL, err := itunesArtworkURL(releaseInfo)
+	if err != nil {
+		log.Printf("Cover: iTunes lookup for %q failed: %v", releaseInfo.Title, err)
+		return "", false
+	}
+	return downloadCover(artURL, localPath)
+}
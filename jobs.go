@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// --- daemon のジョブキュー ---
+// inbox やチャンネル登録から受け取った検索語を jobs.json に保存してから1件ずつ処理する。
+// systemd などでサービスとして動かし、再起動や停止で途中になったジョブは次の起動時に続きから処理する。
+// 処理中に何度も中断される (プロセスごと落ちる) ジョブは maxJobAttempts 回で要確認キューに回す。
+// SQLite は使わない。読み書きするのは daemon のプロセス1つだけ (排他は jobsMu) で件数も少なく、
+// cgo や SQLite のドライバーに依存しないよう標準ライブラリの JSON と fsync 付きの置き換えで済ませる。
+const (
+	jobsFile       = "jobs.json"
+	jobQueued      = "queued"
+	jobRunning     = "running"
+	maxJobAttempts = 3
+)
+
+type daemonJob struct {
+	ID       string    `json:"id"`
+	Query    string    `json:"query"`
+	Status   string    `json:"status"`
+	Added    time.Time `json:"added"`
+	Started  time.Time `json:"started"`
+	Attempts int       `json:"attempts"`
+	// リモートの TUI で選んだ曲 (自動照合せずにそのままダウンロードする)
+	Download *downloadRequest `json:"download,omitempty"`
+}
+
+var jobsMu sync.Mutex
+
+// jobsAdded wakes the daemon when jobs arrive through the API, instead of waiting for the next check.
+var jobsAdded = make(chan struct{}, 1)
+
+func jobsPath() string { return filepath.Join(mainDir, jobsFile) }
+
+func loadJobs() ([]daemonJob, error) {
+	data, err := os.ReadFile(jobsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var jobs []daemonJob
+	return jobs, json.Unmarshal(data, &jobs)
+}
+
+// saveJobs writes through a temporary file so a crash mid-write can't leave a truncated queue. The
+// file is synced before the rename and the folder after it, so a power loss keeps either the old queue
+// or the new one rather than an empty file.
+func saveJobs(jobs []daemonJob) error {
+	data, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := jobsPath() + ".tmp"
+	if err := writeSynced(tmp, data); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, jobsPath()); err != nil {
+		return err
+	}
+	// フォルダの fsync は Windows ではできないので、失敗しても無視する
+	if dir, err := os.Open(filepath.Dir(jobsPath())); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// writeSynced is os.WriteFile followed by an fsync before the file is closed.
+func writeSynced(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// updateJobs applies fn to the stored queue under the lock and saves it.
+func updateJobs(fn func(jobs []daemonJob) []daemonJob) error {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	jobs, err := loadJobs()
+	if err != nil {
+		return err
+	}
+	return saveJobs(fn(jobs))
+}
+
+func addJobs(queries []string) error {
+	_, err := enqueueJobs(queries)
+	return err
+}
+
+// enqueueJobs adds the queries to the queue and returns the new jobs.
+func enqueueJobs(queries []string) ([]daemonJob, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	var added []daemonJob
+	err := updateJobs(func(jobs []daemonJob) []daemonJob {
+		now := time.Now()
+		for i, q := range queries {
+			added = append(added, daemonJob{ID: fmt.Sprintf("%d-%d", now.UnixNano(), i), Query: q, Status: jobQueued, Added: now})
+		}
+		return append(jobs, added...)
+	})
+	if err == nil {
+		select {
+		case jobsAdded <- struct{}{}:
+		default:
+		}
+		for _, j := range added {
+			publishProgress(progressEvent{Job: j.ID, Query: j.Query, Phase: phaseQueued})
+		}
+	}
+	return added, err
+}
+
+// resumeJobs puts jobs left running by a previous process back in the queue, and gives up on those
+// that have been interrupted too often.
+func resumeJobs() (resumed int, err error) {
+	var abandoned []daemonJob
+	err = updateJobs(func(jobs []daemonJob) []daemonJob {
+		kept := jobs[:0]
+		for _, j := range jobs {
+			if j.Status == jobRunning {
+				if j.Attempts >= maxJobAttempts {
+					abandoned = append(abandoned, j)
+					continue
+				}
+				j.Status = jobQueued
+				resumed++
+			}
+			kept = append(kept, j)
+		}
+		return kept
+	})
+	for _, j := range abandoned {
+		fileForReview(reviewEntry{Query: j.Query, Reason: tr("処理中に %d 回中断されました", j.Attempts)})
+	}
+	return resumed, err
+}
+
+// nextJob marks the oldest queued job as running and returns it.
+func nextJob() (daemonJob, bool, error) {
+	var job daemonJob
+	found := false
+	err := updateJobs(func(jobs []daemonJob) []daemonJob {
+		for i := range jobs {
+			if jobs[i].Status == jobQueued {
+				jobs[i].Status, jobs[i].Started = jobRunning, time.Now()
+				jobs[i].Attempts++
+				job, found = jobs[i], true
+				break
+			}
+		}
+		return jobs
+	})
+	return job, found, err
+}
+
+// enqueueDownloadJob queues a download chosen in a remote TUI.
+func enqueueDownloadJob(d downloadRequest) (daemonJob, error) {
+	job := daemonJob{ID: fmt.Sprintf("%d-d", time.Now().UnixNano()), Query: d.VideoURL, Status: jobQueued, Added: time.Now(), Download: &d}
+	if d.Tags.Title != "" {
+		job.Query = d.Tags.Artist + " - " + d.Tags.Title
+	}
+	err := updateJobs(func(jobs []daemonJob) []daemonJob { return append(jobs, job) })
+	if err == nil {
+		select {
+		case jobsAdded <- struct{}{}:
+		default:
+		}
+		publishProgress(progressEvent{Job: job.ID, Query: job.Query, Phase: phaseQueued})
+	}
+	return job, err
+}
+
+func finishJob(id string) error {
+	return updateJobs(func(jobs []daemonJob) []daemonJob {
+		kept := jobs[:0]
+		for _, j := range jobs {
+			if j.ID != id {
+				kept = append(kept, j)
+			}
+		}
+		return kept
+	})
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// --- キー割り当て ---
+// 検索からダウンロードまでの主な操作 (決定・戻る・タグ付けのスキップ・終了・キュー) のキーは
+// config.json の keys で変えられる。画面下の操作説明とヘルプはこの割り当てから作るので、変えたキーがそのまま表示される。
+// キーの名前は bubbletea の表記 (enter / esc / ctrl+q / tab など) で書く。
+// 画面が決め打ちで使うキー (m / c / Space / Ctrl+R など) と重なる割り当てや、文字入力中にも効く操作
+// (決定・戻る・終了・キュー) への文字キーの割り当ては、入力できなくなるので起動時に拒否する。
+type keysConfig struct {
+	// 決定 (既定: ["enter"])
+	Confirm []string `json:"confirm"`
+	// 前の画面に戻る (既定: ["esc"])
+	Back []string `json:"back"`
+	// MusicBrainz のタグ付けをスキップしてダウンロード (既定: ["s"])
+	SkipMB []string `json:"skip_mb"`
+	// 終了 (既定: ["ctrl+c"])
+	Quit []string `json:"quit"`
+	// ダウンロードキューを開く (既定: ["ctrl+q"])
+	Queue []string `json:"queue"`
+}
+
+type keyMap struct {
+	confirm, back, skipMB, quit, queue key.Binding
+}
+
+var keymap keyMap
+
+// fixedKeys are the keys the screens handle themselves, which a configured binding would shadow.
+var fixedKeys = map[string]string{
+	"m": "続き", "a": "自動照合", "g": "版の一覧", "c": "条件", "o": "条件",
+	"t": "条件", "r": "条件", " ": "複数選択", "x": "分割ダウンロード", "w": "1ファイルで保存",
+	"q": "キューに追加", "y": "はい", "n": "いいえ", "?": "ヘルプ", "f1": "ヘルプ",
+	"ctrl+d": "診断", "ctrl+y": "YT Music", "ctrl+n": "新着", "ctrl+o": "要確認", "ctrl+r": "履歴",
+	"ctrl+l": "ライブラリ", "ctrl+t": "Last.fmを採用",
+}
+
+func setupKeys() error {
+	keymap = keyMap{
+		confirm: newKeyBinding(cfg.Keys.Confirm, "enter"),
+		back:    newKeyBinding(cfg.Keys.Back, "esc"),
+		skipMB:  newKeyBinding(cfg.Keys.SkipMB, "s"),
+		quit:    newKeyBinding(cfg.Keys.Quit, "ctrl+c"),
+		queue:   newKeyBinding(cfg.Keys.Queue, "ctrl+q"),
+	}
+	return keymap.validate()
+}
+
+// validate rejects bindings that would take a key away from the screens: keys they handle themselves
+// or navigate lists with, keys bound twice, and printable keys for the actions that also work while a
+// text input has focus (the key would no longer reach the input).
+func (km keyMap) validate() error {
+	lists := list.DefaultKeyMap()
+	navigation := map[string]bool{}
+	for _, b := range []key.Binding{lists.CursorUp, lists.CursorDown, lists.PrevPage, lists.NextPage, lists.GoToStart, lists.GoToEnd, lists.Filter} {
+		for _, k := range b.Keys() {
+			navigation[k] = true
+		}
+	}
+	bound := map[string]string{}
+	for _, a := range []struct {
+		name     string
+		binding  key.Binding
+		textable bool
+	}{
+		{"confirm", km.confirm, true}, {"back", km.back, true}, {"quit", km.quit, true},
+		{"queue", km.queue, true}, {"skip_mb", km.skipMB, false},
+	} {
+		for _, k := range a.binding.Keys() {
+			switch {
+			case a.textable && printableKey(k):
+				return errorf("keys.%s: %s は文字入力中にも使う操作なので、文字のキーは割り当てられません", a.name, k)
+			case fixedKeys[k] != "":
+				return errorf("keys.%s: %s は「%s」で使われています", a.name, k, tr(fixedKeys[k]))
+			case navigation[k]:
+				return errorf("keys.%s: %s はリストの移動で使われています", a.name, k)
+			case bound[k] != "":
+				return errorf("keys.%s: %s は keys.%s にも割り当てられています", a.name, k, bound[k])
+			}
+			bound[k] = a.name
+		}
+	}
+	return nil
+}
+
+// printableKey reports whether a key types a character: a single rune, or space.
+func printableKey(k string) bool {
+	return k == "space" || utf8.RuneCountInString(k) == 1
+}
+
+func newKeyBinding(keys []string, fallback string) key.Binding {
+	var clean []string
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			clean = append(clean, k)
+		}
+	}
+	if len(clean) == 0 {
+		clean = []string{fallback}
+	}
+	return key.NewBinding(key.WithKeys(clean...), key.WithHelp(keyLabel(clean), ""))
+}
+
+// keyLabel spells keys the way the help text does: "ctrl+q" as Ctrl+Q, "enter" as Enter, letters as
+// they are.
+func keyLabel(keys []string) string {
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		parts := strings.Split(k, "+")
+		for j, p := range parts {
+			switch {
+			case len(p) > 1:
+				parts[j] = strings.ToUpper(p[:1]) + p[1:]
+			case len(parts) > 1:
+				parts[j] = strings.ToUpper(p)
+			}
+		}
+		labels[i] = strings.Join(parts, "+")
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyName is the label of a binding for help texts.
+func keyName(b key.Binding) string { return b.Help().Key }
+
+// footer renders a help line from "key: description" hints.
+func footer(hints ...string) string {
+	return helpStyle.Render("  " + strings.Join(hints, " | "))
+}
+
+func hint(k, desc string) string { return k + ": " + desc }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Last.fm による補完 ---
+// MusicBrainzの情報が乏しい (ジャンルやリリース日が無い) 曲について、Last.fm の表記ゆれ補正済みの
+// アーティスト名・曲名と、よく付けられているタグ (ジャンル) を取得し、タグ編集画面で Ctrl+T で採用できるようにする。
+const (
+	lastfmAPI     = "https://ws.audioscrobbler.com/2.0/"
+	lastfmMaxTags = 3
+)
+
+type lastfmConfig struct {
+	// Last.fm の API キー (https://www.last.fm/api/account/create)。空なら使わない
+	APIKey string `json:"api_key"`
+}
+
+// lastfmIgnoredTags are popular Last.fm tags that say nothing about the genre.
+var lastfmIgnoredTags = map[string]bool{
+	"seen live": true, "favorites": true, "favourite": true, "favorite": true, "favourites": true,
+	"my favorite": true, "love": true, "loved": true, "beautiful": true, "awesome": true, "cool": true,
+	"spotify": true, "under 2000 listeners": true, "albums i own": true,
+}
+
+// lastfmInfo is what Last.fm suggests for a track.
+type lastfmInfo struct {
+	Artist, Title string
+	Tags          []string
+}
+
+func (i lastfmInfo) empty() bool { return i.Artist == "" && i.Title == "" && len(i.Tags) == 0 }
+
+func (i lastfmInfo) genre() string {
+	if len(i.Tags) == 0 {
+		return ""
+	}
+	return i.Tags[0]
+}
+
+type lastfmFetchedMsg struct {
+	key  string
+	info lastfmInfo
+	err  error
+}
+
+// thinMetadata reports whether MusicBrainz left out enough that Last.fm is worth asking.
+func thinMetadata(tags finalTags) bool {
+	return tags.Genre == "" || tags.Date == ""
+}
+
+// prefetchLastFM looks the track up on Last.fm when an API key is set and the MB data is thin.
+func (m *model) prefetchLastFM() tea.Cmd {
+	tags := buildTags(m.selectedMB.meta.(MBRelease), m.selectedTrack)
+	m.lastfm, m.lastfmApplied = lastfmInfo{}, false
+	if cfg.LastFM.APIKey == "" || !thinMetadata(tags) {
+		m.lastfmKey = ""
+		return nil
+	}
+	key := lyricsKey(tags)
+	m.lastfmKey = key
+	return func() tea.Msg {
+		info, err := lastfmTrackInfo(tags.Artist, tags.Title)
+		return lastfmFetchedMsg{key: key, info: info, err: err}
+	}
+}
+
+// lastfmTrackInfo asks track.getInfo with autocorrect for the corrected names and top tags, falling
+// back to the artist's tags when the track has none.
+func lastfmTrackInfo(artist, title string) (lastfmInfo, error) {
+	var data struct {
+		Error   int    `json:"error"`
+		Message string `json:"message"`
+		Track   struct {
+			Name   string `json:"name"`
+			Artist struct {
+				Name string `json:"name"`
+			} `json:"artist"`
+			TopTags struct {
+				Tag []struct {
+					Name string `json:"name"`
+				} `json:"tag"`
+			} `json:"toptags"`
+		} `json:"track"`
+	}
+	if err := getJSON("lastfm", lastfmURL("track.getInfo", url.Values{"artist": {artist}, "track": {title}, "autocorrect": {"1"}}), nil, &data); err != nil {
+		return lastfmInfo{}, err
+	}
+	if data.Error != 0 {
+		return lastfmInfo{}, fmt.Errorf("last.fm error %d: %s", data.Error, data.Message)
+	}
+	info := lastfmInfo{Artist: data.Track.Artist.Name, Title: data.Track.Name}
+	var names []string
+	for _, t := range data.Track.TopTags.Tag {
+		names = append(names, t.Name)
+	}
+	info.Tags = genreTags(names, info.Artist)
+	if len(info.Tags) > 0 || info.Artist == "" {
+		return info, nil
+	}
+	var top struct {
+		TopTags struct {
+			Tag []struct {
+				Name string `json:"name"`
+			} `json:"tag"`
+		} `json:"toptags"`
+	}
+	if err := getJSON("lastfm", lastfmURL("artist.getTopTags", url.Values{"artist": {info.Artist}, "autocorrect": {"1"}}), nil, &top); err != nil {
+		return info, nil
+	}
+	names = names[:0]
+	for _, t := range top.TopTags.Tag {
+		names = append(names, t.Name)
+	}
+	info.Tags = genreTags(names, info.Artist)
+	return info, nil
+}
+
+func lastfmURL(method string, params url.Values) string {
+	params.Set("method", method)
+	params.Set("api_key", cfg.LastFM.APIKey)
+	params.Set("format", "json")
+	return lastfmAPI + "?" + params.Encode()
+}
+
+// genreTags keeps the first few tags that look like genres, title-cased ("j-pop" → "J-Pop").
+func genreTags(names []string, artist string) []string {
+	var tags []string
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		lower := strings.ToLower(n)
+		if n == "" || lastfmIgnoredTags[lower] || strings.EqualFold(n, artist) {
+			continue
+		}
+		tags = append(tags, titleCaseTag(lower))
+		if len(tags) == lastfmMaxTags {
+			break
+		}
+	}
+	return tags
+}
+
+func titleCaseTag(s string) string {
+	b := []rune(s)
+	for i := range b {
+		if i == 0 || strings.ContainsRune(" -/&", b[i-1]) {
+			b[i] = []rune(strings.ToUpper(string(b[i])))[0]
+		}
+	}
+	return string(b)
+}
+
+// lastfmLine renders the suggestion for the tag edit screen, or "" when there is nothing to offer.
+func (m model) lastfmLine() string {
+	if m.lastfmKey == "" || m.lastfm.empty() {
+		return ""
+	}
+	var parts []string
+	if m.lastfm.Artist != "" && m.lastfm.Artist != m.tagInputs[1].Value() {
+		parts = append(parts, tr("アーティスト: %s", m.lastfm.Artist))
+	}
+	if m.lastfm.Title != "" && m.lastfm.Title != m.tagInputs[0].Value() {
+		parts = append(parts, tr("曲名: %s", m.lastfm.Title))
+	}
+	if len(m.lastfm.Tags) > 0 {
+		parts = append(parts, tr("ジャンル: %s", strings.Join(m.lastfm.Tags, ", ")))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if m.lastfmApplied {
+		return tr("Last.fm (採用済み): %s", strings.Join(parts, " / "))
+	}
+	return tr("Last.fm: %s (Ctrl+T: 採用)", strings.Join(parts, " / "))
+}
+
+// applyLastFM copies the corrected names into the inputs; the genre is used when the tags are built.
+func (m *model) applyLastFM() {
+	if m.lastfm.empty() {
+		return
+	}
+	if m.lastfm.Title != "" {
+		m.tagInputs[0].SetValue(m.lastfm.Title)
+	}
+	if m.lastfm.Artist != "" {
+		m.tagInputs[1].SetValue(m.lastfm.Artist)
+	}
+	m.lastfmApplied = true
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- ライブラリ容量の管理 ---
+const (
+	flacBytesPerSec = 110 * 1024 // YouTube音源をFLAC化した場合のおおよそのビットレート
+	opusBytesPerSec = 160 * 1000 / 8
+)
+
+type libraryUsageMsg struct {
+	bytes int64
+	err   error
+}
+
+func (c libraryConfig) budgetBytes() int64 { return c.MaxSizeMB * 1024 * 1024 }
+
+func libraryUsage() (int64, error) {
+	var total int64
+	err := filepath.WalkDir(filepath.Join(mainDir, downloadsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+func libraryUsageCmd() tea.Msg {
+	bytes, err := libraryUsage()
+	return libraryUsageMsg{bytes: bytes, err: err}
+}
+
+func estimateFlacBytes(durationSec float64) int64 { return int64(durationSec * flacBytesPerSec) }
+
+// lossySavings estimates how much converting a FLAC file to ~160kbps Opus would free.
+func lossySavings(sizeBytes int64, durationSec float64) int64 {
+	if durationSec <= 0 {
+		durationSec = float64(sizeBytes) / flacBytesPerSec
+	}
+	if saved := sizeBytes - int64(durationSec*opusBytesPerSec); saved > 0 {
+		return saved
+	}
+	return 0
+}
+
+func usageSummary(used int64) string {
+	budget := cfg.Library.budgetBytes()
+	if budget <= 0 {
+		return tr("ライブラリ使用量: %s", formatBytes(used))
+	}
+	return tr("ライブラリ使用量: %s / %s (%d%%)", formatBytes(used), formatBytes(budget), used*100/budget)
+}
+
+// quotaWarning returns a warning when adding incoming bytes would push the library past the budget.
+func quotaWarning(used, incoming int64) string {
+	budget := cfg.Library.budgetBytes()
+	if budget <= 0 || used+incoming <= budget {
+		return ""
+	}
+	return tr("⚠ このダウンロード (約%s) でライブラリの上限 %s を超えます。履歴画面 (Ctrl+R) の c で整理候補を確認できます。",
+		formatBytes(incoming), formatBytes(budget))
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- ライブラリの索引 ---
+// downloads フォルダの音声ファイルを走査してタグを読み、library.json に索引として保存する。前回から
+// サイズと更新日時が変わっていないファイルは読み直さない。索引は重複の検出・統計・ライブラリブラウザに使う。
+const (
+	libraryIndexFile       = "library.json"
+	libraryIndexVersion    = 1
+	duplicateDurationDelta = 3.0 // 同じ曲とみなす長さの差 (秒)
+)
+
+var libraryAudioExts = map[string]bool{".flac": true, ".opus": true, ".ogg": true, ".m4a": true, ".mp3": true, ".mka": true}
+
+type libraryTrack struct {
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+	Format      string    `json:"format"`
+	Title       string    `json:"title"`
+	Artist      string    `json:"artist"`
+	AlbumArtist string    `json:"album_artist,omitempty"`
+	Album       string    `json:"album,omitempty"`
+	Date        string    `json:"date,omitempty"`
+	TrackNumber string    `json:"track_number,omitempty"`
+	ISRC        string    `json:"isrc,omitempty"`
+	Duration    float64   `json:"duration,omitempty"`
+}
+
+type libraryIndex struct {
+	Version int            `json:"version"`
+	Scanned time.Time      `json:"scanned"`
+	Tracks  []libraryTrack `json:"tracks"`
+}
+
+type libraryIndexedMsg struct {
+	index *libraryIndex
+	err   error
+}
+
+// inLibrary reports whether path is inside the downloads folder.
+func inLibrary(path string) bool {
+	root, err := filepath.Abs(filepath.Join(mainDir, downloadsDir))
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func libraryIndexPath() string { return filepath.Join(mainDir, libraryIndexFile) }
+
+func loadLibraryIndex() (*libraryIndex, error) {
+	data, err := os.ReadFile(libraryIndexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return &libraryIndex{Version: libraryIndexVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var idx libraryIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, err
+	}
+	return &idx, nil
+}
+
+func saveLibraryIndex(idx *libraryIndex) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := libraryIndexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, libraryIndexPath())
+}
+
+// scanLibrary refreshes the index from the downloads folder. Without ffmpeg only FLAC files can be
+// read; other formats are indexed by file name.
+func scanLibrary(ffmpegPath string) (*libraryIndex, error) {
+	prev, err := loadLibraryIndex()
+	if err != nil {
+		log.Printf("Library: rebuilding unreadable index: %v", err)
+		prev = &libraryIndex{}
+	}
+	known := map[string]libraryTrack{}
+	for _, t := range prev.Tracks {
+		known[t.Path] = t
+	}
+	idx := &libraryIndex{Version: libraryIndexVersion, Scanned: time.Now()}
+	read := 0
+	err = filepath.WalkDir(filepath.Join(mainDir, downloadsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || !libraryAudioExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if t, ok := known[path]; ok && t.Size == info.Size() && t.ModTime.Equal(info.ModTime()) {
+			idx.Tracks = append(idx.Tracks, t)
+			return nil
+		}
+		t := readLibraryTrack(ffmpegPath, path)
+		t.Size, t.ModTime = info.Size(), info.ModTime()
+		idx.Tracks = append(idx.Tracks, t)
+		read++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Library: indexed %d files (%d read)", len(idx.Tracks), read)
+	return idx, saveLibraryIndex(idx)
+}
+
+func scanLibraryCmd(ffmpegPath string) tea.Cmd {
+	return func() tea.Msg {
+		idx, err := scanLibrary(ffmpegPath)
+		return libraryIndexedMsg{index: idx, err: err}
+	}
+}
+
+// readLibraryTrack reads the tags of one file: FLAC directly, anything else through ffmpeg.
+func readLibraryTrack(ffmpegPath, path string) libraryTrack {
+	ext := strings.ToLower(filepath.Ext(path))
+	t := libraryTrack{Path: path, Format: strings.TrimPrefix(ext, ".")}
+	tags := map[string]string{}
+	if ext == ".flac" {
+		if ft, err := readFLACTags(path); err == nil {
+			for _, c := range ft.comments {
+				if k, v, ok := strings.Cut(c, "="); ok {
+					tags[strings.ToLower(k)] = v
+				}
+			}
+		}
+		if f, err := os.Open(path); err == nil {
+			blocks, _, err := readFLACMetadata(f)
+			f.Close()
+			if err == nil {
+				if rate, samples, err := streamInfo(blocks[0].data); err == nil && rate > 0 {
+					t.Duration = float64(samples) / float64(rate)
+				}
+			}
+		}
+	} else if ffmpegPath != "" {
+		tags = probeFormatTags(ffmpegPath, path)
+		t.Duration, _ = probeDuration(ffmpegPath, path)
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := strings.TrimSpace(tags[k]); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	t.Title = first("title")
+	t.Artist = first("artist")
+	t.AlbumArtist = first("albumartist", "album_artist")
+	t.Album = first("album")
+	t.Date = first("date")
+	t.TrackNumber = first("tracknumber", "track")
+	t.ISRC = first("isrc", "tsrc")
+	if t.Title == "" {
+		t.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return t
+}
+
+// sameSong reports whether two tracks are the same recording: the same ISRC, or the same artist and
+// title with lengths within a few seconds (unknown lengths count as matching).
+func sameSong(a, b libraryTrack) bool {
+	if a.ISRC != "" && a.ISRC == b.ISRC {
+		return true
+	}
+	if normalizeForMatch(a.Title) != normalizeForMatch(b.Title) || normalizeForMatch(a.Artist) != normalizeForMatch(b.Artist) {
+		return false
+	}
+	return a.Duration == 0 || b.Duration == 0 || math.Abs(a.Duration-b.Duration) <= duplicateDurationDelta
+}
+
+// duplicatesOf lists the files that already hold the song about to be downloaded.
+func (idx *libraryIndex) duplicatesOf(tags finalTags) []libraryTrack {
+	if idx == nil {
+		return nil
+	}
+	want := libraryTrack{Title: tags.Title, Artist: tags.Artist, ISRC: tags.ISRC, Duration: float64(tags.DurationSec)}
+	var out []libraryTrack
+	for _, t := range idx.Tracks {
+		if sameSong(want, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// duplicateGroups groups the files holding the same song, largest groups first.
+func (idx *libraryIndex) duplicateGroups() [][]libraryTrack {
+	used := make([]bool, len(idx.Tracks))
+	var groups [][]libraryTrack
+	for i, a := range idx.Tracks {
+		if used[i] {
+			continue
+		}
+		group := []libraryTrack{a}
+		for j := i + 1; j < len(idx.Tracks); j++ {
+			if !used[j] && sameSong(a, idx.Tracks[j]) {
+				used[j] = true
+				group = append(group, idx.Tracks[j])
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i]) > len(groups[j]) })
+	return groups
+}
+
+// historyEntries turns the index into browser rows, carrying over ratings and notes from the history.
+func (idx *libraryIndex) historyEntries(history []historyEntry) []historyEntry {
+	latest := map[string]historyEntry{}
+	for _, e := range history {
+		if e.status() == statusOK {
+			latest[e.Path] = e
+		}
+	}
+	entries := make([]historyEntry, len(idx.Tracks))
+	for i, t := range idx.Tracks {
+		h := latest[t.Path]
+		entries[i] = historyEntry{
+			Time: t.ModTime, Path: t.Path, Title: t.Title, Artist: t.Artist, Album: t.Album, Date: t.Date,
+			VideoID: h.VideoID, VideoURL: h.VideoURL, ReleaseID: h.ReleaseID, RecordingID: h.RecordingID,
+			CoverSource: h.CoverSource, Status: statusOK, Rating: h.Rating, Note: h.Note,
+		}
+	}
+	return entries
+}
+
+func runLibrary(args []string) error {
+	fs := flag.NewFlagSet("library", flag.ContinueOnError)
+	dupes := fs.Bool("dupes", false, tr("同じ曲が複数あるファイルを一覧表示する"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	ffmpegPath, err := findFfmpeg()
+	if err != nil {
+		fmt.Println(tr("ffmpegが見つからないため、FLAC以外のファイルはタグを読まずに登録します。"))
+	}
+	idx, err := scanLibrary(ffmpegPath)
+	if err != nil {
+		return errorf("ライブラリの走査に失敗: %w", err)
+	}
+	fmt.Print(idx.stats())
+	groups := idx.duplicateGroups()
+	if !*dupes {
+		if len(groups) > 0 {
+			fmt.Print(tr("\n重複の可能性がある曲が %d組あります (-dupes で一覧表示)\n", len(groups)))
+		}
+		return nil
+	}
+	for _, g := range groups {
+		fmt.Printf("\n%s - %s\n", g[0].Artist, g[0].Title)
+		for _, t := range g {
+			fmt.Printf("  %s (%s, %s, %s)\n", t.Path, t.Format, formatDuration(int(t.Duration)), formatBytes(t.Size))
+		}
+	}
+	return nil
+}
+
+// stats summarizes the library: counts, total size and length, and the share of each format.
+func (idx *libraryIndex) stats() string {
+	artists, albums := map[string]bool{}, map[string]bool{}
+	formats := map[string]int{}
+	var size int64
+	var seconds float64
+	for _, t := range idx.Tracks {
+		artist := t.AlbumArtist
+		if artist == "" {
+			artist = t.Artist
+		}
+		if t.Artist != "" {
+			artists[normalizeForMatch(t.Artist)] = true
+		}
+		if t.Album != "" {
+			albums[normalizeForMatch(artist)+"\x00"+normalizeForMatch(t.Album)] = true
+		}
+		formats[t.Format]++
+		size += t.Size
+		seconds += t.Duration
+	}
+	var b strings.Builder
+	b.WriteString(tr("曲数: %d / アーティスト: %d / アルバム: %d\n", len(idx.Tracks), len(artists), len(albums)))
+	b.WriteString(tr("合計: %s / %s\n", formatBytes(size), formatHours(seconds)))
+	names := make([]string, 0, len(formats))
+	for f := range formats {
+		names = append(names, f)
+	}
+	sort.Slice(names, func(i, j int) bool { return formats[names[i]] > formats[names[j]] })
+	parts := make([]string, len(names))
+	for i, f := range names {
+		parts[i] = tr("%s %d曲", f, formats[f])
+	}
+	if len(parts) > 0 {
+		b.WriteString(tr("形式: %s\n", strings.Join(parts, " / ")))
+	}
+	return b.String()
+}
+
+func formatHours(seconds float64) string {
+	h := int(seconds) / 3600
+	return tr("%d時間%02d分", h, int(seconds)%3600/60)
+}
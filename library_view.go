@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/width"
+)
+
+// --- ライブラリブラウザ ---
+// 保存済みの曲をアーティスト / アルバム / 年ごとにまとめて表示する。並び順は言語に合わせた照合順
+// (日本語ならかなは五十音順) で、A〜Z や あ/か/さ… の見出しに一気に移動できる。
+const (
+	libraryGroupArtist = "artist"
+	libraryGroupAlbum  = "album"
+	libraryGroupYear   = "year"
+	libraryIndexDigit  = "#"
+	libraryIndexOther  = "他"
+	libraryPageRows    = 10
+)
+
+var libraryGroupKeys = []string{libraryGroupArtist, libraryGroupAlbum, libraryGroupYear}
+
+// kanaRows are the gojūon rows by their first kana in hiragana order (small ゃ/ゎ sort first in
+// their rows).
+var kanaRows = []struct {
+	start rune
+	label string
+}{
+	{'ぁ', "あ"}, {'か', "か"}, {'さ', "さ"}, {'た', "た"}, {'な', "な"},
+	{'は', "は"}, {'ま', "ま"}, {'ゃ', "や"}, {'ら', "ら"}, {'ゎ', "わ"},
+}
+
+type libraryLoadedMsg struct {
+	index   *libraryIndex
+	entries []historyEntry
+	err     error
+}
+
+type libraryGroup struct {
+	name, index string
+	entries     []historyEntry
+}
+
+// libraryRow is one visible line of the browser; entry is -1 for a group header.
+type libraryRow struct{ group, entry int }
+
+func groupLabel(key string) string {
+	switch key {
+	case libraryGroupAlbum:
+		return tr("アルバム")
+	case libraryGroupYear:
+		return tr("年")
+	}
+	return tr("アーティスト")
+}
+
+// loadLibraryCmd rescans the downloads folder, so files added or retagged outside the app show up
+// too. Ratings and notes come from the history.
+func loadLibraryCmd(ffmpegPath string) tea.Cmd {
+	return func() tea.Msg {
+		idx, err := scanLibrary(ffmpegPath)
+		if err != nil {
+			return libraryLoadedMsg{err: err}
+		}
+		history, err := loadHistory()
+		if err != nil {
+			return libraryLoadedMsg{err: err}
+		}
+		return libraryLoadedMsg{index: idx, entries: idx.historyEntries(history)}
+	}
+}
+
+func newLibraryCollator() *collate.Collator {
+	tag, err := language.Parse(cfg.Library.SortLocale)
+	if err != nil {
+		tag = language.Japanese
+	}
+	return collate.New(tag, collate.IgnoreCase, collate.IgnoreWidth, collate.Numeric)
+}
+
+// indexLetter is the jump section a name is filed under: A〜Z, # for digits, the gojūon row for kana
+// and 他 for everything else (kanji included, since their reading isn't known).
+func indexLetter(name string) string {
+	for _, r := range width.Fold.String(name) {
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 'ァ' - 'ぁ'
+		}
+		switch r { // ひらがなの並びで最後に置かれている文字
+		case 'ゔ':
+			r = 'う'
+		case 'ゕ':
+			r = 'か'
+		case 'ゖ':
+			r = 'け'
+		}
+		switch {
+		case unicode.IsPunct(r) || unicode.IsSpace(r) || unicode.IsSymbol(r):
+			continue
+		case r <= unicode.MaxASCII && unicode.IsLetter(r):
+			return string(unicode.ToUpper(r))
+		case unicode.IsDigit(r):
+			return libraryIndexDigit
+		case r >= 'ぁ' && r <= 'ゖ':
+			label := kanaRows[0].label
+			for _, row := range kanaRows {
+				if r >= row.start {
+					label = row.label
+				}
+			}
+			return label
+		}
+		return libraryIndexOther
+	}
+	return libraryIndexOther
+}
+
+func libraryGroupName(e historyEntry, key string) string {
+	var name string
+	switch key {
+	case libraryGroupAlbum:
+		name = e.Album
+	case libraryGroupYear:
+		if len(e.Date) >= 4 {
+			name = e.Date[:4]
+		}
+	default:
+		name = e.Artist
+	}
+	if strings.TrimSpace(name) == "" {
+		return tr("(不明)")
+	}
+	return name
+}
+
+// groupLibrary groups and sorts the entries with the collator. Years are listed newest first.
+func groupLibrary(entries []historyEntry, key string, col *collate.Collator) []libraryGroup {
+	pos := map[string]int{}
+	var groups []libraryGroup
+	for _, e := range entries {
+		name := libraryGroupName(e, key)
+		g, ok := pos[name]
+		if !ok {
+			g = len(groups)
+			pos[name] = g
+			index := indexLetter(name)
+			if key == libraryGroupYear {
+				index = libraryIndexDigit
+			}
+			groups = append(groups, libraryGroup{name: name, index: index})
+		}
+		groups[g].entries = append(groups[g].entries, e)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if key == libraryGroupYear {
+			return col.CompareString(groups[i].name, groups[j].name) > 0
+		}
+		return col.CompareString(groups[i].name, groups[j].name) < 0
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.entries, func(i, j int) bool {
+			a, b := g.entries[i], g.entries[j]
+			var c int
+			switch key {
+			case libraryGroupArtist:
+				c = col.CompareString(a.Album, b.Album)
+			case libraryGroupYear:
+				c = col.CompareString(a.Artist, b.Artist)
+			}
+			if c == 0 {
+				c = col.CompareString(a.Title, b.Title)
+			}
+			return c < 0
+		})
+	}
+	return groups
+}
+
+func (m *model) openLibrary(entries []historyEntry) {
+	m.libEntries, m.libCursor, m.libOpen = entries, 0, map[string]bool{}
+	if m.libGroupBy == "" {
+		m.libGroupBy = cfg.Library.GroupBy
+	}
+	m.libGroups = groupLibrary(entries, m.libGroupBy, newLibraryCollator())
+	m.state = stateLibrary
+}
+
+func (m model) libraryRows() []libraryRow {
+	var rows []libraryRow
+	for g, group := range m.libGroups {
+		rows = append(rows, libraryRow{group: g, entry: -1})
+		if !m.libOpen[group.name] {
+			continue
+		}
+		for i := range group.entries {
+			rows = append(rows, libraryRow{group: g, entry: i})
+		}
+	}
+	return rows
+}
+
+// moveLibraryCursorTo puts the cursor on the header of group g.
+func (m *model) moveLibraryCursorTo(g int) {
+	for r, row := range m.libraryRows() {
+		if row.group == g && row.entry < 0 {
+			m.libCursor = r
+			return
+		}
+	}
+}
+
+// jumpLibrarySection moves to the next (dir 1) or previous (dir -1) index section.
+func (m *model) jumpLibrarySection(dir int) {
+	rows := m.libraryRows()
+	if m.libCursor >= len(rows) {
+		return
+	}
+	cur := rows[m.libCursor].group
+	for g := cur + dir; g >= 0 && g < len(m.libGroups); g += dir {
+		if m.libGroups[g].index == m.libGroups[cur].index {
+			continue
+		}
+		// 前のセクションへ戻るときはそのセクションの先頭に合わせる
+		for dir < 0 && g > 0 && m.libGroups[g-1].index == m.libGroups[g].index {
+			g--
+		}
+		m.moveLibraryCursorTo(g)
+		return
+	}
+}
+
+func (m *model) jumpLibraryIndex(index string) {
+	for g, group := range m.libGroups {
+		if group.index == index {
+			m.moveLibraryCursorTo(g)
+			return
+		}
+	}
+}
+
+func (m *model) updateLibrary(msg tea.KeyMsg) {
+	rows := m.libraryRows()
+	key := msg.String()
+	switch key {
+	case "up", "k":
+		m.libCursor = max(m.libCursor-1, 0)
+	case "down", "j":
+		m.libCursor = min(m.libCursor+1, len(rows)-1)
+	case "pgup":
+		m.libCursor = max(m.libCursor-libraryPageRows, 0)
+	case "pgdown":
+		m.libCursor = min(m.libCursor+libraryPageRows, len(rows)-1)
+	case "home":
+		m.libCursor = 0
+	case "end":
+		m.libCursor = len(rows) - 1
+	case " ", "enter", "left", "right", "h", "l":
+		if m.libCursor >= len(rows) {
+			return
+		}
+		g := rows[m.libCursor].group
+		name := m.libGroups[g].name
+		switch key {
+		case "left", "h":
+			m.libOpen[name] = false
+		case "right", "l":
+			m.libOpen[name] = true
+		default:
+			m.libOpen[name] = !m.libOpen[name]
+		}
+		if !m.libOpen[name] {
+			m.moveLibraryCursorTo(g)
+		}
+	case "g":
+		next := libraryGroupKeys[0]
+		for i, k := range libraryGroupKeys {
+			if k == m.libGroupBy {
+				next = libraryGroupKeys[(i+1)%len(libraryGroupKeys)]
+			}
+		}
+		m.libGroupBy = next
+		m.openLibrary(m.libEntries)
+	case "[":
+		m.jumpLibrarySection(-1)
+	case "]":
+		m.jumpLibrarySection(1)
+	case "#":
+		m.jumpLibraryIndex(libraryIndexDigit)
+	case "esc":
+		m.state = stateInput
+	default:
+		if r := []rune(key); len(r) == 1 && r[0] >= 'A' && r[0] <= 'Z' {
+			m.jumpLibraryIndex(key)
+		}
+	}
+}
+
+// libraryIndexBar lists the sections present in the library, highlighting the current one.
+func (m model) libraryIndexBar(current string) string {
+	var parts []string
+	for g, group := range m.libGroups {
+		if g > 0 && m.libGroups[g-1].index == group.index {
+			continue
+		}
+		if group.index == current {
+			parts = append(parts, lipgloss.NewStyle().Foreground(cyanColor).Bold(true).Render(group.index))
+		} else {
+			parts = append(parts, helpStyle.Render(group.index))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (m model) libraryView() string {
+	if len(m.libEntries) == 0 {
+		return tr("\n  ライブラリに曲がありません。ダウンロードした曲がここに表示されます。\n")
+	}
+	rows := m.libraryRows()
+	var b strings.Builder
+	b.WriteString("\n" + listTitleStyle.Render(tr("ライブラリ (%d曲 / %d%s) — %s順", len(m.libEntries), len(m.libGroups), groupLabel(m.libGroupBy), groupLabel(m.libGroupBy))) + "\n")
+	var current string
+	var selected *historyEntry
+	if m.libCursor < len(rows) {
+		row := rows[m.libCursor]
+		current = m.libGroups[row.group].index
+		if row.entry >= 0 {
+			selected = &m.libGroups[row.group].entries[row.entry]
+		}
+	}
+	b.WriteString("  " + m.libraryIndexBar(current) + "\n\n")
+
+	visible := max(m.height-14, 5)
+	start := min(max(m.libCursor-visible/2, 0), max(len(rows)-visible, 0))
+	for r := start; r < min(start+visible, len(rows)); r++ {
+		row := rows[r]
+		cursor := "  "
+		if r == m.libCursor {
+			cursor = lipgloss.NewStyle().Foreground(cyanColor).Render("> ")
+		}
+		group := m.libGroups[row.group]
+		if row.entry < 0 {
+			arrow := "▶"
+			if m.libOpen[group.name] {
+				arrow = "▼"
+			}
+			title := lipgloss.NewStyle().Foreground(pinkColor).Bold(true).Render(group.name)
+			b.WriteString(fmt.Sprintf("%s%s %s %s\n", cursor, arrow, title, helpStyle.Render(tr("(%d曲)", len(group.entries)))))
+			continue
+		}
+		e := group.entries[row.entry]
+		detail := e.Album
+		if m.libGroupBy != libraryGroupArtist {
+			detail = e.Artist
+		}
+		line := fmt.Sprintf("%s    %s %s", cursor, e.Title, helpStyle.Render(detail))
+		if badge := ratingBadge(e.Rating); badge != "" {
+			line += " " + badge
+		}
+		b.WriteString(line + "\n")
+	}
+	if selected != nil {
+		b.WriteString("\n  " + helpStyle.Render(selected.Path) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// --- ListenBrainz への送信 ---
+// ダウンロードが完了した曲を、MBID付きの再生記録 (listen) として ListenBrainz に送る。
+// MBIDが付いているので ListenBrainz 側で照合 (mapping) し直す必要がない。
+// playlist を指定すると、その ListenBrainz のプレイリストにも録音を追加する。
+const (
+	listenBrainzAPI    = "https://api.listenbrainz.org/1"
+	listenBrainzClient = "GoMusicDownloader"
+)
+
+type listenBrainzConfig struct {
+	// ListenBrainz のユーザートークン (https://listenbrainz.org/settings/)。空なら送らない
+	Token string `json:"token"`
+	// ダウンロードした曲を再生記録として送る
+	SubmitListens bool `json:"submit_listens"`
+	// ダウンロードした録音を追加するプレイリストのMBID。空なら追加しない
+	Playlist string `json:"playlist"`
+}
+
+func (c listenBrainzConfig) enabled() bool {
+	return c.Token != "" && (c.SubmitListens || c.Playlist != "")
+}
+
+type listenBrainzListen struct {
+	ListenedAt    int64 `json:"listened_at"`
+	TrackMetadata struct {
+		ArtistName     string                 `json:"artist_name"`
+		TrackName      string                 `json:"track_name"`
+		ReleaseName    string                 `json:"release_name,omitempty"`
+		AdditionalInfo map[string]interface{} `json:"additional_info"`
+	} `json:"track_metadata"`
+}
+
+// submitToListenBrainz sends a finished download to ListenBrainz. Files retagged from disk are not
+// downloads and are skipped. Failures are logged only; a download never fails because of it.
+func submitToListenBrainz(job convertJob, selectedYT, selectedMB item) {
+	lb := cfg.ListenBrainz
+	if !lb.enabled() || selectedYT.id == "" {
+		return
+	}
+	if lb.SubmitListens {
+		if err := submitListen(newListen(job, selectedYT, selectedMB)); err != nil {
+			log.Printf("ListenBrainz: failed to submit listen for %s: %v", job.tags.Title, err)
+		} else {
+			log.Printf("ListenBrainz: submitted listen for %s", job.tags.Title)
+		}
+	}
+	if lb.Playlist != "" && job.tags.RecordingID != "" {
+		if err := addToListenBrainzPlaylist(lb.Playlist, job.tags.RecordingID); err != nil {
+			log.Printf("ListenBrainz: failed to add %s to playlist: %v", job.tags.Title, err)
+		}
+	}
+}
+
+func newListen(job convertJob, selectedYT, selectedMB item) listenBrainzListen {
+	var l listenBrainzListen
+	l.ListenedAt = time.Now().Unix()
+	l.TrackMetadata.ArtistName = job.tags.Artist
+	l.TrackMetadata.TrackName = job.tags.Title
+	l.TrackMetadata.ReleaseName = job.tags.Album
+	info := map[string]interface{}{"submission_client": listenBrainzClient, "media_player": listenBrainzClient}
+	for k, v := range map[string]string{
+		"recording_mbid": job.tags.RecordingID,
+		"release_mbid":   mbReleaseID(selectedMB.id),
+		"isrc":           job.tags.ISRC,
+		"origin_url":     selectedYT.url,
+	} {
+		if v != "" {
+			info[k] = v
+		}
+	}
+	if job.tags.DurationSec > 0 {
+		info["duration_ms"] = job.tags.DurationSec * 1000
+	}
+	l.TrackMetadata.AdditionalInfo = info
+	return l
+}
+
+func submitListen(l listenBrainzListen) error {
+	return postListenBrainz("/submit-listens", map[string]interface{}{
+		"listen_type": "single",
+		"payload":     []listenBrainzListen{l},
+	})
+}
+
+func addToListenBrainzPlaylist(playlist, recordingID string) error {
+	track := map[string]string{"identifier": "https://musicbrainz.org/recording/" + recordingID}
+	return postListenBrainz("/playlist/"+playlist+"/item/add", map[string]interface{}{
+		"playlist": map[string]interface{}{"track": []interface{}{track}},
+	})
+}
+
+func postListenBrainz(path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", listenBrainzAPI+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Token "+cfg.ListenBrainz.Token)
+	resp, err := doWithRetry("listenbrainz", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("listenbrainz returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
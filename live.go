@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- ライブ配信の録音 ---
+// 配信中・配信直後の動画は yt-dlp の --live-from-start で最初から取得し、ffmpeg で上限時間まで一時ファイルに録音する。
+// 録音した音声は通常の動画と同じように変換され、トラックリストの w (チャプター) / x (分割) もそのまま使える。
+const (
+	liveStatusLive     = "is_live"
+	liveStatusUpcoming = "is_upcoming"
+	liveStatusPostLive = "post_live" // 配信は終わったがアーカイブの処理中
+	liveStatusWasLive  = "was_live"
+	longStreamSec      = 3600
+)
+
+type liveConfig struct {
+	// 配信中の動画を配信の最初から録音する (false なら現在の位置から)
+	FromStart bool `json:"from_start"`
+	// 録音する長さの上限 (分)。0 なら配信が終わるまで録音する
+	MaxMinutes int `json:"max_minutes"`
+}
+
+func liveStatus(yt item) string {
+	info, _ := yt.meta.(ytDlpVideoInfo)
+	return info.LiveStatus
+}
+
+// isLiveStream reports whether the video can't be downloaded as a normal upload yet.
+func isLiveStream(yt item) bool {
+	switch liveStatus(yt) {
+	case liveStatusLive, liveStatusUpcoming, liveStatusPostLive:
+		return true
+	}
+	return false
+}
+
+func liveLimit() time.Duration {
+	return time.Duration(max(cfg.Live.MaxMinutes, 0)) * time.Minute
+}
+
+// liveArgs are the extra yt-dlp options for grabbing a stream that is (or was just) live.
+func liveArgs(yt item) []string {
+	if s := liveStatus(yt); cfg.Live.FromStart && (s == liveStatusLive || s == liveStatusPostLive) {
+		return []string{"--live-from-start"}
+	}
+	return nil
+}
+
+// downloadTimeout gives long uploads such as stream archives more time than a single song needs.
+func downloadTimeout(yt item) time.Duration {
+	if t := cfg.Retry.timeout("youtube"); t > 0 {
+		return t
+	}
+	return max(cmdTimeout*2, time.Duration(videoDuration(yt)/10)*time.Second)
+}
+
+func liveBadge(info ytDlpVideoInfo) string {
+	switch info.LiveStatus {
+	case liveStatusLive:
+		return lipgloss.NewStyle().Foreground(redColor).Bold(true).Render(tr("● 配信中"))
+	case liveStatusUpcoming:
+		return helpStyle.Render(tr("配信予定"))
+	case liveStatusPostLive, liveStatusWasLive:
+		return helpStyle.Render(tr("配信アーカイブ"))
+	}
+	return ""
+}
+
+// liveNote explains how a stream will be recorded, or "" for a normal upload.
+func liveNote(yt item) string {
+	limit := tr("上限なし")
+	if l := liveLimit(); l > 0 {
+		limit = tr("最大%d分", int(l.Minutes()))
+	}
+	from := tr("現在の位置から")
+	if len(liveArgs(yt)) > 0 {
+		from = tr("最初から")
+	}
+	switch liveStatus(yt) {
+	case liveStatusLive:
+		return tr("配信中です。%s録音します (%s)", from, limit)
+	case liveStatusPostLive:
+		return tr("配信が終わったばかりでアーカイブの処理中です。%s録音します (%s)", from, limit)
+	case liveStatusUpcoming:
+		return tr("配信がまだ始まっていないため、ダウンロードできません")
+	case liveStatusWasLive:
+		if d := videoDuration(yt); d >= longStreamSec {
+			return tr("長さ %s の配信アーカイブです。w でチャプター付きの1ファイルにできます", formatDuration(int(d)))
+		}
+	}
+	return ""
+}
+
+// recordLive captures the stream into path without re-encoding, stopping at live.max_minutes.
+func recordLive(ytDlpPath string, yt item, path string) error {
+	if liveStatus(yt) == liveStatusUpcoming {
+		return errorf("配信がまだ始まっていません。開始後にもう一度お試しください")
+	}
+	ffmpegPath, err := findFfmpeg()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	limit := liveLimit()
+	if limit > 0 {
+		// 現在の位置からの録音は実時間かかるので、上限に余裕を足して打ち切る
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limit+cmdTimeout*2)
+		defer cancel()
+	}
+	// HLSの配信には音声だけのフォーマットが無いことがあるので、映像付きから音声だけを取り出す
+	src := ytDlpCommand(ctx, ytDlpPath, append(liveArgs(yt), "--quiet", "--no-warnings", "-f", audioFormat(yt)+"/best", "-o", "-", yt.url)...)
+	args := []string{"-y", "-i", "pipe:0"}
+	if limit > 0 {
+		args = append(args, "-t", fmt.Sprint(int(limit.Seconds())))
+	}
+	conv := exec.Command(ffmpegPath, append(args, "-map", "0:a:0", "-c:a", "copy", "-f", "matroska", path)...)
+	if _, err := runStreamed(src, conv, limit > 0); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errorf("配信の録音がタイムアウトしました (%s)", limit+cmdTimeout*2)
+		}
+		return err
+	}
+	return nil
+}
+
+// liveWarning tells the user when a recording stopped at the time limit rather than at the end of
+// the stream.
+func liveWarning(ffmpegPath string, yt item, path string) string {
+	limit := liveLimit()
+	if s := liveStatus(yt); limit <= 0 || (s != liveStatusLive && s != liveStatusPostLive) {
+		return ""
+	}
+	actual, err := probeDuration(ffmpegPath, path)
+	if err != nil || actual < limit.Seconds()-5 {
+		return ""
+	}
+	return tr("⚠ 録音が上限の%d分に達したため、配信の途中で打ち切りました (live.max_minutes で変更できます)。\n録音の長さ: %s",
+		int(limit.Minutes()), formatDuration(int(math.Round(actual))))
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 歌詞 ---
+type LrclibResponse struct {
+	PlainLyrics  string `json:"plainLyrics"`
+	SyncedLyrics string `json:"syncedLyrics"`
+	Instrumental bool   `json:"instrumental"`
+}
+
+var lrcTimestamp = regexp.MustCompile(`^\[\d+:\d+(?:\.\d+)?\]`)
+
+type lyricsResult struct {
+	Plain        string // タイムスタンプ無しの歌詞
+	Synced       string // LRC形式の同期歌詞
+	Instrumental bool
+	Source       string // 取得元のプロバイダ名
+}
+
+func (r lyricsResult) found() bool { return r.Plain != "" || r.Synced != "" }
+
+const (
+	lyricsKindSynced       = "synced"
+	lyricsKindPlain        = "plain"
+	lyricsKindInstrumental = "instrumental"
+	lyricsKindNone         = "none"
+)
+
+// kind is what the history records about the lyrics, so plain-only tracks can be upgraded later.
+func (r lyricsResult) kind() string {
+	switch {
+	case r.Instrumental:
+		return lyricsKindInstrumental
+	case r.Synced != "":
+		return lyricsKindSynced
+	case r.Plain != "":
+		return lyricsKindPlain
+	}
+	return lyricsKindNone
+}
+
+func (r lyricsResult) status() string {
+	switch {
+	case r.Instrumental:
+		return tr("インストゥルメンタル (歌詞なし)")
+	case r.Synced != "":
+		return tr("あり・同期歌詞 (%s)", r.Source)
+	case r.Plain != "":
+		return tr("あり (%s)", r.Source)
+	}
+	return tr("見つかりませんでした")
+}
+
+// newLyricsResult sorts provider text into plain or synced depending on whether it has LRC timestamps.
+// Synced lyrics also get a plain version with the timestamps stripped.
+func newLyricsResult(text string) lyricsResult {
+	text = strings.TrimSpace(text)
+	if !hasLRCTimestamps(text) {
+		return lyricsResult{Plain: text}
+	}
+	return lyricsResult{Plain: stripLRCTimestamps(text), Synced: text}
+}
+
+func hasLRCTimestamps(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if lrcTimestamp.MatchString(strings.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+var lrcTag = regexp.MustCompile(`^\[[a-z]+:.*\]$`)
+
+func stripLRCTimestamps(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if lrcTag.MatchString(line) {
+			continue // [ar:...] などのLRCヘッダ
+		}
+		for lrcTimestamp.MatchString(line) {
+			line = strings.TrimSpace(lrcTimestamp.ReplaceAllString(line, ""))
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// tags lays out the lyric tags per lyrics.synced_tag. Plain lyrics always go to LYRICS and
+// UNSYNCEDLYRICS unless the synced ones are configured to take over LYRICS.
+func (r lyricsResult) tags() [][2]string {
+	if r.Instrumental || !r.found() {
+		return nil
+	}
+	var out [][2]string
+	embedSynced := r.Synced != "" && cfg.Lyrics.SyncedTag != "" && cfg.Lyrics.LRCSidecar != lrcSidecarOnly
+	if embedSynced && strings.EqualFold(cfg.Lyrics.SyncedTag, "LYRICS") {
+		out = append(out, [2]string{"LYRICS", r.Synced})
+	} else if r.Plain != "" {
+		out = append(out, [2]string{"LYRICS", r.Plain})
+	}
+	if r.Plain != "" {
+		out = append(out, [2]string{"UNSYNCEDLYRICS", r.Plain})
+	}
+	if embedSynced && !strings.EqualFold(cfg.Lyrics.SyncedTag, "LYRICS") {
+		out = append(out, [2]string{strings.ToUpper(cfg.Lyrics.SyncedTag), r.Synced})
+	}
+	return out
+}
+
+const (
+	lrcSidecarOff  = "off"
+	lrcSidecarAlso = "also"
+	lrcSidecarOnly = "only"
+)
+
+// writeLRCSidecar saves synced lyrics as "<same name>.lrc" next to the audio file, which is where
+// players that ignore embedded lyrics look for them.
+func writeLRCSidecar(audioPath, text string) error {
+	lrcPath := strings.TrimSuffix(audioPath, filepath.Ext(audioPath)) + ".lrc"
+	return os.WriteFile(lrcPath, []byte(strings.TrimSpace(text)+"\n"), 0o644)
+}
+
+type lyricsFetchedMsg struct {
+	key    string
+	result lyricsResult
+}
+
+// lyricsKey identifies a lookup so a prefetched result is only reused for the same search terms.
+func lyricsKey(tags finalTags) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d", tags.Artist, tags.Title, tags.Album, tags.DurationSec)
+}
+
+func fetchLyricsCmd(tags finalTags) tea.Cmd {
+	return func() tea.Msg {
+		return lyricsFetchedMsg{key: lyricsKey(tags), result: getLyrics(tags.Artist, tags.Title, tags.Album, tags.DurationSec)}
+	}
+}
+
+type lyricsQuery struct {
+	Artist, Title, Album string
+	Duration             int
+}
+
+// lyricsProvider is one lyrics backend. A provider returns an empty result (not an error) when it simply
+// has no lyrics for the track, so the chain can move on to the next one.
+type lyricsProvider interface {
+	name() string
+	enabled() bool
+	fetch(q lyricsQuery) (lyricsResult, error)
+}
+
+var lyricsProviders = map[string]lyricsProvider{
+	"lrclib":     lrclibProvider{},
+	"genius":     geniusProvider{},
+	"musixmatch": musixmatchProvider{},
+	"netease":    neteaseProvider{},
+}
+
+// getLyrics tries the configured providers in order and returns the first hit. An instrumental flag
+// also counts as a hit so later providers don't attach lyrics to an instrumental track.
+func getLyrics(artist, title, album string, duration int) lyricsResult {
+	q := lyricsQuery{Artist: artist, Title: title, Album: album, Duration: duration}
+	for _, name := range cfg.Lyrics.Providers {
+		p, ok := lyricsProviders[name]
+		if !ok {
+			log.Printf("Lyrics: unknown provider %q in config", name)
+			continue
+		}
+		if !p.enabled() {
+			continue
+		}
+		res, err := p.fetch(q)
+		if err != nil {
+			log.Printf("Lyrics: %s failed: %v", name, err)
+			continue
+		}
+		if res.Instrumental || res.found() {
+			res.Source = p.name()
+			return res
+		}
+		log.Printf("Lyrics: %s had no lyrics for %s - %s", name, artist, title)
+	}
+	return lyricsResult{}
+}
+
+type lrclibProvider struct{}
+
+func (lrclibProvider) name() string  { return "lrclib" }
+func (lrclibProvider) enabled() bool { return true }
+
+func (lrclibProvider) fetch(lq lyricsQuery) (lyricsResult, error) {
+	apiURL := "https://lrclib.net/api/get"
+	req, err := newGetRequest(apiURL)
+	if err != nil {
+		return lyricsResult{}, err
+	}
+	q := req.URL.Query()
+	q.Add("track_name", lq.Title)
+	q.Add("artist_name", lq.Artist)
+	q.Add("album_name", lq.Album)
+	q.Add("duration", fmt.Sprintf("%d", lq.Duration))
+	req.URL.RawQuery = q.Encode()
+
+	log.Printf("Lyrics: Calling API: %s", req.URL.String())
+
+	resp, err := doWithRetry("lrclib", req)
+	if err != nil {
+		return lyricsResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return lyricsResult{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return lyricsResult{}, fmt.Errorf("API returned non-200 status: %s", resp.Status)
+	}
+
+	var data LrclibResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return lyricsResult{}, fmt.Errorf("failed to decode JSON response: %w", err)
+	}
+	if data.Instrumental || isInstrumentalPlaceholder(data.PlainLyrics) || isInstrumentalPlaceholder(data.SyncedLyrics) {
+		log.Printf("Lyrics: Track flagged as instrumental")
+		return lyricsResult{Instrumental: true}, nil
+	}
+	res := lyricsResult{Plain: strings.TrimSpace(data.PlainLyrics), Synced: strings.TrimSpace(data.SyncedLyrics)}
+	if res.Plain == "" && res.Synced != "" {
+		res.Plain = stripLRCTimestamps(res.Synced)
+	}
+	return res, nil
+}
+
+// isInstrumentalPlaceholder catches entries that carry an "[Instrumental]" marker instead of the flag.
+func isInstrumentalPlaceholder(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(lrcTimestamp.ReplaceAllString(line, ""))
+		if line == "" {
+			continue
+		}
+		if !strings.EqualFold(strings.Trim(line, "[]()♪ "), "instrumental") {
+			return false
+		}
+	}
+	return strings.TrimSpace(text) != ""
+}
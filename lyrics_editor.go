@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- 歌詞のプレビュー・編集 ---
+
+// openLyricsEditor loads the pending lyrics into the editor. Synced lyrics are edited as LRC so the
+// timestamps can be fixed too; the plain version is derived from them on save.
+func (m *model) openLyricsEditor() tea.Cmd {
+	ta := textarea.New()
+	ta.ShowLineNumbers = true
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.SetWidth(m.width - 8)
+	ta.SetHeight(max(m.height-12, 5))
+	if l := m.pendingTags.Lyrics; l != nil {
+		text := l.Plain
+		if l.Synced != "" {
+			text = l.Synced
+		}
+		ta.SetValue(text)
+	}
+	ta.CursorStart()
+	m.lyricsArea, m.state = ta, stateLyrics
+	return m.lyricsArea.Focus()
+}
+
+// saveLyricsEdit stores the edited text; an emptied editor means "embed no lyrics".
+func (m *model) saveLyricsEdit() {
+	edited := newLyricsResult(m.lyricsArea.Value())
+	if m.pendingTags.Lyrics != nil {
+		edited.Source = m.pendingTags.Lyrics.Source
+	}
+	m.pendingTags.Lyrics = &edited
+}
+
+func (m model) lyricsEditorView() string {
+	var b strings.Builder
+	title := tr("歌詞の確認・編集 — %s / %s", m.pendingTags.Artist, m.pendingTags.Title)
+	b.WriteString("\n" + listTitleStyle.Render(title) + "\n")
+	if l := m.pendingTags.Lyrics; l != nil && l.Source != "" {
+		b.WriteString(helpStyle.Render(tr("  取得元: %s", l.Source)))
+	}
+	if hasLRCTimestamps(m.lyricsArea.Value()) {
+		b.WriteString(helpStyle.Render(tr("  (同期歌詞: タイムスタンプ付き)")))
+	}
+	b.WriteString("\n\n" + lipgloss.NewStyle().PaddingLeft(2).Render(m.lyricsArea.View()) + "\n")
+	return b.String()
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// --- 歌詞プロバイダ (Genius / Musixmatch / NetEase) ---
+const lyricsMinTitleSim = 0.6
+
+func getJSON(provider, apiURL string, header http.Header, v interface{}) error {
+	req, err := newGetRequest(apiURL)
+	if err != nil {
+		return err
+	}
+	for k, vals := range header {
+		req.Header[k] = vals
+	}
+	resp, err := doWithRetry(provider, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// lyricsCandidateOK guards against the fuzzy search endpoints returning a different song.
+func lyricsCandidateOK(q lyricsQuery, title, artist string, durationSec int) bool {
+	titleSim := max(similarity(title, q.Title), containmentScore(title, q.Title))
+	artistSim := max(similarity(artist, q.Artist), containmentScore(artist, q.Artist), containmentScore(q.Artist, artist))
+	if titleSim < lyricsMinTitleSim || artistSim < 0.5 {
+		return false
+	}
+	if q.Duration > 0 && durationSec > 0 && abs(q.Duration-durationSec) > 10 {
+		return false
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// --- Genius ---
+type geniusProvider struct{}
+
+func (geniusProvider) name() string  { return "genius" }
+func (geniusProvider) enabled() bool { return cfg.Lyrics.GeniusToken != "" }
+
+var (
+	geniusContainerRe = regexp.MustCompile(`(?s)<div[^>]*data-lyrics-container="true"[^>]*>(.*?)</div>`)
+	htmlBreakRe       = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlTagRe         = regexp.MustCompile(`<[^>]+>`)
+)
+
+// fetch searches the Genius API for the song, then scrapes the lyrics from the song page because
+// the API itself doesn't serve lyrics.
+func (geniusProvider) fetch(q lyricsQuery) (lyricsResult, error) {
+	var search struct {
+		Response struct {
+			Hits []struct {
+				Result struct {
+					Title         string `json:"title"`
+					URL           string `json:"url"`
+					PrimaryArtist struct {
+						Name string `json:"name"`
+					} `json:"primary_artist"`
+				} `json:"result"`
+			} `json:"hits"`
+		} `json:"response"`
+	}
+	apiURL := "https://api.genius.com/search?q=" + url.QueryEscape(q.Artist+" "+q.Title)
+	header := http.Header{"Authorization": {"Bearer " + cfg.Lyrics.GeniusToken}}
+	if err := getJSON("genius", apiURL, header, &search); err != nil {
+		return lyricsResult{}, err
+	}
+	for _, hit := range search.Response.Hits {
+		r := hit.Result
+		if !lyricsCandidateOK(q, r.Title, r.PrimaryArtist.Name, 0) {
+			continue
+		}
+		text, err := scrapeGeniusLyrics(r.URL)
+		if err != nil {
+			return lyricsResult{}, err
+		}
+		return newLyricsResult(text), nil
+	}
+	return lyricsResult{}, nil
+}
+
+func scrapeGeniusLyrics(pageURL string) (string, error) {
+	req, err := newGetRequest(pageURL)
+	if err != nil {
+		return "", err
+	}
+	resp, err := doWithRetry("genius", req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("genius page returned %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var parts []string
+	for _, m := range geniusContainerRe.FindAllStringSubmatch(string(body), -1) {
+		text := htmlBreakRe.ReplaceAllString(m[1], "\n")
+		parts = append(parts, html.UnescapeString(htmlTagRe.ReplaceAllString(text, "")))
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n")), nil
+}
+
+// --- Musixmatch ---
+type musixmatchProvider struct{}
+
+func (musixmatchProvider) name() string  { return "musixmatch" }
+func (musixmatchProvider) enabled() bool { return cfg.Lyrics.MusixmatchKey != "" }
+
+// musixmatchDisclaimer is appended by the free API tier, which only returns part of the lyrics.
+const musixmatchDisclaimer = "******* This Lyrics is NOT for Commercial use *******"
+
+func (musixmatchProvider) fetch(q lyricsQuery) (lyricsResult, error) {
+	var data struct {
+		Message struct {
+			Header struct {
+				StatusCode int `json:"status_code"`
+			} `json:"header"`
+			Body json.RawMessage `json:"body"`
+		} `json:"message"`
+	}
+	v := url.Values{}
+	v.Set("q_track", q.Title)
+	v.Set("q_artist", q.Artist)
+	v.Set("apikey", cfg.Lyrics.MusixmatchKey)
+	if err := getJSON("musixmatch", "https://api.musixmatch.com/ws/1.1/matcher.lyrics.get?"+v.Encode(), nil, &data); err != nil {
+		return lyricsResult{}, err
+	}
+	switch data.Message.Header.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return lyricsResult{}, nil
+	default:
+		return lyricsResult{}, fmt.Errorf("musixmatch status %d", data.Message.Header.StatusCode)
+	}
+	var body struct {
+		Lyrics struct {
+			Body         string `json:"lyrics_body"`
+			Instrumental int    `json:"instrumental"`
+		} `json:"lyrics"`
+	}
+	if err := json.Unmarshal(data.Message.Body, &body); err != nil {
+		return lyricsResult{}, err
+	}
+	if body.Lyrics.Instrumental == 1 {
+		return lyricsResult{Instrumental: true}, nil
+	}
+	text, _, _ := strings.Cut(body.Lyrics.Body, musixmatchDisclaimer)
+	return newLyricsResult(text), nil
+}
+
+// --- NetEase Cloud Music ---
+type neteaseProvider struct{}
+
+func (neteaseProvider) name() string  { return "netease" }
+func (neteaseProvider) enabled() bool { return true }
+
+// fetch uses NetEase's public web API, which returns LRC with timestamps for most Asian releases.
+func (neteaseProvider) fetch(q lyricsQuery) (lyricsResult, error) {
+	var search struct {
+		Result struct {
+			Songs []struct {
+				ID       int64  `json:"id"`
+				Name     string `json:"name"`
+				Duration int    `json:"duration"`
+				Artists  []struct {
+					Name string `json:"name"`
+				} `json:"artists"`
+			} `json:"songs"`
+		} `json:"result"`
+	}
+	v := url.Values{}
+	v.Set("s", q.Artist+" "+q.Title)
+	v.Set("type", "1")
+	v.Set("limit", "10")
+	header := http.Header{"Referer": {"https://music.163.com/"}}
+	if err := getJSON("netease", "https://music.163.com/api/search/get/web?"+v.Encode(), header, &search); err != nil {
+		return lyricsResult{}, err
+	}
+	for _, song := range search.Result.Songs {
+		var artists []string
+		for _, a := range song.Artists {
+			artists = append(artists, a.Name)
+		}
+		if !lyricsCandidateOK(q, song.Name, strings.Join(artists, " "), song.Duration/1000) {
+			continue
+		}
+		var lyric struct {
+			Nolyric bool `json:"nolyric"`
+			Lrc     struct {
+				Lyric string `json:"lyric"`
+			} `json:"lrc"`
+		}
+		if err := getJSON("netease", fmt.Sprintf("https://music.163.com/api/song/lyric?id=%d&lv=1", song.ID), header, &lyric); err != nil {
+			return lyricsResult{}, err
+		}
+		if lyric.Nolyric || isInstrumentalPlaceholder(lyric.Lrc.Lyric) {
+			return lyricsResult{Instrumental: true}, nil
+		}
+		return newLyricsResult(lyric.Lrc.Lyric), nil
+	}
+	return lyricsResult{}, nil
+}
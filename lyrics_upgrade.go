@@ -0,0 +1,221 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- 同期歌詞への差し替え (upgrade-lyrics) ---
+// ダウンロード時には通常の歌詞しか見つからなかった曲を lrclib で探し直し、同期歌詞が登録されていれば
+// FLACのタグ (と .lrc) に書き足す。確認した日時は履歴に残し、-interval の間は同じ曲を問い合わせない。
+
+// lyricsUpgradeCandidate is a library file whose embedded lyrics are plain-only.
+type lyricsUpgradeCandidate struct {
+	entry historyEntry
+	tags  *flacTags
+}
+
+func runUpgradeLyrics(args []string) error {
+	fs := flag.NewFlagSet("upgrade-lyrics", flag.ContinueOnError)
+	interval := fs.Duration("interval", 7*24*time.Hour, tr("同じ曲をlrclibに問い合わせ直すまでの間隔"))
+	dryRun := fs.Bool("dry-run", false, tr("書き込まずに対象と結果だけを表示する"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	entries, err := loadHistory()
+	if err != nil {
+		return errorf("履歴の読み込みに失敗: %w", err)
+	}
+	candidates, known := findLyricsUpgrades(entries, time.Now().Add(-*interval))
+	if len(candidates) == 0 {
+		fmt.Println(tr("同期歌詞を探し直す曲はありません。"))
+		return recordLyricsChecks(known, nil)
+	}
+
+	now := time.Now()
+	checked := map[string]string{}
+	upgraded, failed := 0, 0
+	for _, c := range candidates {
+		label := fmt.Sprintf("%s - %s", c.entry.Artist, c.entry.Title)
+		res, err := lrclibProvider{}.fetch(c.query())
+		if err != nil {
+			fmt.Print(tr("%s: 失敗 (%v)\n", label, err))
+			failed++
+			continue
+		}
+		if res.Synced == "" {
+			fmt.Print(tr("%s: 同期歌詞はまだありません\n", label))
+			checked[c.entry.Path] = lyricsKindPlain
+			continue
+		}
+		if *dryRun {
+			fmt.Print(tr("%s: 同期歌詞が見つかりました (-dry-run のため書き込みません)\n", label))
+			continue
+		}
+		if err := upgradeFLACLyrics(c.entry.Path, c.tags, res.Synced); err != nil {
+			fmt.Print(tr("%s: 書き込みに失敗 (%v)\n", label, err))
+			failed++
+			continue
+		}
+		fmt.Print(tr("%s: 同期歌詞を埋め込みました\n", label))
+		checked[c.entry.Path] = lyricsKindSynced
+		upgraded++
+	}
+	fmt.Print(tr("完了: 同期歌詞に更新 %d曲 / 未登録 %d曲 / 失敗 %d曲\n", upgraded, len(checked)-upgraded, failed))
+	if *dryRun {
+		return nil
+	}
+	if err := recordLyricsChecks(checked, &now); err != nil {
+		return err
+	}
+	for path := range checked {
+		delete(known, path)
+	}
+	return recordLyricsChecks(known, nil)
+}
+
+// findLyricsUpgrades returns the plain-only FLAC files last checked before cutoff. Entries recorded
+// before the history tracked lyrics are classified from the file's tags; those are returned in known
+// so the history can be filled in.
+func findLyricsUpgrades(entries []historyEntry, cutoff time.Time) ([]lyricsUpgradeCandidate, map[string]string) {
+	seen := map[string]bool{}
+	known := map[string]string{}
+	var out []lyricsUpgradeCandidate
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.status() != statusOK || seen[e.Path] || !strings.EqualFold(filepath.Ext(e.Path), ".flac") {
+			continue
+		}
+		seen[e.Path] = true
+		if e.Lyrics != "" && e.Lyrics != lyricsKindPlain {
+			continue
+		}
+		if e.LyricsChecked != nil && e.LyricsChecked.After(cutoff) {
+			continue
+		}
+		if _, err := os.Stat(e.Path); err != nil {
+			continue
+		}
+		tags, err := readFLACTags(e.Path)
+		if err != nil {
+			log.Printf("Lyrics: skipping %s: %v", e.Path, err)
+			continue
+		}
+		kind := embeddedLyricsKind(tags)
+		if e.Lyrics == "" {
+			known[e.Path] = kind
+		}
+		if kind == lyricsKindPlain {
+			out = append(out, lyricsUpgradeCandidate{entry: e, tags: tags})
+		}
+	}
+	return out, known
+}
+
+func (t *flacTags) get(key string) string {
+	for _, c := range t.comments {
+		if k, v, ok := strings.Cut(c, "="); ok && strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// embeddedLyricsKind classifies the lyrics already in the file.
+func embeddedLyricsKind(t *flacTags) string {
+	switch {
+	case t.get("INSTRUMENTAL") == "1":
+		return lyricsKindInstrumental
+	case cfg.Lyrics.SyncedTag != "" && hasLRCTimestamps(t.get(cfg.Lyrics.SyncedTag)),
+		hasLRCTimestamps(t.get("LYRICS")), hasLRCTimestamps(t.get("SYNCEDLYRICS")):
+		return lyricsKindSynced
+	case t.get("LYRICS") != "" || t.get("UNSYNCEDLYRICS") != "":
+		return lyricsKindPlain
+	}
+	return lyricsKindNone
+}
+
+// query looks the track up by its current tags, falling back to the history for missing fields.
+func (c lyricsUpgradeCandidate) query() lyricsQuery {
+	q := lyricsQuery{Title: c.tags.get("TITLE"), Artist: c.tags.get("ARTIST"), Album: c.tags.get("ALBUM")}
+	if q.Title == "" {
+		q.Title = c.entry.Title
+	}
+	if q.Artist == "" {
+		q.Artist = c.entry.Artist
+	}
+	if q.Album == "" {
+		q.Album = c.entry.Album
+	}
+	if f, err := os.Open(c.entry.Path); err == nil {
+		blocks, _, err := readFLACMetadata(f)
+		f.Close()
+		if err == nil {
+			if rate, samples, err := streamInfo(blocks[0].data); err == nil && rate > 0 {
+				q.Duration = int(samples / uint64(rate))
+			}
+		}
+	}
+	return q
+}
+
+// upgradeFLACLyrics adds the synced lyrics to the file the same way a fresh download would embed
+// them, keeping the plain lyrics that are already there.
+func upgradeFLACLyrics(path string, tags *flacTags, synced string) error {
+	plain := tags.get("UNSYNCEDLYRICS")
+	if plain == "" {
+		plain = stripLRCTimestamps(tags.get("LYRICS"))
+	}
+	res := lyricsResult{Plain: plain, Synced: synced}
+	replace := map[string]bool{}
+	fresh := res.tags()
+	for _, kv := range fresh {
+		replace[kv[0]] = true
+	}
+	var kept []string
+	for _, c := range tags.comments {
+		key, _, _ := strings.Cut(c, "=")
+		if !replace[strings.ToUpper(key)] {
+			kept = append(kept, c)
+		}
+	}
+	for _, kv := range fresh {
+		kept = append(kept, kv[0]+"="+kv[1])
+	}
+	tags.comments = kept
+	if err := writeFLACTags(path, tags); err != nil {
+		return err
+	}
+	if cfg.Lyrics.LRCSidecar != lrcSidecarOff {
+		return writeLRCSidecar(path, synced)
+	}
+	return nil
+}
+
+// recordLyricsChecks stores the lyrics kind (and the check time, if given) on every history entry for
+// each path.
+func recordLyricsChecks(kinds map[string]string, checked *time.Time) error {
+	if len(kinds) == 0 {
+		return nil
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	for i, e := range entries {
+		if kind, ok := kinds[e.Path]; ok {
+			entries[i].Lyrics = kind
+			if checked != nil {
+				entries[i].LyricsChecked = checked
+			}
+		}
+	}
+	return saveHistory(entries)
+}
@@ -4,20 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
 	"io"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -33,30 +36,23 @@ const (
 )
 
 var (
-	// Colors (Dracula-like theme)
-	fgColor       = lipgloss.Color("#f8f8f2")
-	commentColor  = lipgloss.Color("#6272a4")
-	cyanColor     = lipgloss.Color("#8be9fd")
-	greenColor    = lipgloss.Color("#50fa7b")
-	pinkColor     = lipgloss.Color("#ff79c6")
-	purpleColor   = lipgloss.Color("#bd93f9")
-	redColor      = lipgloss.Color("#ff5555")
+	// Colors (set from the theme, Dracula-like by default; see theme.go)
+	fgColor      lipgloss.Color
+	commentColor lipgloss.Color
+	cyanColor    lipgloss.Color
+	greenColor   lipgloss.Color
+	pinkColor    lipgloss.Color
+	purpleColor  lipgloss.Color
+	redColor     lipgloss.Color
+	yellowColor  lipgloss.Color
+	// Text on the header and list title backgrounds
+	titleColor lipgloss.Color
 
 	appStyle = lipgloss.NewStyle().Margin(1, 2)
 
-	headerStyle = lipgloss.NewStyle().
-			Foreground(fgColor).
-			Background(purpleColor).
-			Padding(0, 1).
-			Bold(true)
-
-	helpStyle = lipgloss.NewStyle().Foreground(commentColor)
-
-	// List Styles
-	listTitleStyle = lipgloss.NewStyle().
-			Background(pinkColor).
-			Foreground(fgColor).
-			Padding(0, 1)
+	headerStyle    lipgloss.Style
+	helpStyle      lipgloss.Style
+	listTitleStyle lipgloss.Style
 
 	paginationStyle = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
 )
@@ -81,6 +77,88 @@ type model struct {
 	width         int
 	height        int
 	lastFile      string
+	lastWarning   string
+	showHelp      bool
+	batch         []queueItem
+	batchIndex    int
+	batchLog      []string
+	batchRunning  bool
+	queueCursor   int
+	queueFolded   map[string]bool
+	queueBack     state
+	matchBack     state // 自動照合を始めた画面 (十分な候補が無ければここに戻る)
+	matchNote     string
+	libraryBytes  int64
+	historyRows   []historyRow
+	historyList   list.Model
+	historyPrune  bool
+	historyInput  textinput.Model
+	historyQuery  string
+	historyTyping bool
+	pendingTags   finalTags
+	coverPreview  image.Image
+	previewFor    string
+	previewNote   string
+	lyricsInfo    lyricsResult
+	lyricsKey     string
+	lyricsBusy    bool
+	lyricsNote    string
+	lastfm        lastfmInfo
+	lastfmKey     string
+	lastfmApplied bool
+	diag          *diagReport
+	replInputs    []textinput.Model
+	replFocus     int
+	replRegex     bool
+	replPlan      []tagChange
+	replNote      string
+	mbCache       map[string][]list.Item
+	prefetchFor   string
+	prefetchSeq   int
+	prefetchBusy  string
+	prefetchLast  time.Time
+	mbWaiting     string
+	lyricsArea    textarea.Model
+	trimEdit      trimOffsets
+	trimFocus     int
+	trimNote      string
+	trimBusy      bool
+	trimStream    string
+	trimStreamFor string
+	review        []reviewEntry
+	reviewList    list.Model
+	ytmList       list.Model
+	newReleases   []newRelease
+	releaseList   list.Model
+	subsQueued    int
+	unfocused     bool
+	cookieRetry   tea.Cmd
+	cookieState   state
+	cookieErr     error
+	cookieCursor  int
+	noteInput     textinput.Model
+	noteEditing   bool
+	searchStart   time.Time
+	searchTook    time.Duration
+	timeline      timeline
+	libEntries    []historyEntry
+	libGroups     []libraryGroup
+	libGroupBy    string
+	libCursor     int
+	libOpen       map[string]bool
+	tagFile       string
+	importQueue   []string
+	importTotal   int
+	importLog     []string
+	libIndex      *libraryIndex
+	lastRetry     retryMsg
+	crashed       []crashedDownload
+	ytMore        morePaging
+	mbMore        morePaging
+	moreBusy      bool
+	mbFilter      mbReleaseFilter
+	pickRecording string
+	groupParent   *list.Model // リリースグループの版を表示中のときの検索結果
 }
 
 type state int
@@ -94,15 +172,30 @@ const (
 	stateSelectMB
 	stateSelectTrack
 	stateEditTags
+	stateLyrics
+	stateCompare
 	stateDownloading
 	stateShowSuccess
 	stateConfirmSkipMB
+	stateHistory
+	stateReplace
+	stateQueue
+	stateDiagnostics
+	stateTrim
+	stateReview
+	stateCookies
+	stateLibrary
+	stateYTMusic
+	stateNewReleases
+	stateRecover
 	stateError
 )
 
 type item struct {
 	title, desc, id, url, artist, itemType string
-	meta                                 interface{}
+	meta                                   interface{}
+	marked                                 bool
+	badge                                  string // タイトルの後ろに表示する (スタイル適用済み)
 }
 
 func (i item) Title() string       { return i.title }
@@ -110,48 +203,123 @@ func (i item) Description() string { return i.desc }
 func (i item) FilterValue() string { return i.title + " " + i.desc }
 
 type finalTags struct {
-	Title, Artist, Album, Date, TrackNumber, AlbumArtist, Lyrics string
-	DurationSec                                                int
+	Title, Artist, Album, Date, TrackNumber, AlbumArtist string
+	ISRC, Label, CatalogNumber, RecordingID              string
+	OriginalDate                                         string
+	Genre                                                string
+	DiscNumber, DiscTotal, TrackTotal                    int
+	DurationSec                                          int
+	Lyrics                                               *lyricsResult // 取得済みの歌詞 (nil なら変換時に取得)
+	Trim                                                 trimOffsets
+	Romanize                                             bool   // ローマ字化する (romanize.enabled が既定。比較画面の r で切替)
+	ArtistSort                                           string // MusicBrainz の別名・ソート名から作ったアーティストの読み
 }
 
 // --- メッセージ ---
 type (
-	ytDlpCheckResultMsg  struct{ path string; err error }
-	ffmpegCheckResultMsg struct{ path string; err error }
-	urlInfoFetchedMsg    struct{ ytItem item; err error }
-	searchFinishedMsg    struct{ ytItems, mbItems []list.Item; err error }
-	mbSearchFinishedMsg  struct{ items []list.Item; err error }
-	tracklistFinishedMsg struct{ items []list.Item; err error }
-	downloadFinishedMsg  struct{ filename string; err error }
-	resetMsg             struct{}
+	ytDlpCheckResultMsg struct {
+		path string
+		err  error
+	}
+	ffmpegCheckResultMsg struct {
+		path string
+		err  error
+	}
+	urlInfoFetchedMsg struct {
+		ytItem item
+		err    error
+	}
+	searchFinishedMsg struct {
+		query            string
+		ytItems, mbItems []list.Item
+		err              error
+	}
+	mbSearchFinishedMsg struct {
+		query string
+		items []list.Item
+		err   error
+	}
+	ytSearchFinishedMsg struct {
+		query string
+		items []list.Item
+		err   error
+	}
+	tracklistFinishedMsg struct {
+		items   []list.Item
+		release MBRelease
+		err     error
+	}
+	downloadFinishedMsg struct {
+		filename, warning string
+		files             []string
+		timeline          timeline
+		err               error
+	}
+	resetMsg struct{}
 )
 
 // --- JSON構造体 ---
 type ytDlpVideoInfo struct {
-	ID       string `json:"id"`
-	Title    string `json:"title"`
-	Uploader string `json:"uploader"`
-	Channel  string `json:"channel"`
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Uploader    string      `json:"uploader"`
+	Channel     string      `json:"channel"`
+	Duration    float64     `json:"duration"`
+	Categories  []string    `json:"categories"`
+	ViewCount   int64       `json:"view_count"`
+	UploadDate  string      `json:"upload_date"` // YYYYMMDD
+	Chapters    []ytChapter `json:"chapters"`
+	Formats     []ytFormat  `json:"formats"`
+	LiveStatus  string      `json:"live_status"` // is_live / is_upcoming / post_live / was_live / not_live
+	Description string      `json:"description"`
+	// 自動生成の動画 (「 - Topic」チャンネル) で yt-dlp が概要欄から読み取った曲の情報
+	Track       string   `json:"track"`
+	Artist      string   `json:"artist"`
+	Artists     []string `json:"artists"`
+	Album       string   `json:"album"`
+	ReleaseDate string   `json:"release_date"` // YYYYMMDD
+
+	audioLang string // 選択された音声トラック (空ならデフォルト)
 }
 
 type (
-	MusicBrainzSearchResponse struct{ Releases []MBRelease `json:"releases"` }
+	MusicBrainzSearchResponse struct {
+		Releases []MBRelease `json:"releases"`
+	}
 	MBRelease struct {
-		ID           string         `json:"id"`
-		Title        string         `json:"title"`
-		ArtistCredit []MBArtist     `json:"artist-credit"`
-		Date         string         `json:"date"`
-		Media        []MBMedia      `json:"media"`
-		ReleaseGroup MBReleaseGroup `json:"release-group"`
-	}
-	MBReleaseGroup struct{ ID, PrimaryType string `json:"id", "primary-type"` }
+		ID             string         `json:"id"`
+		Title          string         `json:"title"`
+		ArtistCredit   []MBArtist     `json:"artist-credit"`
+		Date           string         `json:"date"`
+		Media          []MBMedia      `json:"media"`
+		ReleaseGroup   MBReleaseGroup `json:"release-group"`
+		LabelInfo      []MBLabelInfo  `json:"label-info"`
+		Country        string         `json:"country"`
+		Status         string         `json:"status"`
+		Disambiguation string         `json:"disambiguation"`
+	}
+	MBLabelInfo struct {
+		CatalogNumber string  `json:"catalog-number"`
+		Label         MBLabel `json:"label"`
+	}
+	MBLabel struct {
+		Name string `json:"name"`
+	}
+	MBReleaseGroup struct {
+		ID               string `json:"id"`
+		PrimaryType      string `json:"primary-type"`
+		FirstReleaseDate string `json:"first-release-date"`
+	}
 	MBArtist struct {
-		Name       string `json:"name"`
-		JoinPhrase string `json:"joinphrase"`
+		Name       string       `json:"name"`
+		JoinPhrase string       `json:"joinphrase"`
+		Artist     MBArtistInfo `json:"artist"`
 	}
 	MBMedia struct {
-		Format string    `json:"format"`
-		Tracks []MBTrack `json:"tracks"`
+		Format     string    `json:"format"`
+		Position   int       `json:"position"`
+		TrackCount int       `json:"track-count"`
+		Tracks     []MBTrack `json:"tracks"`
 	}
 	MBTrack struct {
 		ID        string      `json:"id"`
@@ -160,19 +328,21 @@ type (
 		Length    int         `json:"length"` // in milliseconds
 		Recording MBRecording `json:"recording"`
 	}
-	MBRecording struct{ Genres []MBGenre `json:"genres"` }
-	MBGenre     struct{ Name string `json:"name"` }
+	MBRecording struct {
+		ID     string    `json:"id"`
+		Genres []MBGenre `json:"genres"`
+		ISRCs  []string  `json:"isrcs"`
+	}
+	MBGenre struct {
+		Name string `json:"name"`
+	}
 )
 
-type LrclibResponse struct {
-	PlainLyrics string `json:"syncedLyrics"`
-}
-
 // --- Custom Delegate for List ---
 type itemDelegate struct{}
 
-func (d itemDelegate) Height() int                             { return 2 }
-func (d itemDelegate) Spacing() int                            { return 1 }
+func (d itemDelegate) Height() int                               { return 2 }
+func (d itemDelegate) Spacing() int                              { return 1 }
 func (d itemDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
 func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
 	i, ok := listItem.(item)
@@ -184,44 +354,67 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	normalTitleStyle := lipgloss.NewStyle().PaddingLeft(2).Foreground(fgColor)
 	normalDescStyle := lipgloss.NewStyle().PaddingLeft(2).Foreground(commentColor)
 
+	mark, badge := "", ""
+	if i.marked {
+		mark = "✓ "
+	}
+	if i.badge != "" {
+		badge = " " + i.badge
+	}
+	desc := i.desc
+	if info, ok := i.meta.(ytDlpVideoInfo); ok {
+		if details := videoDetails(info); details != "" {
+			desc += "  ·  " + details
+		}
+	}
 	if index == m.Index() {
-		title := selectedTitleStyle.Render("▶ " + i.title)
-		desc := selectedDescStyle.Render("  " + i.desc)
+		title := selectedTitleStyle.Render("▶ "+mark+i.title) + badge
+		desc := selectedDescStyle.Render("  " + desc)
 		fmt.Fprint(w, lipgloss.JoinVertical(lipgloss.Left, title, desc))
 	} else {
-		title := normalTitleStyle.Render("  " + i.title)
-		desc := normalDescStyle.Render("  " + i.desc)
+		title := normalTitleStyle.Render("  "+mark+i.title) + badge
+		desc := normalDescStyle.Render("  " + desc)
 		fmt.Fprint(w, lipgloss.JoinVertical(lipgloss.Left, title, desc))
 	}
 }
 
 func newModel() model {
 	ti := textinput.New()
-	ti.Placeholder = "アーティスト名と曲名、またはYouTubeのURLを入力してください..."
+	ti.Placeholder = tr("アーティスト名と曲名、またはYouTubeのURLを入力してください...")
 	ti.Focus()
 	ti.Width = 60
 	s := spinner.New()
 	s.Spinner = spinner.Pulse
 	s.Style = lipgloss.NewStyle().Foreground(pinkColor)
 	return model{
-		state:     stateCheckingDeps,
-		statusMsg: "依存関係を確認中...",
-		input:     ti,
-		spinner:   s,
-		ytResults: newList("", nil),
-		mbResults: newList("", nil),
-		tracklist: newList("", nil),
+		state:        stateCheckingDeps,
+		statusMsg:    tr("依存関係を確認中..."),
+		input:        ti,
+		spinner:      s,
+		ytResults:    newList("", nil),
+		mbResults:    newList("", nil),
+		tracklist:    newList("", nil),
+		historyList:  newList("", nil),
+		historyInput: newHistoryFilterInput(),
+		noteInput:    newNoteInput(),
+		queueFolded:  map[string]bool{},
+		mbCache:      map[string][]list.Item{},
+		mbFilter:     cfg.Search.MusicBrainzFilter,
 	}
 }
 
 // --- Bubble Tea ---
-func (m model) Init() tea.Cmd { return checkYtDlpCmd }
+func (m model) Init() tea.Cmd { return tea.Batch(checkYtDlpCmd, waitForRetryCmd) }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.FocusMsg:
+		m.unfocused = false
+	case tea.BlurMsg:
+		m.unfocused = true
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
 		listHeight := m.height - 8
@@ -229,69 +422,170 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ytResults.SetSize(listWidth, listHeight)
 		m.mbResults.SetSize(listWidth, listHeight)
 		m.tracklist.SetSize(listWidth, listHeight)
+		m.historyList.SetSize(listWidth, listHeight)
+		m.reviewList.SetSize(listWidth, listHeight)
+		m.ytmList.SetSize(listWidth, listHeight)
+		m.releaseList.SetSize(listWidth, listHeight)
+
+	case tea.MouseMsg:
+		cmds = append(cmds, m.updateMouse(msg))
 
 	case tea.KeyMsg:
-		if msg.Type == tea.KeyCtrlC {
+		if key.Matches(msg, keymap.quit) {
 			return m, tea.Quit
 		}
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		if m.isHelpKey(msg) {
+			m.showHelp = true
+			return m, nil
+		}
+		if (m.state == stateSelectYT || m.state == stateSelectMB || m.state == stateSelectTrack) && filterKey(m.activeList(), msg) {
+			break
+		}
 		switch m.state {
 		case stateSelectYT:
-			if msg.Type == tea.KeyEnter {
+			if m.batchActive() {
+				if key.Matches(msg, keymap.confirm) {
+					if i, ok := m.ytResults.SelectedItem().(item); ok {
+						cmds = append(cmds, m.startBatchDownload(i))
+					}
+				} else if msg.String() == "m" {
+					cmds = append(cmds, m.loadMore())
+				} else if key.Matches(msg, keymap.back) {
+					cmds = append(cmds, m.finishBatchItem(queueSkipped, tr("⏭ %s (スキップ)", m.batch[m.batchIndex].track.title)))
+				} else if key.Matches(msg, keymap.queue) {
+					m.openQueue()
+				}
+			} else if msg.String() == "m" {
+				cmds = append(cmds, m.loadMore())
+			} else if msg.String() == "a" && len(m.mbResults.Items()) > 0 {
+				m.matchBack = m.state
+				m.state, m.statusMsg = stateSearching, tr("最適な音源とトラックを自動で照合中です...")
+				cmds = append(cmds, m.spinner.Tick, autoMatchCmd(m.ytResults.Items(), m.mbResults.Items()))
+			} else if key.Matches(msg, keymap.confirm) {
 				if i, ok := m.ytResults.SelectedItem().(item); ok {
-					m.selectedYT = i
-					m.state = stateSearching
-					m.statusMsg = "MusicBrainzでメタデータを検索中です..."
-					cmds = append(cmds, m.spinner.Tick, searchMusicBrainzCmd(fmt.Sprintf("%s %s", i.title, i.desc)))
+					if cmd, ok := m.useTopicTags(i); ok {
+						cmds = append(cmds, cmd)
+					} else {
+						cmds = append(cmds, m.searchMBFor(i))
+					}
 				}
-			} else if msg.Type == tea.KeyEsc {
+			} else if key.Matches(msg, keymap.back) {
 				m.state = stateInput
 			}
 		case stateSelectMB:
-			if msg.Type == tea.KeyEnter {
+			if key.Matches(msg, keymap.confirm) {
 				if i, ok := m.mbResults.SelectedItem().(item); ok {
-					m.selectedMB = i
+					m.selectedMB, m.pickRecording = releaseToOpen(i)
 					m.state = stateSelectTrack
-					m.statusMsg = "トラックリストを取得中です..."
+					m.statusMsg = tr("トラックリストを取得中です...")
 					cmds = append(cmds, m.spinner.Tick, getTracklistCmd(i.id))
 				}
-			} else if msg.String() == "s" {
+			} else if msg.String() == "g" {
+				cmds = append(cmds, m.openReleaseGroup())
+			} else if k := msg.String(); m.groupParent != nil && (k == "m" || k == "c" || k == "o" || k == "t" || k == "r") {
+				cmds = append(cmds, m.groupOnly())
+			} else if msg.String() == "m" {
+				cmds = append(cmds, m.loadMore())
+			} else if k := msg.String(); k == "c" || k == "o" || k == "t" || k == "r" {
+				cmds = append(cmds, m.toggleMBFilter(k))
+			} else if msg.String() == "a" {
+				m.matchBack = m.state
+				m.state, m.statusMsg = stateSearching, tr("最適なトラックを自動で照合中です...")
+				cmds = append(cmds, m.spinner.Tick, autoMatchCmd([]list.Item{m.selectedYT}, m.mbResults.Items()))
+			} else if key.Matches(msg, keymap.skipMB) && m.tagFile == "" {
 				m.state = stateConfirmSkipMB
-			} else if msg.Type == tea.KeyEsc {
+			} else if key.Matches(msg, keymap.back) && m.closeReleaseGroup() {
+				// 版の一覧から検索結果に戻った
+			} else if key.Matches(msg, keymap.back) && m.importing() {
+				cmds = append(cmds, m.finishImportItem(tr("⏭ %s: スキップ", filepath.Base(m.tagFile))))
+			} else if key.Matches(msg, keymap.back) && m.tagFile != "" {
+				m.state = stateInput
+			} else if key.Matches(msg, keymap.back) {
 				m.state = stateSelectYT
 			}
 		case stateSelectTrack:
-			if msg.Type == tea.KeyEnter {
+			if k := msg.String(); m.tagFile != "" && (k == " " || k == "x" || k == "w" || k == "q") {
+				cmds = append(cmds, m.tracklist.NewStatusMessage(tr("ファイルのタグ付けでは1曲を選んで Enter を押してください")))
+			} else if msg.String() == " " {
+				if i, ok := m.tracklist.SelectedItem().(item); ok {
+					i.marked = !i.marked
+					cmds = append(cmds, m.tracklist.SetItem(m.tracklist.GlobalIndex(), i))
+				}
+			} else if msg.String() == "x" {
+				tracks := splitCandidates(m.tracklist.Items())
+				if splittableTrackCount(len(tracks)) {
+					m.state, m.statusMsg = stateDownloading, tr("動画を%d曲に分割してダウンロード中です...", len(tracks))
+					cmds = append(cmds, m.spinner.Tick, m.retryable(splitDownloadCmd(m.ytDlpPath, m.ffmpegPath, m.selectedYT, m.selectedMB, tracks)))
+				} else {
+					cmds = append(cmds, m.tracklist.NewStatusMessage(tr("分割できるのは%d〜%d曲です (Spaceで対象を選択)", splitMinTracks, splitMaxTracks)))
+				}
+			} else if msg.String() == "w" {
+				tracks := splitCandidates(m.tracklist.Items())
+				m.state, m.statusMsg = stateDownloading, tr("%d曲分のチャプター付きで1ファイルにダウンロード中です...", len(tracks))
+				cmds = append(cmds, m.spinner.Tick, m.retryable(chapterDownloadCmd(m.ytDlpPath, m.ffmpegPath, m.selectedYT, m.selectedMB, tracks)))
+			} else if msg.String() == "q" {
+				tracks := markedItems(m.tracklist.Items())
+				if i, ok := m.tracklist.SelectedItem().(item); ok && len(tracks) == 0 {
+					tracks = []item{i}
+				}
+				added := m.enqueue(tracks, m.selectedMB)
+				cmds = append(cmds, m.unmarkTracks(), m.tracklist.NewStatusMessage(tr("%d曲をキューに追加しました (計%d曲, %s: キューを表示)", added, len(m.batch), keyName(keymap.queue))))
+			} else if key.Matches(msg, keymap.queue) {
+				m.openQueue()
+			} else if marked := markedItems(m.tracklist.Items()); key.Matches(msg, keymap.confirm) && len(marked) > 0 {
+				m.enqueue(marked, m.selectedMB)
+				cmds = append(cmds, m.unmarkTracks(), m.startQueue())
+			} else if key.Matches(msg, keymap.confirm) {
 				if i, ok := m.tracklist.SelectedItem().(item); ok {
 					m.selectedTrack = i
+					m.matchNote = ""
 					m.state = stateEditTags
 					m.focusIndex = 0
 					m.tagInputs = m.createTagInputs()
-					cmds = append(cmds, m.tagInputs[0].Focus())
+					cmds = append(cmds, m.tagInputs[0].Focus(), m.prefetchLyrics(), m.prefetchLastFM())
 				}
-			} else if msg.Type == tea.KeyEsc {
+			} else if key.Matches(msg, keymap.back) {
 				m.state = stateSelectMB
 			}
 		case stateEditTags:
-			if msg.Type == tea.KeyEnter {
+			if key.Matches(msg, keymap.confirm) {
 				if m.focusIndex == len(m.tagInputs)-1 {
-					m.state, m.statusMsg = stateDownloading, "音声・ジャケット・歌詞を取得中です..."
-					trackInfo := m.selectedTrack.meta.(MBTrack)
-					tags := finalTags{
-						Title:       m.tagInputs[0].Value(),
-						Artist:      m.tagInputs[1].Value(),
-						Album:       m.tagInputs[2].Value(),
-						Date:        m.tagInputs[3].Value(),
-						TrackNumber: m.tagInputs[4].Value(),
-						AlbumArtist: m.tagInputs[1].Value(),
-						DurationSec: trackInfo.Length / 1000,
+					tags := buildTags(m.selectedMB.meta.(MBRelease), m.selectedTrack)
+					tags.Title = m.tagInputs[0].Value()
+					tags.Artist = m.tagInputs[1].Value()
+					tags.Album = m.tagInputs[2].Value()
+					tags.Date = m.tagInputs[3].Value()
+					tags.TrackNumber = m.tagInputs[4].Value()
+					tags.AlbumArtist = m.tagInputs[1].Value()
+					if tags.Artist != m.selectedTrack.artist {
+						tags.ArtistSort = "" // 書き換えたアーティストはローマ字化で読みを作る
+					}
+					if g := m.lastfm.genre(); m.lastfmApplied && g != "" {
+						tags.Genre = g
+					}
+					if !m.lyricsBusy && m.lyricsKey == lyricsKey(tags) {
+						lyrics := m.lyricsInfo
+						tags.Lyrics = &lyrics
+					}
+					m.state, m.pendingTags, m.lyricsNote = stateCompare, tags, ""
+					cmds = append(cmds, m.requestCoverPreview())
+					if tags.Lyrics != nil && tags.Lyrics.found() && cfg.Lyrics.Review {
+						cmds = append(cmds, m.openLyricsEditor())
 					}
-					cmds = append(cmds, m.spinner.Tick, downloadCmd(m.ytDlpPath, m.ffmpegPath, m.selectedYT, m.selectedMB, tags))
 				} else {
 					m.focusIndex++
 					cmds = append(cmds, m.tagInputs[m.focusIndex].Focus())
 				}
-			} else if msg.Type == tea.KeyEsc {
+			} else if key.Matches(msg, keymap.back) && m.fromTopic() {
+				m.state = stateSelectYT
+			} else if key.Matches(msg, keymap.back) {
 				m.state = stateSelectTrack
+			} else if msg.Type == tea.KeyCtrlT {
+				m.applyLastFM()
 			} else {
 				if msg.String() == "up" {
 					m.focusIndex--
@@ -311,93 +605,503 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			}
+		case stateCompare:
+			if (key.Matches(msg, keymap.confirm) || msg.String() == "y") && m.tagFile != "" {
+				m.state, m.statusMsg = stateDownloading, tr("ジャケット・歌詞を取得してタグを書き換え中です...")
+				cmds = append(cmds, m.spinner.Tick, retagFileCmd(m.ffmpegPath, m.tagFile, m.selectedMB, m.pendingTags, m.importing()))
+			} else if key.Matches(msg, keymap.confirm) || msg.String() == "y" {
+				m.state, m.statusMsg = stateDownloading, tr("音声・ジャケット・歌詞を取得中です...")
+				cmds = append(cmds, m.spinner.Tick, m.retryable(downloadCmd(m.ytDlpPath, m.ffmpegPath, m.selectedYT, m.selectedMB, m.pendingTags)))
+			} else if key.Matches(msg, keymap.back) || msg.String() == "n" {
+				m.state = stateEditTags
+			} else if msg.String() == "l" {
+				m.selectedYT = cycleAudioTrack(m.selectedYT)
+			} else if msg.String() == "e" && m.pendingTags.Lyrics != nil {
+				cmds = append(cmds, m.openLyricsEditor())
+			} else if msg.String() == "t" && m.tagFile == "" {
+				m.openTrim()
+			} else if msg.String() == "r" {
+				m.pendingTags.Romanize = !m.pendingTags.Romanize
+			} else if msg.String() == "c" && m.offerCaptions() {
+				m.state, m.statusMsg = stateSearching, tr("YouTubeの字幕を取得中です...")
+				cmds = append(cmds, m.spinner.Tick, fetchCaptionLyricsCmd(m.ytDlpPath, m.selectedYT))
+			}
+		case stateTrim:
+			cmds = append(cmds, m.updateTrim(msg))
+		case stateReview:
+			cmds = append(cmds, m.updateReview(msg))
+		case stateYTMusic:
+			cmds = append(cmds, m.updateYTMusic(msg))
+		case stateNewReleases:
+			cmds = append(cmds, m.updateNewReleases(msg))
+		case stateRecover:
+			m.updateRecover(msg)
+		case stateCookies:
+			cmds = append(cmds, m.updateCookies(msg))
+		case stateLyrics:
+			switch msg.Type {
+			case tea.KeyCtrlS:
+				m.saveLyricsEdit()
+				m.state = stateCompare
+			case tea.KeyEsc:
+				m.state = stateCompare
+			}
+		case stateHistory:
+			if m.noteEditing {
+				cmds = append(cmds, m.updateNoteEdit(msg))
+				break
+			}
+			if m.historyTyping {
+				switch msg.Type {
+				case tea.KeyEnter:
+					m.historyTyping = false
+					m.historyInput.Blur()
+					m.historyQuery = strings.TrimSpace(m.historyInput.Value())
+					m.showHistoryList()
+				case tea.KeyEsc:
+					m.historyTyping = false
+					m.historyInput.Blur()
+					m.historyInput.SetValue(m.historyQuery)
+				}
+				break
+			}
+			if m.historyList.FilterState() == list.Filtering {
+				break
+			}
+			switch msg.String() {
+			case "esc":
+				m.state = stateInput
+			case "c":
+				m.historyPrune = !m.historyPrune
+				m.showHistoryList()
+			case "t":
+				m.setHistoryQuickFilter("today")
+			case "w":
+				m.setHistoryQuickFilter("week")
+			case "x":
+				m.setHistoryQuickFilter("failed")
+			case "f":
+				m.historyTyping = true
+				cmds = append(cmds, m.historyInput.Focus())
+			case "r":
+				cmds = append(cmds, m.openReplace())
+			case "1", "2", "3", "4", "5":
+				cmds = append(cmds, m.setRating(int(msg.String()[0]-'0')))
+			case "n":
+				cmds = append(cmds, m.openNoteEdit())
+			}
+		case stateReplace:
+			cmds = append(cmds, m.updateReplace(msg))
+		case stateQueue:
+			cmds = append(cmds, m.updateQueue(msg))
+		case stateLibrary:
+			m.updateLibrary(msg)
+		case stateDiagnostics:
+			switch msg.String() {
+			case "r":
+				m.diag = nil
+				cmds = append(cmds, m.spinner.Tick, runDiagnosticsCmd)
+			case "esc", "q":
+				if m.ytDlpPath != "" && m.ffmpegPath != "" {
+					m.state = stateInput
+				} else {
+					m.state, m.statusMsg = stateCheckingDeps, tr("依存関係を再確認中です...")
+					cmds = append(cmds, m.spinner.Tick, checkYtDlpCmd)
+				}
+			}
 		case stateInput:
-			if msg.Type == tea.KeyEnter {
+			if msg.Type == tea.KeyCtrlD {
+				cmds = append(cmds, m.openDiagnostics())
+			} else if key.Matches(msg, keymap.queue) {
+				m.openQueue()
+			} else if msg.Type == tea.KeyCtrlY {
+				cmds = append(cmds, m.openYTMusic())
+			} else if msg.Type == tea.KeyCtrlN && len(m.newReleases) > 0 {
+				m.openNewReleases()
+			} else if msg.Type == tea.KeyCtrlO {
+				m.openReview()
+				cmds = append(cmds, loadReviewCmd)
+			} else if msg.Type == tea.KeyCtrlR {
+				m.state, m.statusMsg = stateSearching, tr("履歴を読み込み中です...")
+				cmds = append(cmds, m.spinner.Tick, loadHistoryCmd, libraryUsageCmd)
+			} else if msg.Type == tea.KeyCtrlL {
+				m.state, m.statusMsg = stateSearching, tr("ライブラリを読み込み中です...")
+				cmds = append(cmds, m.spinner.Tick, loadLibraryCmd(m.ffmpegPath))
+			} else if key.Matches(msg, keymap.confirm) {
 				query := m.input.Value()
-				if strings.HasPrefix(query, "http") {
-					m.state, m.statusMsg = stateFetchingURLInfo, "URLから情報を取得中です..."
-					cmds = append(cmds, m.spinner.Tick, getURLInfoCmd(m.ytDlpPath, query))
+				m.searchStart, m.searchTook = time.Now(), 0
+				if isLocalFile(query) || isLocalDir(query) {
+					cmds = append(cmds, m.startLocal(query))
+				} else if id, ok := spotifyPlaylistID(query); ok {
+					m.state, m.statusMsg = stateSearching, tr("Spotifyのプレイリストを取得中です...")
+					cmds = append(cmds, m.spinner.Tick, spotifyImportCmd(id))
+				} else if strings.HasPrefix(query, "http") {
+					m.state, m.statusMsg = stateFetchingURLInfo, tr("URLから情報を取得中です...")
+					cmds = append(cmds, m.spinner.Tick, m.retryable(getURLInfoCmd(m.ytDlpPath, query)))
 				} else {
-					m.state, m.statusMsg = stateSearching, "YouTubeとMusicBrainzを検索中です..."
-					cmds = append(cmds, m.spinner.Tick, searchCmd(m.ytDlpPath, query))
+					m.state, m.statusMsg = stateSearching, tr("YouTubeとMusicBrainzを検索中です...")
+					cmds = append(cmds, m.spinner.Tick, searchCmd(m.ytDlpPath, query, m.mbFilter))
 				}
 			}
 		case stateConfirmSkipMB:
-			switch strings.ToLower(msg.String()) {
-			case "y", "enter":
-				m.state, m.statusMsg = stateDownloading, "タグ無しでダウンロード中です..."
-				cmds = append(cmds, m.spinner.Tick, simpleDownloadCmd(m.ytDlpPath, m.ffmpegPath, m.selectedYT))
-			case "n", "esc":
+			if k := strings.ToLower(msg.String()); k == "y" || key.Matches(msg, keymap.confirm) {
+				m.state, m.statusMsg = stateDownloading, tr("MusicBrainzのタグ無しでダウンロード中です...")
+				cmds = append(cmds, m.spinner.Tick, m.retryable(simpleDownloadCmd(m.ytDlpPath, m.ffmpegPath, m.selectedYT)))
+			} else if k == "n" || key.Matches(msg, keymap.back) {
 				m.state = stateSelectYT
+			} else if k == "r" && !m.mbFilter.Recordings {
+				m.mbFilter.Recordings, m.mbCache = true, map[string][]list.Item{}
+				m.state, m.statusMsg = stateSearching, tr("MusicBrainzで録音を検索中です...")
+				cmds = append(cmds, m.spinner.Tick, searchMusicBrainzCmd(m.mbMore.query, m.mbFilter))
 			}
 		case stateShowSuccess, stateError:
+			if m.state == stateError && msg.Type == tea.KeyCtrlD {
+				cmds = append(cmds, m.openDiagnostics())
+				break
+			}
 			cmds = append(cmds, func() tea.Msg { return resetMsg{} })
 		}
 
 	// --- Async Messages ---
 	case ytDlpCheckResultMsg:
-		if msg.err != nil {
+		if msg.err != nil && !remoteEnabled() {
 			m.state, m.error = stateError, msg.err
 		} else {
 			m.ytDlpPath = msg.path
 			cmds = append(cmds, checkFfmpegCmd)
 		}
 	case ffmpegCheckResultMsg:
-		if msg.err != nil {
-			m.state, m.error = stateError, fmt.Errorf("ffmpegが見つかりません。\n音声変換には必須です。OSに合わせてインストールしてください。\n(例: brew install ffmpeg)")
+		if msg.err != nil && !remoteEnabled() {
+			m.state, m.error = stateError, errorf("ffmpegが見つかりません。\n音声変換には必須です。OSに合わせてインストールしてください。\n(例: brew install ffmpeg)")
 		} else {
 			m.ffmpegPath, m.state = msg.path, stateInput
+			cmds = append(cmds, libraryUsageCmd, loadReviewCmd, checkNewReleasesCmd, scanCrashedDownloadsCmd)
+			// リモートではライブラリとチャンネル登録はサーバー側で扱う
+			if !remoteEnabled() {
+				cmds = append(cmds, scanLibraryCmd(msg.path), checkSubscriptionsCmd(m.ytDlpPath))
+			}
+			if m.tagFile != "" {
+				cmds = append(cmds, m.startLocal(m.tagFile))
+			}
 		}
-	case urlInfoFetchedMsg:
+	case replacePlannedMsg:
+		m.state = stateReplace
+		switch {
+		case msg.err != nil:
+			m.replNote = msg.err.Error()
+		case len(msg.changes) == 0:
+			m.replNote = tr("一致するタグはありませんでした")
+		default:
+			m.replPlan, m.replNote = msg.changes, tr("%dファイル / %d項目を変更", msg.files, len(msg.changes))
+		}
+	case replaceAppliedMsg:
+		if msg.err != nil {
+			m.state, m.error = stateError, errorf("%d件を書き換えた後にエラーが発生しました:\n%w", msg.files, msg.err)
+		} else {
+			m.state, m.lastFile, m.lastWarning = stateShowSuccess, tr("%d件のファイルのタグを書き換えました", msg.files), ""
+		}
+	case prefetchTickMsg:
+		cmds = append(cmds, m.firePrefetch(msg.seq))
+	case mbPrefetchedMsg:
+		cmds = append(cmds, m.handlePrefetched(msg))
+	case diagnosticsMsg:
+		m.diag = &msg.report
+	case reviewLoadedMsg:
+		if msg.err != nil {
+			log.Printf("Review: %v", msg.err)
+		} else {
+			m.review = msg.entries
+			if m.state == stateReview {
+				cmds = append(cmds, m.reviewList.SetItems(reviewItems(m.review)))
+				m.reviewList.Title = tr("自動処理で確認が必要な曲 (%d件)", len(m.review))
+			}
+		}
+	case trimPreviewMsg:
+		m.trimBusy, m.trimNote = false, ""
+		if msg.streamURL != "" {
+			m.trimStream, m.trimStreamFor = msg.streamURL, msg.videoID
+		}
+		if msg.err != nil {
+			m.trimNote = msg.err.Error()
+		}
+	case captionLyricsMsg:
+		m.state, m.lyricsNote = stateCompare, ""
+		if msg.err != nil {
+			log.Printf("Lyrics: captions failed: %v", msg.err)
+			m.lyricsNote = msg.err.Error()
+		} else {
+			m.pendingTags.Lyrics = &msg.result
+			if cfg.Lyrics.Review {
+				cmds = append(cmds, m.openLyricsEditor())
+			}
+		}
+	case lyricsFetchedMsg:
+		if msg.key == m.lyricsKey {
+			m.lyricsInfo, m.lyricsBusy = msg.result, false
+		}
+	case lastfmFetchedMsg:
+		if msg.err != nil {
+			log.Printf("Last.fm: %v", msg.err)
+		} else if msg.key == m.lastfmKey {
+			m.lastfm = msg.info
+		}
+	case coverPreviewMsg:
+		if msg.releaseID == m.previewFor {
+			m.coverPreview = msg.img
+			switch {
+			case msg.err != nil:
+				m.previewNote = tr("プレビュー取得失敗")
+				log.Printf("Preview: %v", msg.err)
+			case msg.img == nil:
+				m.previewNote = tr("配信元に画像なし (YouTubeサムネイルを使用)")
+			default:
+				m.previewNote = string(msg.source)
+			}
+		}
+	case libraryUsageMsg:
+		if msg.err != nil {
+			log.Printf("Library: failed to measure usage: %v", msg.err)
+		} else {
+			m.libraryBytes = msg.bytes
+		}
+	case curationSavedMsg:
+		if msg.err != nil && m.state == stateHistory {
+			cmds = append(cmds, m.historyList.NewStatusMessage("⚠ "+msg.err.Error()))
+		}
+	case historyLoadedMsg:
 		if msg.err != nil {
 			m.state, m.error = stateError, msg.err
+		} else {
+			m.state, m.historyRows, m.historyPrune = stateHistory, msg.rows, false
+			m.historyQuery, m.historyTyping = "", false
+			m.historyInput.SetValue("")
+			m.showHistoryList()
+		}
+	case urlInfoFetchedMsg:
+		m.endSearchPhase(msg.err == nil)
+		if msg.err != nil {
+			if !m.promptCookies(msg.err) {
+				m.state, m.error = stateError, msg.err
+			}
+		} else if cmd, ok := m.useTopicTags(msg.ytItem); ok {
+			cmds = append(cmds, cmd)
 		} else {
 			m.selectedYT = msg.ytItem
-			m.state, m.statusMsg = stateSearching, "MusicBrainzでメタデータを検索中です..."
-			cmds = append(cmds, m.spinner.Tick, searchMusicBrainzCmd(fmt.Sprintf("%s %s", msg.ytItem.title, msg.ytItem.desc)))
+			m.state, m.statusMsg = stateSearching, tr("MusicBrainzでメタデータを検索中です...")
+			cmds = append(cmds, m.spinner.Tick, searchMusicBrainzCmd(mbQueryFor(msg.ytItem), m.mbFilter))
 		}
 	case searchFinishedMsg:
+		m.endSearchPhase(false)
 		if msg.err != nil {
 			m.state, m.error = stateError, msg.err
 		} else {
 			m.state = stateSelectYT
-			m.ytResults = newList("どの音源をダウンロードしますか？", msg.ytItems)
-			m.mbResults = newList("どのリリースからタグ情報を取得しますか？", msg.mbItems)
+			m.ytResults = newList(tr("どの音源をダウンロードしますか？"), msg.ytItems)
+			m.mbResults, m.groupParent = newList(m.mbListTitle(), msg.mbItems), nil
+			m.ytMore, m.mbMore = morePaging{query: msg.query, pages: 1}, morePaging{query: msg.query, pages: 1}
+			m.ytResults.SetSize(m.width-4, m.height-8)
+		}
+	case ytSearchFinishedMsg:
+		if msg.err != nil || len(msg.items) == 0 {
+			reason := tr("見つかりませんでした")
+			if msg.err != nil {
+				reason = msg.err.Error()
+			}
+			cmds = append(cmds, m.finishBatchItem(queueFailed, fmt.Sprintf("❌ %s: %s", m.batch[m.batchIndex].track.title, reason)))
+		} else {
+			m.state = stateSelectYT
+			m.ytResults = newList(tr("(%d/%d) 「%s」の音源を選択してください", m.batchIndex+1, len(m.batch), m.batch[m.batchIndex].track.title), msg.items)
+			m.ytMore = morePaging{query: msg.query, pages: 1}
 			m.ytResults.SetSize(m.width-4, m.height-8)
 		}
+	case autoMatchFinishedMsg:
+		if msg.err != nil {
+			// 検索結果の一覧に戻り、手動で選べるようにする
+			m.state, m.statusMsg = m.matchBack, msg.err.Error()
+			if l := m.activeList(); l != nil {
+				cmds = append(cmds, l.NewStatusMessage("⚠ "+firstLine(m.statusMsg)))
+			}
+		} else {
+			m.selectedYT, m.selectedMB, m.selectedTrack = msg.yt, msg.release, msg.track
+			m.matchNote = tr("自動選択: %s\n  %s", msg.yt.title, msg.score)
+			m.state = stateEditTags
+			m.focusIndex = 0
+			m.tagInputs = m.createTagInputs()
+			cmds = append(cmds, m.tagInputs[0].Focus(), m.prefetchLyrics(), m.prefetchLastFM())
+		}
+	case tagFileLoadedMsg:
+		m.endSearchPhase(msg.err == nil)
+		if msg.err != nil && m.importing() {
+			cmds = append(cmds, m.finishImportItem(fmt.Sprintf("❌ %s: %v", filepath.Base(m.tagFile), msg.err)))
+		} else if msg.err != nil {
+			m.state, m.error = stateError, msg.err
+		} else {
+			m.selectedYT = msg.file
+			m.statusMsg = tr("MusicBrainzでメタデータを検索中です...")
+			cmds = append(cmds, searchMusicBrainzCmd(mbQueryFor(msg.file), m.mbFilter))
+		}
+	case spotifyImportedMsg:
+		m.endSearchPhase(msg.err == nil)
+		if msg.err != nil {
+			m.state, m.error = stateError, msg.err
+		} else {
+			added := m.enqueueItems(msg.items)
+			log.Printf("Spotify: queued %d of %d tracks from %q", added, len(msg.items), msg.name)
+			m.state = stateInput
+			m.openQueue()
+		}
+	case crashedDownloadsMsg:
+		if m.crashed = msg.items; len(m.crashed) > 0 && m.state == stateInput {
+			m.state = stateRecover
+		}
+	case newReleasesMsg:
+		if msg.err != nil {
+			log.Printf("Watch: %v", msg.err)
+		}
+		m.newReleases = msg.pending
+	case subscriptionUploadsMsg:
+		if msg.err != nil {
+			log.Printf("Subscriptions: %v", msg.err)
+		}
+		if len(msg.items) > 0 {
+			m.subsQueued += m.enqueueItems(msg.items)
+			log.Printf("Subscriptions: queued %d new uploads", len(msg.items))
+		}
+	case newReleaseQueuedMsg:
+		if msg.err != nil {
+			m.state, m.error = stateError, fmt.Errorf("「%s」: %w", msg.group.Title, msg.err)
+		} else {
+			var tracks []item
+			for _, li := range msg.tracks {
+				tracks = append(tracks, li.(item))
+			}
+			m.enqueue(tracks, msg.release)
+			m.removeNewRelease(msg.group.GroupID)
+			m.state = stateInput
+			m.openQueue()
+		}
+	case ytmPlaylistsMsg:
+		if msg.err != nil {
+			if !m.promptCookies(msg.err) {
+				m.state, m.error = stateError, msg.err
+			}
+		} else {
+			m.state = stateYTMusic
+			m.ytmList = newList(tr("YouTube Music: どのプレイリストをキューに追加しますか？"), msg.items)
+			m.ytmList.SetSize(m.width-4, m.height-8)
+		}
+	case ytmTracksMsg:
+		if msg.err != nil {
+			if !m.promptCookies(msg.err) {
+				m.state, m.error = stateError, msg.err
+			}
+		} else {
+			added := m.enqueueItems(msg.items)
+			log.Printf("YouTube Music: queued %d of %d tracks", added, len(msg.items))
+			m.state = stateYTMusic
+			m.openQueue()
+		}
+	case libraryLoadedMsg:
+		if msg.err != nil {
+			m.state, m.error = stateError, msg.err
+		} else {
+			m.libIndex = msg.index
+			m.openLibrary(msg.entries)
+		}
+	case retryMsg:
+		m.lastRetry = msg
+		cmds = append(cmds, waitForRetryCmd)
+	case libraryIndexedMsg:
+		if msg.err != nil {
+			log.Printf("Library: failed to index downloads: %v", msg.err)
+		} else {
+			m.libIndex = msg.index
+		}
 	case mbSearchFinishedMsg:
+		m.endSearchPhase(false)
 		if msg.err != nil {
 			m.state, m.error = stateError, msg.err
+		} else if len(msg.items) == 0 && m.importing() {
+			cmds = append(cmds, m.finishImportItem(tr("⏭ %s: MusicBrainzで見つかりませんでした", filepath.Base(m.tagFile))))
+		} else if len(msg.items) == 0 && m.tagFile != "" {
+			m.state, m.error = stateError, errorf("MusicBrainzで「%s」が見つかりませんでした。\nファイル名かタグを曲名に直してからもう一度お試しください。", mbQueryFor(m.selectedYT))
 		} else if len(msg.items) == 0 {
-			m.state = stateConfirmSkipMB
+			m.state, m.mbMore = stateConfirmSkipMB, morePaging{query: msg.query}
 		} else {
 			m.state = stateSelectMB
-			m.mbResults = newList("どのリリースからタグ情報を取得しますか？", msg.items)
+			m.mbResults, m.groupParent = newList(m.mbListTitle(), msg.items), nil
+			m.mbMore = morePaging{query: msg.query, pages: 1}
 			m.mbResults.SetSize(m.width-4, m.height-8)
 		}
+	case moreResultsMsg:
+		cmds = append(cmds, m.appendMore(msg))
+	case mbRefilteredMsg:
+		cmds = append(cmds, m.applyMBFilter(msg))
+	case groupReleasesMsg:
+		cmds = append(cmds, m.showReleaseGroup(msg))
 	case tracklistFinishedMsg:
 		if msg.err != nil {
 			m.state, m.error = stateError, msg.err
 		} else if len(msg.items) == 0 {
-			m.state, m.error = stateError, fmt.Errorf("選択したリリースにはトラック情報が含まれていませんでした。別のリリースを選択してください。")
+			m.state, m.error = stateError, errorf("選択したリリースにはトラック情報が含まれていませんでした。別のリリースを選択してください。")
 		} else {
 			m.state = stateSelectTrack
-			m.tracklist = newList(fmt.Sprintf("「%s」から曲を選択してください", m.selectedMB.title), msg.items)
+			m.selectedMB.meta = msg.release
+			title := tr("「%s」から曲を選択してください", m.selectedMB.title)
+			if looksLikeFullUpload(m.selectedYT, splitCandidates(msg.items)) {
+				title += tr(" — この動画は全曲入りのようです (x: 分割 / w: 1ファイル)")
+			} else if note := liveNote(m.selectedYT); note != "" {
+				title += " — " + note
+			}
+			m.tracklist = newList(title, msg.items)
 			m.tracklist.SetSize(m.width-4, m.height-8)
+			if m.pickRecording != "" {
+				if cmd, ok := m.pickRecordingTrack(msg.items); ok {
+					cmds = append(cmds, cmd)
+				}
+			}
 		}
 	case downloadFinishedMsg:
-		if msg.err != nil {
-			m.state, m.error = stateError, msg.err
+		if m.importing() {
+			if msg.err != nil {
+				cmds = append(cmds, m.finishImportItem(fmt.Sprintf("❌ %s: %v", filepath.Base(m.tagFile), msg.err)))
+			} else {
+				file, _, _ := strings.Cut(msg.filename, "\n")
+				cmds = append(cmds, m.finishImportItem("✅ "+file))
+			}
+		} else if m.batchActive() {
+			if msg.warning != "" {
+				m.lastWarning = strings.TrimSpace(m.lastWarning + "\n" + msg.warning)
+			}
+			if msg.err != nil {
+				cmds = append(cmds, m.finishBatchItem(queueFailed, fmt.Sprintf("❌ %s: %v", m.batch[m.batchIndex].track.title, msg.err)))
+			} else {
+				if len(msg.files) > 0 {
+					m.batch[m.batchIndex].path = msg.files[0]
+				}
+				cmds = append(cmds, m.finishBatchItem(queueDone, "✅ "+msg.filename))
+			}
+		} else if msg.err != nil {
+			if !m.promptCookies(msg.err) {
+				m.state, m.error = stateError, msg.err
+				cmds = append(cmds, m.desktopNotifyCmd(tr("ダウンロード失敗"), firstLine(msg.err.Error())))
+			}
 		} else {
-			m.state, m.lastFile = stateShowSuccess, msg.filename
+			m.state, m.lastFile, m.lastWarning = stateShowSuccess, msg.filename, msg.warning
+			m.timeline = m.jobTimeline(msg.timeline)
+			file, _, _ := strings.Cut(msg.filename, "\n")
+			cmds = append(cmds, m.desktopNotifyCmd(tr("ダウンロード完了"), file))
 		}
 	case resetMsg:
-		ytPath, ffPath, w, h := m.ytDlpPath, m.ffmpegPath, m.width, m.height
+		ytPath, ffPath, w, h, queue, unfocused, filter := m.ytDlpPath, m.ffmpegPath, m.width, m.height, m.batch, m.unfocused, m.mbFilter
 		m = newModel()
-		m.ytDlpPath, m.ffmpegPath, m.width, m.height = ytPath, ffPath, w, h
+		m.ytDlpPath, m.ffmpegPath, m.width, m.height, m.batch, m.unfocused, m.mbFilter = ytPath, ffPath, w, h, queue, unfocused, filter
 		m.state = stateInput
 		m.statusMsg = ""
-		cmds = append(cmds, textinput.Blink)
+		cmds = append(cmds, textinput.Blink, libraryUsageCmd, loadReviewCmd, checkNewReleasesCmd)
+		if !remoteEnabled() {
+			cmds = append(cmds, scanLibraryCmd(ffPath)) // リモートではライブラリはサーバー側にある
+		}
 	case spinner.TickMsg:
 		m.spinner, cmd = m.spinner.Update(msg)
 		cmds = append(cmds, cmd)
@@ -409,13 +1113,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	case stateSelectYT:
 		m.ytResults, cmd = m.ytResults.Update(msg)
-		cmds = append(cmds, cmd)
+		cmds = append(cmds, cmd, m.schedulePrefetch())
 	case stateSelectMB:
 		m.mbResults, cmd = m.mbResults.Update(msg)
 		cmds = append(cmds, cmd)
 	case stateSelectTrack:
 		m.tracklist, cmd = m.tracklist.Update(msg)
 		cmds = append(cmds, cmd)
+	case stateHistory:
+		if m.noteEditing {
+			m.noteInput, cmd = m.noteInput.Update(msg)
+		} else if m.historyTyping {
+			m.historyInput, cmd = m.historyInput.Update(msg)
+		} else {
+			m.historyList, cmd = m.historyList.Update(msg)
+		}
+		cmds = append(cmds, cmd)
+	case stateLyrics:
+		m.lyricsArea, cmd = m.lyricsArea.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateReview:
+		m.reviewList, cmd = m.reviewList.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateYTMusic:
+		m.ytmList, cmd = m.ytmList.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateNewReleases:
+		m.releaseList, cmd = m.releaseList.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateReplace:
+		if m.replPlan == nil {
+			m.replInputs[m.replFocus], cmd = m.replInputs[m.replFocus].Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case stateEditTags:
 		if m.focusIndex < len(m.tagInputs) {
 			m.tagInputs[m.focusIndex], cmd = m.tagInputs[m.focusIndex].Update(msg)
@@ -425,54 +1155,221 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+const appTitle = "🎵 yt-Music Downloader v1.0 by andromeda"
+
 func (m model) View() string {
 	var finalView string
 
-	if m.state == stateShowSuccess {
-		successBox := lipgloss.NewStyle().Border(lipgloss.DoubleBorder()).BorderForeground(greenColor).Padding(1, 2).Align(lipgloss.Center).Render(fmt.Sprintf("%s\n%s", lipgloss.NewStyle().Foreground(greenColor).Render("✅ ダウンロード完了"), m.lastFile))
-		help := helpStyle.Render("何かキーを押すと最初の画面に戻ります...")
-		finalView = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Center, successBox, help))
+	if m.showHelp {
+		finalView = m.helpView()
+	} else if m.state == stateShowSuccess {
+		successBox := lipgloss.NewStyle().Border(lipgloss.DoubleBorder()).BorderForeground(greenColor).Padding(1, 2).Align(lipgloss.Center).Render(fmt.Sprintf("%s\n%s", lipgloss.NewStyle().Foreground(greenColor).Render(tr("✅ ダウンロード完了")), m.lastFile))
+		help := helpStyle.Render(tr("何かキーを押すと最初の画面に戻ります..."))
+		parts := []string{successBox}
+		if m.lastWarning != "" {
+			parts = append(parts, lipgloss.NewStyle().Foreground(yellowColor).Padding(1, 0).Render(m.lastWarning))
+		}
+		if len(m.timeline) > 0 {
+			parts = append(parts, lipgloss.NewStyle().Padding(1, 0).Render(m.timeline.view()))
+		}
+		parts = append(parts, help)
+		finalView = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Center, parts...))
 	} else {
-		var content, help string
+		var content string
 		switch m.state {
 		case stateCheckingDeps, stateFetchingURLInfo, stateSearching, stateDownloading:
 			content = fmt.Sprintf("\n %s %s\n", m.spinner.View(), m.statusMsg)
-			help = helpStyle.Render("  Ctrl+C: 終了")
+			if line := m.retryLine(); line != "" {
+				content += "\n   " + line + "\n"
+			}
+			if m.batchActive() {
+				content += m.queueView(false)
+			}
+		case stateReview:
+			content = m.reviewList.View()
+		case stateNewReleases:
+			content = m.releaseList.View()
+		case stateRecover:
+			content = m.recoverView()
+		case stateYTMusic:
+			content = m.ytmList.View()
+		case stateCookies:
+			content = m.cookiesView()
+		case stateTrim:
+			content = m.trimView()
+		case stateLyrics:
+			content = m.lyricsEditorView()
+		case stateReplace:
+			content = m.replaceView()
+		case stateLibrary:
+			content = m.libraryView()
+		case stateQueue:
+			content = m.queueView(true)
 		case stateInput:
-			content = fmt.Sprintf("\n%s\n", m.input.View())
-			help = helpStyle.Render("  Enter: 検索 | Ctrl+C: 終了")
+			usageStyle := helpStyle
+			if budget := cfg.Library.budgetBytes(); budget > 0 && m.libraryBytes > budget {
+				usageStyle = lipgloss.NewStyle().Foreground(yellowColor)
+			}
+			content = fmt.Sprintf("\n%s\n\n%s\n", m.input.View(), usageStyle.Render(usageSummary(m.libraryBytes)))
+			if len(m.review) > 0 {
+				content += lipgloss.NewStyle().Foreground(yellowColor).Render(tr("自動処理で確認が必要な曲が %d 件あります (Ctrl+O で確認)", len(m.review))) + "\n"
+			}
+			if len(m.newReleases) > 0 {
+				content += lipgloss.NewStyle().Foreground(greenColor).Render(tr("ウォッチ中のアーティストの新譜が %d 件あります (Ctrl+N で確認)", len(m.newReleases))) + "\n"
+			}
+			if remoteEnabled() {
+				content += helpStyle.Render(tr("ダウンロードはサーバー (%s) で行います", cfg.Remote.URL)) + "\n"
+			}
+			if m.subsQueued > 0 && len(m.batch) > 0 && !m.batchRunning {
+				content += lipgloss.NewStyle().Foreground(greenColor).Render(tr("登録チャンネルの新着 %d 曲をキューに追加しました (%s で確認)", m.subsQueued, keyName(keymap.queue))) + "\n"
+			}
 		case stateConfirmSkipMB:
-			content = fmt.Sprintf("\n%s\n\n%s", "MusicBrainzにデータが見つかりませんでした。", "YouTubeのタイトルを元にタグ無しでダウンロードしますか？")
-			help = helpStyle.Render("  y/Enter: はい | n/Esc: いいえ")
+			guess := guessTags(m.selectedYT)
+			content = fmt.Sprintf("\n%s\n\n%s\n\n  %s", tr("MusicBrainzにデータが見つかりませんでした。"), tr("YouTubeのタイトルを元にタグ無しでダウンロードしますか？"),
+				tr("曲名: %s / アーティスト: %s", guess.Title, guess.Artist))
+		case stateDiagnostics:
+			content = m.diagnosticsView()
+		case stateCompare:
+			content = m.compareView()
+		case stateHistory:
+			content = m.historyList.View()
+			if m.noteEditing {
+				content = tr("%s\n  メモ: %s", content, m.noteInput.View())
+			} else if m.historyTyping {
+				content = tr("%s\n  フィルタ: %s", content, m.historyInput.View())
+			}
 		case stateSelectYT, stateSelectMB, stateSelectTrack:
 			lists := map[state]list.Model{stateSelectYT: m.ytResults, stateSelectMB: m.mbResults, stateSelectTrack: m.tracklist}
 			content = lists[m.state].View()
-			if m.state == stateSelectMB {
-				help = helpStyle.Render("  Enter: 決定 | ↑/↓: 移動 | s: スキップ | Esc: 戻る | Ctrl+C: 終了")
-			} else {
-				help = helpStyle.Render("  Enter: 決定 | ↑/↓: 移動 | Esc: 戻る | Ctrl+C: 終了")
-			}
 		case stateEditTags:
 			var b strings.Builder
-			b.WriteString("\nメタデータを確認・編集してください:\n\n")
-			labels := []string{"タイトル:", "アーティスト:", "アルバム:", "リリース日:", "トラック番号:"}
+			if m.matchNote != "" {
+				b.WriteString("\n" + lipgloss.NewStyle().Foreground(greenColor).Render(m.matchNote) + "\n")
+			}
+			if w := durationMismatchWarning(m.selectedYT, m.selectedTrack); w != "" {
+				b.WriteString("\n" + lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderForeground(redColor).Foreground(yellowColor).Bold(true).Padding(0, 1).Render(w) + "\n")
+			}
+			if info, ok := m.selectedYT.meta.(ytDlpVideoInfo); ok {
+				if w := quotaWarning(m.libraryBytes, estimateFlacBytes(info.Duration)); w != "" {
+					b.WriteString("\n" + lipgloss.NewStyle().Foreground(yellowColor).Render(w) + "\n")
+				}
+			}
+			b.WriteString(tr("\nメタデータを確認・編集してください:\n\n"))
+			labels := []string{tr("タイトル:"), tr("アーティスト:"), tr("アルバム:"), tr("リリース日:"), tr("トラック番号:")}
 			for i, input := range m.tagInputs {
 				b.WriteString(fmt.Sprintf("  %s %s\n", labels[i], input.View()))
 			}
+			lyricsStatus := m.lyricsInfo.status()
+			if m.lyricsBusy {
+				lyricsStatus = tr("取得中...")
+			}
+			b.WriteString(fmt.Sprintf("\n  %s %s\n", helpStyle.Render(tr("歌詞:")), lyricsStatus))
+			if line := m.lastfmLine(); line != "" {
+				b.WriteString("  " + lipgloss.NewStyle().Foreground(cyanColor).Render(line) + "\n")
+			}
 			content = b.String()
-			help = helpStyle.Render("  ↑/↓: 移動 | Enter: 次へ/決定 | Esc: 戻る | Ctrl+C: 終了")
 		case stateError:
-			errorBox := lipgloss.NewStyle().Border(lipgloss.DoubleBorder()).BorderForeground(redColor).Padding(1, 2).Render(fmt.Sprintf("%s\n%s", lipgloss.NewStyle().Foreground(redColor).Render("❌ エラーが発生しました"), m.error.Error()))
+			errorBox := lipgloss.NewStyle().Border(lipgloss.DoubleBorder()).BorderForeground(redColor).Padding(1, 2).Render(fmt.Sprintf("%s\n%s", lipgloss.NewStyle().Foreground(redColor).Render(tr("❌ エラーが発生しました")), m.error.Error()))
 			content = lipgloss.Place(m.width-4, m.height-7, lipgloss.Center, lipgloss.Center, errorBox)
-			help = helpStyle.Render("  何かキーを押すと最初の画面に戻ります...")
 		}
-		header := headerStyle.Render("🎵 yt-Music Downloader v1.0 by andromeda")
+		header := headerStyle.Render(appTitle)
 		mainContent := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(purpleColor).Width(m.width - 4).Height(m.height - 7).Render(content)
-		finalView = appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, header, mainContent, help))
+		finalView = appStyle.Render(lipgloss.JoinVertical(lipgloss.Left, header, mainContent, m.footerView()))
 	}
 	return finalView
 }
 
+// footerView is the help line under the content box, built apart from the content so a mouse click
+// can find its hints without drawing the whole screen.
+func (m model) footerView() string {
+	switch m.state {
+	case stateCheckingDeps, stateFetchingURLInfo, stateSearching, stateDownloading:
+		return footer(hint("?", tr("ヘルプ")), hint(keyName(keymap.quit), tr("終了")))
+	case stateReview:
+		return helpStyle.Render(tr("  Enter: 候補を開く | s: 手動で検索 | d: 削除 | Esc: 戻る | ?: ヘルプ"))
+	case stateNewReleases:
+		return helpStyle.Render(tr("  Enter: 全曲をキューに追加 | d: 一覧から消す | /: 絞り込み | Esc: 戻る | ?: ヘルプ"))
+	case stateRecover:
+		return helpStyle.Render(tr("  y/Enter: キューに入れ直す | n/Esc: 破棄 | ?: ヘルプ"))
+	case stateYTMusic:
+		return helpStyle.Render(tr("  Enter: キューに追加 | /: 絞り込み | Esc: 戻る | ?: ヘルプ"))
+	case stateCookies:
+		return helpStyle.Render(tr("  ↑/↓: 選択 | Enter: このブラウザで再試行 | s: 設定に保存して再試行 | Esc: やめる | ?: ヘルプ"))
+	case stateTrim:
+		return helpStyle.Render(tr("  ←/→: ±0.5秒 | Shift+←/→: ±5秒 | Tab: 先頭/末尾 | p: 試聴 | 0: リセット | Enter: 決定 | Esc: 戻る | ?: ヘルプ"))
+	case stateLyrics:
+		return helpStyle.Render(tr("  Ctrl+S: 保存して次へ | Esc: 編集を破棄して次へ | F1: ヘルプ"))
+	case stateReplace:
+		if m.replPlan != nil {
+			return helpStyle.Render(tr("  y/Enter: 書き換える | n/Esc: 編集に戻る | ?: ヘルプ"))
+		}
+		return helpStyle.Render(tr("  Enter: 次へ/プレビュー | ↑/↓: 移動 | Ctrl+T: 正規表現 | Esc: 履歴に戻る | F1: ヘルプ"))
+	case stateLibrary:
+		return helpStyle.Render(tr("  ↑/↓: 移動 | Space/←/→: 折りたたみ | g: グループ切替 | A〜Z/#: 頭文字へ | [/]: 前/次の見出し | Esc: 戻る | ?: ヘルプ"))
+	case stateQueue:
+		if m.batchActive() {
+			return helpStyle.Render(tr("  ↑/↓: 移動 | Space/←/→: 折りたたみ | Esc: 戻る | ?: ヘルプ"))
+		}
+		return helpStyle.Render(tr("  ↑/↓: 移動 | Space/←/→: 折りたたみ | d: 削除 | Enter: 開始 | Esc: 戻る | ?: ヘルプ"))
+	case stateInput:
+		return footer(hint(keyName(keymap.confirm), tr("検索")), hint("Ctrl+R", tr("履歴")), hint("Ctrl+L", tr("ライブラリ")), hint(keyName(keymap.queue), tr("キュー")),
+			hint("Ctrl+Y", "YT Music"), hint("Ctrl+O", tr("要確認")), hint("Ctrl+D", tr("診断")), hint("F1", tr("ヘルプ")), hint(keyName(keymap.quit), tr("終了")))
+	case stateConfirmSkipMB:
+		if !m.mbFilter.Recordings && m.mbMore.query != "" {
+			return footer(hint("y/"+keyName(keymap.confirm), tr("はい")), hint("n/"+keyName(keymap.back), tr("いいえ")), hint("r", tr("録音で検索")), hint("?", tr("ヘルプ")))
+		}
+		return footer(hint("y/"+keyName(keymap.confirm), tr("はい")), hint("n/"+keyName(keymap.back), tr("いいえ")), hint("?", tr("ヘルプ")))
+	case stateDiagnostics:
+		return helpStyle.Render(tr("  r: 再実行 | Esc: 戻る | ?: ヘルプ"))
+	case stateCompare:
+		info, _ := m.selectedYT.meta.(ytDlpVideoInfo)
+		switch {
+		case m.importing():
+			return footer(hint("y/"+keyName(keymap.confirm), tr("取り込む")), hint("e", tr("歌詞を編集")), hint("n/"+keyName(keymap.back), tr("タグ編集に戻る")), hint("?", tr("ヘルプ")))
+		case m.tagFile != "":
+			return footer(hint("y/"+keyName(keymap.confirm), tr("タグを書き換える")), hint("e", tr("歌詞を編集")), hint("n/"+keyName(keymap.back), tr("タグ編集に戻る")), hint("?", tr("ヘルプ")))
+		case len(info.audioTracks()) > 1:
+			return footer(hint("y/"+keyName(keymap.confirm), tr("ダウンロード")), hint("l", tr("音声トラック切替")), hint("e", tr("歌詞")), hint("t", tr("トリム")), hint("n/"+keyName(keymap.back), tr("タグ編集に戻る")), hint("?", tr("ヘルプ")))
+		case m.offerCaptions():
+			return footer(hint("y/"+keyName(keymap.confirm), tr("ダウンロード")), hint("c", tr("字幕から歌詞を作成")), hint("e", tr("歌詞を入力")), hint("t", tr("トリム")), hint("n/"+keyName(keymap.back), tr("タグ編集に戻る")), hint("?", tr("ヘルプ")))
+		case m.pendingTags.Lyrics != nil:
+			return footer(hint("y/"+keyName(keymap.confirm), tr("ダウンロード")), hint("e", tr("歌詞を編集")), hint("t", tr("トリム")), hint("n/"+keyName(keymap.back), tr("タグ編集に戻る")), hint("?", tr("ヘルプ")))
+		}
+		return footer(hint("y/"+keyName(keymap.confirm), tr("ダウンロード")), hint("t", tr("トリム")), hint("r", tr("ローマ字")), hint("n/"+keyName(keymap.back), tr("タグ編集に戻る")), hint("?", tr("ヘルプ")))
+	case stateHistory:
+		if m.noteEditing {
+			return helpStyle.Render(tr("  Enter: 保存 | Esc: キャンセル"))
+		} else if m.historyTyping {
+			return helpStyle.Render(tr("  Enter: 適用 | Esc: キャンセル | 例: artist:名前 format:flac from:2024-01-01 rating:4 failed"))
+		}
+		return helpStyle.Render(tr("  f: フィルタ | t: 今日 | w: 今週 | x: 失敗のみ | 1-5: 評価 | n: メモ | c: 整理候補 | r: 一括置換 | Esc: 戻る | ?: ヘルプ"))
+	case stateSelectMB:
+		return footer(hint(keyName(keymap.confirm), tr("決定")), hint("a", tr("自動照合")), hint(keyName(keymap.skipMB), tr("スキップ")), hint("m", tr("続き")), hint("c/o/t/r", tr("条件")), hint("g", tr("版の一覧")), hint("/", tr("絞り込み")), hint(keyName(keymap.back), tr("戻る")), hint("?", tr("ヘルプ")))
+	case stateSelectTrack:
+		return footer(hint(keyName(keymap.confirm), tr("決定")), hint("Space", tr("複数選択")), hint("q", tr("キューに追加")), hint("w", tr("1ファイルで保存")), hint("/", tr("絞り込み")), hint(keyName(keymap.back), tr("戻る")), hint("?", tr("ヘルプ")))
+	case stateSelectYT:
+		if !m.batchActive() {
+			return footer(hint(keyName(keymap.confirm), tr("決定")), hint("a", tr("自動照合")), hint("m", tr("続き")), hint("/", tr("絞り込み")), hint(keyName(keymap.back), tr("戻る")), hint("?", tr("ヘルプ")))
+		}
+		return footer(hint(keyName(keymap.confirm), tr("決定")), hint(keyName(keymap.back), tr("スキップ")), hint("m", tr("続き")), hint(keyName(keymap.queue), tr("キュー")), hint("?", tr("ヘルプ")))
+	case stateEditTags:
+		if m.lastfmLine() != "" && !m.lastfmApplied {
+			return footer(hint(keyName(keymap.confirm), tr("次へ/決定")), hint("Ctrl+T", tr("Last.fmを採用")), hint(keyName(keymap.back), tr("戻る")), hint("F1", tr("ヘルプ")))
+		}
+		return footer(hint(keyName(keymap.confirm), tr("次へ/決定")), hint(keyName(keymap.back), tr("戻る")), hint("F1", tr("ヘルプ")))
+	case stateError:
+		return helpStyle.Render(tr("  何かキーを押すと最初の画面に戻ります..."))
+	}
+	return ""
+}
+
+// prefetchLyrics looks up lyrics for the track as initially tagged so the editor can show their status.
+func (m *model) prefetchLyrics() tea.Cmd {
+	tags := buildTags(m.selectedMB.meta.(MBRelease), m.selectedTrack)
+	m.lyricsKey, m.lyricsBusy, m.lyricsInfo = lyricsKey(tags), true, lyricsResult{}
+	return fetchLyricsCmd(tags)
+}
+
 func (m *model) createTagInputs() []textinput.Model {
 	inputs := make([]textinput.Model, 5)
 	releaseInfo := m.selectedMB.meta.(MBRelease)
@@ -494,9 +1391,24 @@ func newList(title string, items []list.Item) list.Model {
 	l.Styles.Title = listTitleStyle
 	l.Styles.PaginationStyle = list.DefaultStyles().PaginationStyle.PaddingLeft(4)
 	l.SetShowHelp(false)
+	// 終了は Update で扱う (リスト既定の q / Esc で終了させない)
+	l.DisableQuitKeybindings()
 	return l
 }
 
+// filterKey reports whether a key on a result list goes to its "/" filter: every key while the filter
+// is being typed, and Back while one is applied, which clears it instead of leaving the screen.
+func filterKey(l *list.Model, msg tea.KeyMsg) bool {
+	switch {
+	case l.FilterState() == list.Filtering:
+		return true
+	case l.FilterState() == list.FilterApplied && key.Matches(msg, keymap.back):
+		l.ResetFilter()
+		return true
+	}
+	return false
+}
+
 func joinArtistCredits(credits []MBArtist) string {
 	var b strings.Builder
 	for _, credit := range credits {
@@ -506,6 +1418,36 @@ func joinArtistCredits(credits []MBArtist) string {
 	return b.String()
 }
 
+// releaseLabel returns the first label name and catalog number credited on the release.
+func releaseLabel(r MBRelease) (label, catalogNumber string) {
+	for _, li := range r.LabelInfo {
+		if label == "" {
+			label = li.Label.Name
+		}
+		if catalogNumber == "" {
+			catalogNumber = li.CatalogNumber
+		}
+	}
+	return label, catalogNumber
+}
+
+// trackDiscInfo locates the medium holding the track and returns its disc position plus the disc and track totals.
+func trackDiscInfo(r MBRelease, trackID string) (disc, discTotal, trackTotal int) {
+	discTotal = len(r.Media)
+	for _, media := range r.Media {
+		for _, t := range media.Tracks {
+			if t.ID == trackID {
+				trackTotal = media.TrackCount
+				if trackTotal == 0 {
+					trackTotal = len(media.Tracks)
+				}
+				return media.Position, discTotal, trackTotal
+			}
+		}
+	}
+	return 0, discTotal, 0
+}
+
 func checkYtDlpCmd() tea.Msg {
 	path, err := exec.LookPath("yt-dlp")
 	if err == nil {
@@ -518,10 +1460,11 @@ func checkYtDlpCmd() tea.Msg {
 	if _, err := os.Stat(localPath); err == nil {
 		return ytDlpCheckResultMsg{path: "./" + localPath}
 	}
-	return ytDlpCheckResultMsg{err: fmt.Errorf("yt-dlpが見つかりません。パスが通っているか、実行ファイルと同じフォルダに配置してください。")}
+	return ytDlpCheckResultMsg{err: errorf("yt-dlpが見つかりません。パスが通っているか、実行ファイルと同じフォルダに配置してください。")}
 }
+func findFfmpeg() (string, error) { return exec.LookPath("ffmpeg") }
 func checkFfmpegCmd() tea.Msg {
-	path, err := exec.LookPath("ffmpeg")
+	path, err := findFfmpeg()
 	if err != nil {
 		return ffmpegCheckResultMsg{err: err}
 	}
@@ -529,60 +1472,147 @@ func checkFfmpegCmd() tea.Msg {
 }
 func getURLInfoCmd(ytDlpPath, query string) tea.Cmd {
 	return func() tea.Msg {
+		if remoteEnabled() {
+			return remoteURLInfo(query)
+		}
 		ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
 		defer cancel()
-		cmd := exec.CommandContext(ctx, ytDlpPath, "--quiet", "--no-warnings", "--dump-json", query)
+		args := []string{"--quiet", "--no-warnings", "--dump-json"}
+		if isYTMusicURL(query) {
+			// YouTube Music の共有リンクには再生中のキュー (list=) が付いていることが多いので、曲だけを読む
+			args = append(args, "--no-playlist")
+		}
+		cmd := ytDlpCommand(ctx, ytDlpPath, append(args, query)...)
 		output, err := cmd.CombinedOutput()
 		if err != nil {
 			if ctx.Err() == context.DeadlineExceeded {
-				return urlInfoFetchedMsg{err: fmt.Errorf("URL情報の取得がタイムアウトしました (30s)")}
+				return urlInfoFetchedMsg{err: errorf("URL情報の取得がタイムアウトしました (30s)")}
 			}
-			return urlInfoFetchedMsg{err: fmt.Errorf("URL情報の取得に失敗:\n%s", string(output))}
+			return urlInfoFetchedMsg{err: errorf("URL情報の取得に失敗:\n%s", string(output))}
 		}
 		var info ytDlpVideoInfo
 		if err := json.Unmarshal(output, &info); err != nil {
-			return urlInfoFetchedMsg{err: fmt.Errorf("URL情報のJSON解析に失敗:\n%v", err)}
-		}
-		artist := info.Uploader
-		if artist == "" {
-			artist = info.Channel
+			return urlInfoFetchedMsg{err: errorf("URL情報のJSON解析に失敗:\n%v", err)}
 		}
-		item := item{title: info.Title, desc: artist, id: info.ID, url: query}
-		return urlInfoFetchedMsg{ytItem: item}
+		return urlInfoFetchedMsg{ytItem: videoItem(info, query)}
+	}
+}
+
+// videoItem is the list row for a video yt-dlp described.
+func videoItem(info ytDlpVideoInfo, url string) item {
+	artist := info.Uploader
+	if artist == "" {
+		artist = info.Channel
 	}
+	return item{title: info.Title, desc: artist, id: info.ID, url: url, meta: info, badge: liveBadge(info)}
+}
+
+// videoDetails is the length, view count and upload date shown after the channel in the list.
+func videoDetails(info ytDlpVideoInfo) string {
+	var parts []string
+	if info.Duration > 0 {
+		parts = append(parts, formatDuration(int(info.Duration)))
+	}
+	if info.ViewCount > 0 {
+		parts = append(parts, tr("%s回視聴", groupDigits(info.ViewCount)))
+	}
+	if d := info.UploadDate; len(d) == 8 {
+		parts = append(parts, d[:4]+"-"+d[4:6]+"-"+d[6:])
+	}
+	return strings.Join(parts, " · ")
+}
+
+// groupDigits writes n with thousands separators (1234567 as 1,234,567).
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
 }
 func doMusicBrainzSearch(query string) ([]list.Item, error) {
-	apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release/?query=%s&fmt=json&inc=artist-credits+release-groups", url.QueryEscape(query))
-	req, _ := http.NewRequest("GET", apiURL, nil)
-	req.Header.Set("User-Agent", "GoMusicDownloader/1.7 ( your-contact-info@example.com )")
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	return doMusicBrainzSearchPage(query, 0, cfg.Search.MusicBrainzFilter)
+}
+
+// doMusicBrainzSearchPage searches releases matching the filter, mbSearchPage() at a time; page counts from 0.
+func doMusicBrainzSearchPage(query string, page int, filter mbReleaseFilter) ([]list.Item, error) {
+	items, err := searchReleasesPage(query, page, filter)
+	if relaxed, ok := relaxedQuery(query); ok && err == nil && len(items) == 0 {
+		log.Printf("MusicBrainz: nothing by the guessed artist, retrying %q", relaxed)
+		return searchReleasesPage(relaxed, page, filter)
+	}
+	return items, err
+}
+
+func searchReleasesPage(query string, page int, filter mbReleaseFilter) ([]list.Item, error) {
+	if filter.Recordings {
+		return doRecordingSearch(query, page, filter)
 	}
-	defer resp.Body.Close()
+	apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release/?query=%s&fmt=json&inc=artist-credits+release-groups&limit=%d&offset=%d",
+		url.QueryEscape(filter.query(query)), mbSearchPage(), page*mbSearchPage())
 	var data MusicBrainzSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if err := mbGetJSON(apiURL, &data); err != nil {
 		return nil, err
 	}
 	var items []list.Item
+	owned := ownedTracks()
 	for _, r := range data.Releases {
 		artist := joinArtistCredits(r.ArtistCredit)
 		desc := fmt.Sprintf("%s (%s) [%s]", artist, r.Date, r.ReleaseGroup.PrimaryType)
-		items = append(items, item{title: r.Title, desc: desc, id: r.ID, meta: r})
+		items = append(items, item{title: r.Title, desc: desc, id: r.ID, meta: r, badge: completionBadge(owned[r.ID], releaseTrackCount(r))})
 	}
 	return items, nil
 }
-func searchMusicBrainzCmd(query string) tea.Cmd {
+func searchMusicBrainzCmd(query string, filter mbReleaseFilter) tea.Cmd {
 	return func() tea.Msg {
-		items, err := doMusicBrainzSearch(query)
+		items, err := doMusicBrainzSearchPage(query, 0, filter)
 		if err != nil {
-			return mbSearchFinishedMsg{err: err}
+			return mbSearchFinishedMsg{query: query, err: err}
+		}
+		return mbSearchFinishedMsg{query: query, items: items}
+	}
+}
+func doYouTubeSearch(ytDlpPath, query string) ([]list.Item, error) {
+	return doYouTubeSearchPage(ytDlpPath, query, 0)
+}
+
+// doYouTubeSearchPage searches videos, ytSearchPage() at a time; page counts from 0. Videos the search
+// settings rule out are left out of the page.
+func doYouTubeSearchPage(ytDlpPath, query string, page int) ([]list.Item, error) {
+	if remoteEnabled() {
+		items, err := remoteYouTubeSearch(query, page)
+		return filterYouTubeResults(items, query), err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+	defer cancel()
+	first, last := page*ytSearchPage()+1, (page+1)*ytSearchPage()
+	cmd := ytDlpCommand(ctx, ytDlpPath, "--quiet", "--no-warnings", "--dump-json", "--default-search", fmt.Sprintf("ytsearch%d", last),
+		"--playlist-items", fmt.Sprintf("%d-%d", first, last), query)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errorf("YouTube検索がタイムアウトしました")
+		}
+		return nil, errorf("YouTube検索に失敗:\n%s", string(output))
+	}
+	var items []list.Item
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for _, line := range lines {
+		var info ytDlpVideoInfo
+		if err := json.Unmarshal([]byte(line), &info); err != nil {
+			continue
 		}
-		return mbSearchFinishedMsg{items: items}
+		items = append(items, videoItem(info, "https://www.youtube.com/watch?v="+info.ID))
 	}
+	return filterYouTubeResults(items, query), nil
 }
-func searchCmd(ytDlpPath, query string) tea.Cmd {
+func searchYouTubeCmd(ytDlpPath, query string) tea.Cmd {
+	return func() tea.Msg {
+		items, err := doYouTubeSearch(ytDlpPath, query)
+		return ytSearchFinishedMsg{query: query, items: items, err: err}
+	}
+}
+func searchCmd(ytDlpPath, query string, filter mbReleaseFilter) tea.Cmd {
 	return func() tea.Msg {
 		var wg sync.WaitGroup
 		wg.Add(2)
@@ -590,36 +1620,11 @@ func searchCmd(ytDlpPath, query string) tea.Cmd {
 		var ytErr, mbErr error
 		go func() {
 			defer wg.Done()
-			ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
-			defer cancel()
-			cmd := exec.CommandContext(ctx, ytDlpPath, "--quiet", "--no-warnings", "--dump-json", "--default-search", "ytsearch5", query)
-			output, err := cmd.CombinedOutput()
-			if err != nil {
-				if ctx.Err() == context.DeadlineExceeded {
-					ytErr = fmt.Errorf("YouTube検索がタイムアウトしました")
-				} else {
-					ytErr = fmt.Errorf("YouTube検索に失敗:\n%s", string(output))
-				}
-				return
-			}
-			var items []list.Item
-			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-			for _, line := range lines {
-				var info ytDlpVideoInfo
-				if err := json.Unmarshal([]byte(line), &info); err != nil {
-					continue
-				}
-				artist := info.Uploader
-				if artist == "" {
-					artist = info.Channel
-				}
-				items = append(items, item{title: info.Title, desc: artist, id: info.ID, url: "https://www.youtube.com/watch?v=" + info.ID})
-			}
-			ytItems = items
+			ytItems, ytErr = doYouTubeSearch(ytDlpPath, query)
 		}()
 		go func() {
 			defer wg.Done()
-			mbItems, mbErr = doMusicBrainzSearch(query)
+			mbItems, mbErr = doMusicBrainzSearchPage(query, 0, filter)
 		}()
 		wg.Wait()
 		if ytErr != nil {
@@ -628,205 +1633,40 @@ func searchCmd(ytDlpPath, query string) tea.Cmd {
 		if mbErr != nil {
 			return searchFinishedMsg{err: mbErr}
 		}
-		return searchFinishedMsg{ytItems: ytItems, mbItems: mbItems}
-	}
-}
-func getTracklistCmd(releaseID string) tea.Cmd {
-	return func() tea.Msg {
-		apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release/%s?inc=artist-credits+media+recordings&fmt=json", releaseID)
-		req, _ := http.NewRequest("GET", apiURL, nil)
-		req.Header.Set("User-Agent", "GoMusicDownloader/1.7 ( your-contact-info@example.com )")
-		client := &http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			return tracklistFinishedMsg{err: err}
-		}
-		defer resp.Body.Close()
-		var releaseData MBRelease
-		if err := json.NewDecoder(resp.Body).Decode(&releaseData); err != nil {
-			return tracklistFinishedMsg{err: err}
-		}
-		var items []list.Item
-		artist := joinArtistCredits(releaseData.ArtistCredit)
-		for _, media := range releaseData.Media {
-			for _, t := range media.Tracks {
-				desc := fmt.Sprintf("Track %s", t.Number)
-				if media.Format != "" {
-					desc = fmt.Sprintf("Track %s (%s)", t.Number, media.Format)
-				}
-				items = append(items, item{title: t.Title, desc: desc, meta: t, artist: artist})
-			}
-		}
-		return tracklistFinishedMsg{items: items}
+		return searchFinishedMsg{query: query, ytItems: ytItems, mbItems: mbItems}
 	}
 }
-func getLyrics(artist, title, album string, duration int) string {
-	apiURL := "https://lrclib.net/api/get"
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		log.Printf("Lyrics: Failed to create request: %v", err)
-		return ""
-	}
-	q := req.URL.Query()
-	q.Add("track_name", title)
-	q.Add("artist_name", artist)
-	q.Add("album_name", album)
-	q.Add("duration", fmt.Sprintf("%d", duration))
-	req.URL.RawQuery = q.Encode()
-
-	log.Printf("Lyrics: Calling API: %s", req.URL.String())
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Printf("Lyrics: API request failed: %v", err)
-		return ""
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Lyrics: API returned non-200 status: %s", resp.Status)
-		return ""
-	}
-
-	var data LrclibResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		log.Printf("Lyrics: Failed to decode JSON response: %v", err)
-		return ""
+func fetchTracklist(releaseID string) ([]list.Item, MBRelease, error) {
+	apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release/%s?inc=%s&fmt=json", releaseID, releaseLookupIncludes(cfg.MusicBrainz))
+	var releaseData MBRelease
+	if err := mbGetJSON(apiURL, &releaseData); err != nil {
+		return nil, releaseData, err
 	}
-	return data.PlainLyrics
-}
-func simpleDownloadCmd(ytDlpPath, ffmpegPath string, selectedYT item) tea.Cmd {
-	return func() tea.Msg {
-		tmpDirPath := filepath.Join(mainDir, tempDir)
-		tmpDir, err := os.MkdirTemp(tmpDirPath, "gomusicdl_*")
-		if err != nil {
-			return downloadFinishedMsg{err: err}
-		}
-		defer os.RemoveAll(tmpDir)
-		audioPath := filepath.Join(tmpDir, "audio.tmp")
-		ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout*2) // ダウンロードは長めに
-		defer cancel()
-		dlCmd := exec.CommandContext(ctx, ytDlpPath, "-f", "bestaudio", "-o", audioPath, selectedYT.url)
-		if out, err := dlCmd.CombinedOutput(); err != nil {
-			return downloadFinishedMsg{err: fmt.Errorf("音声のダウンロード失敗:\n%s", string(out))}
-		}
-		downloadsPath := filepath.Join(mainDir, downloadsDir)
-		finalFilename := sanitizeFilename(fmt.Sprintf("%s.flac", selectedYT.title))
-		finalPath := filepath.Join(downloadsPath, finalFilename)
-		convCmd := exec.Command(ffmpegPath, "-y", "-i", audioPath, "-c:a", "flac", finalPath)
-		if out, err := convCmd.CombinedOutput(); err != nil {
-			return downloadFinishedMsg{err: fmt.Errorf("ffmpegでの変換失敗:\n%s", string(out))}
+	var items []list.Item
+	artist := joinArtistCredits(releaseData.ArtistCredit)
+	for _, media := range releaseData.Media {
+		for _, t := range media.Tracks {
+			desc := fmt.Sprintf("Track %s", t.Number)
+			if len(releaseData.Media) > 1 {
+				desc = fmt.Sprintf("Disc %d Track %s", media.Position, t.Number)
+			}
+			if media.Format != "" {
+				desc = fmt.Sprintf("%s (%s)", desc, media.Format)
+			}
+			items = append(items, item{title: t.Title, desc: desc, meta: t, artist: artist})
 		}
-		return downloadFinishedMsg{filename: finalPath}
 	}
+	return items, releaseData, nil
 }
-func downloadCmd(ytDlpPath, ffmpegPath string, selectedYT, selectedMB item, tags finalTags) tea.Cmd {
+func getTracklistCmd(releaseID string) tea.Cmd {
 	return func() tea.Msg {
-		var wg sync.WaitGroup
-		wg.Add(3)
-		var audioPath, coverPath, lyrics string
-		var dlErr error
-
-		tmpDirPath := filepath.Join(mainDir, tempDir)
-		tmpDir, err := os.MkdirTemp(tmpDirPath, "gomusicdl_*")
+		items, releaseData, err := fetchTracklist(releaseID)
 		if err != nil {
-			return downloadFinishedMsg{err: err}
-		}
-		defer os.RemoveAll(tmpDir)
-
-		go func() {
-			defer wg.Done()
-			audioPath = filepath.Join(tmpDir, "audio.tmp")
-			ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout*2)
-			defer cancel()
-			dlCmd := exec.CommandContext(ctx, ytDlpPath, "-f", "bestaudio", "-o", audioPath, selectedYT.url)
-			if out, err := dlCmd.CombinedOutput(); err != nil {
-				dlErr = fmt.Errorf("音声のダウンロード失敗:\n%s", string(out))
-			}
-		}()
-
-		go func() {
-			defer wg.Done()
-			releaseInfo := selectedMB.meta.(MBRelease)
-			coverURL := fmt.Sprintf("https://coverartarchive.org/release/%s/front-500", releaseInfo.ID)
-			resp, err := http.Get(coverURL)
-			if err == nil && resp.StatusCode == 200 {
-				localPath := filepath.Join(tmpDir, "cover.jpg")
-				file, _ := os.Create(localPath)
-				io.Copy(file, resp.Body)
-				file.Close()
-				coverPath = localPath
-			}
-			if resp != nil {
-				resp.Body.Close()
-			}
-
-			if coverPath == "" && releaseInfo.ReleaseGroup.ID != "" {
-				coverGroupURL := fmt.Sprintf("https://coverartarchive.org/release-group/%s/front-500", releaseInfo.ReleaseGroup.ID)
-				resp, err = http.Get(coverGroupURL)
-				if err == nil && resp.StatusCode == 200 {
-					localPath := filepath.Join(tmpDir, "cover.jpg")
-					file, _ := os.Create(localPath)
-					io.Copy(file, resp.Body)
-					file.Close()
-					coverPath = localPath
-				}
-				if resp != nil {
-					resp.Body.Close()
-				}
-			}
-		}()
-
-		go func() {
-			defer wg.Done()
-			lyrics = getLyrics(tags.Artist, tags.Title, tags.Album, tags.DurationSec)
-		}()
-
-		wg.Wait()
-
-		if dlErr != nil {
-			return downloadFinishedMsg{err: dlErr}
-		}
-
-		downloadsPath := filepath.Join(mainDir, downloadsDir)
-		finalFilename := sanitizeFilename(fmt.Sprintf("%s - %s.flac", tags.Artist, tags.Title))
-		finalPath := filepath.Join(downloadsPath, finalFilename)
-
-		ffmpegArgs := []string{"-y", "-i", audioPath}
-		if coverPath != "" {
-			ffmpegArgs = append(ffmpegArgs, "-i", coverPath, "-map", "0:a:0", "-map", "1:v:0", "-disposition:v", "attached_pic")
-		}
-		ffmpegArgs = append(ffmpegArgs,
-			"-c:a", "flac",
-			"-metadata", fmt.Sprintf("title=%s", tags.Title),
-			"-metadata", fmt.Sprintf("artist=%s", tags.Artist),
-			"-metadata", fmt.Sprintf("album_artist=%s", tags.AlbumArtist),
-			"-metadata", fmt.Sprintf("album=%s", tags.Album),
-			"-metadata", fmt.Sprintf("track=%s", tags.TrackNumber),
-			"-metadata", fmt.Sprintf("date=%s", tags.Date),
-		)
-		if lyrics != "" {
-			ffmpegArgs = append(ffmpegArgs, "-metadata", fmt.Sprintf("LYRICS=%s", lyrics))
-		}
-		ffmpegArgs = append(ffmpegArgs, finalPath)
-
-		convCmd := exec.Command(ffmpegPath, ffmpegArgs...)
-		if out, err := convCmd.CombinedOutput(); err != nil {
-			return downloadFinishedMsg{err: fmt.Errorf("ffmpegでの変換失敗:\n%s", string(out))}
-		}
-
-		finalMsg := finalPath
-		if lyrics != "" {
-			finalMsg += " (歌詞付き)"
+			return tracklistFinishedMsg{err: err}
 		}
-		return downloadFinishedMsg{filename: finalMsg}
+		return tracklistFinishedMsg{items: items, release: releaseData}
 	}
 }
-func sanitizeFilename(name string) string {
-	r := strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "'", "<", "-", ">", "-", "|", "-")
-	return r.Replace(name)
-}
 func setupAppDirs() error {
 	dirs := []string{mainDir, filepath.Join(mainDir, downloadsDir), filepath.Join(mainDir, tempDir), filepath.Join(mainDir, logsDir)}
 	for _, dir := range dirs {
@@ -838,19 +1678,53 @@ func setupAppDirs() error {
 }
 func main() {
 	if err := setupAppDirs(); err != nil {
-		fmt.Printf("ディレクトリの作成に失敗しました: %v\n", err)
+		fmt.Print(tr("ディレクトリの作成に失敗しました: %v\n", err))
 		os.Exit(1)
 	}
 	logPath := filepath.Join(mainDir, logsDir, "debug.log")
 	f, err := tea.LogToFile(logPath, "debug")
 	if err != nil {
-		fmt.Printf("ログファイルの作成に失敗しました: %v\n", err)
+		fmt.Print(tr("ログファイルの作成に失敗しました: %v\n", err))
 		os.Exit(1)
 	}
 	defer f.Close()
-	p := tea.NewProgram(newModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Printf("アプリケーションエラー: %v", err)
+	if cfg, err = loadConfig(); err != nil {
+		log.Printf("Config: failed to load %s, using defaults: %v", configPath(), err)
+	}
+	setupLanguage()
+	setupTheme()
+	if err := setupKeys(); err != nil {
+		fmt.Fprint(os.Stderr, tr("エラー: %v\n", err))
+		os.Exit(1)
+	}
+	setupHTTPClient()
+	pruneHTTPCache()
+	args, err := takeYtDlpArgsFlag(os.Args[1:])
+	if err != nil {
+		fmt.Fprint(os.Stderr, tr("エラー: %v\n", err))
+		os.Exit(1)
+	}
+	if len(args) > 0 {
+		err := runSubcommand(args[0], args[1:])
+		flushUploads()
+		flushMediaRefresh()
+		if err != nil {
+			fmt.Fprint(os.Stderr, tr("エラー: %v\n", err))
+			os.Exit(1)
+		}
+		return
+	}
+	tuiMode = true
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithReportFocus()}
+	if cfg.UI.Mouse {
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	p := tea.NewProgram(newModel(), opts...)
+	_, err = p.Run()
+	flushUploads()
+	flushMediaRefresh()
+	if err != nil {
+		fmt.Print(tr("アプリケーションエラー: %v", err))
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// --- 共有用マニフェスト ---
+// 音声は含めず、MBIDと動画IDとタグだけを書き出す。受け取った側は import-manifest で同じ曲を自分で取得する。
+const manifestVersion = 1
+
+type shareManifest struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Tracks  []manifestTrack `json:"tracks"`
+}
+
+type manifestTrack struct {
+	VideoID     string `json:"video_id"`
+	ReleaseID   string `json:"release_id,omitempty"`
+	RecordingID string `json:"recording_id,omitempty"`
+	Title       string `json:"title"`
+	Artist      string `json:"artist"`
+	Album       string `json:"album,omitempty"`
+	Date        string `json:"date,omitempty"`
+}
+
+func runExportManifest(args []string) error {
+	fs := flag.NewFlagSet("export-manifest", flag.ContinueOnError)
+	out := fs.String("out", filepath.Join(mainDir, "manifest.json"), tr("出力先ファイル"))
+	query := fs.String("filter", "", tr("履歴のフィルタ (例: \"artist:名前 from:2024-01-01\")"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	filter, err := parseHistoryFilter(*query, time.Now())
+	if err != nil {
+		return err
+	}
+	loaded := loadHistoryCmd().(historyLoadedMsg)
+	if loaded.err != nil {
+		return errorf("履歴の読み込みに失敗: %w", loaded.err)
+	}
+	m := shareManifest{Version: manifestVersion, Created: time.Now()}
+	seen := map[string]bool{}
+	rows := filterHistory(loaded.rows, filter)
+	for i := len(rows) - 1; i >= 0; i-- { // 古い順に並べる
+		e := rows[i].entry
+		key := e.VideoID + "\x00" + e.ReleaseID + "\x00" + e.RecordingID
+		if e.status() != statusOK || e.VideoID == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		m.Tracks = append(m.Tracks, manifestTrack{
+			VideoID: e.VideoID, ReleaseID: e.ReleaseID, RecordingID: e.RecordingID,
+			Title: e.Title, Artist: e.Artist, Album: e.Album, Date: e.Date,
+		})
+	}
+	if len(m.Tracks) == 0 {
+		return errorf("書き出せるダウンロード履歴がありません")
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Print(tr("%d曲をマニフェストに書き出しました: %s\n", len(m.Tracks), *out))
+	return nil
+}
+
+func runImportManifest(args []string) error {
+	fs := flag.NewFlagSet("import-manifest", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, tr("ダウンロードせずに対象の曲を表示する"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errorf("使い方: import-manifest [-dry-run] <マニフェストのファイル>")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var m shareManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return errorf("マニフェストの解析に失敗: %w", err)
+	}
+	if m.Version > manifestVersion {
+		return errorf("このバージョンでは読めないマニフェストです (version %d)", m.Version)
+	}
+
+	ytCheck := checkYtDlpCmd().(ytDlpCheckResultMsg)
+	ffmpegPath, ffErr := findFfmpeg()
+	if !*dryRun {
+		if ytCheck.err != nil {
+			return ytCheck.err
+		}
+		if ffErr != nil {
+			return errorf("ffmpegが見つかりません: %w", ffErr)
+		}
+	}
+	have := map[string]bool{}
+	if loaded := loadHistoryCmd().(historyLoadedMsg); loaded.err == nil {
+		for _, r := range loaded.rows {
+			if r.exists && r.entry.status() == statusOK {
+				have[r.entry.VideoID+"\x00"+r.entry.RecordingID] = true
+			}
+		}
+	}
+
+	releases := map[string]MBRelease{}
+	tracklists := map[string][]list.Item{}
+	var done, skipped, failed int
+	for n, t := range m.Tracks {
+		label := fmt.Sprintf("[%d/%d] %s - %s", n+1, len(m.Tracks), t.Artist, t.Title)
+		if have[t.VideoID+"\x00"+t.RecordingID] {
+			fmt.Print(tr("%s: 取得済みのためスキップ\n", label))
+			skipped++
+			continue
+		}
+		if *dryRun {
+			fmt.Printf("%s (https://www.youtube.com/watch?v=%s)\n", label, t.VideoID)
+			continue
+		}
+		tags, release, err := manifestTags(t, releases, tracklists)
+		if err != nil {
+			fmt.Print(tr("%s: 失敗 (%v)\n", label, err))
+			failed++
+			continue
+		}
+		yt := item{title: t.Title, id: t.VideoID, url: "https://www.youtube.com/watch?v=" + t.VideoID}
+		res := downloadCmd(ytCheck.path, ffmpegPath, yt, release, tags)().(downloadFinishedMsg)
+		if res.err != nil {
+			fmt.Print(tr("%s: 失敗 (%v)\n", label, res.err))
+			failed++
+			continue
+		}
+		fmt.Printf("%s: %s\n", label, res.filename)
+		if res.warning != "" {
+			fmt.Printf("  %s\n", res.warning)
+		}
+		done++
+	}
+	if !*dryRun {
+		fmt.Print(tr("完了: %d曲 / スキップ: %d曲 / 失敗: %d曲\n", done, skipped, failed))
+	}
+	return nil
+}
+
+// manifestTags rebuilds the tags from MusicBrainz so the copy gets the full metadata, then applies the
+// manifest's own title/artist/album/date, which carry any edits the sharer made.
+func manifestTags(t manifestTrack, releases map[string]MBRelease, tracklists map[string][]list.Item) (finalTags, item, error) {
+	tags := finalTags{Title: t.Title, Artist: t.Artist, AlbumArtist: t.Artist, Album: t.Album, Date: t.Date}
+	if t.ReleaseID == "" {
+		return tags, item{meta: MBRelease{}}, nil
+	}
+	if _, ok := releases[t.ReleaseID]; !ok {
+		items, release, err := fetchTracklist(t.ReleaseID)
+		if err != nil {
+			return tags, item{}, errorf("MusicBrainzの取得に失敗: %w", err)
+		}
+		releases[t.ReleaseID], tracklists[t.ReleaseID] = release, items
+	}
+	release := releases[t.ReleaseID]
+	releaseItem := item{title: release.Title, id: release.ID, meta: release}
+	for _, li := range tracklists[t.ReleaseID] {
+		track := li.(item)
+		info := track.meta.(MBTrack)
+		if (t.RecordingID != "" && info.Recording.ID == t.RecordingID) || (t.RecordingID == "" && info.Title == t.Title) {
+			full := buildTags(release, track)
+			full.Title, full.Artist, full.Album = firstNonEmpty(t.Title, full.Title), firstNonEmpty(t.Artist, full.Artist), firstNonEmpty(t.Album, full.Album)
+			full.Date = firstNonEmpty(t.Date, full.Date)
+			return full, releaseItem, nil
+		}
+	}
+	return tags, releaseItem, nil
+}
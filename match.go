@@ -0,0 +1,208 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- YouTube結果とMusicBrainzトラックの自動照合 ---
+const (
+	matchWeightTitle    = 0.5
+	matchWeightDuration = 0.3
+	matchWeightArtist   = 0.2
+	matchMinScore       = 0.45
+	autoMatchReleases   = 3 // トラックリストを取得するリリース数の上限
+	// 語として含まれるときの最低点。残りは含まれる側に占める長さの割合 (の平方根) で足す
+	containmentFloor = 0.5
+)
+
+type matchScore struct {
+	Title, Duration, Artist, Total float64
+}
+
+func (s matchScore) String() string {
+	return tr("一致度 %d%% (タイトル %d%% / 長さ %d%% / アーティスト %d%%)",
+		pct(s.Total), pct(s.Title), pct(s.Duration), pct(s.Artist))
+}
+
+func pct(f float64) int { return int(math.Round(f * 100)) }
+
+type autoMatchFinishedMsg struct {
+	yt, release, track item
+	score              matchScore
+	err                error
+}
+
+// normalizeForMatch lowercases and keeps only letters and digits so punctuation and spacing don't affect similarity.
+func normalizeForMatch(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// similarity is the Dice coefficient over rune bigrams, which works for both spaced and CJK text.
+func similarity(a, b string) float64 {
+	a, b = normalizeForMatch(a), normalizeForMatch(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+	bigrams := func(s string) map[string]int {
+		r := []rune(s)
+		m := map[string]int{}
+		if len(r) == 1 {
+			m[s]++
+		}
+		for i := 0; i+1 < len(r); i++ {
+			m[string(r[i:i+2])]++
+		}
+		return m
+	}
+	ab, bb := bigrams(a), bigrams(b)
+	var inter, total int
+	for k, n := range ab {
+		total += n
+		if m, ok := bb[k]; ok {
+			inter += min(n, m)
+		}
+	}
+	for _, n := range bb {
+		total += n
+	}
+	return 2 * float64(inter) / float64(total)
+}
+
+// containmentScore rates needle appearing as whole words inside haystack, since YouTube titles usually
+// wrap the song title in extra words. The score grows with the share of the haystack the needle
+// covers, so a short word inside a long title doesn't count as a full match.
+func containmentScore(haystack, needle string) float64 {
+	sim := similarity(haystack, needle)
+	h, n := matchTokens(haystack), matchTokens(needle)
+	if len(n) == 0 || !containsTokens(h, n) {
+		return sim
+	}
+	ratio := float64(tokenRunes(n)) / float64(tokenRunes(h))
+	return math.Max(sim, containmentFloor+(1-containmentFloor)*math.Sqrt(ratio))
+}
+
+// matchTokens splits lowercased text into words of letters and digits. Kanji and kana count as one
+// word each, since Japanese doesn't space its words.
+func matchTokens(s string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// containsTokens reports whether needle appears in haystack as a run of consecutive words.
+func containsTokens(haystack, needle []string) bool {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		match := true
+		for j, t := range needle {
+			if haystack[i+j] != t {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func tokenRunes(tokens []string) int {
+	n := 0
+	for _, t := range tokens {
+		n += utf8.RuneCountInString(t)
+	}
+	return n
+}
+
+func durationScore(ytSec float64, mbMs int) float64 {
+	if ytSec <= 0 || mbMs <= 0 {
+		return 0.5 // 不明な場合は中立
+	}
+	delta := math.Abs(ytSec - float64(mbMs)/1000)
+	return math.Max(0, 1-delta/30)
+}
+
+func scoreMatch(yt item, track item) matchScore {
+	info, _ := yt.meta.(ytDlpVideoInfo)
+	trackInfo, _ := track.meta.(MBTrack)
+	s := matchScore{
+		Title:    containmentScore(yt.title, trackInfo.Title),
+		Duration: durationScore(info.Duration, trackInfo.Length),
+		Artist:   math.Max(containmentScore(yt.desc, track.artist), containmentScore(yt.title, track.artist)),
+	}
+	s.Total = s.Title*matchWeightTitle + s.Duration*matchWeightDuration + s.Artist*matchWeightArtist
+	return s
+}
+
+// autoMatchCmd fetches the tracklists of the top releases and picks the highest scoring YouTube/track pair.
+func autoMatchCmd(ytItems, releases []list.Item) tea.Cmd {
+	return func() tea.Msg {
+		var best autoMatchFinishedMsg
+		var lastErr error
+		for n, r := range releases {
+			if n >= autoMatchReleases {
+				break
+			}
+			release, ok := r.(item)
+			if !ok {
+				continue
+			}
+			tracks, releaseData, err := fetchTracklist(release.id)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			release.title, release.meta, release.itemType = releaseData.Title, releaseData, ""
+			for _, y := range ytItems {
+				yt, _ := y.(item)
+				for _, t := range tracks {
+					track, _ := t.(item)
+					if score := scoreMatch(yt, track); score.Total > best.score.Total {
+						best = autoMatchFinishedMsg{yt: yt, release: release, track: track, score: score}
+					}
+				}
+			}
+		}
+		if best.score.Total < matchMinScore {
+			if lastErr != nil {
+				return autoMatchFinishedMsg{err: lastErr}
+			}
+			// the best pair is kept so the daemon can still offer it for review
+			best.err = errorf("十分に一致する候補が見つかりませんでした (最高 %d%%)。手動で選択してください。", pct(best.score.Total))
+		}
+		return best
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestContainmentScore(t *testing.T) {
+	tests := []struct {
+		name, haystack, needle string
+		want                   float64
+	}{
+		{"same", "Lemon", "Lemon", 1},
+		{"case and punctuation", "LEMON!!", "lemon", 1},
+		{"word in a video title", "米津玄師 - Lemon (Official Video)", "Lemon", 0.7384},
+		{"most of the title", "Lemon (Live)", "Lemon", 0.8727},
+		{"small part of a long title", "Lemon - Very Long Title With Extra Words Live", "Lemon", 0.6863},
+		{"japanese in brackets", "YOASOBI「夜に駆ける」Official Music Video", "夜に駆ける", 0.7041},
+		{"japanese prefix", "夜に駆けるな", "夜に駆ける", 0.9564},
+		{"part of a word", "Lemonade", "Lemon", 8.0 / 11},
+		{"words out of order", "Blue Bird", "Bird Blue", 6.0 / 7},
+		{"empty needle", "Lemon", "", 0},
+		{"empty haystack", "", "Lemon", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containmentScore(tt.haystack, tt.needle); math.Abs(got-tt.want) > 0.0001 {
+				t.Errorf("containmentScore(%q, %q) = %.4f, want %.4f", tt.haystack, tt.needle, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainmentScoreOrder(t *testing.T) {
+	// 同じ曲名なら、タイトルに占める割合が大きいほど高い
+	titles := []string{"Lemon", "Lemon (Live)", "米津玄師 - Lemon (Official Video)", "Lemon - Very Long Title With Extra Words Live"}
+	for i := 1; i < len(titles); i++ {
+		prev, cur := containmentScore(titles[i-1], "Lemon"), containmentScore(titles[i], "Lemon")
+		if cur >= prev {
+			t.Errorf("%q scores %.4f, not below %q at %.4f", titles[i], cur, titles[i-1], prev)
+		}
+		if cur < containmentFloor {
+			t.Errorf("%q scores %.4f, below the floor %.2f", titles[i], cur, containmentFloor)
+		}
+	}
+}
+
+func TestMatchTokens(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Lemon (Official Video)", []string{"lemon", "official", "video"}},
+		{"AC/DC - T.N.T.", []string{"ac", "dc", "t", "n", "t"}},
+		{"夜に駆ける", []string{"夜", "に", "駆", "け", "る"}},
+		{"YOASOBI「群青」", []string{"yoasobi", "群", "青"}},
+		{"Track 01", []string{"track", "01"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := matchTokens(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchTokens(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDurationScore(t *testing.T) {
+	tests := []struct {
+		ytSec float64
+		mbMs  int
+		want  float64
+	}{
+		{0, 200000, 0.5},
+		{200, 0, 0.5},
+		{200, 200000, 1},
+		{215, 200000, 0.5},
+		{185, 200000, 0.5},
+		{230, 200000, 0},
+		{400, 200000, 0},
+	}
+	for _, tt := range tests {
+		if got := durationScore(tt.ytSec, tt.mbMs); math.Abs(got-tt.want) > 0.0001 {
+			t.Errorf("durationScore(%v, %d) = %.4f, want %.4f", tt.ytSec, tt.mbMs, got, tt.want)
+		}
+	}
+}
+
+func TestScoreMatch(t *testing.T) {
+	yt := item{
+		title: "YOASOBI「夜に駆ける」Official Music Video", desc: "Ayase / YOASOBI",
+		meta: ytDlpVideoInfo{Duration: 261},
+	}
+	tests := []struct {
+		name      string
+		track     item
+		wantMatch bool
+	}{
+		{"same song", item{artist: "YOASOBI", meta: MBTrack{Title: "夜に駆ける", Length: 261000}}, true},
+		{"same song, length unknown", item{artist: "YOASOBI", meta: MBTrack{Title: "夜に駆ける"}}, true},
+		{"other song by the artist", item{artist: "YOASOBI", meta: MBTrack{Title: "群青", Length: 248000}}, false},
+		{"other artist and length", item{artist: "Aimer", meta: MBTrack{Title: "カタオモイ", Length: 320000}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := scoreMatch(yt, tt.track)
+			want := s.Title*matchWeightTitle + s.Duration*matchWeightDuration + s.Artist*matchWeightArtist
+			if math.Abs(s.Total-want) > 1e-9 {
+				t.Errorf("Total = %.4f, want the weighted sum %.4f", s.Total, want)
+			}
+			if got := s.Total >= matchMinScore; got != tt.wantMatch {
+				t.Errorf("scoreMatch = %v, match %v, want %v", s, got, tt.wantMatch)
+			}
+		})
+	}
+}
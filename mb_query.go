@@ -0,0 +1,112 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// --- MusicBrainz の検索語 ---
+// YouTube のタイトルには「Official Music Video」「【MV】」「(Lyric Video)」「HD」のような曲名以外の語や、
+// 括弧書きの翻訳が付いていることが多く、そのまま検索すると目的のリリースが出てこない。
+// 検索の前にそれらとチャンネル名の「 - Topic」「VEVO」などを取り除き、Lucene の記号を空白にする。
+var (
+	// 括弧の中にこれらの語があれば、括弧ごと取り除く
+	noiseBracketRe = regexp.MustCompile(`(?i)official|music ?video|\bm/?v\b|\bp/?v\b|lyrics?|video|audio|visuali[sz]er|\bhd\b|\bhq\b|\b4k\b|full ?ver|\bsub(title)?s?\b|translat|romaji|romanized|color coded|公式|歌詞|字幕|和訳|日本語訳|中字|歌ってみた|フル|가사`)
+	// 括弧の外にあっても取り除く語
+	noiseWordsRe = regexp.MustCompile(`(?i)\bofficial\s+(music\s+)?(video|audio|mv|lyric\s+video|visualizer)\b|\b(music|lyric)\s+video\b|\bfull\s+ver(sion|\.)?|\b(mv|pv|hd|hq|4k)\b|公式`)
+	// 【】 [] () （） 〔〕 ［］ の括弧書き
+	bracketRe = regexp.MustCompile(`【[^】]*】|\[[^\]]*\]|\([^)]*\)|（[^）]*）|〔[^〕]*〕|［[^］]*］`)
+	// チャンネル名の末尾
+	channelSuffixRe = regexp.MustCompile(`(?i)\s*-\s*topic$|vevo$|\s*(official)?\s*(youtube)?\s*channel$|\s*official$|\s*公式(チャンネル)?$`)
+	luceneSpecial   = strings.NewReplacer(`+`, " ", `-`, " ", `&&`, " ", `||`, " ", `!`, " ", `(`, " ", `)`, " ", `{`, " ", `}`, " ",
+		`[`, " ", `]`, " ", `^`, " ", `"`, " ", `~`, " ", `*`, " ", `?`, " ", `:`, " ", `\`, " ", `/`, " ", `|`, " ")
+)
+
+// mbQueryFor builds the MusicBrainz search for a YouTube result (or a file to tag) from its title and
+// channel, without the noise around the song title. When the artist is known (from yt-dlp's song fields
+// or the title), the search requires it (see relaxedQuery for the fallback); a known album is preferred.
+func mbQueryFor(yt item) string {
+	if info, ok := yt.meta.(ytDlpVideoInfo); ok {
+		if song, ok := songFields(info); ok {
+			q := "(" + luceneText(song.Title) + mbArtistClause + luceneText(song.Artist) + ")"
+			if album := luceneText(song.Album); album != "" {
+				q += " release:(" + album + ")"
+			}
+			return q
+		}
+	}
+	if artist, title, ok := splitArtistTitle(yt); ok {
+		return "(" + luceneText(title) + mbArtistClause + luceneText(artist) + ")"
+	}
+	return luceneText(cleanTitle(yt.title) + " " + cleanChannel(yt.desc))
+}
+
+const mbArtistClause = ") AND artist:("
+
+// relaxedQuery turns the artist requirement of a query from mbQueryFor into a preference, for a second
+// try when the split guessed wrong and nothing matched.
+func relaxedQuery(q string) (string, bool) {
+	title, artist, ok := strings.Cut(q, mbArtistClause)
+	if !ok {
+		return "", false
+	}
+	return title + ") artist:(" + artist, true
+}
+
+// cleanTitle drops noise words and noisy or translated bracket groups from a video title, keeping the
+// original when nothing would be left.
+func cleanTitle(title string) string {
+	cleaned := strings.NewReplacer("「", " ", "」", " ", "『", " ", "』", " ").Replace(stripNoise(title))
+	if cleaned = strings.Join(strings.Fields(cleaned), " "); cleaned == "" {
+		return title
+	}
+	return cleaned
+}
+
+// stripNoise is cleanTitle without touching the 「」 quotes, which the artist/title split still needs.
+func stripNoise(title string) string {
+	outside := bracketRe.ReplaceAllString(title, " ")
+	cleaned := bracketRe.ReplaceAllStringFunc(title, func(group string) string {
+		inner := strings.TrimSpace(string([]rune(group)[1 : len([]rune(group))-1]))
+		if noiseBracketRe.MatchString(inner) || isTranslation(inner, outside) {
+			return " "
+		}
+		return " " + inner + " "
+	})
+	cleaned = noiseWordsRe.ReplaceAllString(cleaned, " ")
+	return strings.Join(strings.Fields(cleaned), " ")
+}
+
+// isTranslation reports whether a bracketed part is the title in another script: Latin words
+// beside a Japanese, Chinese or Korean title.
+func isTranslation(inner, outside string) bool {
+	return hasCJK(outside) && !hasCJK(inner) && len(strings.Fields(inner)) >= 2 &&
+		!strings.HasPrefix(strings.ToLower(inner), "feat") && !strings.HasPrefix(strings.ToLower(inner), "ft.")
+}
+
+func hasCJK(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanChannel drops " - Topic", "VEVO", "Official Channel" and the like from a channel name.
+func cleanChannel(channel string) string {
+	for {
+		cleaned := strings.TrimSpace(channelSuffixRe.ReplaceAllString(channel, ""))
+		if cleaned == channel || cleaned == "" {
+			return channel
+		}
+		channel = cleaned
+	}
+}
+
+// luceneText turns free text into search terms, so brackets, quotes and operators in titles don't
+// break the query syntax.
+func luceneText(s string) string {
+	return strings.Join(strings.Fields(luceneSpecial.Replace(s)), " ")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- メディアサーバーの再スキャン ---
+// downloads フォルダに曲が増えたら Jellyfin / Navidrome / Plex の API でライブラリのスキャンを始めさせ、
+// サーバー側にすぐ反映されるようにする。キューで続けてダウンロードするとスキャンが重なるので、
+// 最後のダウンロードから media_server.delay_sec 待ってから1回だけ呼ぶ。
+const defaultMediaRefreshDelay = 30
+
+type mediaServerConfig struct {
+	Jellyfin struct {
+		// Jellyfin のURL (例: http://localhost:8096)。空なら使わない
+		URL string `json:"url"`
+		// ダッシュボードの「APIキー」で発行したキー
+		APIKey string `json:"api_key"`
+	} `json:"jellyfin"`
+	Navidrome struct {
+		// Navidrome のURL (例: http://localhost:4533)。空なら使わない
+		URL string `json:"url"`
+		// スキャンを実行できる (管理者の) ユーザー名とパスワード
+		User     string `json:"user"`
+		Password string `json:"password"`
+	} `json:"navidrome"`
+	Plex struct {
+		// Plex Media Server のURL (例: http://localhost:32400)。空なら使わない
+		URL string `json:"url"`
+		// X-Plex-Token
+		Token string `json:"token"`
+		// 音楽ライブラリのセクションID
+		SectionID string `json:"section_id"`
+	} `json:"plex"`
+	// 最後のダウンロードからスキャンを始めるまでの待ち時間 (秒)
+	DelaySec int `json:"delay_sec"`
+}
+
+func (c mediaServerConfig) enabled() bool {
+	return c.Jellyfin.URL != "" || c.Navidrome.URL != "" || c.Plex.URL != ""
+}
+
+var mediaRefresh struct {
+	sync.Mutex
+	timer *time.Timer
+}
+
+// scheduleMediaRefresh (re)starts the countdown to the scan after a download.
+func scheduleMediaRefresh() {
+	if !cfg.MediaServer.enabled() {
+		return
+	}
+	delay := cfg.MediaServer.DelaySec
+	if delay <= 0 {
+		delay = defaultMediaRefreshDelay
+	}
+	mediaRefresh.Lock()
+	defer mediaRefresh.Unlock()
+	if mediaRefresh.timer != nil {
+		mediaRefresh.timer.Stop()
+	}
+	mediaRefresh.timer = time.AfterFunc(time.Duration(delay)*time.Second, func() {
+		mediaRefresh.Lock()
+		mediaRefresh.timer = nil
+		mediaRefresh.Unlock()
+		refreshMediaServers()
+	})
+}
+
+// flushMediaRefresh runs a scan that is still waiting, so one isn't lost when the program exits.
+func flushMediaRefresh() {
+	mediaRefresh.Lock()
+	pending := mediaRefresh.timer != nil && mediaRefresh.timer.Stop()
+	mediaRefresh.timer = nil
+	mediaRefresh.Unlock()
+	if pending {
+		refreshMediaServers()
+	}
+}
+
+// refreshMediaServers asks every configured server to rescan. Failures are logged only.
+func refreshMediaServers() {
+	ms := cfg.MediaServer
+	if ms.Jellyfin.URL != "" {
+		logMediaRefresh("Jellyfin", refreshJellyfin(ms.Jellyfin.URL, ms.Jellyfin.APIKey))
+	}
+	if ms.Navidrome.URL != "" {
+		logMediaRefresh("Navidrome", refreshNavidrome(ms.Navidrome.URL, ms.Navidrome.User, ms.Navidrome.Password))
+	}
+	if ms.Plex.URL != "" {
+		logMediaRefresh("Plex", refreshPlex(ms.Plex.URL, ms.Plex.Token, ms.Plex.SectionID))
+	}
+}
+
+func logMediaRefresh(server string, err error) {
+	if err != nil {
+		log.Printf("MediaServer: %s library scan failed: %v", server, err)
+		return
+	}
+	log.Printf("MediaServer: started %s library scan", server)
+}
+
+func refreshJellyfin(baseURL, apiKey string) error {
+	req, err := http.NewRequest("POST", strings.TrimRight(baseURL, "/")+"/Library/Refresh", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Emby-Token", apiKey)
+	return sendMediaRefresh(req)
+}
+
+// refreshNavidrome calls the Subsonic API's startScan with a salted token instead of the password.
+func refreshNavidrome(baseURL, user, password string) error {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	s := hex.EncodeToString(salt)
+	token := md5.Sum([]byte(password + s))
+	params := url.Values{
+		"u": {user}, "t": {hex.EncodeToString(token[:])}, "s": {s},
+		"v": {"1.16.1"}, "c": {"GoMusicDownloader"}, "f": {"json"},
+	}
+	req, err := http.NewRequest("GET", strings.TrimRight(baseURL, "/")+"/rest/startScan?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	return sendMediaRefresh(req)
+}
+
+func refreshPlex(baseURL, token, sectionID string) error {
+	if sectionID == "" {
+		return errorf("media_server.plex.section_id を設定してください")
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/library/sections/%s/refresh", strings.TrimRight(baseURL, "/"), url.PathEscape(sectionID)), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Plex-Token", token)
+	return sendMediaRefresh(req)
+}
+
+func sendMediaRefresh(req *http.Request) error {
+	req.Header.Set("User-Agent", userAgent())
+	resp, err := doWithRetry("mediaserver", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	// Subsonic API はエラーでも 200 を返し、本文の status で失敗を伝える
+	if strings.Contains(string(body), `"status":"failed"`) {
+		return fmt.Errorf("%s: %s", req.URL.Host, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 検索結果の続き ---
+// 検索は1ページずつ (既定では YouTube は5件、MusicBrainz は25件) 行う。音源やリリースの一覧で m を押すと、
+// 同じ検索語で次のページを取得して一覧の末尾に足す (検索語を入力し直す必要はない)。
+
+// morePaging remembers the query behind a result list and how many pages of it are shown.
+type morePaging struct {
+	query string
+	pages int
+}
+
+type moreResultsMsg struct {
+	list  state // stateSelectYT / stateSelectMB
+	query string
+	items []list.Item
+	err   error
+}
+
+// loadMore fetches the next page for the YouTube or MusicBrainz list on screen.
+func (m *model) loadMore() tea.Cmd {
+	l, paging := &m.ytResults, &m.ytMore
+	if m.state == stateSelectMB {
+		l, paging = &m.mbResults, &m.mbMore
+	}
+	if paging.query == "" {
+		return nil
+	}
+	if m.moreBusy {
+		return l.NewStatusMessage(tr("続きを取得中です..."))
+	}
+	m.moreBusy = true
+	which, query, page, ytDlpPath, filter := m.state, paging.query, paging.pages, m.ytDlpPath, m.mbFilter
+	fetch := func() tea.Msg {
+		var items []list.Item
+		var err error
+		if which == stateSelectMB {
+			items, err = doMusicBrainzSearchPage(query, page, filter)
+		} else {
+			items, err = doYouTubeSearchPage(ytDlpPath, query, page)
+		}
+		return moreResultsMsg{list: which, query: query, items: items, err: err}
+	}
+	return tea.Batch(l.NewStatusMessage(tr("続きを取得中です...")), fetch)
+}
+
+// appendMore adds the fetched page to its list, leaving out results it already shows.
+func (m *model) appendMore(msg moreResultsMsg) tea.Cmd {
+	m.moreBusy = false
+	l, paging := &m.ytResults, &m.ytMore
+	if msg.list == stateSelectMB {
+		l, paging = &m.mbResults, &m.mbMore
+	}
+	if msg.query != paging.query || (msg.list == stateSelectMB && m.groupParent != nil) {
+		return nil // 別の検索に移ったか、版の一覧を表示中
+	}
+	if msg.err != nil {
+		return l.NewStatusMessage(tr("続きの取得に失敗しました: %v", msg.err))
+	}
+	paging.pages++
+	shown := map[string]bool{}
+	for _, it := range l.Items() {
+		shown[it.(item).id] = true
+	}
+	items := slices.Clone(l.Items())
+	for _, it := range msg.items {
+		if !shown[it.(item).id] {
+			items = append(items, it)
+		}
+	}
+	added := len(items) - len(l.Items())
+	if added == 0 {
+		return l.NewStatusMessage(tr("これ以上の結果はありません"))
+	}
+	return tea.Batch(l.SetItems(items), l.NewStatusMessage(tr("%d件を追加しました (計%d件)", added, len(items))))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- マウス操作 ---
+// ホイールは ↑/↓ キーとして扱う。リストは項目のクリックで選択し、選択中の項目をもう一度クリックすると決定する。
+// 画面下の操作説明は「キー: 説明」の部分をクリックすると、そのキーを押したのと同じになる。
+// 位置は画面の組み立て (余白・見出し・枠、リストのタイトルと状態の行、項目の高さ) から計算する。既定では無効で、ui.mouse を true にすると使える。
+
+// activeList returns the list shown on the current screen, if any.
+func (m *model) activeList() *list.Model {
+	switch m.state {
+	case stateSelectYT:
+		return &m.ytResults
+	case stateSelectMB:
+		return &m.mbResults
+	case stateSelectTrack:
+		return &m.tracklist
+	case stateHistory:
+		if !m.noteEditing && !m.historyTyping {
+			return &m.historyList
+		}
+	case stateReview:
+		return &m.reviewList
+	case stateYTMusic:
+		return &m.ytmList
+	case stateNewReleases:
+		return &m.releaseList
+	}
+	return nil
+}
+
+func (m *model) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.showHelp {
+		return nil
+	}
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		return keyCmd(tea.KeyMsg{Type: tea.KeyUp})
+	case msg.Button == tea.MouseButtonWheelDown:
+		return keyCmd(tea.KeyMsg{Type: tea.KeyDown})
+	case msg.Button != tea.MouseButtonLeft || msg.Action != tea.MouseActionPress:
+		return nil
+	}
+	if msg.Y == m.footerRow() {
+		return clickFooter(ansi.Strip(m.footerView()), msg.X-appStyle.GetMarginLeft())
+	}
+	if l := m.activeList(); l != nil && l.FilterState() != list.Filtering {
+		return clickListItem(l, msg.Y-contentTop()-listItemsTop(*l))
+	}
+	return nil
+}
+
+func keyCmd(k tea.KeyMsg) tea.Cmd { return func() tea.Msg { return k } }
+
+// contentTop is the first row inside the content box: the margin, the header and the box's border.
+func contentTop() int {
+	return appStyle.GetMarginTop() + lipgloss.Height(headerStyle.Render(appTitle)) + 1
+}
+
+// footerRow is the row of the help line, right under the content box (its height is fixed in View).
+func (m model) footerRow() int {
+	return contentTop() + m.height - 7 + 1
+}
+
+// listItemsTop is the number of rows the list draws above its items: the title bar and the status bar.
+func listItemsTop(l list.Model) int {
+	rows := 0
+	if l.ShowTitle() || (l.ShowFilter() && l.FilteringEnabled()) {
+		rows += lipgloss.Height(l.Styles.TitleBar.Render(l.Styles.Title.Render(l.Title)))
+	}
+	if l.ShowStatusBar() {
+		rows += lipgloss.Height(l.Styles.StatusBar.Render(" "))
+	}
+	return rows
+}
+
+// clickListItem selects the item drawn at row y of the list's items on the current page; clicking the
+// selected item again confirms it.
+func clickListItem(l *list.Model, y int) tea.Cmd {
+	d := itemDelegate{}
+	rows := d.Height() + d.Spacing()
+	if y < 0 || y%rows >= d.Height() {
+		return nil // 項目の上か、項目の間の空行
+	}
+	start, end := l.Paginator.GetSliceBounds(len(l.VisibleItems()))
+	target := start + y/rows
+	if target >= end {
+		return nil
+	}
+	if target == l.Index() {
+		if k, ok := keyMsgFor(keymap.confirm.Keys()[0]); ok {
+			return keyCmd(k)
+		}
+		return nil
+	}
+	l.Select(target)
+	return nil
+}
+
+// clickFooter presses the key of the "key: description" hint under x.
+func clickFooter(line string, x int) tea.Cmd {
+	col := 0
+	for _, part := range strings.Split(line, " | ") {
+		w := lipgloss.Width(part)
+		if x >= col && x < col+w {
+			label, _, ok := strings.Cut(strings.TrimSpace(part), ": ")
+			if !ok {
+				return nil
+			}
+			// y/Enter や ↑/↓ のように複数あるときは最初のキー
+			if first, _, found := strings.Cut(label, "/"); found && first != "" {
+				label = first
+			}
+			if k, ok := keyMsgFor(label); ok {
+				return keyCmd(k)
+			}
+			return nil
+		}
+		col += w + lipgloss.Width(" | ")
+	}
+	return nil
+}
+
+// keyMsgFor builds the key press for a key as written in the config or the help ("ctrl+q", "Enter", "a").
+func keyMsgFor(k string) (tea.KeyMsg, bool) {
+	names := map[string]tea.KeyType{
+		"enter": tea.KeyEnter, "esc": tea.KeyEsc, "tab": tea.KeyTab, "space": tea.KeySpace, "f1": tea.KeyF1,
+		"up": tea.KeyUp, "down": tea.KeyDown, "left": tea.KeyLeft, "right": tea.KeyRight,
+		"↑": tea.KeyUp, "↓": tea.KeyDown, "←": tea.KeyLeft, "→": tea.KeyRight,
+	}
+	lower := strings.ToLower(k)
+	if t, ok := names[lower]; ok {
+		return tea.KeyMsg{Type: t}, true
+	}
+	if c, ok := strings.CutPrefix(lower, "ctrl+"); ok && len(c) == 1 && c[0] >= 'a' && c[0] <= 'z' {
+		return tea.KeyMsg{Type: tea.KeyCtrlA + tea.KeyType(c[0]-'a')}, true
+	}
+	if utf8.RuneCountInString(k) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}, true
+	}
+	return tea.KeyMsg{}, false
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- MusicBrainz 補助 ---
+// MusicBrainz allows about one request per second per client and answers 503 (or 429) beyond that.
+const mbMinInterval = time.Second
+
+// mbLimiter hands out request slots at least mbMinInterval apart, shared by every goroutine.
+var mbLimiter struct {
+	sync.Mutex
+	next time.Time
+}
+
+func mbWait() {
+	mbLimiter.Lock()
+	now := time.Now()
+	wait := max(mbLimiter.next.Sub(now), 0)
+	mbLimiter.next = now.Add(wait + mbMinInterval)
+	mbLimiter.Unlock()
+	time.Sleep(wait)
+}
+
+// mbHoldOff delays every later request, not just the retried one, after MB asked us to slow down.
+func mbHoldOff(d time.Duration) {
+	mbLimiter.Lock()
+	if t := time.Now().Add(d); t.After(mbLimiter.next) {
+		mbLimiter.next = t
+	}
+	mbLimiter.Unlock()
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(h string) time.Duration {
+	if secs, err := strconv.Atoi(strings.TrimSpace(h)); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func mbGetJSON(apiURL string, v interface{}) error { return mbGet(apiURL, v, true) }
+
+// mbGetFreshJSON skips the cache, for lookups whose answer is expected to change (new releases).
+func mbGetFreshJSON(apiURL string, v interface{}) error { return mbGet(apiURL, v, false) }
+
+func mbGet(apiURL string, v interface{}, cached bool) error {
+	req, err := newGetRequest(apiURL)
+	if err != nil {
+		return err
+	}
+	if body, _, ok := cacheGet(apiURL); cached && ok && body != nil {
+		return json.Unmarshal(body, v)
+	}
+	client := clientFor("musicbrainz")
+	for retry := 1; ; retry++ {
+		mbWait()
+		resp, err := client.Do(req)
+		if err != nil || retryableStatus(resp.StatusCode) {
+			// 待ち時間は他のリクエストにも適用するため、doWithRetry ではなくレート制限の側で待つ
+			var cause string
+			if err != nil {
+				cause = shortError(err)
+			} else {
+				cause = resp.Status
+				resp.Body.Close()
+			}
+			if retry > cfg.Retry.Attempts {
+				if err != nil {
+					return err
+				}
+				return errorf("MusicBrainz returned %s (%d回再試行しました)", cause, retry-1)
+			}
+			backoff := max(cfg.Retry.backoff(retry), mbMinInterval)
+			if err == nil {
+				backoff = max(backoff, retryAfter(resp.Header.Get("Retry-After")))
+			}
+			if limit := time.Duration(cfg.Retry.MaxBackoffSec * float64(time.Second)); limit > 0 {
+				backoff = min(backoff, max(limit, mbMinInterval))
+			}
+			notifyRetry("musicbrainz", retry, backoff, cause)
+			mbHoldOff(backoff)
+			continue
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("MusicBrainz returned %s", resp.Status)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, v); err != nil {
+			return err
+		}
+		cachePut(apiURL, body)
+		return nil
+	}
+}
+
+// mbIncludes composes the inc= parameter of a lookup, dropping duplicates.
+type mbIncludes []string
+
+func (inc mbIncludes) with(names ...string) mbIncludes {
+	for _, n := range names {
+		if n != "" && !containsString(inc, n) {
+			inc = append(inc, n)
+		}
+	}
+	return inc
+}
+
+func (inc mbIncludes) String() string { return strings.Join(inc, "+") }
+
+// releaseLookupIncludes returns the includes for the tracklist lookup, requesting optional data
+// only for the tag features that are enabled.
+func releaseLookupIncludes(c mbConfig) mbIncludes {
+	inc := mbIncludes{"artist-credits", "media", "recordings", "release-groups"}
+	if c.LabelTags {
+		inc = inc.with("labels")
+	}
+	if c.ISRCTags {
+		inc = inc.with("isrcs")
+	}
+	if c.GenreTags {
+		inc = inc.with("genres")
+	}
+	if c.AliasLookup || c.SortTags {
+		inc = inc.with("aliases")
+	}
+	return inc.with(c.ExtraIncludes...)
+}
+
+type (
+	MBRelation struct {
+		Type   string   `json:"type"`
+		Artist MBArtist `json:"artist"`
+		Work   MBWork   `json:"work"`
+	}
+	MBWork struct {
+		ID        string       `json:"id"`
+		Title     string       `json:"title"`
+		Relations []MBRelation `json:"relations"`
+	}
+	mbRelationsResponse struct {
+		Relations []MBRelation `json:"relations"`
+	}
+)
+
+type workCredits struct {
+	Composers, Lyricists, Arrangers []string
+}
+
+func (c *workCredits) add(rel MBRelation) {
+	name := rel.Artist.Name
+	if name == "" {
+		return
+	}
+	switch rel.Type {
+	case "composer", "writer":
+		c.Composers = appendUnique(c.Composers, name)
+	case "lyricist":
+		c.Lyricists = appendUnique(c.Lyricists, name)
+	case "arranger", "orchestrator":
+		c.Arrangers = appendUnique(c.Arrangers, name)
+	}
+}
+
+// fetchWorkCredits follows the recording's performance relationships to its works and collects the
+// composer, lyricist and arranger credits from both levels.
+func fetchWorkCredits(recordingID string) (workCredits, error) {
+	var credits workCredits
+	if recordingID == "" || !cfg.MusicBrainz.CreditTags {
+		return credits, nil
+	}
+	var rec mbRelationsResponse
+	if err := mbGetJSON(fmt.Sprintf("https://musicbrainz.org/ws/2/recording/%s?inc=work-rels+artist-rels&fmt=json", recordingID), &rec); err != nil {
+		return credits, err
+	}
+	for _, rel := range rec.Relations {
+		credits.add(rel)
+		if rel.Type != "performance" || rel.Work.ID == "" {
+			continue
+		}
+		var work MBWork
+		if err := mbGetJSON(fmt.Sprintf("https://musicbrainz.org/ws/2/work/%s?inc=artist-rels&fmt=json", rel.Work.ID), &work); err != nil {
+			return credits, err
+		}
+		for _, wrel := range work.Relations {
+			credits.add(wrel)
+		}
+	}
+	return credits, nil
+}
+
+func (c workCredits) tags() [][2]string {
+	return [][2]string{
+		{"COMPOSER", strings.Join(c.Composers, "; ")},
+		{"LYRICIST", strings.Join(c.Lyricists, "; ")},
+		{"ARRANGER", strings.Join(c.Arrangers, "; ")},
+	}
+}
+
+func appendUnique(list []string, s string) []string {
+	if containsString(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// mbReleaseID is the MusicBrainz release ID of a release item, or "" for the releases made up for
+// Spotify and YouTube Music imports, whose IDs carry a "service:" prefix that an MBID never has.
+func mbReleaseID(id string) string {
+	if strings.Contains(id, ":") {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- 保存先のファイル名テンプレート ---
+const defaultNamingTemplate = "{Artist} - {Title}"
+
+var (
+	templateKeyRe     = regexp.MustCompile(`\{(\w+)\}`)
+	emptyBracketsRe   = regexp.MustCompile(`\(\s*\)|\[\s*\]`)
+	doubleSeparatorRe = regexp.MustCompile(`\s*-\s*(?:-\s*)+`)
+	multiSpaceRe      = regexp.MustCompile(`\s{2,}`)
+)
+
+// yearOf returns the leading year of an MB date, which may be "2024", "2024-05" or "2024-05-01".
+func yearOf(date string) string {
+	if len(date) >= 4 {
+		if _, err := strconv.Atoi(date[:4]); err == nil {
+			return date[:4]
+		}
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// templateValues resolves the placeholders. When MusicBrainz only knows one of the release date and
+// the release group's first release date, each date placeholder falls back to the other.
+func templateValues(tags finalTags) map[string]string {
+	year, origYear := yearOf(tags.Date), yearOf(tags.OriginalDate)
+	track := tags.TrackNumber
+	if n, err := strconv.Atoi(track); err == nil {
+		track = fmt.Sprintf("%02d", n)
+	}
+	return map[string]string{
+		"Artist":      tags.Artist,
+		"AlbumArtist": firstNonEmpty(tags.AlbumArtist, tags.Artist),
+		"Album":       tags.Album,
+		"Title":       tags.Title,
+		"Track":       track,
+		"Disc":        optionalInt(tags.DiscNumber),
+		"Genre":       tags.Genre,
+		"Year":        firstNonEmpty(year, origYear),
+		"OrigYear":    firstNonEmpty(origYear, year),
+		"Date":        firstNonEmpty(tags.Date, tags.OriginalDate),
+	}
+}
+
+// expandTemplate fills a naming template and returns a relative path. Each "/"-separated segment is
+// sanitized on its own, and brackets or separators left dangling by empty values are removed so an
+// unknown year doesn't produce names like "Album ()".
+func expandTemplate(tmpl string, tags finalTags) string {
+	values := templateValues(tags)
+	expanded := templateKeyRe.ReplaceAllStringFunc(tmpl, func(key string) string {
+		name := key[1 : len(key)-1]
+		v, ok := values[name]
+		if !ok {
+			log.Printf("Naming: unknown placeholder %s in template", key)
+		}
+		return strings.ReplaceAll(v, "/", "-")
+	})
+	var segments []string
+	for _, seg := range strings.Split(expanded, "/") {
+		seg = emptyBracketsRe.ReplaceAllString(seg, "")
+		seg = doubleSeparatorRe.ReplaceAllString(seg, " - ")
+		seg = multiSpaceRe.ReplaceAllString(seg, " ")
+		seg = strings.Trim(seg, " -_.")
+		if seg == "" {
+			seg = "Unknown"
+		}
+		segments = append(segments, sanitizeFilename(seg))
+	}
+	return filepath.Join(segments...)
+}
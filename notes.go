@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- メモと評価 ---
+// 履歴の各曲に★1〜5の評価とメモを付ける。library.rating_tags が有効ならFLACの RATING / COMMENT にも書き込む。
+const maxRating = 5
+
+type curationSavedMsg struct {
+	entry historyEntry
+	err   error
+}
+
+func ratingStars(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("★", n) + strings.Repeat("☆", maxRating-n)
+}
+
+func ratingBadge(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(yellowColor).Render(ratingStars(n))
+}
+
+func sameHistoryEntry(a, b historyEntry) bool {
+	return a.Time.Equal(b.Time) && a.Path == b.Path && a.VideoID == b.VideoID
+}
+
+// saveCurationCmd stores the entry's rating and note in the history and, if enabled, in the file's tags.
+func saveCurationCmd(e historyEntry) tea.Cmd {
+	return func() tea.Msg {
+		historyMu.Lock()
+		entries, err := loadHistory()
+		if err == nil {
+			for i := range entries {
+				if sameHistoryEntry(entries[i], e) {
+					entries[i].Rating, entries[i].Note = e.Rating, e.Note
+				}
+			}
+			err = saveHistory(entries)
+		}
+		historyMu.Unlock()
+		if err != nil {
+			return curationSavedMsg{entry: e, err: err}
+		}
+		if cfg.Library.RatingTags && e.status() == statusOK && strings.EqualFold(filepath.Ext(e.Path), ".flac") {
+			if err := writeCurationTags(e.Path, e.Rating, e.Note); err != nil {
+				log.Printf("Notes: failed to tag %s: %v", e.Path, err)
+				return curationSavedMsg{entry: e, err: errorf("履歴には保存しましたが、タグの書き込みに失敗しました: %w", err)}
+			}
+		}
+		return curationSavedMsg{entry: e}
+	}
+}
+
+// writeCurationTags replaces the RATING and COMMENT tags; a zero rating or an empty note removes them.
+func writeCurationTags(path string, rating int, note string) error {
+	tags, err := readFLACTags(path)
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, c := range tags.comments {
+		key, _, _ := strings.Cut(c, "=")
+		if !strings.EqualFold(key, "RATING") && !strings.EqualFold(key, "COMMENT") {
+			kept = append(kept, c)
+		}
+	}
+	if rating > 0 {
+		kept = append(kept, fmt.Sprintf("RATING=%d", rating))
+	}
+	if note != "" {
+		kept = append(kept, "COMMENT="+note)
+	}
+	tags.comments = kept
+	return writeFLACTags(path, tags)
+}
+
+func newNoteInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = tr("メモ (空にすると削除)")
+	ti.CharLimit = 500
+	ti.Width = 60
+	return ti
+}
+
+// selectedHistoryEntry returns the history entry under the cursor.
+func (m model) selectedHistoryEntry() (historyEntry, bool) {
+	i, ok := m.historyList.SelectedItem().(item)
+	if !ok {
+		return historyEntry{}, false
+	}
+	r, ok := i.meta.(historyRow)
+	return r.entry, ok
+}
+
+// setRating rates the selected entry; giving the same rating again clears it.
+func (m *model) setRating(n int) tea.Cmd {
+	e, ok := m.selectedHistoryEntry()
+	if !ok || e.status() != statusOK {
+		return nil
+	}
+	if e.Rating == n {
+		n = 0
+	}
+	e.Rating = n
+	m.updateHistoryRow(e)
+	return saveCurationCmd(e)
+}
+
+func (m *model) openNoteEdit() tea.Cmd {
+	e, ok := m.selectedHistoryEntry()
+	if !ok || e.status() != statusOK {
+		return nil
+	}
+	m.noteEditing = true
+	m.noteInput.SetValue(e.Note)
+	m.noteInput.CursorEnd()
+	return m.noteInput.Focus()
+}
+
+func (m *model) updateNoteEdit(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.noteEditing = false
+		m.noteInput.Blur()
+		e, ok := m.selectedHistoryEntry()
+		if !ok {
+			return nil
+		}
+		e.Note = strings.TrimSpace(m.noteInput.Value())
+		m.updateHistoryRow(e)
+		return saveCurationCmd(e)
+	case tea.KeyEsc:
+		m.noteEditing = false
+		m.noteInput.Blur()
+	}
+	return nil
+}
+
+// updateHistoryRow applies an edited entry to the loaded rows and redraws the list at the same position.
+func (m *model) updateHistoryRow(e historyEntry) {
+	for i := range m.historyRows {
+		if sameHistoryEntry(m.historyRows[i].entry, e) {
+			m.historyRows[i].entry = e
+		}
+	}
+	index := m.historyList.Index()
+	m.showHistoryList()
+	m.historyList.Select(index)
+}
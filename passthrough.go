@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// --- 無劣化パススルー ---
+// YouTubeの音声 (Opus / AAC) はもともと非可逆なので、FLACに変換せずにそのまま .opus / .m4a に入れ直して保存する。
+const (
+	outputFLAC     = "flac"
+	outputOriginal = "original"
+)
+
+type outputConfig struct {
+	// 保存形式: flac (FLACに変換) / original (元の音声を再エンコードせず .opus / .m4a で保存)
+	// / mka (FLACをMKAに入れ、カバー・同期歌詞・メタデータJSONを添付するアーカイブ用)
+	Format string `json:"format"`
+	// FLACの圧縮レベル (0〜12, ffmpegの既定は5)。音質は変わらず、大きいほどファイルが小さく変換が遅い
+	FLACCompression int `json:"flac_compression"`
+	// 出力のサンプリングレート (例: 44100)。0 なら元のまま
+	SampleRate int `json:"sample_rate"`
+	// 出力のビット深度: 16 / 24。0 なら元のまま
+	BitDepth int `json:"bit_depth"`
+	// 全曲入りの動画を1ファイルで保存 (w) したときに、トラック位置を書いた .cue も書き出す
+	CueSheet bool `json:"cue_sheet"`
+	// 一括ダウンロード・アルバムの分割が終わったら、曲順どおりの .m3u8 を downloads に書き出す
+	Playlist bool `json:"playlist"`
+}
+
+var ffmpegAudioCodecRe = regexp.MustCompile(`Stream #\d+:\d+.*?: Audio: (\w+)`)
+
+// probeAudioCodec reads the codec of the first audio stream from ffmpeg's input banner.
+func probeAudioCodec(ffmpegPath, path string) string {
+	out, _ := exec.Command(ffmpegPath, "-hide_banner", "-i", path).CombinedOutput()
+	if m := ffmpegAudioCodecRe.FindStringSubmatch(string(out)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// passthroughExt is the container a codec is remuxed into, or "" if it has to be converted to FLAC.
+// YouTube only serves Opus and AAC; the others come from local files in the tagger.
+func passthroughExt(codec string) string {
+	switch codec {
+	case "opus":
+		return ".opus"
+	case "aac":
+		return ".m4a"
+	case "flac":
+		return ".flac"
+	case "mp3":
+		return ".mp3"
+	case "vorbis":
+		return ".ogg"
+	}
+	return ""
+}
+
+// choosePassthrough sets the job up to keep the downloaded stream as-is when the output format asks for it.
+func (job *convertJob) choosePassthrough(ffmpegPath string) {
+	if cfg.Output.Format != outputOriginal {
+		return
+	}
+	codec := probeAudioCodec(ffmpegPath, job.audioPath)
+	if passthroughExt(codec) == "" {
+		log.Printf("Passthrough: %q can't be stored as-is, converting to FLAC", codec)
+		return
+	}
+	job.codec = codec
+}
+
+// outputExt is the extension of the file convertAudio writes.
+func (job convertJob) outputExt() string {
+	if ext := passthroughExt(job.codec); ext != "" {
+		return ext
+	}
+	if cfg.Output.Format == outputMKA {
+		return ".mka"
+	}
+	return ".flac"
+}
+
+// pictureBlock encodes an image as a FLAC PICTURE block (front cover), which Ogg Opus stores base64
+// encoded in METADATA_BLOCK_PICTURE.
+func pictureBlock(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	imgCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	mime := http.DetectContentType(data)
+	var buf bytes.Buffer
+	for _, v := range []uint32{3, uint32(len(mime))} { // 3 = front cover
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+	buf.WriteString(mime)
+	for _, v := range []uint32{0, uint32(imgCfg.Width), uint32(imgCfg.Height), 24, 0, uint32(len(data))} {
+		binary.Write(&buf, binary.BigEndian, v) // 説明 (空), 幅, 高さ, 色深度, パレット数, 画像サイズ
+	}
+	buf.Write(data)
+	return buf.Bytes(), nil
+}
+
+// writeFFMetadataTags writes global tags in ffmpeg's FFMETADATA format. Used for values too large to
+// pass as -metadata arguments, such as an embedded picture.
+func writeFFMetadataTags(path string, tags [][2]string) error {
+	esc := strings.NewReplacer(`\`, `\\`, "=", `\=`, ";", `\;`, "#", `\#`, "\n", "\\\n")
+	var b strings.Builder
+	b.WriteString(";FFMETADATA1\n")
+	for _, kv := range tags {
+		fmt.Fprintf(&b, "%s=%s\n", esc.Replace(kv[0]), esc.Replace(kv[1]))
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// opusCoverTag is the METADATA_BLOCK_PICTURE comment for the cover.
+func opusCoverTag(coverPath string) ([2]string, error) {
+	block, err := pictureBlock(coverPath)
+	if err != nil {
+		return [2]string{}, err
+	}
+	return [2]string{"METADATA_BLOCK_PICTURE", base64.StdEncoding.EncodeToString(block)}, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- M3U8 プレイリスト ---
+// output.playlist を有効にすると、キューの一括ダウンロードやアルバムの分割ダウンロードが終わったときに、
+// 保存したファイルを曲順に並べた .m3u8 を downloads フォルダに書き出す。パスは .m3u8 からの相対パス。
+const playlistExt = ".m3u8"
+
+type playlistEntry struct {
+	path string
+	tags finalTags
+}
+
+// writePlaylist writes an extended M3U8 named after name into the downloads folder and returns its path.
+func writePlaylist(name string, entries []playlistEntry) (string, error) {
+	dir := filepath.Join(mainDir, downloadsDir)
+	path := filepath.Join(dir, sanitizeFilename(name)+playlistExt)
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	for _, e := range entries {
+		duration := e.tags.DurationSec
+		if duration <= 0 {
+			duration = -1
+		}
+		fmt.Fprintf(&b, "#EXTINF:%d,%s - %s\n%s\n", duration, e.tags.Artist, e.tags.Title, playlistPath(dir, e.path))
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return "", err
+	}
+	// 曲と同じ送り先に送り、送り先でも同じ相対パスで開けるようにする (曲の送信のあとに並ぶ)
+	queueUpload(path, finalTags{})
+	return path, nil
+}
+
+// playlistPath is the entry relative to the playlist with "/" separators, which every player accepts,
+// or the absolute path when the file is on another drive (e.g. moved to a beets staging folder).
+func playlistPath(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// sortByTrack orders entries by disc and track number.
+func sortByTrack(entries []playlistEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].tags, entries[j].tags
+		if a.DiscNumber != b.DiscNumber {
+			return a.DiscNumber < b.DiscNumber
+		}
+		return trackNo(a.TrackNumber) < trackNo(b.TrackNumber)
+	})
+}
+
+// trackNo reads an MB track number; vinyl sides ("A1") keep their queue order.
+func trackNo(number string) int {
+	n, _ := strconv.Atoi(number)
+	return n
+}
+
+// batchPlaylist writes the playlist for a finished queue: a queue from one release is saved in track
+// order under the album's name, a mixed queue in queue order with the date as its name.
+func batchPlaylist(items []queueItem) (string, error) {
+	var entries []playlistEntry
+	releases := map[string]bool{}
+	for _, q := range items {
+		if q.status != queueDone || q.path == "" {
+			continue
+		}
+		entries = append(entries, playlistEntry{path: q.path, tags: buildTags(q.release.meta.(MBRelease), q.track)})
+		releases[q.release.id] = true
+	}
+	if len(entries) < 2 {
+		return "", nil
+	}
+	name := "キュー " + time.Now().Format("2006-01-02 1504")
+	if len(releases) == 1 {
+		sortByTrack(entries)
+		name = firstNonEmpty(entries[0].tags.AlbumArtist, entries[0].tags.Artist) + " - " + entries[0].tags.Album
+	}
+	return writePlaylist(name, entries)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- MusicBrainz検索の先読み ---
+// YouTube結果の一覧でカーソルが止まった項目について、Enterを押す前にMusicBrainz検索を済ませておく。
+const (
+	prefetchDebounce = 400 * time.Millisecond
+	prefetchInterval = time.Second // MusicBrainzのレート制限 (1リクエスト/秒) に合わせる
+)
+
+type prefetchTickMsg struct{ seq int }
+
+type mbPrefetchedMsg struct {
+	query string
+	items []list.Item
+	err   error
+}
+
+// schedulePrefetch restarts the debounce timer whenever the highlighted YouTube result changes.
+func (m *model) schedulePrefetch() tea.Cmd {
+	i, ok := m.ytResults.SelectedItem().(item)
+	if !ok || m.batchActive() {
+		return nil
+	}
+	query := mbQueryFor(i)
+	if query == m.prefetchFor {
+		return nil
+	}
+	m.prefetchFor = query
+	m.prefetchSeq++
+	seq := m.prefetchSeq
+	return tea.Tick(prefetchDebounce, func(time.Time) tea.Msg { return prefetchTickMsg{seq: seq} })
+}
+
+// firePrefetch starts the request for the highlighted item once the cursor has settled, waiting for
+// the previous request and the rate limit interval first.
+func (m *model) firePrefetch(seq int) tea.Cmd {
+	if seq != m.prefetchSeq || m.state != stateSelectYT {
+		return nil
+	}
+	query := m.prefetchFor
+	if _, ok := m.mbCache[query]; ok {
+		return nil
+	}
+	if wait := prefetchInterval - time.Since(m.prefetchLast); m.prefetchBusy != "" || wait > 0 {
+		wait = max(wait, prefetchDebounce)
+		return tea.Tick(wait, func(time.Time) tea.Msg { return prefetchTickMsg{seq: seq} })
+	}
+	m.prefetchBusy, m.prefetchLast = query, time.Now()
+	filter := m.mbFilter
+	return func() tea.Msg {
+		items, err := doMusicBrainzSearchPage(query, 0, filter)
+		return mbPrefetchedMsg{query: query, items: items, err: err}
+	}
+}
+
+// searchMBFor shows cached results instantly, waits for an in-flight prefetch of the same query, or
+// falls back to a normal search.
+func (m *model) searchMBFor(yt item) tea.Cmd {
+	query := mbQueryFor(yt)
+	m.selectedYT = yt
+	m.searchStart = time.Now()
+	if items, ok := m.mbCache[query]; ok {
+		log.Printf("Prefetch: cache hit for %q", query)
+		return func() tea.Msg { return mbSearchFinishedMsg{query: query, items: items} }
+	}
+	m.state = stateSearching
+	m.statusMsg = tr("MusicBrainzでメタデータを検索中です...")
+	if m.prefetchBusy == query {
+		m.mbWaiting = query
+		return m.spinner.Tick
+	}
+	return tea.Batch(m.spinner.Tick, searchMusicBrainzCmd(query, m.mbFilter))
+}
+
+func (m *model) handlePrefetched(msg mbPrefetchedMsg) tea.Cmd {
+	m.prefetchBusy = ""
+	if msg.err == nil {
+		m.mbCache[msg.query] = msg.items
+	} else {
+		log.Printf("Prefetch: %q failed: %v", msg.query, msg.err)
+	}
+	if m.mbWaiting == msg.query && m.state == stateSearching {
+		m.mbWaiting = ""
+		return func() tea.Msg { return mbSearchFinishedMsg{query: msg.query, items: msg.items, err: msg.err} }
+	}
+	return nil
+}
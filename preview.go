@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- ターミナル内のカバープレビュー ---
+const (
+	previewCols = 24
+	previewRows = 12
+)
+
+const (
+	previewAuto   = "auto"
+	previewKitty  = "kitty"
+	previewITerm2 = "iterm2"
+	previewSixel  = "sixel"
+	previewBlocks = "blocks"
+	previewOff    = "off"
+)
+
+type coverPreviewMsg struct {
+	releaseID string
+	img       image.Image
+	source    coverSource
+	err       error
+}
+
+// previewProtocol resolves "auto" from well-known terminal environment variables. Sixel cannot be
+// detected reliably without querying the terminal, so it must be chosen explicitly.
+func previewProtocol() string {
+	p := strings.ToLower(cfg.Preview.Protocol)
+	if p != "" && p != previewAuto {
+		return p
+	}
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || os.Getenv("TERM") == "xterm-kitty":
+		return previewKitty
+	case os.Getenv("TERM_PROGRAM") == "iTerm.app" || os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return previewITerm2
+	}
+	return previewBlocks
+}
+
+func coverPreviewCmd(release item) tea.Cmd {
+	return func() tea.Msg {
+		releaseInfo, ok := release.meta.(MBRelease)
+		if !ok {
+			return coverPreviewMsg{releaseID: release.id, err: fmt.Errorf("no release")}
+		}
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return coverPreviewMsg{releaseID: release.id, err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+		path, src := fetchCoverArt(tmpDir, releaseInfo)
+		if path == "" {
+			return coverPreviewMsg{releaseID: release.id, source: src}
+		}
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return coverPreviewMsg{releaseID: release.id, err: err}
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		return coverPreviewMsg{releaseID: release.id, img: img, source: src, err: err}
+	}
+}
+
+// requestCoverPreview starts fetching the cover of the selected release unless it is already shown.
+func (m *model) requestCoverPreview() tea.Cmd {
+	if previewProtocol() == previewOff || m.previewFor == m.selectedMB.id {
+		return nil
+	}
+	m.previewFor, m.coverPreview, m.previewNote = m.selectedMB.id, nil, tr("カバー取得中...")
+	return coverPreviewCmd(m.selectedMB)
+}
+
+// renderPreview returns previewRows lines, each previewCols cells wide.
+func renderPreview(img image.Image) string {
+	switch previewProtocol() {
+	case previewKitty:
+		return reserveAndDraw(kittyImage(img))
+	case previewITerm2:
+		return reserveAndDraw(iterm2Image(img))
+	case previewSixel:
+		return reserveAndDraw(sixelImage(resizeSquare(img, previewRows*20)))
+	}
+	return blockImage(img)
+}
+
+// reserveAndDraw emits blank cells for the image area and draws the graphic from the last line, moving
+// the cursor back up with save/restore so the TUI layout below is not shifted and the blank cells written
+// earlier in the frame do not overwrite it.
+func reserveAndDraw(graphic string) string {
+	blank := strings.Repeat(" ", previewCols)
+	lines := make([]string, previewRows)
+	for i := range lines {
+		lines[i] = blank
+	}
+	lines[previewRows-1] += fmt.Sprintf("\x1b7\x1b[%dA\x1b[%dD%s\x1b8", previewRows-1, previewCols, graphic)
+	return strings.Join(lines, "\n")
+}
+
+func encodePNG(img image.Image) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func kittyImage(img image.Image) string {
+	payload := base64.StdEncoding.EncodeToString(encodePNG(img))
+	var b strings.Builder
+	for i := 0; i < len(payload); i += 4096 {
+		chunk := payload[i:min(i+4096, len(payload))]
+		more := 1
+		if i+4096 >= len(payload) {
+			more = 0
+		}
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", previewCols, previewRows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+func iterm2Image(img image.Image) string {
+	data := encodePNG(img)
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data), previewCols, previewRows, base64.StdEncoding.EncodeToString(data))
+}
+
+// sixelImage encodes the image with a fixed 6x6x6 color cube palette.
+func sixelImage(img image.Image) string {
+	bnd := img.Bounds()
+	w, h := bnd.Dx(), bnd.Dy()
+	idx := make([]int, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, bl, _ := img.At(bnd.Min.X+x, bnd.Min.Y+y).RGBA()
+			idx[y*w+x] = int(r>>8*6/256)*36 + int(g>>8*6/256)*6 + int(bl>>8*6/256)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+	for band := 0; band < h; band += 6 {
+		used := map[int]bool{}
+		for y := band; y < min(band+6, h); y++ {
+			for x := 0; x < w; x++ {
+				used[idx[y*w+x]] = true
+			}
+		}
+		for c := 0; c < 216; c++ {
+			if !used[c] {
+				continue
+			}
+			fmt.Fprintf(&b, "#%d", c)
+			var run byte
+			n := 0
+			flush := func() {
+				if n > 3 {
+					fmt.Fprintf(&b, "!%d%c", n, run)
+				} else {
+					b.WriteString(strings.Repeat(string(run), n))
+				}
+			}
+			for x := 0; x < w; x++ {
+				var bits byte
+				for k := 0; k < 6 && band+k < h; k++ {
+					if idx[(band+k)*w+x] == c {
+						bits |= 1 << k
+					}
+				}
+				ch := bits + 63
+				if n > 0 && ch != run {
+					flush()
+					n = 0
+				}
+				run = ch
+				n++
+			}
+			flush()
+			b.WriteByte('$')
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// blockImage draws two pixels per cell with the upper half block, using truecolor foreground/background.
+func blockImage(img image.Image) string {
+	small := resizeSquare(cropSquare(img), previewCols)
+	bnd := small.Bounds()
+	lines := make([]string, 0, previewRows)
+	for row := 0; row < previewRows; row++ {
+		var b strings.Builder
+		for col := 0; col < previewCols; col++ {
+			x := bnd.Min.X + col*bnd.Dx()/previewCols
+			tr, tg, tb, _ := small.At(x, bnd.Min.Y+(row*2)*bnd.Dy()/(previewRows*2)).RGBA()
+			br, bg, bb, _ := small.At(x, bnd.Min.Y+(row*2+1)*bnd.Dy()/(previewRows*2)).RGBA()
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr>>8, tg>>8, tb>>8, br>>8, bg>>8, bb>>8)
+		}
+		b.WriteString("\x1b[0m")
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n")
+}
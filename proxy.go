@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// --- プロキシ ---
+// network.proxy が設定されていればそれを、無ければ HTTPS_PROXY / HTTP_PROXY / ALL_PROXY を使う。
+// http:// と socks5:// のどちらも指定できる。
+func getenvAny(names ...string) string {
+	for _, n := range names {
+		if v := os.Getenv(n); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// proxySetting is the proxy passed to yt-dlp, which only takes a single proxy for everything.
+func proxySetting() string {
+	if cfg.Network.Proxy != "" {
+		return cfg.Network.Proxy
+	}
+	return getenvAny("HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "ALL_PROXY", "all_proxy")
+}
+
+// proxyForRequest is the Proxy func of the shared transport. Go's own environment lookup doesn't know
+// ALL_PROXY, so that one is handled here as the last fallback.
+func proxyForRequest(req *http.Request) (*url.URL, error) {
+	if cfg.Network.Proxy != "" {
+		return url.Parse(cfg.Network.Proxy)
+	}
+	if u, err := http.ProxyFromEnvironment(req); u != nil || err != nil {
+		return u, err
+	}
+	if all := getenvAny("ALL_PROXY", "all_proxy"); all != "" && !noProxy(req.URL.Hostname()) {
+		return url.Parse(all)
+	}
+	return nil, nil
+}
+
+func noProxy(host string) bool {
+	if host == "localhost" || host == "127.0.0.1" || host == "::1" {
+		return true
+	}
+	for _, p := range strings.Split(getenvAny("NO_PROXY", "no_proxy"), ",") {
+		p = strings.TrimPrefix(strings.TrimSpace(p), ".")
+		if p == "*" || (p != "" && (host == p || strings.HasSuffix(host, "."+p))) {
+			return true
+		}
+	}
+	return false
+}
+
+// ytDlpCommand builds a yt-dlp invocation with the proxy, cookies and the user's extra arguments applied.
+func ytDlpCommand(ctx context.Context, ytDlpPath string, args ...string) *exec.Cmd {
+	args = append(append(cookieArgs(), args...), ytDlpExtraArgs()...)
+	if p := proxySetting(); p != "" {
+		args = append([]string{"--proxy", p}, args...)
+	}
+	return exec.CommandContext(ctx, ytDlpPath, args...)
+}
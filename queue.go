@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- ダウンロードキュー (アルバムごとにグループ化) ---
+type queueStatus int
+
+const (
+	queuePending queueStatus = iota
+	queueActive
+	queueDone
+	queueFailed
+	queueSkipped
+)
+
+func (s queueStatus) icon() string {
+	switch s {
+	case queueActive:
+		return lipgloss.NewStyle().Foreground(cyanColor).Render("▶")
+	case queueDone:
+		return lipgloss.NewStyle().Foreground(greenColor).Render("✓")
+	case queueFailed:
+		return lipgloss.NewStyle().Foreground(redColor).Render("✗")
+	case queueSkipped:
+		return helpStyle.Render("⏭")
+	}
+	return helpStyle.Render("·")
+}
+
+type queueItem struct {
+	track, release item
+	status         queueStatus
+	path           string     // 保存先 (完了後)
+	source         item       // 音源が決まっている場合 (YouTube Music のプレイリスト) はその動画
+	tags           *finalTags // 編集済みのタグ (中断から入れ直した曲)。nil ならリリースから作る
+}
+
+type queueGroup struct {
+	release item
+	indices []int
+}
+
+// queueRow is one visible line of the queue view; index is -1 for a group header.
+type queueRow struct{ group, index int }
+
+// enqueue adds tracks of a release, skipping ones that are already queued.
+func (m *model) enqueue(tracks []item, release item) int {
+	items := make([]queueItem, len(tracks))
+	for i, t := range tracks {
+		items[i] = queueItem{track: t, release: release}
+	}
+	return m.enqueueItems(items)
+}
+
+// enqueueItems adds prepared queue items, skipping ones that are already queued.
+func (m *model) enqueueItems(items []queueItem) int {
+	added := 0
+	for _, it := range items {
+		dup := false
+		for _, q := range m.batch {
+			if sameTrack(q.track, it.track) && q.release.id == it.release.id {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			it.track.marked = false
+			m.batch = append(m.batch, it)
+			added++
+		}
+	}
+	return added
+}
+
+func sameTrack(a, b item) bool {
+	ta, okA := a.meta.(MBTrack)
+	tb, okB := b.meta.(MBTrack)
+	return okA && okB && ta.ID == tb.ID
+}
+
+func (m model) nextPending(from int) int {
+	for i := from; i < len(m.batch); i++ {
+		if m.batch[i].status == queuePending {
+			return i
+		}
+	}
+	return -1
+}
+
+// startQueue begins working through the pending queue items, or returns nil when there are none.
+func (m *model) startQueue() tea.Cmd {
+	next := m.nextPending(0)
+	if next < 0 {
+		return nil
+	}
+	m.batchRunning, m.batchIndex, m.batchLog = true, next, nil
+	return m.searchBatchTrack()
+}
+
+// finishBatchItem records the outcome of the current queue item and moves on.
+func (m *model) finishBatchItem(status queueStatus, logLine string) tea.Cmd {
+	m.batch[m.batchIndex].status = status
+	m.batchLog = append(m.batchLog, logLine)
+	return m.advanceBatch()
+}
+
+func groupQueue(items []queueItem) []queueGroup {
+	var groups []queueGroup
+	pos := map[string]int{}
+	for i, q := range items {
+		g, ok := pos[q.release.id]
+		if !ok {
+			g = len(groups)
+			pos[q.release.id] = g
+			groups = append(groups, queueGroup{release: q.release})
+		}
+		groups[g].indices = append(groups[g].indices, i)
+	}
+	return groups
+}
+
+// queueRows lists the visible lines. With autoFold (the progress view) only the group being worked on
+// is expanded so a long mixed queue still fits under the spinner.
+func (m model) queueRows(autoFold bool) []queueRow {
+	var rows []queueRow
+	for g, group := range groupQueue(m.batch) {
+		rows = append(rows, queueRow{group: g, index: -1})
+		if m.folded(group, autoFold) {
+			continue
+		}
+		for _, i := range group.indices {
+			rows = append(rows, queueRow{group: g, index: i})
+		}
+	}
+	return rows
+}
+
+func (m model) folded(group queueGroup, autoFold bool) bool {
+	if !autoFold {
+		return m.queueFolded[group.release.id]
+	}
+	for _, i := range group.indices {
+		if m.batch[i].status == queueActive {
+			return false
+		}
+	}
+	return true
+}
+
+func progressBar(done, total, width int) string {
+	if total == 0 {
+		return ""
+	}
+	filled := done * width / total
+	return lipgloss.NewStyle().Foreground(greenColor).Render(strings.Repeat("█", filled)) +
+		helpStyle.Render(strings.Repeat("░", width-filled))
+}
+
+func (m model) groupHeader(group queueGroup, autoFold bool) string {
+	var done, failed int
+	for _, i := range group.indices {
+		switch m.batch[i].status {
+		case queueDone:
+			done++
+		case queueFailed, queueSkipped:
+			failed++
+		}
+	}
+	arrow := "▼"
+	if m.folded(group, autoFold) {
+		arrow = "▶"
+	}
+	summary := fmt.Sprintf("%d/%d", done+failed, len(group.indices))
+	if failed > 0 {
+		summary += lipgloss.NewStyle().Foreground(redColor).Render(tr(" (失敗・スキップ %d)", failed))
+	}
+	title := lipgloss.NewStyle().Foreground(pinkColor).Bold(true).Render(group.release.title)
+	return fmt.Sprintf("%s %s %s %s %s", arrow, title, helpStyle.Render(group.release.desc), progressBar(done+failed, len(group.indices), 10), summary)
+}
+
+// queueView renders the grouped queue; the cursor is only drawn on the interactive queue screen.
+func (m model) queueView(withCursor bool) string {
+	if len(m.batch) == 0 {
+		return "\n  " + tr("キューは空です。トラック選択画面で Space で選んで q で追加できます。") + "\n"
+	}
+	groups := groupQueue(m.batch)
+	var b strings.Builder
+	b.WriteString("\n" + listTitleStyle.Render(tr("ダウンロードキュー (%d曲 / %dアルバム)", len(m.batch), len(groups))) + "\n\n")
+	for r, row := range m.queueRows(!withCursor) {
+		cursor := "  "
+		if withCursor && r == m.queueCursor {
+			cursor = lipgloss.NewStyle().Foreground(cyanColor).Render("> ")
+		}
+		if row.index < 0 {
+			b.WriteString(cursor + m.groupHeader(groups[row.group], !withCursor) + "\n")
+			continue
+		}
+		q := m.batch[row.index]
+		b.WriteString(fmt.Sprintf("%s    %s %s\n", cursor, q.status.icon(), q.track.title))
+	}
+	return b.String()
+}
+
+func (m *model) openQueue() {
+	m.queueBack, m.state, m.queueCursor = m.state, stateQueue, 0
+}
+
+// unmarkTracks clears the ✓ marks in the track list once the tracks have been queued.
+func (m *model) unmarkTracks() tea.Cmd {
+	var cmds []tea.Cmd
+	for idx, li := range m.tracklist.Items() {
+		if i, ok := li.(item); ok && i.marked {
+			i.marked = false
+			cmds = append(cmds, m.tracklist.SetItem(idx, i))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// updateQueue handles keys on the queue screen.
+func (m *model) updateQueue(msg tea.KeyMsg) tea.Cmd {
+	rows := m.queueRows(false)
+	switch msg.String() {
+	case "up", "k":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+	case "down", "j":
+		if m.queueCursor < len(rows)-1 {
+			m.queueCursor++
+		}
+	case " ", "left", "right", "h", "l":
+		if m.queueCursor < len(rows) {
+			id := groupQueue(m.batch)[rows[m.queueCursor].group].release.id
+			switch msg.String() {
+			case "left", "h":
+				m.queueFolded[id] = true
+			case "right", "l":
+				m.queueFolded[id] = false
+			default:
+				m.queueFolded[id] = !m.queueFolded[id]
+			}
+			// keep the cursor on the group header after folding
+			for r, row := range m.queueRows(false) {
+				if row.index < 0 && groupQueue(m.batch)[row.group].release.id == id {
+					m.queueCursor = r
+					break
+				}
+			}
+		}
+	case "d":
+		if m.queueCursor < len(rows) && !m.batchRunning {
+			row := rows[m.queueCursor]
+			if row.index >= 0 {
+				m.batch = append(m.batch[:row.index], m.batch[row.index+1:]...)
+			} else {
+				id := groupQueue(m.batch)[row.group].release.id
+				var kept []queueItem
+				for _, q := range m.batch {
+					if q.release.id != id {
+						kept = append(kept, q)
+					}
+				}
+				m.batch = kept
+			}
+			m.queueCursor = max(0, min(m.queueCursor, len(m.queueRows(false))-1))
+		}
+	case "enter":
+		if !m.batchRunning {
+			return m.startQueue()
+		}
+	case "esc":
+		m.state = m.queueBack
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 録音 (recording) の検索 ---
+// アルバムに入っていないシングルなどはリリースの検索で見つからないことがあるので、録音を直接検索する。
+// 結果は録音ごとに最初のリリース (絞り込みの条件に合うものを優先) と組にして並べ、選ぶとそのリリースの
+// トラックリストを取得して、録音に対応するトラックのタグ編集に進む。
+const mbItemRecording = "recording"
+
+type mbRecordingSearchResponse struct {
+	Recordings []mbSearchRecording `json:"recordings"`
+}
+
+type mbSearchRecording struct {
+	ID           string               `json:"id"`
+	Title        string               `json:"title"`
+	ArtistCredit []MBArtist           `json:"artist-credit"`
+	Releases     []mbRecordingRelease `json:"releases"`
+}
+
+// mbRecordingRelease is a release as the recording search lists it, with only the recording's track.
+type mbRecordingRelease struct {
+	MBRelease
+	Media []struct {
+		Position int       `json:"position"`
+		Format   string    `json:"format"`
+		Track    []MBTrack `json:"track"`
+	} `json:"media"`
+}
+
+// doRecordingSearch searches recordings matching the filter, mbSearchPage() at a time.
+func doRecordingSearch(query string, page int, filter mbReleaseFilter) ([]list.Item, error) {
+	apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/recording/?query=%s&fmt=json&limit=%d&offset=%d",
+		url.QueryEscape(filter.query(query)), mbSearchPage(), page*mbSearchPage())
+	var data mbRecordingSearchResponse
+	if err := mbGetJSON(apiURL, &data); err != nil {
+		return nil, err
+	}
+	var items []list.Item
+	for _, rec := range data.Recordings {
+		r, ok := firstRelease(rec, filter)
+		if !ok {
+			continue // どのリリースにも入っていない録音はタグを作れない
+		}
+		desc := fmt.Sprintf("%s — %s (%s) [%s]", joinArtistCredits(rec.ArtistCredit), r.Title, r.Date, r.ReleaseGroup.PrimaryType)
+		items = append(items, item{title: rec.Title, desc: desc, id: r.ID, meta: r, itemType: mbItemRecording})
+	}
+	return items, nil
+}
+
+// firstRelease picks the earliest release of the recording, preferring ones the filter allows, and
+// keeps the recording's track on it.
+func firstRelease(rec mbSearchRecording, filter mbReleaseFilter) (MBRelease, bool) {
+	best, bestMatch := -1, false
+	for n, r := range rec.Releases {
+		if len(r.Media) == 0 || len(r.Media[0].Track) == 0 {
+			continue
+		}
+		match := (filter.Country == "" || strings.EqualFold(r.Country, filter.Country)) &&
+			(!filter.Official || strings.EqualFold(r.Status, "official")) &&
+			(filter.Format == "" || strings.EqualFold(r.Media[0].Format, filter.Format))
+		earlier := best >= 0 && r.Date != "" && (rec.Releases[best].Date == "" || r.Date < rec.Releases[best].Date)
+		if best < 0 || (match && !bestMatch) || (match == bestMatch && earlier) {
+			best, bestMatch = n, match
+		}
+	}
+	if best < 0 {
+		return MBRelease{}, false
+	}
+	r := rec.Releases[best]
+	track := r.Media[0].Track[0]
+	track.Recording.ID = rec.ID
+	release := r.MBRelease
+	release.Media = []MBMedia{{Position: r.Media[0].Position, Format: r.Media[0].Format, Tracks: []MBTrack{track}}}
+	return release, true
+}
+
+// releaseToOpen returns the release item behind a result row and, for a recording result, the
+// recording whose track to pick once the tracklist arrives.
+func releaseToOpen(i item) (item, string) {
+	if i.itemType != mbItemRecording {
+		return i, ""
+	}
+	r := i.meta.(MBRelease)
+	i.title, i.itemType = r.Title, ""
+	return i, r.Media[0].Tracks[0].Recording.ID
+}
+
+// pickRecordingTrack goes straight to tag editing for the track of the chosen recording; it reports
+// false when the release's tracklist doesn't have it, leaving the user on the tracklist.
+func (m *model) pickRecordingTrack(items []list.Item) (tea.Cmd, bool) {
+	recordingID := m.pickRecording
+	m.pickRecording = ""
+	for n, it := range items {
+		i := it.(item)
+		if t, ok := i.meta.(MBTrack); ok && t.Recording.ID == recordingID {
+			m.tracklist.Select(n)
+			m.selectedTrack = i
+			m.matchNote = tr("録音「%s」のトラックを選びました", i.title)
+			m.state = stateEditTags
+			m.focusIndex = 0
+			m.tagInputs = m.createTagInputs()
+			return tea.Batch(m.tagInputs[0].Focus(), m.prefetchLyrics(), m.prefetchLastFM()), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 中断したダウンロードの復旧 ---
+// 一時フォルダには作ったプロセスのID (.pid) を、ダウンロード中はその曲の情報 (pending.json) も書いておく。
+// 起動時に持ち主のプロセスがもう動いていない一時フォルダを探し、途中まで書かれた保存先のファイルを消して、
+// TUI では中断された曲をキューに入れ直すか尋ねる。曲の情報がない一時フォルダはそのまま消す。
+const (
+	tempPIDFile = ".pid"
+	pendingFile = "pending.json"
+)
+
+type pendingDownload struct {
+	downloadRequest
+	// 変換の出力先。中断されたら途中までのファイルなので消す
+	Output  string    `json:"output,omitempty"`
+	Started time.Time `json:"started"`
+}
+
+// crashedDownload is a download whose process died; dir is its temp folder, removed once the user
+// has decided what to do with it.
+type crashedDownload struct {
+	dir     string
+	pending pendingDownload
+}
+
+type crashedDownloadsMsg struct{ items []crashedDownload }
+
+func writePending(path string, p pendingDownload) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// notePendingOutput records where the conversion writes, just before it starts.
+func notePendingOutput(path, output string) {
+	if path == "" {
+		return
+	}
+	var p pendingDownload
+	data, err := os.ReadFile(path)
+	if err == nil {
+		err = json.Unmarshal(data, &p)
+	}
+	if err == nil {
+		p.Output = output
+		err = writePending(path, p)
+	}
+	if err != nil {
+		log.Printf("Recovery: failed to note output %s: %v", output, err)
+	}
+}
+
+// processAlive reports whether a process with the pid still runs. On Windows FindProcess only
+// succeeds for a running process.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		p.Release()
+		return true
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// tempDirAbandoned tells whether no running process owns the temp folder. Folders from versions that
+// didn't write .pid are judged by age.
+func tempDirAbandoned(dir string, info os.FileInfo) bool {
+	data, err := os.ReadFile(filepath.Join(dir, tempPIDFile))
+	if err != nil {
+		return time.Since(info.ModTime()) > staleTempAge
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return err != nil || !processAlive(pid)
+}
+
+// scanCrashedDownloads removes abandoned temp folders and the partial files their downloads left, and
+// returns the downloads that can be retried (their folders are kept until removeCrashed).
+func scanCrashedDownloads() []crashedDownload {
+	root := filepath.Join(mainDir, tempDir)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		log.Printf("Recovery: %v", err)
+		return nil
+	}
+	var crashed []crashedDownload
+	for _, e := range entries {
+		info, err := e.Info()
+		dir := filepath.Join(root, e.Name())
+		if err != nil || !e.IsDir() || !strings.HasPrefix(e.Name(), "gomusicdl_") || !tempDirAbandoned(dir, info) {
+			continue
+		}
+		var p pendingDownload
+		if data, err := os.ReadFile(filepath.Join(dir, pendingFile)); err == nil && json.Unmarshal(data, &p) == nil && p.VideoURL != "" {
+			if p.Output != "" {
+				if err := os.Remove(p.Output); err == nil {
+					log.Printf("Recovery: removed incomplete file %s", p.Output)
+				}
+			}
+			crashed = append(crashed, crashedDownload{dir: dir, pending: p})
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Recovery: failed to remove %s: %v", dir, err)
+		} else {
+			log.Printf("Recovery: removed orphaned temp folder %s", dir)
+		}
+	}
+	return crashed
+}
+
+func scanCrashedDownloadsCmd() tea.Msg { return crashedDownloadsMsg{items: scanCrashedDownloads()} }
+
+func removeCrashed(items []crashedDownload) {
+	for _, c := range items {
+		if err := os.RemoveAll(c.dir); err != nil {
+			log.Printf("Recovery: failed to remove %s: %v", c.dir, err)
+		}
+	}
+}
+
+func (c crashedDownload) String() string {
+	t := c.pending.Tags
+	if t.Artist == "" {
+		return t.Title
+	}
+	return fmt.Sprintf("%s - %s", t.Artist, t.Title)
+}
+
+// queueItem rebuilds the download as a queue entry that keeps the tags as they were edited.
+func (c crashedDownload) queueItem() queueItem {
+	p := c.pending
+	tags := p.Tags
+	id := "recovered:" + filepath.Base(c.dir)
+	track := item{
+		id: id, title: tags.Title, desc: tags.Artist, artist: tags.Artist,
+		meta: MBTrack{ID: id, Title: tags.Title, Length: tags.DurationSec * 1000},
+	}
+	release := item{id: p.ReleaseID, title: tags.Album, meta: MBRelease{}}
+	if p.Release != nil {
+		release.title, release.meta = p.Release.Title, *p.Release
+	}
+	source := item{
+		title: tags.Title, desc: tags.Artist, id: p.VideoID, url: p.VideoURL,
+		meta: ytDlpVideoInfo{ID: p.VideoID, Title: tags.Title, Duration: float64(tags.DurationSec), audioLang: p.AudioLang},
+	}
+	return queueItem{track: track, release: release, source: source, tags: &tags}
+}
+
+// updateRecover handles the prompt about downloads cut off by a crash.
+func (m *model) updateRecover(msg tea.KeyMsg) {
+	switch strings.ToLower(msg.String()) {
+	case "y", "enter":
+		items := make([]queueItem, len(m.crashed))
+		for i, c := range m.crashed {
+			items[i] = c.queueItem()
+		}
+		m.enqueueItems(items)
+		removeCrashed(m.crashed)
+		m.crashed = nil
+		m.state = stateInput
+		m.openQueue()
+	case "n", "esc":
+		removeCrashed(m.crashed)
+		m.crashed = nil
+		m.state = stateInput
+	}
+}
+
+func (m model) recoverView() string {
+	var b strings.Builder
+	b.WriteString("\n" + tr("前回、次の %d 曲のダウンロード中にプログラムが終了しました。", len(m.crashed)) + "\n\n")
+	for _, c := range m.crashed {
+		fmt.Fprintf(&b, "  • %s  %s\n", c, helpStyle.Render(c.pending.Started.Format("01/02 15:04")))
+	}
+	b.WriteString("\n" + tr("キューに入れ直してもう一度ダウンロードしますか？ (途中まで保存されたファイルは削除済みです)") + "\n")
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- リリースグループの版 ---
+// MusicBrainz のリリース一覧で g を押すと、選んだリリースと同じリリースグループのリリース
+// (通常盤・初回盤・再発・地域違い・配信など) をすべて並べ、タグの元にする版を選び直せる。
+// 版の一覧は検索結果の一覧と入れ替えて表示し、Esc で元の検索結果に戻る。
+type groupReleasesMsg struct {
+	title string // 選んだリリースの名前
+	items []list.Item
+	err   error
+}
+
+// groupReleases browses every release in the release group, oldest first.
+func groupReleases(groupID string) ([]MBRelease, error) {
+	var releases []MBRelease
+	for offset := 0; ; offset += mbBrowseLimit {
+		var page struct {
+			Count    int         `json:"release-count"`
+			Releases []MBRelease `json:"releases"`
+		}
+		apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release?release-group=%s&inc=artist-credits+labels+media+release-groups&fmt=json&limit=%d&offset=%d",
+			groupID, mbBrowseLimit, offset)
+		if err := mbGetJSON(apiURL, &page); err != nil {
+			return nil, err
+		}
+		releases = append(releases, page.Releases...)
+		if len(page.Releases) == 0 || len(releases) >= page.Count {
+			break
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, b := releases[i].Date, releases[j].Date
+		return a != "" && (b == "" || a < b)
+	})
+	return releases, nil
+}
+
+func groupReleasesCmd(groupID, title string) tea.Cmd {
+	return func() tea.Msg {
+		releases, err := groupReleases(groupID)
+		if err != nil {
+			return groupReleasesMsg{title: title, err: err}
+		}
+		owned := ownedTracks()
+		var items []list.Item
+		for _, r := range releases {
+			items = append(items, item{title: editionTitle(r), desc: editionDesc(r), id: r.ID, meta: r, badge: completionBadge(owned[r.ID], releaseTrackCount(r))})
+		}
+		return groupReleasesMsg{title: title, items: items}
+	}
+}
+
+func editionTitle(r MBRelease) string {
+	if r.Disambiguation != "" {
+		return fmt.Sprintf("%s (%s)", r.Title, r.Disambiguation)
+	}
+	return r.Title
+}
+
+// editionDesc tells editions apart: date, country, media, label and catalog number.
+func editionDesc(r MBRelease) string {
+	parts := []string{firstNonEmpty(r.Date, tr("日付不明"))}
+	if r.Country != "" {
+		parts = append(parts, r.Country)
+	}
+	var formats []string
+	for _, media := range r.Media {
+		if media.Format != "" && !slices.Contains(formats, media.Format) {
+			formats = append(formats, media.Format)
+		}
+	}
+	if media := strings.Join(formats, "+"); len(r.Media) > 1 {
+		parts = append(parts, fmt.Sprintf("%s ×%d", firstNonEmpty(media, "?"), len(r.Media)))
+	} else if media != "" {
+		parts = append(parts, media)
+	}
+	if label, catno := releaseLabel(r); label != "" || catno != "" {
+		parts = append(parts, strings.TrimSpace(label+" "+catno))
+	}
+	if r.Status != "" && !strings.EqualFold(r.Status, "official") {
+		parts = append(parts, r.Status)
+	}
+	return fmt.Sprintf("%s — %s", joinArtistCredits(r.ArtistCredit), strings.Join(parts, " · "))
+}
+
+// openReleaseGroup lists the editions of the highlighted release in place of the search results.
+func (m *model) openReleaseGroup() tea.Cmd {
+	if m.groupParent != nil {
+		return m.groupOnly()
+	}
+	i, ok := m.mbResults.SelectedItem().(item)
+	if !ok {
+		return nil
+	}
+	r, _ := i.meta.(MBRelease)
+	if r.ReleaseGroup.ID == "" {
+		return m.mbResults.NewStatusMessage(tr("このリリースのリリースグループが分かりません"))
+	}
+	return tea.Batch(m.mbResults.NewStatusMessage(tr("同じリリースグループの版を取得中です...")), groupReleasesCmd(r.ReleaseGroup.ID, r.Title))
+}
+
+func (m *model) showReleaseGroup(msg groupReleasesMsg) tea.Cmd {
+	if m.state != stateSelectMB || m.groupParent != nil {
+		return nil
+	}
+	if msg.err != nil {
+		return m.mbResults.NewStatusMessage(tr("版の一覧の取得に失敗しました: %v", msg.err))
+	}
+	parent := m.mbResults
+	m.groupParent = &parent
+	m.mbResults = newList(tr("「%s」の版 (%d件)", msg.title, len(msg.items)), msg.items)
+	m.mbResults.SetSize(m.width-4, m.height-8)
+	return nil
+}
+
+// groupOnly tells that search keys (more results, filters) wait until the search results are back.
+func (m *model) groupOnly() tea.Cmd {
+	return m.mbResults.NewStatusMessage(tr("版の一覧を表示中です (%s: 検索結果に戻る)", keyName(keymap.back)))
+}
+
+// closeReleaseGroup goes back to the search results; it reports false when they are already shown.
+func (m *model) closeReleaseGroup() bool {
+	if m.groupParent == nil {
+		return false
+	}
+	m.mbResults, m.groupParent = *m.groupParent, nil
+	return true
+}
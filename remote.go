@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- リモート (daemon のクライアント) ---
+// remote.url を設定すると、TUI は手元で yt-dlp を動かさず、daemon -listen の API を通して
+// サーバー側で YouTube を検索し、ダウンロードもサーバーのジョブキューに任せる。MusicBrainz の検索と
+// タグの編集は手元で行い、決めたタグをそのまま送る。進み具合は /events で追いかける。
+const remoteRequestTimeout = cmdTimeout + 15*time.Second
+
+type remoteConfig struct {
+	// daemon -listen のURL (例: http://nas.local:8080)。空なら手元でダウンロードする
+	URL string `json:"url"`
+	// token サブコマンドで発行した (または server.tokens に書いた) トークン
+	Token string `json:"token"`
+}
+
+// tuiMode is set when the TUI runs. Only the TUI uses remote.url: the daemon and the other
+// subcommands always work locally, so a server with remote.url in its own config doesn't forward jobs
+// to itself.
+var tuiMode bool
+
+func remoteEnabled() bool { return tuiMode && cfg.Remote.URL != "" }
+
+// downloadRequest is a download as decided in the TUI: the video, the release it was matched to and
+// the tags as edited. Release is nil for a download without tags. Remote TUIs send it to the server,
+// and it is kept in the temp folder to retry a download that was cut off.
+type downloadRequest struct {
+	VideoURL  string     `json:"video_url"`
+	VideoID   string     `json:"video_id,omitempty"`
+	AudioLang string     `json:"audio_lang,omitempty"`
+	ReleaseID string     `json:"release_id,omitempty"`
+	Release   *MBRelease `json:"release,omitempty"`
+	Tags      finalTags  `json:"tags"`
+}
+
+func newDownloadRequest(selectedYT, selectedMB item, tags finalTags) downloadRequest {
+	d := downloadRequest{VideoURL: selectedYT.url, VideoID: selectedYT.id, Tags: tags}
+	if info, ok := selectedYT.meta.(ytDlpVideoInfo); ok {
+		d.AudioLang = info.audioLang
+	}
+	if release, ok := selectedMB.meta.(MBRelease); ok {
+		d.Release, d.ReleaseID = &release, selectedMB.id
+	}
+	return d
+}
+
+// --- サーバー側 ---
+
+func serveYouTubeSearch(ytDlpPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := strings.TrimSpace(r.URL.Query().Get("q"))
+		if q == "" {
+			http.Error(w, "q is required", http.StatusBadRequest)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		items, err := doYouTubeSearchPage(ytDlpPath, q, max(page, 0))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		infos := []ytDlpVideoInfo{}
+		for _, i := range items {
+			infos = append(infos, i.(item).meta.(ytDlpVideoInfo))
+		}
+		writeJSON(w, http.StatusOK, infos)
+	}
+}
+
+func serveURLInfo(ytDlpPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := strings.TrimSpace(r.URL.Query().Get("url"))
+		if u == "" {
+			http.Error(w, "url is required", http.StatusBadRequest)
+			return
+		}
+		info := getURLInfoCmd(ytDlpPath, u)().(urlInfoFetchedMsg)
+		if info.err != nil {
+			http.Error(w, info.err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeJSON(w, http.StatusOK, info.ytItem.meta)
+	}
+}
+
+func submitDownload(w http.ResponseWriter, r *http.Request) {
+	var d downloadRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 4<<20)).Decode(&d); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !strings.HasPrefix(d.VideoURL, "http") {
+		http.Error(w, "video_url is required", http.StatusBadRequest)
+		return
+	}
+	job, err := enqueueDownloadJob(d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// processRemoteDownload downloads a job sent by a remote TUI. The video is looked up again here, since
+// the stream URLs the client saw may not be valid from the server.
+func processRemoteDownload(ytDlpPath, ffmpegPath string, job daemonJob) {
+	d := job.Download
+	info := getURLInfoCmd(ytDlpPath, d.VideoURL)().(urlInfoFetchedMsg)
+	if info.err != nil {
+		reportPhase(phaseFailed, firstLine(info.err.Error()))
+		daemonLog("失敗: %s: %s", job.Query, firstLine(info.err.Error()))
+		return
+	}
+	yt := info.ytItem
+	if d.AudioLang != "" {
+		meta := yt.meta.(ytDlpVideoInfo)
+		meta.audioLang = d.AudioLang
+		yt.meta = meta
+	}
+	reportPhase(phaseDownload, yt.title)
+	var res downloadFinishedMsg
+	if d.Release == nil {
+		res = simpleDownloadCmd(ytDlpPath, ffmpegPath, yt)().(downloadFinishedMsg)
+	} else {
+		release := item{title: d.Release.Title, id: d.ReleaseID, meta: *d.Release}
+		res = downloadCmd(ytDlpPath, ffmpegPath, yt, release, d.Tags)().(downloadFinishedMsg)
+	}
+	if res.err != nil {
+		reportPhase(phaseFailed, firstLine(res.err.Error()))
+		daemonLog("失敗: %s: %s", job.Query, firstLine(res.err.Error()))
+		return
+	}
+	reportPhase(phaseDone, res.filename)
+	daemonLog("完了: %s → %s (リモート)", job.Query, res.filename)
+}
+
+// --- TUI 側 ---
+
+func remoteRequest(method, path string, body interface{}) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimRight(cfg.Remote.URL, "/")+path, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if cfg.Remote.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Remote.Token)
+	}
+	return req, nil
+}
+
+// remoteCall sends the request and decodes the JSON answer into v.
+func remoteCall(method, path string, body, v interface{}) error {
+	req, err := remoteRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	client := *httpClient
+	client.Timeout = remoteRequestTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return errorf("サーバーに接続できません: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		if resp.StatusCode == http.StatusUnauthorized {
+			return errorf("サーバーに認証されませんでした。remote.token を確認してください")
+		}
+		return errorf("サーバーのエラー (%s):\n%s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func remoteYouTubeSearch(query string, page int) ([]list.Item, error) {
+	var infos []ytDlpVideoInfo
+	if err := remoteCall("GET", fmt.Sprintf("/youtube/search?q=%s&page=%d", url.QueryEscape(query), page), nil, &infos); err != nil {
+		return nil, err
+	}
+	var items []list.Item
+	for _, info := range infos {
+		items = append(items, videoItem(info, "https://www.youtube.com/watch?v="+info.ID))
+	}
+	return items, nil
+}
+
+func remoteURLInfo(query string) tea.Msg {
+	var info ytDlpVideoInfo
+	if err := remoteCall("GET", "/youtube/info?url="+url.QueryEscape(query), nil, &info); err != nil {
+		return urlInfoFetchedMsg{err: err}
+	}
+	return urlInfoFetchedMsg{ytItem: videoItem(info, query)}
+}
+
+// remoteDownloadCmd hands the download to the server and waits for its job to finish. The event
+// stream is opened before submitting so none of the job's events are missed.
+func remoteDownloadCmd(selectedYT, selectedMB item, tags finalTags) tea.Cmd {
+	return func() tea.Msg {
+		d := newDownloadRequest(selectedYT, selectedMB, tags)
+		req, err := remoteRequest("GET", "/events", nil)
+		if err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		streamClient := *httpClient
+		streamClient.Timeout = 0 // ダウンロードが終わるまで読み続ける
+		resp, err := streamClient.Do(req)
+		if err != nil {
+			return downloadFinishedMsg{err: errorf("サーバーに接続できません: %w", err)}
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return downloadFinishedMsg{err: errorf("サーバーの進捗を受け取れません (%s)", resp.Status)}
+		}
+		events, done := make(chan progressEvent, 64), make(chan struct{})
+		defer close(done)
+		go readProgressEvents(resp.Body, events, done)
+
+		var job daemonJob
+		if err := remoteCall("POST", "/downloads", d, &job); err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		for e := range events {
+			if e.Job != job.ID {
+				continue
+			}
+			switch e.Phase {
+			case phaseDone:
+				return downloadFinishedMsg{filename: tr("サーバー: %s", e.Message)}
+			case phaseFailed:
+				return downloadFinishedMsg{err: errorf("サーバーでのダウンロードに失敗:\n%s", e.Message)}
+			}
+		}
+		return downloadFinishedMsg{err: errorf("サーバーとの接続が切れました。ダウンロードはサーバーで続いている可能性があります")}
+	}
+}
+
+// readProgressEvents parses a Server-Sent Events stream until it ends or done is closed.
+func readProgressEvents(r io.Reader, events chan<- progressEvent, done <-chan struct{}) {
+	defer close(events)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e progressEvent
+		if json.Unmarshal([]byte(data), &e) != nil {
+			continue
+		}
+		select {
+		case events <- e:
+		case <-done:
+			return
+		}
+	}
+}
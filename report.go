@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// --- ライブラリレポート ---
+type reportAlbum struct {
+	Title, Artist, Year string
+	Formats             []string
+	Tracks              int
+	SizeBytes           int64
+	CoverFile           string // カバーを埋め込んでいる代表ファイル
+	CoverSource         coverSource
+}
+
+func runExportReport(args []string) error {
+	fs := flag.NewFlagSet("export-report", flag.ContinueOnError)
+	format := fs.String("format", "html", tr("出力形式 (html または md)"))
+	out := fs.String("out", "", tr("出力先ファイル (省略時は %s/library-report.<形式>)", mainDir))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "html" && *format != "md" {
+		return errorf("未対応の形式です: %s", *format)
+	}
+	if *out == "" {
+		*out = filepath.Join(mainDir, "library-report."+*format)
+	}
+	entries, err := loadHistory()
+	if err != nil {
+		return errorf("履歴の読み込みに失敗: %w", err)
+	}
+	albums := buildReportAlbums(entries)
+	ffmpegPath, _ := findFfmpeg()
+
+	var content string
+	if *format == "html" {
+		content = renderHTMLReport(albums, ffmpegPath)
+	} else {
+		content, err = renderMarkdownReport(albums, ffmpegPath, *out)
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(*out, []byte(content), 0o644); err != nil {
+		return err
+	}
+	fmt.Print(tr("%d件のアルバムをレポートに出力しました: %s\n", len(albums), *out))
+	return nil
+}
+
+func buildReportAlbums(entries []historyEntry) []*reportAlbum {
+	byKey := map[string]*reportAlbum{}
+	var albums []*reportAlbum
+	for _, e := range entries {
+		info, err := os.Stat(e.Path)
+		if err != nil {
+			continue // 削除・移動済みのファイルは対象外
+		}
+		title := e.Album
+		if title == "" {
+			title = tr("(アルバム情報なし)")
+		}
+		key := strings.ToLower(e.Artist + "\x00" + title)
+		a, ok := byKey[key]
+		if !ok {
+			a = &reportAlbum{Title: title, Artist: e.Artist}
+			byKey[key] = a
+			albums = append(albums, a)
+		}
+		if a.Year == "" && len(e.Date) >= 4 {
+			a.Year = e.Date[:4]
+		}
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(e.Path)), ".")
+		if !containsString(a.Formats, ext) {
+			a.Formats = append(a.Formats, ext)
+		}
+		a.Tracks++
+		a.SizeBytes += info.Size()
+		if a.CoverFile == "" && e.CoverSource != coverSourceNone {
+			a.CoverFile, a.CoverSource = e.Path, e.CoverSource
+		}
+	}
+	sort.Slice(albums, func(i, j int) bool {
+		if albums[i].Artist != albums[j].Artist {
+			return albums[i].Artist < albums[j].Artist
+		}
+		return albums[i].Year < albums[j].Year
+	})
+	return albums
+}
+
+// extractThumbnail pulls the embedded picture out of an audio file as a small JPEG.
+func extractThumbnail(ffmpegPath, audioPath string) ([]byte, error) {
+	if ffmpegPath == "" || audioPath == "" {
+		return nil, fmt.Errorf("no source")
+	}
+	cmd := exec.Command(ffmpegPath, "-v", "error", "-i", audioPath, "-an", "-vf", "scale=160:160:force_original_aspect_ratio=decrease", "-frames:v", "1", "-f", "image2", "-c:v", "mjpeg", "pipe:1")
+	out, err := cmd.Output()
+	if err != nil || len(out) == 0 {
+		return nil, fmt.Errorf("thumbnail extraction failed: %v", err)
+	}
+	return out, nil
+}
+
+func renderHTMLReport(albums []*reportAlbum, ffmpegPath string) string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html>
+<html lang="ja"><head><meta charset="utf-8"><title>Music Library Report</title>
+<style>
+body{font-family:sans-serif;background:#282a36;color:#f8f8f2;margin:2em}
+.grid{display:grid;grid-template-columns:repeat(auto-fill,minmax(180px,1fr));gap:1.2em}
+.album{background:#44475a;border-radius:6px;padding:10px}
+.album img,.noart{width:160px;height:160px;object-fit:cover;display:block;margin:auto}
+.noart{background:#6272a4;line-height:160px;text-align:center;color:#ff5555}
+.title{font-weight:bold;margin-top:6px}.meta{color:#bd93f9;font-size:.85em}
+</style></head><body>
+`)
+	var totalSize int64
+	var artless int
+	for _, a := range albums {
+		totalSize += a.SizeBytes
+		if a.CoverFile == "" {
+			artless++
+		}
+	}
+	b.WriteString(fmt.Sprintf("<h1>Music Library Report</h1>\n<p>%d albums, %s, %d without art — generated %s</p>\n<div class=\"grid\">\n",
+		len(albums), formatBytes(totalSize), artless, time.Now().Format("2006-01-02 15:04")))
+	for _, a := range albums {
+		b.WriteString(`<div class="album">`)
+		if thumb, err := extractThumbnail(ffmpegPath, a.CoverFile); err == nil {
+			b.WriteString(fmt.Sprintf(`<img src="data:image/jpeg;base64,%s" alt="">`, base64.StdEncoding.EncodeToString(thumb)))
+		} else {
+			b.WriteString(`<div class="noart">NO ART</div>`)
+		}
+		b.WriteString(fmt.Sprintf(`<div class="title">%s</div><div>%s</div><div class="meta">%s · %s · %d tracks · %s</div>`,
+			html.EscapeString(a.Title), html.EscapeString(a.Artist), html.EscapeString(orDash(a.Year)),
+			html.EscapeString(strings.Join(a.Formats, "/")), a.Tracks, formatBytes(a.SizeBytes)))
+		// 画像が無いアルバムは NO ART で分かるので、代わりの画像を使っているときだけ出所を書く
+		if a.CoverSource == coverSourceYTThumbnail {
+			b.WriteString(fmt.Sprintf(`<div class="meta">art: %s</div>`, html.EscapeString(string(a.CoverSource))))
+		}
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</div>\n</body></html>\n")
+	return b.String()
+}
+
+func renderMarkdownReport(albums []*reportAlbum, ffmpegPath, outPath string) (string, error) {
+	assetsDir := strings.TrimSuffix(outPath, filepath.Ext(outPath)) + "_covers"
+	var b strings.Builder
+	b.WriteString("# Music Library Report\n\n")
+	b.WriteString(fmt.Sprintf("Generated %s\n\n", time.Now().Format("2006-01-02 15:04")))
+	b.WriteString("| Cover | Album | Artist | Year | Format | Tracks | Size |\n|---|---|---|---|---|---|---|\n")
+	var artless []string
+	for i, a := range albums {
+		cover := "—"
+		if thumb, err := extractThumbnail(ffmpegPath, a.CoverFile); err == nil {
+			if err := os.MkdirAll(assetsDir, os.ModePerm); err != nil {
+				return "", err
+			}
+			name := fmt.Sprintf("%03d.jpg", i+1)
+			if err := os.WriteFile(filepath.Join(assetsDir, name), thumb, 0o644); err != nil {
+				return "", err
+			}
+			cover = fmt.Sprintf("![](%s/%s)", filepath.Base(assetsDir), name)
+		} else {
+			artless = append(artless, fmt.Sprintf("%s — %s", a.Artist, a.Title))
+		}
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %d | %s |\n", cover, mdEscape(a.Title), mdEscape(a.Artist),
+			orDash(a.Year), strings.Join(a.Formats, "/"), a.Tracks, formatBytes(a.SizeBytes)))
+	}
+	if len(artless) > 0 {
+		b.WriteString("\n## Albums without art\n\n")
+		for _, s := range artless {
+			b.WriteString("- " + mdEscape(s) + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+func mdEscape(s string) string { return strings.ReplaceAll(s, "|", `\|`) }
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
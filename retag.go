@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- タグの一括置換 ---
+const (
+	replFieldsInput = iota
+	replFindInput
+	replWithInput
+)
+
+type tagChange struct{ path, key, old, new string }
+
+type replaceSpec struct {
+	fields []string // 空なら LYRICS 以外の全タグ
+	find   *regexp.Regexp
+	with   string
+}
+
+type replacePlannedMsg struct {
+	changes []tagChange
+	files   int
+	err     error
+}
+
+type replaceAppliedMsg struct {
+	files int
+	err   error
+}
+
+func newReplaceInputs() []textinput.Model {
+	inputs := make([]textinput.Model, 3)
+	placeholders := []string{tr("artist, albumartist (空ならすべてのタグ)"), tr("置換前の文字列"), tr("置換後の文字列 (正規表現では $1 で参照)")}
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].Placeholder = placeholders[i]
+		inputs[i].Width = 50
+	}
+	inputs[replFieldsInput].SetValue("artist")
+	return inputs
+}
+
+func parseReplaceSpec(fields, find, with string, useRegex bool) (replaceSpec, error) {
+	if find == "" {
+		return replaceSpec{}, errorf("置換前の文字列を入力してください")
+	}
+	pattern := find
+	if !useRegex {
+		pattern = regexp.QuoteMeta(find)
+		with = strings.ReplaceAll(with, "$", "$$")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return replaceSpec{}, errorf("正規表現が不正です: %w", err)
+	}
+	spec := replaceSpec{find: re, with: with}
+	for _, f := range strings.Split(fields, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			spec.fields = append(spec.fields, strings.ToUpper(f))
+		}
+	}
+	return spec, nil
+}
+
+func (s replaceSpec) covers(key string) bool {
+	key = strings.ToUpper(key)
+	if len(s.fields) == 0 {
+		return key != "LYRICS"
+	}
+	return containsString(s.fields, key)
+}
+
+// planReplaceCmd reads the tags of every existing FLAC among rows and collects the changes the
+// replacement would make, without writing anything.
+func planReplaceCmd(rows []historyRow, spec replaceSpec) tea.Cmd {
+	return func() tea.Msg {
+		var changes []tagChange
+		files := 0
+		for _, r := range rows {
+			if !r.exists || !strings.EqualFold(filepath.Ext(r.entry.Path), ".flac") {
+				continue
+			}
+			tags, err := readFLACTags(r.entry.Path)
+			if err != nil {
+				log.Printf("Retag: skipping %s: %v", r.entry.Path, err)
+				continue
+			}
+			changed := false
+			for _, c := range tags.comments {
+				key, value, ok := strings.Cut(c, "=")
+				if !ok || !spec.covers(key) {
+					continue
+				}
+				if nv := spec.find.ReplaceAllString(value, spec.with); nv != value {
+					changes = append(changes, tagChange{path: r.entry.Path, key: strings.ToUpper(key), old: value, new: nv})
+					changed = true
+				}
+			}
+			if changed {
+				files++
+			}
+		}
+		return replacePlannedMsg{changes: changes, files: files}
+	}
+}
+
+// applyReplaceCmd rewrites the planned tags in place and keeps the history entries in sync.
+func applyReplaceCmd(changes []tagChange) tea.Cmd {
+	return func() tea.Msg {
+		byPath := map[string][]tagChange{}
+		var order []string
+		for _, c := range changes {
+			if _, ok := byPath[c.path]; !ok {
+				order = append(order, c.path)
+			}
+			byPath[c.path] = append(byPath[c.path], c)
+		}
+		written := 0
+		for _, path := range order {
+			tags, err := readFLACTags(path)
+			if err != nil {
+				return replaceAppliedMsg{files: written, err: err}
+			}
+			for i, c := range tags.comments {
+				key, value, _ := strings.Cut(c, "=")
+				for _, ch := range byPath[path] {
+					if strings.EqualFold(key, ch.key) && value == ch.old {
+						tags.comments[i] = key + "=" + ch.new
+					}
+				}
+			}
+			if err := writeFLACTags(path, tags); err != nil {
+				return replaceAppliedMsg{files: written, err: fmt.Errorf("%s: %w", filepath.Base(path), err)}
+			}
+			written++
+		}
+		if err := syncHistoryTags(byPath); err != nil {
+			log.Printf("Retag: failed to update history: %v", err)
+		}
+		return replaceAppliedMsg{files: written}
+	}
+}
+
+func syncHistoryTags(byPath map[string][]tagChange) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	for i, e := range entries {
+		for _, c := range byPath[e.Path] {
+			switch c.key {
+			case "TITLE":
+				entries[i].Title = c.new
+			case "ARTIST":
+				entries[i].Artist = c.new
+			case "ALBUM":
+				entries[i].Album = c.new
+			}
+		}
+	}
+	return saveHistory(entries)
+}
+
+func (m *model) openReplace() tea.Cmd {
+	m.state, m.replPlan, m.replNote, m.replFocus = stateReplace, nil, "", 0
+	if m.replInputs == nil {
+		m.replInputs = newReplaceInputs()
+	}
+	return m.focusReplaceInput()
+}
+
+func (m *model) focusReplaceInput() tea.Cmd {
+	var cmd tea.Cmd
+	for i := range m.replInputs {
+		if i == m.replFocus {
+			cmd = m.replInputs[i].Focus()
+		} else {
+			m.replInputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+// updateReplace handles keys on the bulk replace screen: editing the fields, then previewing the plan.
+func (m *model) updateReplace(msg tea.KeyMsg) tea.Cmd {
+	if m.replPlan != nil {
+		switch msg.String() {
+		case "y", "enter":
+			changes := m.replPlan
+			m.state, m.statusMsg = stateSearching, tr("タグを書き換え中です...")
+			return tea.Batch(m.spinner.Tick, applyReplaceCmd(changes))
+		case "n", "esc":
+			m.replPlan = nil
+			return m.focusReplaceInput()
+		}
+		return nil
+	}
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.state = stateHistory
+	case tea.KeyCtrlT:
+		m.replRegex = !m.replRegex
+	case tea.KeyUp, tea.KeyShiftTab:
+		m.replFocus = (m.replFocus + len(m.replInputs) - 1) % len(m.replInputs)
+		return m.focusReplaceInput()
+	case tea.KeyDown, tea.KeyTab:
+		m.replFocus = (m.replFocus + 1) % len(m.replInputs)
+		return m.focusReplaceInput()
+	case tea.KeyEnter:
+		if m.replFocus < len(m.replInputs)-1 {
+			m.replFocus++
+			return m.focusReplaceInput()
+		}
+		spec, err := parseReplaceSpec(m.replInputs[replFieldsInput].Value(), m.replInputs[replFindInput].Value(), m.replInputs[replWithInput].Value(), m.replRegex)
+		if err != nil {
+			m.replNote = err.Error()
+			return nil
+		}
+		rows, _ := m.visibleHistory(time.Now())
+		m.state, m.statusMsg = stateSearching, tr("変更内容を確認中です...")
+		return tea.Batch(m.spinner.Tick, planReplaceCmd(rows, spec))
+	}
+	return nil
+}
+
+func (m model) replaceView() string {
+	var b strings.Builder
+	rows, label := m.visibleHistory(time.Now())
+	if m.replPlan != nil {
+		b.WriteString("\n" + listTitleStyle.Render(tr("置換のプレビュー — %s", m.replNote)) + "\n\n")
+		limit := max(m.height-12, 5)
+		last := ""
+		for i, c := range m.replPlan {
+			if i >= limit {
+				b.WriteString(helpStyle.Render(tr("  … 他 %d 項目", len(m.replPlan)-limit)) + "\n")
+				break
+			}
+			if c.path != last {
+				b.WriteString("  " + lipgloss.NewStyle().Foreground(pinkColor).Render(filepath.Base(c.path)) + "\n")
+				last = c.path
+			}
+			b.WriteString(fmt.Sprintf("    %s %s → %s\n", helpStyle.Render(c.key+":"),
+				lipgloss.NewStyle().Foreground(redColor).Render(c.old), lipgloss.NewStyle().Foreground(greenColor).Render(c.new)))
+		}
+		return b.String()
+	}
+	target := tr("対象: 履歴の%d件", len(rows))
+	if m.historyQuery != "" {
+		target += tr(" (フィルタ: %s)", label)
+	}
+	b.WriteString("\n" + listTitleStyle.Render(tr("タグの一括置換")) + "\n\n  " + helpStyle.Render(target) + "\n\n")
+	labels := []string{tr("タグ:"), tr("検索:"), tr("置換:")}
+	for i, input := range m.replInputs {
+		b.WriteString(fmt.Sprintf("  %-6s %s\n", labels[i], input.View()))
+	}
+	regex := "OFF"
+	if m.replRegex {
+		regex = "ON"
+	}
+	b.WriteString(tr("\n  正規表現: %s\n", regex))
+	if m.replNote != "" {
+		b.WriteString("\n  " + lipgloss.NewStyle().Foreground(yellowColor).Render(m.replNote) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- 再試行 ---
+// 通信エラーや 5xx / 429 の応答、yt-dlp の失敗は retry の設定に従って間隔を空けて再試行する。
+// 再試行の様子は進捗画面に「再試行 2/3 (5秒後)」のように表示する。
+const (
+	defaultRetryAttempts      = 3
+	defaultRetryBackoffSec    = 2
+	defaultRetryMaxBackoffSec = 30
+	retryNoteLinger           = 10 * time.Second // 再試行が始まってから表示を残す時間
+)
+
+type retryConfig struct {
+	// 失敗したときに再試行する回数。0 で再試行しない
+	Attempts int `json:"attempts"`
+	// 最初の再試行までの待ち時間 (秒)。以降は倍々に伸びる
+	BackoffSec float64 `json:"backoff_sec"`
+	// 待ち時間の上限 (秒)
+	MaxBackoffSec float64 `json:"max_backoff_sec"`
+	// 取得元ごとの制限時間 (秒)。network.timeout_sec より優先する
+	// キー: musicbrainz / lrclib / genius / musixmatch / netease / itunes / cover / youtube / listenbrainz / lastfm / spotify / webhook / mediaserver / rclone / sftp / webdav / s3
+	TimeoutSec map[string]int `json:"timeout_sec"`
+}
+
+// retryMsg reports a retry that is about to happen, for the progress line.
+type retryMsg struct {
+	provider     string
+	attempt, max int
+	wait         time.Duration
+	cause        string
+	at           time.Time
+}
+
+// retryEvents carries retries from the worker goroutines to the TUI. Sends never block, so headless
+// commands (nobody listening) are unaffected.
+var retryEvents = make(chan retryMsg, 16)
+
+// waitForRetryCmd delivers the next retry; the model re-issues it after each one.
+func waitForRetryCmd() tea.Msg { return <-retryEvents }
+
+// backoff is the wait before the given retry (1 = the first).
+func (c retryConfig) backoff(retry int) time.Duration {
+	d := time.Duration(c.BackoffSec * float64(time.Second))
+	for i := 1; i < retry; i++ {
+		d *= 2
+	}
+	if limit := time.Duration(c.MaxBackoffSec * float64(time.Second)); limit > 0 && d > limit {
+		d = limit
+	}
+	return d
+}
+
+// timeout is the provider's own time limit, or 0 to use the default.
+func (c retryConfig) timeout(provider string) time.Duration {
+	return time.Duration(c.TimeoutSec[provider]) * time.Second
+}
+
+// notifyRetry logs a retry and shows it in the TUI if one is running.
+func notifyRetry(provider string, retry int, wait time.Duration, cause string) {
+	log.Printf("Retry: %s failed (%s), retry %d/%d in %s", provider, cause, retry, cfg.Retry.Attempts, wait)
+	select {
+	case retryEvents <- retryMsg{provider: provider, attempt: retry, max: cfg.Retry.Attempts, wait: wait, cause: cause, at: time.Now()}:
+	default:
+	}
+}
+
+// clientFor is the shared client with the provider's timeout applied (same transport, so connections
+// are still reused).
+func clientFor(provider string) *http.Client {
+	t := cfg.Retry.timeout(provider)
+	if t <= 0 {
+		return httpClient
+	}
+	c := *httpClient
+	c.Timeout = t
+	return &c
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// doWithRetry sends a request, retrying network errors and 5xx / 429 responses. Request bodies are
+// rewound through GetBody. The last response or error is returned as-is once the retries run out.
+func doWithRetry(provider string, req *http.Request) (*http.Response, error) {
+	client := clientFor(provider)
+	for retry := 1; ; retry++ {
+		if retry > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		resp, err := client.Do(req)
+		var cause string
+		var wait time.Duration
+		switch {
+		case err != nil:
+			cause = shortError(err)
+		case retryableStatus(resp.StatusCode):
+			cause = resp.Status
+			wait = retryAfter(resp.Header.Get("Retry-After"))
+		default:
+			return resp, nil
+		}
+		if retry > cfg.Retry.Attempts {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		wait = max(wait, cfg.Retry.backoff(retry))
+		if limit := time.Duration(cfg.Retry.MaxBackoffSec * float64(time.Second)); limit > 0 {
+			wait = min(wait, limit)
+		}
+		notifyRetry(provider, retry, wait, cause)
+		time.Sleep(wait)
+	}
+}
+
+// shortError keeps the last part of a wrapped network error ("connection refused", "timeout", ...).
+func shortError(err error) string {
+	msg := err.Error()
+	if i := strings.LastIndex(msg, ": "); i >= 0 {
+		msg = msg[i+2:]
+	}
+	return msg
+}
+
+// retryLine renders the latest retry for the progress screen while it is recent.
+func (m model) retryLine() string {
+	r := m.lastRetry
+	if r.provider == "" || time.Since(r.at) > r.wait+retryNoteLinger {
+		return ""
+	}
+	text := tr("%s: %s — 再試行 %d/%d", r.provider, r.cause, r.attempt, r.max)
+	if left := time.Until(r.at.Add(r.wait)); left > 0 {
+		text += tr(" (%d秒後)…", int(left.Seconds())+1)
+	} else {
+		text += tr(" 中…")
+	}
+	return lipgloss.NewStyle().Foreground(yellowColor).Render(text)
+}
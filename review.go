@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 要確認キュー ---
+// daemon が自信を持って照合できなかった曲。TUIで候補を確認するか、手動で検索し直す。
+const reviewFile = "review.json"
+
+type reviewEntry struct {
+	Time         time.Time `json:"time"`
+	Query        string    `json:"query"`
+	Reason       string    `json:"reason"`
+	Score        float64   `json:"score,omitempty"`
+	VideoID      string    `json:"video_id,omitempty"`
+	VideoURL     string    `json:"video_url,omitempty"`
+	VideoTitle   string    `json:"video_title,omitempty"`
+	ReleaseID    string    `json:"release_id,omitempty"`
+	ReleaseTitle string    `json:"release_title,omitempty"`
+	RecordingID  string    `json:"recording_id,omitempty"`
+	TrackTitle   string    `json:"track_title,omitempty"`
+}
+
+func (e reviewEntry) hasCandidate() bool { return e.VideoURL != "" && e.ReleaseID != "" }
+
+type reviewLoadedMsg struct {
+	entries []reviewEntry
+	err     error
+}
+
+var reviewMu sync.Mutex
+
+func reviewPath() string { return filepath.Join(mainDir, reviewFile) }
+
+func loadReview() ([]reviewEntry, error) {
+	data, err := os.ReadFile(reviewPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []reviewEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func saveReview(entries []reviewEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := reviewPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, reviewPath())
+}
+
+func appendReview(e reviewEntry) error {
+	reviewMu.Lock()
+	defer reviewMu.Unlock()
+	entries, err := loadReview()
+	if err != nil {
+		return err
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	return saveReview(append(entries, e))
+}
+
+// removeReview drops a resolved entry, identified by its time and query.
+func removeReview(e reviewEntry) error {
+	reviewMu.Lock()
+	defer reviewMu.Unlock()
+	entries, err := loadReview()
+	if err != nil {
+		return err
+	}
+	var kept []reviewEntry
+	for _, r := range entries {
+		if !(r.Time.Equal(e.Time) && r.Query == e.Query) {
+			kept = append(kept, r)
+		}
+	}
+	return saveReview(kept)
+}
+
+func loadReviewCmd() tea.Msg {
+	entries, err := loadReview()
+	return reviewLoadedMsg{entries: entries, err: err}
+}
+
+func removeReviewCmd(e reviewEntry) tea.Cmd {
+	return func() tea.Msg {
+		if err := removeReview(e); err != nil {
+			return reviewLoadedMsg{err: err}
+		}
+		return loadReviewCmd()
+	}
+}
+
+func reviewItems(entries []reviewEntry) []list.Item {
+	items := make([]list.Item, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		desc := fmt.Sprintf("%s  %s", e.Time.Format("01/02 15:04"), e.Reason)
+		if e.hasCandidate() {
+			desc = tr("%s  候補: %s / %s (%d%%)", e.Time.Format("01/02 15:04"), e.TrackTitle, e.ReleaseTitle, pct(e.Score))
+		}
+		items = append(items, item{title: e.Query, desc: desc, meta: e})
+	}
+	return items
+}
+
+func (m *model) openReview() {
+	m.state = stateReview
+	m.reviewList = newList(tr("自動処理で確認が必要な曲 (%d件)", len(m.review)), reviewItems(m.review))
+	m.reviewList.SetSize(m.width-4, m.height-8)
+}
+
+// reviewCandidateCmd re-fetches the stored candidate and hands it to the same flow as the interactive
+// auto-match, so the user lands on the tag editor with the pair preselected.
+func reviewCandidateCmd(ytDlpPath string, e reviewEntry) tea.Cmd {
+	return func() tea.Msg {
+		info := getURLInfoCmd(ytDlpPath, e.VideoURL)().(urlInfoFetchedMsg)
+		if info.err != nil {
+			return autoMatchFinishedMsg{err: info.err}
+		}
+		tracks, release, err := fetchTracklist(e.ReleaseID)
+		if err != nil {
+			return autoMatchFinishedMsg{err: err}
+		}
+		for _, t := range tracks {
+			track := t.(item)
+			if track.meta.(MBTrack).Recording.ID == e.RecordingID {
+				releaseItem := item{title: release.Title, id: release.ID, meta: release}
+				return autoMatchFinishedMsg{yt: info.ytItem, release: releaseItem, track: track, score: scoreMatch(info.ytItem, track)}
+			}
+		}
+		return autoMatchFinishedMsg{err: errorf("候補のトラックがリリースに見つかりませんでした。手動で検索してください。")}
+	}
+}
+
+// updateReview handles keys on the review screen.
+func (m *model) updateReview(msg tea.KeyMsg) tea.Cmd {
+	if m.reviewList.FilterState() == list.Filtering {
+		return nil
+	}
+	i, ok := m.reviewList.SelectedItem().(item)
+	switch msg.String() {
+	case "enter":
+		if !ok {
+			return nil
+		}
+		e := i.meta.(reviewEntry)
+		if !e.hasCandidate() {
+			return m.searchReview(e)
+		}
+		m.state, m.statusMsg = stateSearching, tr("候補を読み込み中です...")
+		return tea.Batch(m.spinner.Tick, reviewCandidateCmd(m.ytDlpPath, e), removeReviewCmd(e))
+	case "s":
+		if ok {
+			return m.searchReview(i.meta.(reviewEntry))
+		}
+	case "d":
+		if ok {
+			return removeReviewCmd(i.meta.(reviewEntry))
+		}
+	case "esc":
+		m.state = stateInput
+	}
+	return nil
+}
+
+// searchReview runs the entry's query through the normal interactive search.
+func (m *model) searchReview(e reviewEntry) tea.Cmd {
+	m.input.SetValue(e.Query)
+	if strings.HasPrefix(e.Query, "http") {
+		m.state, m.statusMsg = stateFetchingURLInfo, tr("URLから情報を取得中です...")
+		return tea.Batch(m.spinner.Tick, m.retryable(getURLInfoCmd(m.ytDlpPath, e.Query)), removeReviewCmd(e))
+	}
+	m.state, m.statusMsg = stateSearching, tr("YouTubeとMusicBrainzを検索中です...")
+	return tea.Batch(m.spinner.Tick, searchCmd(m.ytDlpPath, e.Query, m.mbFilter), removeReviewCmd(e))
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os/exec"
+	"strings"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// --- ローマ字化 ---
+// 日本語のタイトルをローマ字にして、ASCII のファイル名や「原題 (Romaji)」のタグを作る。
+// 漢字の読みは kakasi (別途インストール) に任せ、見つからなければ仮名だけを組み込みの表でヘボン式にする
+// (その場合の漢字はそのまま残り、ファイル名にはローマ字化できなかった項目だけ元の表記を使う)。romanize.enabled を既定に、比較画面の r で1曲ごとに切り替えられる。
+const (
+	romanizeDual   = "dual"
+	romanizeRomaji = "romaji"
+)
+
+type romanizeConfig struct {
+	// 既定でローマ字化する (比較画面の r で1曲ごとに切り替えられる)
+	Enabled bool `json:"enabled"`
+	// タグの扱い: "" (そのまま) / dual (「原題 (Romaji)」) / romaji (ローマ字に置き換え)。ファイル名は常にローマ字になる
+	Tags string `json:"tags"`
+	// 漢字の読みに使う kakasi のパス (空なら PATH から探す)
+	Kakasi string `json:"kakasi"`
+}
+
+var (
+	romajiCache   sync.Map // 原文 → ローマ字
+	kakasiMissing sync.Once
+	asciiWarned   sync.Map // 警告済みの原文 (比較画面は描画のたびにファイル名を作る)
+)
+
+// romanize transliterates the Japanese in s to Hepburn romaji, leaving other text alone.
+func romanize(s string) string {
+	if !hasKana(s) && !hasHan(s) {
+		return s
+	}
+	if v, ok := romajiCache.Load(s); ok {
+		return v.(string)
+	}
+	out := ""
+	if hasHan(s) {
+		out = kakasi(s)
+	}
+	if out == "" {
+		out = kanaToRomaji(s)
+	}
+	out = strings.Join(strings.Fields(out), " ")
+	if r := []rune(out); len(r) > 0 {
+		out = string(unicode.ToUpper(r[0])) + string(r[1:])
+	}
+	romajiCache.Store(s, out)
+	return out
+}
+
+// kakasi reads kanji (and everything else) with the kakasi command, or returns "" when it isn't
+// installed or fails.
+func kakasi(s string) string {
+	path, err := exec.LookPath(firstNonEmpty(cfg.Romanize.Kakasi, "kakasi"))
+	if err != nil {
+		kakasiMissing.Do(func() { log.Printf("Romanize: kakasi not found, kanji are left as they are") })
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, "-i", "utf8", "-o", "utf8", "-Ja", "-Ha", "-Ka", "-Ea", "-s")
+	cmd.Stdin = strings.NewReader(s)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		log.Printf("Romanize: kakasi failed for %q: %v", s, err)
+		return ""
+	}
+	// kakasi は長音を ^ で書く
+	return strings.ReplaceAll(strings.TrimSpace(out.String()), "^", "")
+}
+
+func hasKana(s string) bool {
+	for _, r := range s {
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+var kanaPunct = strings.NewReplacer("「", " ", "」", " ", "『", " ", "』", " ", "【", " [", "】", "] ",
+	"、", ", ", "。", ". ", "・", " ", "〜", "~", "　", " ")
+
+// kanaToRomaji is the built-in Hepburn table for hiragana and katakana, with the small kana, っ, ー
+// and ん before a vowel (n') handled; kanji and other scripts pass through.
+func kanaToRomaji(s string) string {
+	var b strings.Builder
+	runes := []rune(kanaPunct.Replace(s))
+	double := false // 直前が「っ」
+	afterN := false // 直前が「ん」(母音や y が続くときは n' と書き、かに kani と かんい kan'i を分ける)
+	for n := 0; n < len(runes); n++ {
+		r := runes[n]
+		if r >= 'ァ' && r <= 'ヴ' {
+			r -= 'ァ' - 'ぁ' // カタカナはひらがなの表を使う
+		} else if r >= '！' && r <= '～' {
+			r -= '！' - '!' // 全角英数
+		}
+		if r == 'っ' {
+			double = true
+			continue
+		}
+		if r == 'ー' {
+			afterN = false
+			if v := lastVowel(b.String()); v != 0 {
+				b.WriteRune(v)
+			}
+			continue
+		}
+		syl, ok := kanaRomaji[r]
+		if !ok {
+			double, afterN = false, false
+			b.WriteRune(r)
+			continue
+		}
+		if n+1 < len(runes) {
+			next := runes[n+1]
+			if next >= 'ァ' && next <= 'ヴ' {
+				next -= 'ァ' - 'ぁ'
+			}
+			if joined, ok := joinSmallKana(syl, next); ok {
+				syl = joined
+				n++
+			}
+		}
+		if double && syl[0] != 'a' && syl[0] != 'i' && syl[0] != 'u' && syl[0] != 'e' && syl[0] != 'o' {
+			if strings.HasPrefix(syl, "ch") {
+				b.WriteByte('t')
+			} else {
+				b.WriteByte(syl[0])
+			}
+		}
+		if afterN && strings.IndexByte("aiueoy", syl[0]) >= 0 {
+			b.WriteByte('\'')
+		}
+		double, afterN = false, r == 'ん'
+		b.WriteString(syl)
+	}
+	return b.String()
+}
+
+// joinSmallKana combines a syllable with the small kana after it: きゃ kya, しょ sho, ファ fa, ティ ti.
+func joinSmallKana(syl string, next rune) (string, bool) {
+	switch next {
+	case 'ゃ', 'ゅ', 'ょ':
+		if !strings.HasSuffix(syl, "i") || len(syl) < 2 {
+			return "", false
+		}
+		stem := strings.TrimSuffix(syl, "i")
+		if stem == "sh" || stem == "ch" || stem == "j" {
+			return stem + kanaRomaji[next][1:], true
+		}
+		return stem + kanaRomaji[next], true
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		v := kanaRomaji[next]
+		switch {
+		case syl == "fu" || syl == "vu":
+			return syl[:1] + v, true
+		case (syl == "te" || syl == "de") && v == "i", (syl == "to" || syl == "do") && v == "u":
+			return syl[:1] + v, true
+		case (syl == "shi" || syl == "chi" || syl == "ji") && v == "e":
+			return strings.TrimSuffix(syl, "i") + v, true
+		case syl == "u" && v != "u":
+			return "w" + v, true
+		case syl == "tsu":
+			return "ts" + v, true
+		}
+	}
+	return "", false
+}
+
+func lastVowel(s string) rune {
+	for n := len(s) - 1; n >= 0; n-- {
+		if strings.IndexByte("aiueo", s[n]) >= 0 {
+			return rune(s[n])
+		}
+	}
+	return 0
+}
+
+// romanizedTags applies romanize.tags to the text fields written to the file.
+func romanizedTags(tags finalTags) finalTags {
+	if !tags.Romanize {
+		return tags
+	}
+	switch cfg.Romanize.Tags {
+	case romanizeRomaji:
+		return romajiFields(tags)
+	case romanizeDual:
+		for _, f := range []*string{&tags.Title, &tags.Artist, &tags.AlbumArtist, &tags.Album} {
+			if r := romanize(*f); r != *f {
+				*f = *f + " (" + r + ")"
+			}
+		}
+	}
+	return tags
+}
+
+// romajiFields replaces the title, artist and album with their romaji, as the file name uses them.
+func romajiFields(tags finalTags) finalTags {
+	for _, f := range []*string{&tags.Title, &tags.Artist, &tags.AlbumArtist, &tags.Album} {
+		*f = romanize(*f)
+	}
+	return tags
+}
+
+var latinFolds = strings.NewReplacer("ß", "ss", "æ", "ae", "Æ", "AE", "œ", "oe", "Œ", "OE", "ø", "o", "Ø", "O",
+	"ł", "l", "Ł", "L", "đ", "d", "Đ", "D", "þ", "th", "Þ", "Th")
+
+// asciiName makes a romanized name plain ASCII: accents are dropped (é e, ō o), full-width letters
+// narrowed and symbols like ♪ and ☆ removed. It reports false when letters remain that have no
+// ASCII spelling, such as kanji left unread without kakasi, or when nothing is left at all.
+func asciiName(s string) (string, bool) {
+	ok := true
+	name := strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		switch {
+		case r < unicode.MaxASCII:
+			return r
+		case unicode.Is(unicode.Mn, r):
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			ok = false
+		}
+		return ' '
+	}, norm.NFKD.String(latinFolds.Replace(s)))), " ")
+	return name, ok && (name != "" || strings.TrimSpace(s) == "")
+}
+
+// asciiFields romanizes the title, artist and album for the file name. A field that can't be
+// spelled in ASCII keeps its original text, with a warning in the log.
+func asciiFields(tags finalTags) finalTags {
+	for _, f := range []*string{&tags.Title, &tags.Artist, &tags.AlbumArtist, &tags.Album} {
+		if name, ok := asciiName(romanize(*f)); ok {
+			*f = name
+		} else if _, warned := asciiWarned.LoadOrStore(*f, true); !warned {
+			log.Printf("Romanize: %q has no ASCII spelling (is kakasi installed?), keeping it in the file name", *f)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,81 @@
+package main
+
+import "testing"
+
+func TestKanaToRomaji(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"hiragana", "ありがとう", "arigatou"},
+		{"katakana", "カラオケ", "karaoke"},
+		{"youon", "きゃしょちゃじゅ", "kyashochaju"},
+		{"sokuon", "きって", "kitte"},
+		{"sokuon before ch", "まっちゃ", "matcha"},
+		{"long vowel", "ラーメン", "raamen"},
+		{"small vowels", "ファンティーヴァイオリン", "fantiivaiorin"},
+		{"wi and tsa", "ウィツァ", "witsa"},
+		{"she", "シェフ", "shefu"},
+		{"n before vowel", "かんい", "kan'i"},
+		{"n before y", "ほんや", "hon'ya"},
+		{"n before consonant", "さんま", "sanma"},
+		{"na without n", "かに", "kani"},
+		{"n before long o", "しんおおさか", "shin'oosaka"},
+		{"n at end", "みかん", "mikan"},
+		{"old kana", "ゐゑを", "ieo"},
+		{"punctuation", "ねこ、いぬ。", "neko, inu. "},
+		{"brackets", "【ね】", " [ne] "},
+		{"full-width ascii", "ＡＢＣ１", "ABC1"},
+		{"kanji pass through", "東京タワー", "東京tawaa"},
+		{"latin pass through", "Love ソング", "Love songu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kanaToRomaji(tt.in); got != tt.want {
+				t.Errorf("kanaToRomaji(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRomanize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Lemon", "Lemon"},
+		{"よるにかける", "Yorunikakeru"},
+		{"「 ね 」", "Ne"},
+		{"きんいろ モザイク", "Kin'iro mozaiku"},
+	}
+	for _, tt := range tests {
+		if got := romanize(tt.in); got != tt.want {
+			t.Errorf("romanize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestASCIIName(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+		ok             bool
+	}{
+		{"ascii", "Yoru ni kakeru", "Yoru ni kakeru", true},
+		{"apostrophe", "Kan'i", "Kan'i", true},
+		{"accents", "Café Naïve Ōsaka", "Cafe Naive Osaka", true},
+		{"folded letters", "Straße Øresund Łódź", "Strasse Oresund Lodz", true},
+		{"full-width", "Ｒｏｍａｊｉ", "Romaji", true},
+		{"symbols", "Love ♪ Song ☆", "Love Song", true},
+		{"empty", "", "", true},
+		{"kanji left", "東京 tawaa", "tawaa", false},
+		{"hangul", "사랑", "", false},
+		{"only symbols", "♪☆", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := asciiName(tt.in)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("asciiName(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
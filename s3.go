@@ -0,0 +1,172 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- S3 互換ストレージ ---
+// 送り先の type に s3 を指定すると、完成したファイルを S3 / MinIO などのバケットに PUT する。
+// SDK は使わず、署名 (AWS Signature Version 4) はここで計算する。オブジェクトのキーは key_template で
+// 命名テンプレートと同じプレースホルダーを使って決められる (空なら downloads フォルダからの相対パス)。
+const (
+	destS3          = "s3"
+	defaultS3Region = "us-east-1"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+)
+
+type s3Destination struct {
+	endpoint, bucket, region string
+	accessKey, secretKey     string
+	keyTemplate, prefix      string
+	pathStyle                bool
+}
+
+func newS3Destination(c destinationConfig) (destination, error) {
+	if c.Bucket == "" || c.AccessKey == "" || c.SecretKey == "" {
+		return nil, errorf("s3 の送り先には bucket / access_key / secret_key が必要です")
+	}
+	d := s3Destination{
+		endpoint: strings.TrimRight(c.Endpoint, "/"), bucket: c.Bucket, region: firstNonEmpty(c.Region, defaultS3Region),
+		accessKey: c.AccessKey, secretKey: c.SecretKey, keyTemplate: c.KeyTemplate,
+		prefix: strings.Trim(c.Path, "/"), pathStyle: c.PathStyle,
+	}
+	if d.endpoint == "" {
+		d.endpoint = "https://s3." + d.region + ".amazonaws.com"
+	} else if !strings.Contains(d.endpoint, "://") {
+		d.endpoint = "https://" + d.endpoint
+	}
+	// MinIO など AWS 以外はバケット名のサブドメインを持たないことが多い
+	if c.Endpoint != "" && !strings.HasSuffix(d.endpoint, ".amazonaws.com") {
+		d.pathStyle = true
+	}
+	return d, nil
+}
+
+func (d s3Destination) String() string { return "s3://" + d.bucket + "/" + d.prefix }
+
+func (d s3Destination) location(f uploadFile) string {
+	return "s3://" + d.bucket + "/" + d.objectKey(f)
+}
+
+// objectKey expands the key template for the audio file; sidecars share its name with their own
+// extension.
+func (d s3Destination) objectKey(f uploadFile) string {
+	key := f.rel
+	// プレイリストの中のパスは downloads フォルダの構成なので、テンプレートを使わずにそのまま置く
+	if d.keyTemplate != "" && path.Ext(f.rel) != playlistExt {
+		key = filepath.ToSlash(expandTemplate(d.keyTemplate, f.tags)) + path.Ext(f.rel)
+	}
+	if d.prefix != "" {
+		key = d.prefix + "/" + key
+	}
+	return key
+}
+
+func (d s3Destination) objectURL(key string) (host, uri, rawURL string) {
+	scheme, host, _ := strings.Cut(d.endpoint, "://")
+	uri = "/" + s3URIEncode(key, false)
+	if d.pathStyle {
+		uri = "/" + s3URIEncode(d.bucket, true) + uri
+	} else {
+		host = d.bucket + "." + host
+	}
+	return host, uri, scheme + "://" + host + uri
+}
+
+// upload PUTs the file with a signed request. The payload hash is computed up front, so the file is
+// read twice.
+func (d s3Destination) upload(f uploadFile) error {
+	file, err := os.Open(f.local)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	host, uri, rawURL := d.objectURL(d.objectKey(f))
+	req, err := http.NewRequest("PUT", rawURL, file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("User-Agent", userAgent())
+	if ct := mime.TypeByExtension(filepath.Ext(f.local)); ct != "" {
+		req.Header.Set("Content-Type", ct)
+	}
+	d.sign(req, host, uri, hex.EncodeToString(h.Sum(nil)), time.Now().UTC())
+	client := *httpClient
+	client.Timeout = uploadTimeout(destS3)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("PUT %s: %s: %s", uri, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 headers for a request without a query string.
+func (d s3Destination) sign(req *http.Request, host, uri, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method, uri, "",
+		"host:" + host, "x-amz-content-sha256:" + payloadHash, "x-amz-date:" + amzDate, "",
+		signedHeaders, payloadHash,
+	}, "\n")
+	scope := day + "/" + d.region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(sum[:])}, "\n")
+	key := []byte("AWS4" + d.secretKey)
+	for _, part := range []string{day, d.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", s3Algorithm, d.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3URIEncode percent-encodes everything but the unreserved characters, as SigV4 expects; "/" is
+// kept unless encodeSlash is set.
+func s3URIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
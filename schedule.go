@@ -0,0 +1,190 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- daemon のスケジュール ---
+// 従量制や共用の回線向けに、daemon がジョブキューを処理する時刻やチャンネル登録・新譜を確認する時刻を
+// cron 形式 ("分 時 日 月 曜日") または "@every 6h" / "@daily" などで指定できるようにする。時刻はローカル時間。
+type scheduleConfig struct {
+	// ジョブキューを処理する時刻 (例: "0 3 * * *" で毎日3時)。空なら受け取ったらすぐ処理する
+	Queue string `json:"queue"`
+	// チャンネル登録の新着を確認する時刻 (例: "@every 6h")。空なら subscriptions.interval_hours ごと
+	Subscriptions string `json:"subscriptions"`
+	// ウォッチ中のアーティストの新譜を確認してジョブキューに入れる時刻。空なら daemon では確認しない
+	NewReleases string `json:"new_releases"`
+}
+
+// schedule gives the first run time strictly after the given time.
+type schedule interface {
+	next(after time.Time) time.Time
+}
+
+type everySchedule struct{ every time.Duration }
+
+func (s everySchedule) next(after time.Time) time.Time { return after.Add(s.every) }
+
+// cronSchedule holds a bit per allowed value of each field.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+func parseSchedule(spec string) (schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || every < time.Minute {
+			return nil, errorf("スケジュール %q: @every には1分以上の間隔を指定してください (例: @every 6h)", spec)
+		}
+		return everySchedule{every}, nil
+	}
+	if alias, ok := cronAliases[spec]; ok {
+		spec = alias
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errorf("スケジュール %q: cron 形式は「分 時 日 月 曜日」の5項目です", spec)
+	}
+	var s cronSchedule
+	var err error
+	ranges := []struct {
+		dst      *uint64
+		min, max int
+	}{{&s.minute, 0, 59}, {&s.hour, 0, 23}, {&s.dom, 1, 31}, {&s.month, 1, 12}, {&s.dow, 0, 7}}
+	for i, r := range ranges {
+		if *r.dst, err = parseCronField(fields[i], r.min, r.max); err != nil {
+			return nil, errorf("スケジュール %q: %w", spec, err)
+		}
+	}
+	// 日曜日は 0 と 7 のどちらでもよい
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny, s.dowAny = fields[2] == "*", fields[4] == "*"
+	return s, nil
+}
+
+// parseCronField reads "*", "5", "1-5", "*/15", "0-30/10" and comma-separated lists of those.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, errorf("不正な間隔 %q", part)
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(a); err != nil {
+				return 0, errorf("不正な値 %q", part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(b); err != nil {
+					return 0, errorf("不正な値 %q", part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, errorf("%q は %d〜%d の範囲外です", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	dom, dow := s.dom&(1<<t.Day()) != 0, s.dow&(1<<int(t.Weekday())) != 0
+	// cron の決まりどおり、日と曜日の両方が指定されていればどちらかに合えばよい
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+func (s cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// 4年分探しても見つからなければ (2月30日など) 実行しない
+	limit := t.AddDate(4, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// scheduledTask tracks when a scheduled daemon task runs next.
+type scheduledTask struct {
+	name  string
+	sched schedule
+	at    time.Time
+}
+
+// newScheduledTask returns nil for an empty spec. "@every" tasks run right away, cron ones at their
+// next time.
+func newScheduledTask(name, spec string, now time.Time) (*scheduledTask, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	sched, err := parseSchedule(spec)
+	if err != nil {
+		return nil, err
+	}
+	t := &scheduledTask{name: name, sched: sched, at: sched.next(now)}
+	if _, ok := sched.(everySchedule); ok {
+		t.at = now
+	}
+	return t, nil
+}
+
+// due reports whether the task's time has come, and if so moves it to the following run.
+func (t *scheduledTask) due(now time.Time) bool {
+	if t.at.IsZero() || now.Before(t.at) {
+		return false
+	}
+	t.at = t.sched.next(now)
+	return true
+}
+
+func (t *scheduledTask) String() string {
+	if t.at.IsZero() {
+		return tr("%s: 実行されません", t.name)
+	}
+	return tr("%s: 次回 %s", t.name, t.at.Format("01/02 15:04"))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	at := func(y int, mo time.Month, d, h, mi int) time.Time { return time.Date(y, mo, d, h, mi, 0, 0, time.UTC) }
+	tests := []struct {
+		name, spec  string
+		after, want time.Time
+	}{
+		{"daily at three", "0 3 * * *", at(2024, 1, 1, 2, 59), at(2024, 1, 1, 3, 0)},
+		{"strictly after", "0 3 * * *", at(2024, 1, 1, 3, 0), at(2024, 1, 2, 3, 0)},
+		{"seconds are dropped", "0 3 * * *", time.Date(2024, 1, 1, 2, 59, 30, 0, time.UTC), at(2024, 1, 1, 3, 0)},
+		{"step", "*/15 * * * *", at(2024, 1, 1, 10, 7), at(2024, 1, 1, 10, 15)},
+		{"range with step", "0-30/10 * * * *", at(2024, 1, 1, 10, 31), at(2024, 1, 1, 11, 0)},
+		{"list", "5,35 8,20 * * *", at(2024, 1, 1, 8, 40), at(2024, 1, 1, 20, 5)},
+		{"daily alias", "@daily", at(2024, 1, 1, 12, 0), at(2024, 1, 2, 0, 0)},
+		{"hourly alias", "@hourly", at(2024, 1, 1, 12, 0), at(2024, 1, 1, 13, 0)},
+		{"monthly alias", "@monthly", at(2024, 1, 15, 0, 0), at(2024, 2, 1, 0, 0)},
+		{"sunday as 7", "0 0 * * 7", at(2024, 1, 1, 0, 0), at(2024, 1, 7, 0, 0)},
+		{"sunday as 0", "0 0 * * 0", at(2024, 1, 1, 0, 0), at(2024, 1, 7, 0, 0)},
+		{"weekday range", "30 6 * * 1-5", at(2024, 1, 5, 7, 0), at(2024, 1, 8, 6, 30)},
+		{"day or weekday", "0 9 1 * 1", at(2024, 1, 2, 0, 0), at(2024, 1, 8, 9, 0)},
+		{"day or weekday, day first", "0 9 1 * 1", at(2024, 1, 29, 10, 0), at(2024, 2, 1, 9, 0)},
+		{"month", "0 0 1 6 *", at(2024, 7, 1, 0, 0), at(2025, 6, 1, 0, 0)},
+		{"leap day", "0 0 29 2 *", at(2024, 3, 1, 0, 0), at(2028, 2, 29, 0, 0)},
+		{"never", "0 0 30 2 *", at(2024, 1, 1, 0, 0), time.Time{}},
+		{"every", "@every 6h", at(2024, 1, 1, 10, 7), at(2024, 1, 1, 16, 7)},
+		{"every with spaces", " @every  90m ", at(2024, 1, 1, 10, 0), at(2024, 1, 1, 11, 30)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("parseSchedule(%q): %v", tt.spec, err)
+			}
+			if got := s.next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name, spec string
+	}{
+		{"empty", ""},
+		{"every too short", "@every 30s"},
+		{"every without unit", "@every 6"},
+		{"four fields", "* * * *"},
+		{"six fields", "0 * * * * *"},
+		{"unknown alias", "@yearly"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "0 24 * * *"},
+		{"day zero", "0 0 0 * *"},
+		{"month out of range", "0 0 1 13 *"},
+		{"weekday out of range", "0 0 * * 8"},
+		{"reversed range", "5-1 * * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"bad step", "*/x * * * *"},
+		{"not a number", "a * * * *"},
+		{"empty list item", "1,,2 * * * *"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSchedule(tt.spec); err == nil {
+				t.Errorf("parseSchedule(%q) succeeded, want an error", tt.spec)
+			}
+		})
+	}
+}
+
+func TestScheduledTaskDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 7, 0, 0, time.UTC)
+	tests := []struct {
+		name, spec string
+		wantNil    bool
+		wantAt     time.Time
+	}{
+		{"empty", "  ", true, time.Time{}},
+		{"every runs now", "@every 1h", false, now},
+		{"cron waits", "0 12 * * *", false, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := newScheduledTask("test", tt.spec, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantNil {
+				if task != nil {
+					t.Errorf("newScheduledTask(%q) = %v, want nil", tt.spec, task)
+				}
+				return
+			}
+			if !task.at.Equal(tt.wantAt) {
+				t.Errorf("at = %v, want %v", task.at, tt.wantAt)
+			}
+			if task.due(tt.wantAt.Add(-time.Minute)) {
+				t.Error("due before its time")
+			}
+			if !task.due(tt.wantAt) {
+				t.Error("not due at its time")
+			}
+			if !task.at.After(tt.wantAt) {
+				t.Errorf("at = %v after running, want a later time", task.at)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 検索の設定 ---
+// 1ページの件数と、YouTube の検索結果から外す動画の条件。条件は yt-dlp が返した動画の情報で判定するので、
+// 外れた分だけそのページの件数は少なくなる (m で続きを読み込める)。
+const (
+	defaultYouTubeResults     = 5
+	defaultMusicBrainzResults = 25
+	maxMusicBrainzResults     = 100 // MusicBrainz の検索APIの上限
+)
+
+type searchConfig struct {
+	// YouTube の検索で1ページに取得する件数
+	YouTubeResults int `json:"youtube_results"`
+	// MusicBrainz の検索で1ページに取得する件数 (最大100)
+	MusicBrainzResults int `json:"musicbrainz_results"`
+	// カテゴリが「音楽」(Music) の動画だけを表示する
+	MusicOnly bool `json:"music_only"`
+	// 動画の長さの範囲 (秒)。0 なら制限しない。ショート動画を外すには min を 61 にする
+	MinDurationSec int `json:"min_duration_sec"`
+	MaxDurationSec int `json:"max_duration_sec"`
+	// 配信中・配信予定の動画を表示しない
+	ExcludeLive bool `json:"exclude_live"`
+	// タイトルにこの語を含む動画を表示しない (例: "reaction", "cover", "歌ってみた")。大文字小文字は区別しない
+	// 検索語に含まれている語は除外に使わない
+	ExcludeKeywords []string `json:"exclude_keywords"`
+	// MusicBrainz のリリース検索の既定の絞り込み
+	MusicBrainzFilter mbReleaseFilter `json:"musicbrainz_filter"`
+	// 自動生成の動画 (「 - Topic」チャンネル) でも概要欄の情報を使わずに MusicBrainz を検索する
+	MusicBrainzForTopic bool `json:"musicbrainz_for_topic"`
+}
+
+func ytSearchPage() int {
+	if n := cfg.Search.YouTubeResults; n > 0 {
+		return n
+	}
+	return defaultYouTubeResults
+}
+
+func mbSearchPage() int {
+	if n := cfg.Search.MusicBrainzResults; n > 0 {
+		return min(n, maxMusicBrainzResults)
+	}
+	return defaultMusicBrainzResults
+}
+
+// filterYouTubeResults drops the videos search.* rules out for the query.
+func filterYouTubeResults(items []list.Item, query string) []list.Item {
+	var kept []list.Item
+	for _, it := range items {
+		i := it.(item)
+		info, _ := i.meta.(ytDlpVideoInfo)
+		if reason := searchExcludeReason(info, query); reason != "" {
+			log.Printf("Search: skipped %q (%s)", i.title, reason)
+			continue
+		}
+		kept = append(kept, it)
+	}
+	return kept
+}
+
+// searchExcludeReason says why the video is left out of the results, or "" if it is kept.
+func searchExcludeReason(info ytDlpVideoInfo, query string) string {
+	s := cfg.Search
+	switch {
+	case s.MusicOnly && !slices.Contains(info.Categories, "Music"):
+		return "not in the Music category"
+	case s.MinDurationSec > 0 && info.Duration > 0 && info.Duration < float64(s.MinDurationSec):
+		return "too short"
+	case s.MaxDurationSec > 0 && info.Duration > float64(s.MaxDurationSec):
+		return "too long"
+	case s.ExcludeLive && (info.LiveStatus == liveStatusLive || info.LiveStatus == liveStatusUpcoming):
+		return "live stream"
+	}
+	title, q := strings.ToLower(info.Title), strings.ToLower(query)
+	for _, k := range s.ExcludeKeywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k != "" && strings.Contains(title, k) && !strings.Contains(q, k) {
+			return "keyword " + k
+		}
+	}
+	return ""
+}
+
+// --- MusicBrainz の絞り込み ---
+// 同じアルバムの版違い (国・形態・ブートレグなど) が並ばないよう、リリースの検索に条件を足す。
+// 既定の条件は search.musicbrainz_filter で決め、リリースの一覧では c / o / t (r で録音の検索) でその場で切り替えて検索し直せる。
+var mbFormats = []string{"", "CD", "Digital Media"}
+
+// mbReleaseFilter narrows release searches to a country, official releases and a medium.
+type mbReleaseFilter struct {
+	// 発売国 (ISO 3166-1 の2文字。例: JP)。空なら絞り込まない
+	Country string `json:"country"`
+	// 公式リリース (status: official) だけにする
+	Official bool `json:"official"`
+	// メディアの形態 (例: CD / Digital Media / Vinyl)。空なら絞り込まない
+	Format string `json:"format"`
+	// リリースではなく録音 (recording) を検索する。アルバムに入っていないシングル向け
+	Recordings bool `json:"recordings"`
+}
+
+// query adds the filter's conditions to a release search.
+func (f mbReleaseFilter) query(q string) string {
+	var conds []string
+	if f.Country != "" {
+		conds = append(conds, "country:"+strings.ToUpper(f.Country))
+	}
+	if f.Official {
+		conds = append(conds, "status:official")
+	}
+	if f.Format != "" {
+		conds = append(conds, `format:"`+f.Format+`"`)
+	}
+	if len(conds) == 0 {
+		return q
+	}
+	return "(" + q + ") AND " + strings.Join(conds, " AND ")
+}
+
+func (f mbReleaseFilter) label() string {
+	var parts []string
+	if f.Country != "" {
+		parts = append(parts, strings.ToUpper(f.Country))
+	}
+	if f.Official {
+		parts = append(parts, tr("公式"))
+	}
+	if f.Format != "" {
+		parts = append(parts, f.Format)
+	}
+	if f.Recordings {
+		parts = append(parts, tr("録音"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+type mbRefilteredMsg struct {
+	filter mbReleaseFilter
+	items  []list.Item
+	err    error
+}
+
+func (m model) mbListTitle() string {
+	title := tr("どのリリースからタグ情報を取得しますか？")
+	if label := m.mbFilter.label(); label != "" {
+		title += " [" + label + "]"
+	}
+	return title
+}
+
+// toggleMBFilter switches one condition of the release filter (c: country, o: official, t: medium,
+// r: recordings) and searches again.
+func (m *model) toggleMBFilter(k string) tea.Cmd {
+	f := m.mbFilter
+	switch k {
+	case "c":
+		if f.Country != "" {
+			f.Country = ""
+		} else if f.Country = cfg.Search.MusicBrainzFilter.Country; f.Country == "" {
+			f.Country = "JP"
+		}
+	case "o":
+		f.Official = !f.Official
+	case "r":
+		f.Recordings = !f.Recordings
+	case "t":
+		next := slices.Index(mbFormats, f.Format) + 1
+		f.Format = mbFormats[next%len(mbFormats)]
+	default:
+		return nil
+	}
+	m.mbFilter = f
+	m.mbCache = map[string][]list.Item{}
+	m.mbResults.Title = m.mbListTitle()
+	query := m.mbMore.query
+	if query == "" {
+		return nil
+	}
+	return tea.Batch(m.mbResults.NewStatusMessage(tr("条件を変えて検索し直しています...")), func() tea.Msg {
+		items, err := doMusicBrainzSearchPage(query, 0, f)
+		return mbRefilteredMsg{filter: f, items: items, err: err}
+	})
+}
+
+func (m *model) applyMBFilter(msg mbRefilteredMsg) tea.Cmd {
+	if msg.filter != m.mbFilter || m.groupParent != nil {
+		return nil // もう一度切り替えられたか、版の一覧を表示中
+	}
+	if msg.err != nil {
+		return m.mbResults.NewStatusMessage(tr("検索に失敗しました: %v", msg.err))
+	}
+	m.mbMore.pages = 1
+	m.mbResults.ResetFilter()
+	m.mbResults.Select(0)
+	cmd := m.mbResults.SetItems(msg.items)
+	if len(msg.items) == 0 {
+		return tea.Batch(cmd, m.mbResults.NewStatusMessage(tr("条件に合うリリースはありません")))
+	}
+	return tea.Batch(cmd, m.mbResults.NewStatusMessage(tr("%d件のリリース", len(msg.items))))
+}
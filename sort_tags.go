@@ -0,0 +1,87 @@
+package main
+
+import "strings"
+
+// --- 並び替え用のタグ ---
+// TITLE / ARTIST は元の表記 (日本語) のまま、ARTISTSORT / ALBUMARTISTSORT / TITLESORT / ALBUMSORT に
+// ローマ字の読みを書き、プレーヤーが日本語のアーティストを正しい位置に並べられるようにする。
+// アーティストの読みは MusicBrainz の別名 (ja-Latn, en の順) とソート名を使い、無ければローマ字化する。
+type (
+	MBArtistInfo struct {
+		ID       string    `json:"id"`
+		Name     string    `json:"name"`
+		SortName string    `json:"sort-name"`
+		Aliases  []MBAlias `json:"aliases"` // musicbrainz.alias_lookup / sort_tags のときだけ
+	}
+	MBAlias struct {
+		Name     string `json:"name"`
+		SortName string `json:"sort-name"`
+		Locale   string `json:"locale"`
+		Primary  bool   `json:"primary"`
+	}
+)
+
+// artistSortName is the romaji sort name of an artist credit, joined like the credit itself.
+func artistSortName(credits []MBArtist) string {
+	var b strings.Builder
+	for _, c := range credits {
+		b.WriteString(firstNonEmpty(latinSortName(c.Artist), romajiSort(c.Name), c.Name))
+		b.WriteString(c.JoinPhrase)
+	}
+	return b.String()
+}
+
+// latinSortName picks the artist's sort name in Latin letters: the primary ja-Latn alias, the primary
+// English alias, the artist's own sort name, then any Latin alias.
+func latinSortName(a MBArtistInfo) string {
+	for _, locale := range []string{"ja-Latn", "en"} {
+		for _, alias := range a.Aliases {
+			if alias.Primary && strings.EqualFold(alias.Locale, locale) {
+				return firstNonEmpty(alias.SortName, alias.Name)
+			}
+		}
+	}
+	if a.SortName != "" && !hasCJK(a.SortName) {
+		return a.SortName
+	}
+	for _, alias := range a.Aliases {
+		if s := firstNonEmpty(alias.SortName, alias.Name); s != "" && !hasCJK(s) {
+			return s
+		}
+	}
+	return ""
+}
+
+// sortTags lists the sort tags to write: the MusicBrainz sort name of the artist when the artist
+// wasn't edited, otherwise the romaji of the field. Fields that sort as they are get none, and so do
+// romanizations that still hold kanji because kakasi isn't installed.
+func sortTags(tags finalTags) [][2]string {
+	if !cfg.MusicBrainz.SortTags {
+		return nil
+	}
+	artistSort := tags.ArtistSort
+	if artistSort == "" {
+		artistSort = romajiSort(tags.Artist)
+	}
+	albumArtistSort := artistSort
+	if tags.AlbumArtist != tags.Artist {
+		albumArtistSort = romajiSort(tags.AlbumArtist)
+	}
+	var out [][2]string
+	for _, kv := range [][3]string{
+		{"ARTISTSORT", tags.Artist, artistSort}, {"ALBUMARTISTSORT", tags.AlbumArtist, albumArtistSort},
+		{"TITLESORT", tags.Title, romajiSort(tags.Title)}, {"ALBUMSORT", tags.Album, romajiSort(tags.Album)},
+	} {
+		if kv[2] != "" && kv[2] != kv[1] {
+			out = append(out, [2]string{kv[0], kv[2]})
+		}
+	}
+	return out
+}
+
+func romajiSort(s string) string {
+	if r := romanize(s); !hasHan(r) {
+		return r
+	}
+	return ""
+}
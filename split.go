@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 複数曲入り動画 (シングル+カップリング等) の分割 ---
+const (
+	splitMinTracks      = 2
+	splitMaxTracks      = 3
+	splitTotalTolerance = 10.0 // 動画の長さとトラック合計の許容差 (秒)
+	splitBoundarySearch = 20.0 // 想定境界から無音区間を探す範囲 (秒)
+	silenceDetectFilter = "silencedetect=noise=-45dB:d=1"
+)
+
+type ytChapter struct {
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Title     string  `json:"title"`
+}
+
+var (
+	silenceStartRe = regexp.MustCompile(`silence_start: (-?[\d.]+)`)
+	silenceEndRe   = regexp.MustCompile(`silence_end: ([\d.]+)`)
+)
+
+func splittableTrackCount(n int) bool { return n >= splitMinTracks && n <= splitMaxTracks }
+
+// looksLikeFullUpload reports whether the video length matches the sum of the given tracks, i.e. the
+// upload probably contains all of them back to back.
+func looksLikeFullUpload(yt item, tracks []item) bool {
+	info, ok := yt.meta.(ytDlpVideoInfo)
+	if !ok || info.Duration <= 0 || !splittableTrackCount(len(tracks)) {
+		return false
+	}
+	var total float64
+	for _, t := range tracks {
+		total += float64(t.meta.(MBTrack).Length) / 1000
+	}
+	return math.Abs(info.Duration-total) <= splitTotalTolerance
+}
+
+// detectSilences returns the midpoints of silent stretches in the audio.
+func detectSilences(ffmpegPath, audioPath string) ([]float64, error) {
+	out, err := exec.Command(ffmpegPath, "-hide_banner", "-i", audioPath, "-af", silenceDetectFilter, "-f", "null", "-").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("silencedetect failed: %v", err)
+	}
+	var mids []float64
+	var start float64
+	for _, line := range strings.Split(string(out), "\n") {
+		if m := silenceStartRe.FindStringSubmatch(line); m != nil {
+			start, _ = strconv.ParseFloat(m[1], 64)
+		} else if m := silenceEndRe.FindStringSubmatch(line); m != nil {
+			end, _ := strconv.ParseFloat(m[1], 64)
+			mids = append(mids, (start+end)/2)
+		}
+	}
+	return mids, nil
+}
+
+// planSegments maps the audio onto the tracks, preferring YouTube chapters, then silences near the
+// boundaries expected from the MusicBrainz track lengths, then the expected boundaries themselves.
+func planSegments(ffmpegPath, audioPath string, info ytDlpVideoInfo, tracks []MBTrack) ([]audioSegment, string) {
+	if len(info.Chapters) == len(tracks) {
+		segs := make([]audioSegment, len(tracks))
+		for i, c := range info.Chapters {
+			segs[i] = audioSegment{Start: c.StartTime, End: c.EndTime}
+		}
+		segs[len(segs)-1].End = 0
+		return segs, tr("チャプター")
+	}
+
+	actual, err := probeDuration(ffmpegPath, audioPath)
+	var expected float64
+	for _, t := range tracks {
+		expected += float64(t.Length) / 1000
+	}
+	scale := 1.0
+	if err == nil && expected > 0 {
+		scale = actual / expected
+	}
+	silences, err := detectSilences(ffmpegPath, audioPath)
+	if err != nil {
+		log.Printf("Split: %v", err)
+	}
+
+	method := tr("無音検出")
+	boundaries := []float64{0}
+	var cum float64
+	for _, t := range tracks[:len(tracks)-1] {
+		cum += float64(t.Length) / 1000 * scale
+		best, bestDelta := cum, splitBoundarySearch
+		for _, s := range silences {
+			if d := math.Abs(s - cum); d < bestDelta {
+				best, bestDelta = s, d
+			}
+		}
+		if bestDelta == splitBoundarySearch {
+			method = tr("トラック長")
+		}
+		boundaries = append(boundaries, best)
+	}
+	segs := make([]audioSegment, len(tracks))
+	for i := range tracks {
+		segs[i].Start = boundaries[i]
+		if i+1 < len(boundaries) {
+			segs[i].End = boundaries[i+1]
+		}
+	}
+	return segs, method
+}
+
+func splitDownloadCmd(ytDlpPath, ffmpegPath string, selectedYT, selectedMB item, tracks []item) tea.Cmd {
+	return func() tea.Msg {
+		releaseInfo := selectedMB.meta.(MBRelease)
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+
+		audioPath := filepath.Join(tmpDir, "audio.tmp")
+		var coverPath string
+		var coverSrc coverSource
+		var dlErr error
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			dlErr = downloadAudio(ytDlpPath, selectedYT, audioPath)
+		}()
+		go func() {
+			defer wg.Done()
+			if coverPath, coverSrc = fetchCoverArt(tmpDir, releaseInfo); coverPath == "" {
+				coverPath, coverSrc = fetchThumbnailCover(ffmpegPath, tmpDir, selectedYT.id)
+			}
+		}()
+		wg.Wait()
+		if dlErr != nil {
+			return downloadFinishedMsg{err: dlErr}
+		}
+
+		info, _ := selectedYT.meta.(ytDlpVideoInfo)
+		trackInfos := make([]MBTrack, len(tracks))
+		for i, t := range tracks {
+			trackInfos[i] = t.meta.(MBTrack)
+		}
+		segments, method := planSegments(ffmpegPath, audioPath, info, trackInfos)
+		log.Printf("Split: %d segments by %s: %+v", len(segments), method, segments)
+
+		var results []string
+		var entries []playlistEntry
+		for i, t := range tracks {
+			job := convertJob{audioPath: audioPath, coverPath: coverPath, coverSrc: coverSrc, tags: buildTags(releaseInfo, t), segment: segments[i]}
+			job.lyrics, job.credits = fetchTrackExtras(job.tags)
+			finalPath, err := convertAudio(ffmpegPath, job)
+			if err != nil {
+				return downloadFinishedMsg{err: err}
+			}
+			finalPath = recordDownload(finalPath, job, selectedYT, selectedMB)
+			queueUpload(finalPath, job.tags)
+			entries = append(entries, playlistEntry{path: finalPath, tags: job.tags})
+			results = append(results, fmt.Sprintf("%s (%s-%s)", finalPath, formatDuration(int(job.segment.Start)), formatSegmentEnd(job.segment.End)))
+		}
+		if cfg.Output.Playlist && len(entries) > 1 {
+			sortByTrack(entries)
+			if path, err := writePlaylist(firstNonEmpty(entries[0].tags.AlbumArtist, entries[0].tags.Artist)+" - "+releaseInfo.Title, entries); err != nil {
+				log.Printf("Playlist: %v", err)
+			} else {
+				results = append(results, "📃 "+path)
+			}
+		}
+		return downloadFinishedMsg{filename: tr("%d曲に分割しました (%s)\n%s", len(tracks), method, strings.Join(results, "\n")), warning: liveWarning(ffmpegPath, selectedYT, audioPath)}
+	}
+}
+
+func formatSegmentEnd(end float64) string {
+	if end <= 0 {
+		return tr("終了")
+	}
+	return formatDuration(int(end))
+}
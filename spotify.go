@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Spotify プレイリストの取り込み ---
+// 入力画面に Spotify のプレイリストURLを貼ると、Spotify API で全曲の曲名・アーティスト・アルバム・ISRCを取得し、
+// その情報をタグとしてキューに追加する。YouTubeの音源はキューの実行時に1曲ずつ検索する。
+// MusicBrainzは経由しないので、リリースは "spotify:" で始まるIDで区別し、MBIDとしては記録しない。
+const (
+	spotifyTokenURL = "https://accounts.spotify.com/api/token"
+	spotifyAPI      = "https://api.spotify.com/v1"
+	spotifyIDPrefix = "spotify:"
+)
+
+type spotifyConfig struct {
+	// Spotify アプリの Client ID / Client Secret (https://developer.spotify.com/dashboard)
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+var spotifyPlaylistRe = regexp.MustCompile(`^(?:https?://open\.spotify\.com/(?:intl-\w+/)?playlist/|spotify:playlist:)([A-Za-z0-9]+)`)
+
+// spotifyPlaylistID extracts the playlist ID from a pasted link or URI.
+func spotifyPlaylistID(query string) (string, bool) {
+	m := spotifyPlaylistRe.FindStringSubmatch(strings.TrimSpace(query))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+var spotifyToken struct {
+	sync.Mutex
+	value   string
+	expires time.Time
+}
+
+// spotifyAccessToken gets an app token with the client credentials flow and reuses it until it expires.
+func spotifyAccessToken() (string, error) {
+	spotifyToken.Lock()
+	defer spotifyToken.Unlock()
+	if spotifyToken.value != "" && time.Now().Before(spotifyToken.expires) {
+		return spotifyToken.value, nil
+	}
+	if cfg.Spotify.ClientID == "" || cfg.Spotify.ClientSecret == "" {
+		return "", errorf("config.json の spotify.client_id と spotify.client_secret を設定してください")
+	}
+	req, err := http.NewRequest("POST", spotifyTokenURL, strings.NewReader(url.Values{"grant_type": {"client_credentials"}}.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.Spotify.ClientID+":"+cfg.Spotify.ClientSecret)))
+	resp, err := doWithRetry("spotify", req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errorf("Spotifyの認証に失敗しました (%s)。client_id / client_secret を確認してください", resp.Status)
+	}
+	var data struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return "", err
+	}
+	spotifyToken.value = data.AccessToken
+	spotifyToken.expires = time.Now().Add(time.Duration(data.ExpiresIn-60) * time.Second)
+	return data.AccessToken, nil
+}
+
+func spotifyGet(apiURL string, v interface{}) error {
+	token, err := spotifyAccessToken()
+	if err != nil {
+		return err
+	}
+	return getJSON("spotify", apiURL, http.Header{"Authorization": {"Bearer " + token}}, v)
+}
+
+type spotifyArtist struct {
+	Name string `json:"name"`
+}
+
+type spotifyTrack struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	DurationMS  int             `json:"duration_ms"`
+	TrackNumber int             `json:"track_number"`
+	DiscNumber  int             `json:"disc_number"`
+	Artists     []spotifyArtist `json:"artists"`
+	ExternalIDs struct {
+		ISRC string `json:"isrc"`
+	} `json:"external_ids"`
+	Album struct {
+		ID          string          `json:"id"`
+		Name        string          `json:"name"`
+		ReleaseDate string          `json:"release_date"`
+		TotalTracks int             `json:"total_tracks"`
+		Artists     []spotifyArtist `json:"artists"`
+	} `json:"album"`
+}
+
+func joinSpotifyArtists(artists []spotifyArtist) string {
+	names := make([]string, len(artists))
+	for i, a := range artists {
+		names[i] = a.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+type spotifyImportedMsg struct {
+	name  string
+	items []queueItem
+	err   error
+}
+
+// spotifyImportCmd reads every track of the playlist, following the pages of 100.
+func spotifyImportCmd(playlistID string) tea.Cmd {
+	return func() tea.Msg {
+		var meta struct {
+			Name string `json:"name"`
+		}
+		if err := spotifyGet(spotifyAPI+"/playlists/"+playlistID+"?fields=name", &meta); err != nil {
+			return spotifyImportedMsg{err: errorf("Spotifyのプレイリストを取得できませんでした: %w", err)}
+		}
+		var tracks []spotifyTrack
+		next := spotifyAPI + "/playlists/" + playlistID + "/tracks?limit=100&additional_types=track"
+		for next != "" {
+			var page struct {
+				Next  string `json:"next"`
+				Items []struct {
+					IsLocal bool          `json:"is_local"`
+					Track   *spotifyTrack `json:"track"`
+				} `json:"items"`
+			}
+			if err := spotifyGet(next, &page); err != nil {
+				return spotifyImportedMsg{err: errorf("Spotifyのプレイリストを取得できませんでした: %w", err)}
+			}
+			for _, it := range page.Items {
+				// ローカルファイル・削除済みの曲・ポッドキャストのエピソードは飛ばす
+				if it.IsLocal || it.Track == nil || it.Track.ID == "" || it.Track.Type != "track" {
+					continue
+				}
+				tracks = append(tracks, *it.Track)
+			}
+			next = page.Next
+		}
+		if len(tracks) == 0 {
+			return spotifyImportedMsg{err: errorf("プレイリスト「%s」にダウンロードできる曲がありません", meta.Name)}
+		}
+		return spotifyImportedMsg{name: meta.Name, items: spotifyQueueItems(tracks)}
+	}
+}
+
+// spotifyQueueItems turns the tracks into queue items, with a made-up one-disc release per album so
+// buildTags fills album, date, track number and ISRC as it does for MusicBrainz data.
+func spotifyQueueItems(tracks []spotifyTrack) []queueItem {
+	releases := map[string]item{}
+	var items []queueItem
+	for _, t := range tracks {
+		release, ok := releases[t.Album.ID]
+		if !ok {
+			albumArtist := joinSpotifyArtists(t.Album.Artists)
+			release = item{
+				id: spotifyIDPrefix + "album:" + t.Album.ID, title: t.Album.Name, desc: albumArtist,
+				meta: MBRelease{Title: t.Album.Name, Date: t.Album.ReleaseDate, ArtistCredit: []MBArtist{{Name: albumArtist}}},
+			}
+			releases[t.Album.ID] = release
+		}
+		mbTrack := MBTrack{
+			ID: spotifyIDPrefix + "track:" + t.ID, Title: t.Name, Number: fmt.Sprint(t.TrackNumber), Length: t.DurationMS,
+		}
+		if t.ExternalIDs.ISRC != "" {
+			mbTrack.Recording.ISRCs = []string{strings.ToUpper(t.ExternalIDs.ISRC)}
+		}
+		artist := joinSpotifyArtists(t.Artists)
+		track := item{id: mbTrack.ID, title: t.Name, desc: artist, artist: artist, meta: mbTrack}
+		items = append(items, queueItem{track: track, release: release})
+	}
+	return items
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// --- yt-dlp → ffmpeg のストリーミング ---
+// 音声を一時ファイルに書かずに、yt-dlp の標準出力をそのまま ffmpeg の標準入力に流して変換する。
+
+type downloadConfig struct {
+	// yt-dlp の出力を一時ファイルを使わずに ffmpeg へ直接渡す。変換に失敗する場合は自動で一時ファイルに切り替える
+	Stream bool `json:"stream"`
+}
+
+// streamConvertError is a streamed conversion that failed on ffmpeg's side. The usual cause is a
+// container ffmpeg can't read from a pipe, so the caller retries through a temp file.
+type streamConvertError struct{ out string }
+
+func (e streamConvertError) Error() string { return tr("ffmpegでの変換失敗:\n%s", e.out) }
+
+// streamAudioCmd makes yt-dlp write the chosen audio format to stdout.
+func streamAudioCmd(ctx context.Context, ytDlpPath string, yt item) *exec.Cmd {
+	return ytDlpCommand(ctx, ytDlpPath, append(append(liveArgs(yt), progressArgs()...), "--quiet", "--no-warnings", "-f", audioFormat(yt), "-o", "-", yt.url)...)
+}
+
+// countingReader counts the bytes passed through to ffmpeg.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// runStreamed pipes src's stdout into conv and waits for both. When yt-dlp fails before sending
+// anything, ffmpeg's complaint about the empty input is not what went wrong, so the download error
+// is reported instead. With cut set, conv stops reading early on purpose (a trim or a time limit), so
+// src failing on the closed pipe after a successful conversion is expected. It returns the number of
+// bytes ffmpeg read.
+func runStreamed(src, conv *exec.Cmd, cut bool) (int64, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return 0, err
+	}
+	var srcLog, convLog bytes.Buffer
+	counter := &countingReader{r: pr}
+	src.Stdout, src.Stderr = pw, progressOutput(&srcLog)
+	conv.Stdin, conv.Stdout, conv.Stderr = counter, &convLog, &convLog
+	if err := conv.Start(); err != nil {
+		pr.Close()
+		pw.Close()
+		return 0, err
+	}
+	convDone := make(chan error, 1)
+	go func() {
+		convDone <- conv.Wait()
+		pr.Close() // ffmpeg が先に終了したら yt-dlp の書き込みを失敗させて止める
+	}()
+	srcErr := src.Start()
+	pw.Close() // yt-dlp が終了したら ffmpeg に EOF が届くように
+	if srcErr == nil {
+		srcErr = src.Wait()
+	}
+	convErr := <-convDone
+	switch {
+	case srcErr != nil && counter.n == 0:
+		return 0, errorf("音声のダウンロード失敗:\n%s", srcLog.String())
+	case convErr != nil:
+		return counter.n, streamConvertError{out: convLog.String()}
+	case srcErr != nil && !cut:
+		return counter.n, errorf("音声のダウンロード失敗:\n%s", srcLog.String())
+	}
+	return counter.n, nil
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- チャンネル登録 ---
+// subscribe add で登録したYouTubeのチャンネル (トピックチャンネルを含む) の新しいアップロードを定期的に確認し、
+// TUIでは起動時にダウンロードキューへ、daemon では inbox と同じ自動照合に回す。
+// ダウンロードした動画は yt-dlp の --download-archive と同じ形式のアーカイブに記録し、二度ダウンロードしない。
+const (
+	subscriptionsFile       = "subscriptions.json"
+	subscriptionArchiveFile = "subscriptions_archive.txt"
+	subscriptionIDPrefix    = "subscription:"
+	defaultSubsInterval     = 6
+	defaultSubsLimit        = 30
+)
+
+type subscriptionsConfig struct {
+	// 新しいアップロードを確認する間隔 (時間)。0 なら確認しない
+	IntervalHours int `json:"interval_hours"`
+	// 1回の確認でチャンネルごとに見る最新の動画の数
+	Limit int `json:"limit"`
+}
+
+type subscription struct {
+	ChannelID string `json:"channel_id"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+}
+
+// uploadsURL is the channel's uploads playlist, which topic channels have too even when they show no
+// videos tab.
+func (s subscription) uploadsURL() string {
+	return "https://www.youtube.com/playlist?list=UU" + strings.TrimPrefix(s.ChannelID, "UC")
+}
+
+// artist is the channel name without the " - Topic" of auto-generated channels.
+func (s subscription) artist() string { return strings.TrimSuffix(s.Name, ytmTopicSuffix) }
+
+type subscriptionList struct {
+	Channels  []subscription `json:"channels"`
+	LastCheck time.Time      `json:"last_check"`
+}
+
+// channelUpload is a new video found on a subscribed channel.
+type channelUpload struct {
+	sub   subscription
+	entry ytmEntry
+}
+
+type subscriptionUploadsMsg struct {
+	items []queueItem
+	err   error
+}
+
+var subscriptionsMu sync.Mutex
+
+func subscriptionsPath() string { return filepath.Join(mainDir, subscriptionsFile) }
+
+func subscriptionArchivePath() string { return filepath.Join(mainDir, subscriptionArchiveFile) }
+
+func loadSubscriptions() (subscriptionList, error) {
+	var s subscriptionList
+	data, err := os.ReadFile(subscriptionsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	return s, json.Unmarshal(data, &s)
+}
+
+// updateSubscriptions applies fn to the stored list under the lock and saves it.
+func updateSubscriptions(fn func(s *subscriptionList) error) error {
+	subscriptionsMu.Lock()
+	defer subscriptionsMu.Unlock()
+	s, err := loadSubscriptions()
+	if err != nil {
+		return err
+	}
+	if err := fn(&s); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(subscriptionsPath(), data, 0o644)
+}
+
+// loadArchive reads the video IDs of the archive ("youtube <id>" per line, as yt-dlp writes it).
+func loadArchive() (map[string]bool, error) {
+	ids := map[string]bool{}
+	f, err := os.Open(subscriptionArchivePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if extractor, id, ok := strings.Cut(strings.TrimSpace(sc.Text()), " "); ok && extractor == "youtube" {
+			ids[id] = true
+		}
+	}
+	return ids, sc.Err()
+}
+
+// archiveVideos adds the IDs that aren't in the archive yet.
+func archiveVideos(ids ...string) error {
+	subscriptionsMu.Lock()
+	defer subscriptionsMu.Unlock()
+	known, err := loadArchive()
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, id := range ids {
+		if id != "" && !known[id] {
+			known[id] = true
+			fmt.Fprintf(&b, "youtube %s\n", id)
+		}
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(subscriptionArchivePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// archiveDownload records a finished download in the archive once any channel is subscribed, so
+// an upload fetched by hand isn't queued again.
+func archiveDownload(videoID string) {
+	if videoID == "" {
+		return
+	}
+	if _, err := os.Stat(subscriptionsPath()); err != nil {
+		return
+	}
+	if err := archiveVideos(videoID); err != nil {
+		log.Printf("Subscriptions: failed to update archive: %v", err)
+	}
+}
+
+// channelPage is what yt-dlp -J --flat-playlist reports for a channel page or playlist.
+type channelPage struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	ChannelID string     `json:"channel_id"`
+	Channel   string     `json:"channel"`
+	Uploader  string     `json:"uploader"`
+	Entries   []ytmEntry `json:"entries"`
+}
+
+// channelFlat lists a channel page or playlist without resolving each video.
+func channelFlat(ytDlpPath, pageURL string, limit int) (channelPage, error) {
+	var page channelPage
+	ctx, cancel := context.WithTimeout(context.Background(), 4*cmdTimeout)
+	defer cancel()
+	output, err := ytDlpCommand(ctx, ytDlpPath, "--quiet", "--no-warnings", "--flat-playlist", "--playlist-end", fmt.Sprint(limit), "-J", pageURL).CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return page, errorf("チャンネルの取得がタイムアウトしました")
+		}
+		return page, errorf("チャンネルの取得に失敗:\n%s", string(output))
+	}
+	if err := json.Unmarshal(output, &page); err != nil {
+		return page, errorf("yt-dlpの出力のJSON解析に失敗:\n%v", err)
+	}
+	return page, nil
+}
+
+// resolveChannel finds the channel behind a channel URL (/@handle, /channel/UC…) or one of its videos.
+func resolveChannel(ytDlpPath, channelURL string) (subscription, error) {
+	page, err := channelFlat(ytDlpPath, channelURL, 1)
+	if err != nil {
+		return subscription{}, err
+	}
+	id := page.ChannelID
+	if id == "" && strings.HasPrefix(page.ID, "UC") {
+		id = page.ID
+	}
+	if id == "" {
+		return subscription{}, errorf("%s からチャンネルを特定できませんでした", channelURL)
+	}
+	name := firstNonEmpty(page.Channel, page.Uploader, strings.TrimSuffix(page.Title, " - Videos"), id)
+	return subscription{ChannelID: id, Name: name, URL: channelURL}, nil
+}
+
+// channelUploads returns the latest uploads of the channel that aren't in the archive.
+func channelUploads(ytDlpPath string, sub subscription, archived map[string]bool) ([]channelUpload, error) {
+	limit := cfg.Subscriptions.Limit
+	if limit <= 0 {
+		limit = defaultSubsLimit
+	}
+	page, err := channelFlat(ytDlpPath, sub.uploadsURL(), limit)
+	if err != nil {
+		return nil, err
+	}
+	var uploads []channelUpload
+	for _, e := range page.Entries {
+		if !e.unavailable() && !archived[e.ID] {
+			uploads = append(uploads, channelUpload{sub: sub, entry: e})
+		}
+	}
+	return uploads, nil
+}
+
+// checkSubscriptions looks at every subscribed channel when the interval is due (or always when force
+// is set) and returns the uploads that haven't been downloaded, oldest first.
+func checkSubscriptions(ytDlpPath string, force bool) ([]channelUpload, error) {
+	subs, err := loadSubscriptions()
+	if err != nil || len(subs.Channels) == 0 {
+		return nil, err
+	}
+	interval := time.Duration(cfg.Subscriptions.IntervalHours) * time.Hour
+	if !force && (interval <= 0 || time.Since(subs.LastCheck) < interval) {
+		return nil, nil
+	}
+	archived, err := loadArchive()
+	if err != nil {
+		return nil, err
+	}
+	var uploads []channelUpload
+	for _, sub := range subs.Channels {
+		found, err := channelUploads(ytDlpPath, sub, archived)
+		if err != nil {
+			return uploads, errorf("%s の確認に失敗: %w", sub.Name, err)
+		}
+		// 新しい順に並んでいるので、古いものから処理されるよう逆にする
+		for i := len(found) - 1; i >= 0; i-- {
+			uploads = append(uploads, found[i])
+		}
+	}
+	return uploads, updateSubscriptions(func(s *subscriptionList) error {
+		s.LastCheck = time.Now()
+		return nil
+	})
+}
+
+// checkSubscriptionsCmd queues the new uploads at startup. They stay out of the archive until they
+// are downloaded, so ones left in the queue come back at the next check.
+func checkSubscriptionsCmd(ytDlpPath string) tea.Cmd {
+	return func() tea.Msg {
+		uploads, err := checkSubscriptions(ytDlpPath, false)
+		items := make([]queueItem, len(uploads))
+		for i, u := range uploads {
+			release := item{id: subscriptionIDPrefix + u.sub.ChannelID, title: u.sub.Name, desc: tr("チャンネル登録"), meta: MBRelease{}}
+			items[i] = videoQueueItem(u.entry, u.sub.artist(), release)
+		}
+		return subscriptionUploadsMsg{items: items, err: err}
+	}
+}
+
+// queueSubscriptionJobs adds the new uploads to the daemon's job queue as URLs. They are archived once
+// queued: the queue keeps them from here on, and one filed for review isn't retried at every check.
+func queueSubscriptionJobs(ytDlpPath string, force bool) error {
+	uploads, err := checkSubscriptions(ytDlpPath, force)
+	if err != nil {
+		daemonLog("チャンネルの確認に失敗: %v", err)
+	}
+	queries := make([]string, len(uploads))
+	ids := make([]string, len(uploads))
+	for i, u := range uploads {
+		queries[i] = "https://www.youtube.com/watch?v=" + u.entry.ID
+		ids[i] = u.entry.ID
+	}
+	if err := addJobs(queries); err != nil {
+		return err
+	}
+	return archiveVideos(ids...)
+}
+
+func runSubscribe(args []string) error {
+	if len(args) == 0 {
+		return errorf("使い方: subscribe add <チャンネルのURL> | subscribe remove <チャンネル名またはURL> | subscribe list | subscribe check")
+	}
+	target := strings.TrimSpace(strings.Join(args[1:], " "))
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("subscribe add", flag.ContinueOnError)
+		all := fs.Bool("all", false, tr("登録時点の動画もダウンロードの対象にする"))
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errorf("使い方: subscribe add [-all] <チャンネルのURL>")
+		}
+		ytDlpPath, err := subscriptionYtDlp()
+		if err != nil {
+			return err
+		}
+		sub, err := resolveChannel(ytDlpPath, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		// 登録時点の動画はアーカイブに入れ、これ以降のアップロードだけを対象にする
+		skipped := 0
+		if !*all {
+			existing, err := channelUploads(ytDlpPath, sub, nil)
+			if err != nil {
+				return err
+			}
+			ids := make([]string, len(existing))
+			for i, u := range existing {
+				ids[i] = u.entry.ID
+			}
+			if err := archiveVideos(ids...); err != nil {
+				return err
+			}
+			skipped = len(ids)
+		}
+		err = updateSubscriptions(func(s *subscriptionList) error {
+			for _, c := range s.Channels {
+				if c.ChannelID == sub.ChannelID {
+					return errorf("%s はすでに登録されています", c.Name)
+				}
+			}
+			s.Channels = append(s.Channels, sub)
+			return nil
+		})
+		if err == nil {
+			fmt.Print(tr("%s (%s) を登録しました。既存の動画 %d 本はダウンロードしません。\n", sub.Name, sub.ChannelID, skipped))
+		}
+		return err
+	case "remove":
+		return updateSubscriptions(func(s *subscriptionList) error {
+			for i, c := range s.Channels {
+				if strings.EqualFold(c.Name, target) || c.URL == target || c.ChannelID == target {
+					s.Channels = append(s.Channels[:i], s.Channels[i+1:]...)
+					return nil
+				}
+			}
+			return errorf("%s は登録されていません", target)
+		})
+	case "list":
+		subs, err := loadSubscriptions()
+		if err != nil {
+			return err
+		}
+		for _, c := range subs.Channels {
+			fmt.Printf("%s\t%s\n", c.Name, c.URL)
+		}
+		if !subs.LastCheck.IsZero() {
+			fmt.Print(tr("\n最終確認: %s\n", subs.LastCheck.Format("2006-01-02 15:04")))
+		}
+		return nil
+	case "check":
+		ytDlpPath, err := subscriptionYtDlp()
+		if err != nil {
+			return err
+		}
+		uploads, err := checkSubscriptions(ytDlpPath, true)
+		for _, u := range uploads {
+			fmt.Printf("%s\t%s\thttps://www.youtube.com/watch?v=%s\n", u.sub.Name, u.entry.Title, u.entry.ID)
+		}
+		if err == nil && len(uploads) == 0 {
+			fmt.Println(tr("新しいアップロードはありません。"))
+		}
+		return err
+	}
+	return errorf("不明な操作です: %s (add / remove / list / check)", args[0])
+}
+
+func subscriptionYtDlp() (string, error) {
+	ytCheck := checkYtDlpCmd().(ytDlpCheckResultMsg)
+	return ytCheck.path, ytCheck.err
+}
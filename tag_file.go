@@ -0,0 +1,239 @@
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 既存ファイルのタグ付け ---
+// 手持ちの音声ファイルを、ダウンロードと同じ MusicBrainz検索 → トラック選択 → タグ編集 の流れでタグ付けし直す。
+// 音声は再エンコードせずにコピーし (FLAC / Opus / AAC / MP3 / Vorbis 以外はFLACに変換)、タグ・カバー画像・歌詞を書き換える。
+
+type tagFileLoadedMsg struct {
+	file item
+	err  error
+}
+
+// ffmpegFormatTagRe matches a container-level tag in ffmpeg's input banner (stream tags are indented
+// further).
+var ffmpegFormatTagRe = regexp.MustCompile(`^    ([^ :][^:]*?)\s*: (.*)$`)
+
+// trackNumberRe matches a leading track number in a file name ("01 ", "01. ", "1-02 - ").
+var trackNumberRe = regexp.MustCompile(`^\d+(?:-\d+)?[.\s_-]*`)
+
+func runTagFile(args []string) error {
+	if len(args) != 1 {
+		return errorf("使い方: tag <音声ファイル>")
+	}
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+		return errorf("ファイルが見つかりません: %s", args[0])
+	}
+	m := newModel()
+	m.tagFile = path
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// isLocalFile reports whether the search input is a file on disk rather than a query or URL.
+func isLocalFile(query string) bool {
+	fi, err := os.Stat(strings.Trim(query, `"'`))
+	return err == nil && !fi.IsDir()
+}
+
+// probeFormatTags reads the file's existing tags (keys lowercased) from ffmpeg's input banner.
+func probeFormatTags(ffmpegPath, path string) map[string]string {
+	out, _ := exec.Command(ffmpegPath, "-hide_banner", "-i", path).CombinedOutput()
+	tags := map[string]string{}
+	inFormat := false
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case line == "  Metadata:":
+			inFormat = true
+		case inFormat && ffmpegFormatTagRe.MatchString(line):
+			m := ffmpegFormatTagRe.FindStringSubmatch(line)
+			tags[strings.ToLower(m[1])] = m[2]
+		default:
+			inFormat = false
+		}
+	}
+	return tags
+}
+
+// loadTagFileCmd turns the file into a source item so the rest of the flow (matching, comparison)
+// treats it like a YouTube result.
+func loadTagFileCmd(ffmpegPath, path string) tea.Cmd {
+	return func() tea.Msg {
+		duration, err := probeDuration(ffmpegPath, path)
+		if err != nil {
+			return tagFileLoadedMsg{err: errorf("音声ファイルとして読み込めません: %s", path)}
+		}
+		tags := probeFormatTags(ffmpegPath, path)
+		title, artist := tags["title"], tags["artist"]
+		if title == "" {
+			// タグが無ければ「アーティスト - 曲名」形式のファイル名から読み取る
+			title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			if a, t, ok := strings.Cut(trackNumberRe.ReplaceAllString(title, ""), " - "); ok && artist == "" {
+				artist, title = strings.TrimSpace(a), strings.TrimSpace(t)
+			}
+		}
+		info := ytDlpVideoInfo{Title: title, Uploader: artist, Duration: duration}
+		return tagFileLoadedMsg{file: item{title: title, desc: artist, url: path, meta: info}}
+	}
+}
+
+func (m *model) startTagger(path string) tea.Cmd {
+	m.tagFile = strings.Trim(path, `"'`)
+	m.searchStart, m.searchTook = time.Now(), 0
+	m.state, m.statusMsg = stateSearching, tr("ファイルを読み込み中です...")
+	return tea.Batch(m.spinner.Tick, loadTagFileCmd(m.ffmpegPath, m.tagFile))
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// retagFileCmd rewrites the file with the chosen tags, art and lyrics. FLAC files are edited in place;
+// anything else is copied aside first and put back if ffmpeg fails. With move the result goes to the
+// naming template's path in the library instead of staying next to the original.
+func retagFileCmd(ffmpegPath, path string, selectedMB item, tags finalTags, move bool) tea.Cmd {
+	return func() tea.Msg {
+		tmpDir, err := newTempDir()
+		if err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		defer os.RemoveAll(tmpDir)
+
+		var tl timeline
+		ext := filepath.Ext(path)
+		job := convertJob{audioPath: path, tags: tags, timeline: &tl, codec: probeAudioCodec(ffmpegPath, path)}
+		native := job.codec == "flac" && strings.EqualFold(ext, ".flac")
+		if !native {
+			src := filepath.Join(tmpDir, "source"+ext)
+			start := time.Now()
+			if err := copyFile(path, src); err != nil {
+				return downloadFinishedMsg{err: err}
+			}
+			tl.add(tr("コピー"), start, fileSize(src))
+			job.audioPath = src
+		}
+		if passthroughExt(job.codec) == "" {
+			log.Printf("Tagger: %q can't be kept as-is, converting to FLAC", job.codec)
+			job.codec = ""
+		}
+		job.outputPath = strings.TrimSuffix(path, ext) + job.outputExt()
+		if move {
+			job.outputPath = filepath.Join(mainDir, downloadsDir, trackFilename(tags, job.outputExt()))
+			if fi, err := os.Stat(job.outputPath); err == nil {
+				if orig, err := os.Stat(path); err != nil || !os.SameFile(fi, orig) {
+					return downloadFinishedMsg{err: errorf("ライブラリに同じ名前のファイルがあります: %s", job.outputPath)}
+				}
+			}
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		var coverPhase, extrasPhase timeline
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			job.coverPath, job.coverSrc = fetchCoverArt(tmpDir, selectedMB.meta.(MBRelease))
+			coverPhase.add(tr("カバー画像"), start, 0)
+		}()
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			job.lyrics, job.credits = fetchTrackExtras(tags)
+			extrasPhase.add(tr("歌詞・クレジット"), start, 0)
+		}()
+		wg.Wait()
+		tl = append(append(tl, coverPhase...), extrasPhase...)
+
+		if native {
+			return retagFLACInPlace(path, job, selectedMB)
+		}
+		finalPath, err := convertAudio(ffmpegPath, job)
+		if err != nil {
+			if job.outputPath == path {
+				if rerr := copyFile(job.audioPath, path); rerr != nil {
+					log.Printf("Tagger: failed to restore %s: %v", path, rerr)
+				}
+			} else {
+				os.Remove(job.outputPath)
+			}
+			return downloadFinishedMsg{err: err}
+		}
+		if finalPath != path {
+			// 別の拡張子に入れ直した場合は元のファイルを残さない
+			if err := os.Remove(path); err != nil {
+				log.Printf("Tagger: failed to remove %s: %v", path, err)
+			}
+		}
+		finalPath = recordDownload(finalPath, job, item{}, selectedMB)
+		queueUpload(finalPath, job.tags)
+		log.Printf("Timeline: %s", tl)
+		return downloadFinishedMsg{filename: finalPath + retagNote(move), timeline: tl}
+	}
+}
+
+func retagNote(move bool) string {
+	if move {
+		return tr("\n(ライブラリに取り込みました)")
+	}
+	return tr("\n(タグを書き換えました)")
+}
+
+// retagFLACInPlace writes the job's tags and cover straight into the FLAC metadata blocks, leaving
+// the audio frames untouched, then moves the file if the job goes elsewhere.
+func retagFLACInPlace(path string, job convertJob, selectedMB item) tea.Msg {
+	start := time.Now()
+	if err := writeFLACJob(path, job); err != nil {
+		return downloadFinishedMsg{err: errorf("FLACのタグ書き込みに失敗: %w", err)}
+	}
+	job.timeline.add(tr("タグ書き込み"), start, 0)
+	finalPath := path
+	if moved := job.outputPath; moved != path {
+		if err := os.MkdirAll(filepath.Dir(moved), os.ModePerm); err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		if err := moveFile(path, moved); err != nil {
+			return downloadFinishedMsg{err: err}
+		}
+		finalPath = moved
+	}
+	if job.lyrics.Synced != "" && cfg.Lyrics.LRCSidecar != lrcSidecarOff {
+		if err := writeLRCSidecar(finalPath, job.lyrics.Synced); err != nil {
+			log.Printf("Lyrics: failed to write .lrc for %s: %v", finalPath, err)
+		}
+	}
+	finalPath = recordDownload(finalPath, job, item{}, selectedMB)
+	queueUpload(finalPath, job.tags)
+	log.Printf("Timeline: %s", job.timeline)
+	return downloadFinishedMsg{filename: finalPath + retagNote(finalPath != path), timeline: *job.timeline}
+}
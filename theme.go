@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// --- 配色テーマ ---
+// 色は ui.theme のプリセットから選び、ui.colors で1色ずつ上書きできる。
+// dark は従来の Dracula 風、light は明るい背景の端末向け、auto は端末の背景色を問い合わせてどちらかを選ぶ。
+// 環境変数 NO_COLOR が設定されているか ui.theme が none のときは色も装飾も付けない。
+const (
+	themeDark  = "dark"
+	themeLight = "light"
+	themeAuto  = "auto"
+	themeNone  = "none"
+)
+
+type palette struct {
+	fg, comment, cyan, green, pink, purple, red, yellow, title string
+}
+
+var themePresets = map[string]palette{
+	themeDark: {
+		fg: "#f8f8f2", comment: "#6272a4", cyan: "#8be9fd", green: "#50fa7b", pink: "#ff79c6",
+		purple: "#bd93f9", red: "#ff5555", yellow: "#f1fa8c", title: "#f8f8f2",
+	},
+	themeLight: {
+		fg: "#1f1f1f", comment: "#6c664b", cyan: "#036a96", green: "#14710a", pink: "#a3144d",
+		purple: "#644ac9", red: "#cb3a2a", yellow: "#846e15", title: "#ffffff",
+	},
+}
+
+// setupTheme sets the colors and the styles built from them. It runs after the config is loaded,
+// before anything is drawn.
+func setupTheme() {
+	name := strings.ToLower(cfg.UI.Theme)
+	if os.Getenv("NO_COLOR") != "" || name == themeNone {
+		lipgloss.SetColorProfile(termenv.Ascii)
+		name = themeDark
+	}
+	if name == themeAuto {
+		name = themeDark
+		if !lipgloss.HasDarkBackground() {
+			name = themeLight
+		}
+	}
+	p, ok := themePresets[name]
+	if !ok {
+		if name != "" {
+			log.Printf("Theme: unknown preset %q, using %s", cfg.UI.Theme, themeDark)
+		}
+		p = themePresets[themeDark]
+	}
+	colors := map[string]*string{
+		"fg": &p.fg, "comment": &p.comment, "cyan": &p.cyan, "green": &p.green, "pink": &p.pink,
+		"purple": &p.purple, "red": &p.red, "yellow": &p.yellow, "title": &p.title,
+	}
+	for key, value := range cfg.UI.Colors {
+		c, ok := colors[strings.ToLower(key)]
+		if !ok {
+			log.Printf("Theme: unknown color %q in ui.colors", key)
+			continue
+		}
+		*c = value
+	}
+	fgColor, commentColor, cyanColor = lipgloss.Color(p.fg), lipgloss.Color(p.comment), lipgloss.Color(p.cyan)
+	greenColor, pinkColor, purpleColor = lipgloss.Color(p.green), lipgloss.Color(p.pink), lipgloss.Color(p.purple)
+	redColor, yellowColor, titleColor = lipgloss.Color(p.red), lipgloss.Color(p.yellow), lipgloss.Color(p.title)
+
+	headerStyle = lipgloss.NewStyle().Foreground(titleColor).Background(purpleColor).Padding(0, 1).Bold(true)
+	helpStyle = lipgloss.NewStyle().Foreground(commentColor)
+	listTitleStyle = lipgloss.NewStyle().Background(pinkColor).Foreground(titleColor).Padding(0, 1)
+	helpKeyStyle = lipgloss.NewStyle().Foreground(cyanColor).Bold(true).Width(14)
+	compareLabelStyle = lipgloss.NewStyle().Foreground(commentColor).Width(12)
+	compareOKStyle = lipgloss.NewStyle().Foreground(greenColor)
+	compareBadStyle = lipgloss.NewStyle().Foreground(redColor).Bold(true)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- 処理時間のタイムライン ---
+// 検索・ダウンロード・カバー画像・歌詞・変換にかかった時間を完了画面に表示し、ログにも残す。
+const timelineBarWidth = 24
+
+type phase struct {
+	name  string
+	took  time.Duration
+	bytes int64 // 転送量 (0 なら速度を表示しない)
+}
+
+type timeline []phase
+
+// add records a phase that started at start. A nil timeline ignores it, so jobs without one don't
+// have to check.
+func (t *timeline) add(name string, start time.Time, bytes int64) {
+	if t == nil {
+		return
+	}
+	*t = append(*t, phase{name: name, took: time.Since(start), bytes: bytes})
+}
+
+func (p phase) String() string {
+	s := fmt.Sprintf("%s %.1fs", p.name, p.took.Seconds())
+	if rate := p.rate(); rate != "" {
+		s += " @ " + rate
+	}
+	return s
+}
+
+func (p phase) rate() string {
+	if p.bytes <= 0 || p.took <= 0 {
+		return ""
+	}
+	return formatBytes(int64(float64(p.bytes)/p.took.Seconds())) + "/s"
+}
+
+// fileSize is the size of a downloaded file, or 0 if it can't be read.
+func fileSize(path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return fi.Size()
+}
+
+func (t timeline) String() string {
+	parts := make([]string, len(t))
+	for i, p := range t {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// view draws each phase with a bar scaled to the slowest one.
+func (t timeline) view() string {
+	var longest time.Duration
+	nameWidth := 0
+	for _, p := range t {
+		longest = max(longest, p.took)
+		nameWidth = max(nameWidth, lipgloss.Width(p.name))
+	}
+	barStyle := lipgloss.NewStyle().Foreground(cyanColor)
+	var b strings.Builder
+	b.WriteString(helpStyle.Render(tr("処理時間")) + "\n")
+	for _, p := range t {
+		n := 1
+		if longest > 0 {
+			n = max(int(float64(timelineBarWidth)*p.took.Seconds()/longest.Seconds()), 1)
+		}
+		line := fmt.Sprintf("%s%s %s %6.1fs", p.name, strings.Repeat(" ", nameWidth-lipgloss.Width(p.name)), barStyle.Render(strings.Repeat("█", n))+strings.Repeat(" ", timelineBarWidth-n), p.took.Seconds())
+		if p.bytes > 0 {
+			line += helpStyle.Render(fmt.Sprintf("  %s @ %s", formatBytes(p.bytes), p.rate()))
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// endSearchPhase adds the time since the search started to the search phase. next restarts the
+// clock for a follow-up lookup (the MusicBrainz search after a URL was resolved).
+func (m *model) endSearchPhase(next bool) {
+	if !m.searchStart.IsZero() {
+		m.searchTook += time.Since(m.searchStart)
+		m.searchStart = time.Time{}
+	}
+	if next {
+		m.searchStart = time.Now()
+	}
+}
+
+// jobTimeline puts the search phase in front of the download job's phases.
+func (m model) jobTimeline(job timeline) timeline {
+	if m.searchTook <= 0 {
+		return job
+	}
+	return append(timeline{{name: tr("検索"), took: m.searchTook}}, job...)
+}
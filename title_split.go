@@ -0,0 +1,68 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// --- アーティストと曲名の分割 ---
+// YouTube のタイトルによくある「アーティスト - 曲名」「アーティスト「曲名」」「曲名 / アーティスト」の形から
+// アーティストと曲名を取り出し、MusicBrainz の検索をアーティストで絞り込む。タグ付けをスキップして
+// ダウンロードするときも、取り出した曲名とアーティストをタグに使う。
+// どちら側がアーティストかはチャンネル名と似ている方を優先し、無ければ上の並びの慣習に従う。
+var versionSuffixRe = regexp.MustCompile(`(?i)^(\d{4}\s+)?(re-?master(ed)?|remix|live|version|ver\.?|edit|instrumental|acoustic|karaoke|off vocal|short)\b`)
+
+// splitArtistTitle guesses the artist and the song from a video title.
+func splitArtistTitle(yt item) (artist, title string, ok bool) {
+	s := stripNoise(yt.title)
+	channel := cleanChannel(yt.desc)
+	for _, q := range [][2]string{{"「", "」"}, {"『", "』"}} {
+		before, rest, found := strings.Cut(s, q[0])
+		inner, after, closed := strings.Cut(rest, q[1])
+		if !found || !closed || strings.TrimSpace(inner) == "" {
+			continue
+		}
+		artist = strings.TrimSpace(before)
+		if artist == "" {
+			artist = strings.TrimSpace(after)
+		}
+		return artist, strings.TrimSpace(inner), artist != ""
+	}
+	for _, sep := range []string{" - ", " – ", " — ", " / ", " ／ "} {
+		left, right, found := strings.Cut(s, sep)
+		left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+		if !found || left == "" || right == "" {
+			continue
+		}
+		artist, title = left, right // アーティスト - 曲名
+		if strings.Contains(sep, "/") || strings.Contains(sep, "／") {
+			artist, title = right, left // 曲名 / アーティスト
+		}
+		if channel != "" && similarity(title, channel) > similarity(artist, channel) {
+			artist, title = title, artist
+		}
+		if versionSuffixRe.MatchString(title) {
+			return "", "", false // 「曲名 - Remastered」のような版の説明
+		}
+		return artist, title, true
+	}
+	return "", "", false
+}
+
+// guessTags are the tags of a download that skips MusicBrainz: what an auto-generated video publishes,
+// yt-dlp's song fields, the split title and artist, or the cleaned title and the channel.
+func guessTags(yt item) finalTags {
+	if info, ok := yt.meta.(ytDlpVideoInfo); ok {
+		t, ok := topicMetadata(info)
+		if !ok {
+			t, ok = songFields(info)
+		}
+		if ok {
+			return finalTags{Title: t.Title, Artist: t.Artist, AlbumArtist: t.Artist, Album: t.Album, Date: t.Date, Label: t.Label}
+		}
+	}
+	if artist, title, ok := splitArtistTitle(yt); ok {
+		return finalTags{Title: title, Artist: artist}
+	}
+	return finalTags{Title: cleanTitle(yt.title), Artist: cleanChannel(yt.desc)}
+}
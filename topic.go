@@ -0,0 +1,148 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- 自動生成の動画 (「 - Topic」チャンネル) ---
+// レーベルが配信した曲は YouTube が「アーティスト名 - Topic」のチャンネルに自動で動画を作り、概要欄の
+// 「Provided to YouTube by …」に曲名・アーティスト・アルバム・発売日・レーベルが決まった形で書かれている。
+// このような動画を選んだときは MusicBrainz のあいまいな照合を行わず、その情報からそのままタグ編集に進む。
+// MusicBrainz で探したいときは search.musicbrainz_for_topic を true にする。
+const (
+	topicIDPrefix   = "youtube:"
+	providedToYT    = "Provided to YouTube by "
+	autoGeneratedYT = "Auto-generated by YouTube."
+)
+
+var (
+	// 「℗ 2019 Sony Music Labels Inc.」
+	phonogramRe = regexp.MustCompile(`^℗\s*(\d{4})\s*(.*)$`)
+	// 「Released on: 2019-12-15」
+	releasedOnRe = regexp.MustCompile(`^Released on:\s*(\d{4}-\d{2}-\d{2})`)
+)
+
+// topicInfo is the metadata YouTube publishes with an auto-generated track.
+type topicInfo struct {
+	Title  string
+	Artist string
+	Album  string
+	Date   string
+	Label  string
+}
+
+// topicMetadata reads the track behind an auto-generated video: yt-dlp's own fields when it filled them,
+// then the "Provided to YouTube" description, then the video title and the Topic channel's artist.
+func topicMetadata(info ytDlpVideoInfo) (topicInfo, bool) {
+	t, described := parseProvidedDescription(info.Description)
+	if !described && !strings.HasSuffix(info.Channel, ytmTopicSuffix) && !strings.HasSuffix(info.Uploader, ytmTopicSuffix) {
+		return topicInfo{}, false
+	}
+	song, _ := songFields(info)
+	t.Title = firstNonEmpty(song.Title, t.Title, info.Title)
+	t.Artist = firstNonEmpty(song.Artist, t.Artist, strings.TrimSuffix(firstNonEmpty(info.Channel, info.Uploader), ytmTopicSuffix))
+	t.Album = firstNonEmpty(song.Album, t.Album)
+	t.Date = firstNonEmpty(t.Date, song.Date)
+	return t, t.Title != "" && t.Artist != ""
+}
+
+// songFields is the song metadata yt-dlp exposes for music videos (track, artist, album and release
+// date), mostly for music.youtube.com links; it reports false unless the track and an artist are there.
+func songFields(info ytDlpVideoInfo) (topicInfo, bool) {
+	t := topicInfo{
+		Title: strings.TrimSpace(info.Track), Album: strings.TrimSpace(info.Album),
+		Artist: strings.TrimSpace(firstNonEmpty(strings.Join(info.Artists, ", "), info.Artist)),
+	}
+	if d := info.ReleaseDate; len(d) == 8 {
+		t.Date = d[:4] + "-" + d[4:6] + "-" + d[6:]
+	}
+	return t, t.Title != "" && t.Artist != ""
+}
+
+// parseProvidedDescription reads the fixed layout of an auto-generated description:
+//
+//	Provided to YouTube by <distributor>
+//
+//	<title> · <artist> · <artist>
+//
+//	<album>
+//
+//	℗ <year> <label>
+//
+//	Released on: <date>
+func parseProvidedDescription(desc string) (topicInfo, bool) {
+	var paras []string
+	for _, p := range strings.Split(strings.ReplaceAll(desc, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paras = append(paras, p)
+		}
+	}
+	if len(paras) < 2 || !strings.HasPrefix(paras[0], providedToYT) {
+		return topicInfo{}, false
+	}
+	var t topicInfo
+	credits := strings.Split(paras[1], " · ")
+	t.Title = strings.TrimSpace(credits[0])
+	if len(credits) > 1 {
+		t.Artist = strings.Join(credits[1:], ", ")
+	}
+	for n, p := range paras[2:] {
+		if m := phonogramRe.FindStringSubmatch(p); m != nil {
+			t.Label = strings.TrimSpace(m[2])
+			if t.Date == "" {
+				t.Date = m[1]
+			}
+		} else if m := releasedOnRe.FindStringSubmatch(p); m != nil {
+			t.Date = m[1]
+		} else if n == 0 && !strings.Contains(p, ":") && p != autoGeneratedYT {
+			t.Album = p // アルバム名は曲名の次の段落 (シングルでは省かれることがある)
+		}
+	}
+	return t, t.Title != ""
+}
+
+// topicItems turns the metadata into the release and track the tag editor and the download expect.
+// The release ID has a prefix, so nothing looks it up on MusicBrainz; the cover falls back to iTunes
+// or the video thumbnail.
+func topicItems(yt item, t topicInfo) (release, track item) {
+	info := yt.meta.(ytDlpVideoInfo)
+	r := MBRelease{
+		ID: topicIDPrefix + info.ID, Title: firstNonEmpty(t.Album, t.Title), Date: t.Date,
+		ArtistCredit: []MBArtist{{Name: t.Artist}},
+	}
+	if t.Label != "" {
+		r.LabelInfo = []MBLabelInfo{{Label: MBLabel{Name: t.Label}}}
+	}
+	song := MBTrack{ID: topicIDPrefix + info.ID, Title: t.Title, Length: int(info.Duration * 1000)}
+	release = item{id: r.ID, title: r.Title, desc: "YouTube", meta: r}
+	track = item{id: song.ID, title: song.Title, desc: t.Artist, artist: t.Artist, meta: song}
+	return release, track
+}
+
+// useTopicTags opens the tag editor with the metadata of an auto-generated video, skipping the
+// MusicBrainz search; it reports false for other videos.
+func (m *model) useTopicTags(yt item) (tea.Cmd, bool) {
+	info, ok := yt.meta.(ytDlpVideoInfo)
+	if !ok || cfg.Search.MusicBrainzForTopic {
+		return nil, false
+	}
+	t, ok := topicMetadata(info)
+	if !ok {
+		return nil, false
+	}
+	m.selectedYT = yt
+	m.selectedMB, m.selectedTrack = topicItems(yt, t)
+	m.matchNote = tr("YouTubeの自動生成の情報を使っています (MusicBrainzは検索しません)")
+	m.state = stateEditTags
+	m.focusIndex = 0
+	m.tagInputs = m.createTagInputs()
+	return tea.Batch(m.tagInputs[0].Focus(), m.prefetchLyrics(), m.prefetchLastFM()), true
+}
+
+// fromTopic reports whether the tags being edited came from an auto-generated video.
+func (m model) fromTopic() bool {
+	return strings.HasPrefix(m.selectedMB.id, topicIDPrefix)
+}
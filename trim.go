@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- 前後のトリム ---
+// 曲が始まる前のトークや無音・黒画面を変換時に削る。試聴には ffplay を使う。
+const (
+	trimStep        = 0.5
+	trimBigStep     = 5.0
+	trimPreviewSec  = 6.0
+	trimMinDuration = 1.0
+)
+
+// trimOffsets are the seconds cut from the head and the tail of the source audio.
+type trimOffsets struct{ Head, Tail float64 }
+
+func (t trimOffsets) active() bool { return t.Head > 0 || t.Tail > 0 }
+
+// segment converts the offsets into the part of a source of the given length to keep. When the
+// length is unknown only the head can be trimmed.
+func (t trimOffsets) segment(durationSec float64) audioSegment {
+	seg := audioSegment{Start: t.Head}
+	if t.Tail > 0 && durationSec > 0 {
+		seg.End = math.Max(durationSec-t.Tail, t.Head+trimMinDuration)
+	}
+	return seg
+}
+
+func (t trimOffsets) String() string {
+	return tr("先頭 %.1f秒 / 末尾 %.1f秒", t.Head, t.Tail)
+}
+
+type trimPreviewMsg struct {
+	videoID, streamURL string
+	err                error
+}
+
+func videoDuration(yt item) float64 {
+	info, _ := yt.meta.(ytDlpVideoInfo)
+	return info.Duration
+}
+
+func (m *model) openTrim() {
+	m.trimEdit, m.trimFocus, m.trimNote, m.state = m.pendingTags.Trim, 0, "", stateTrim
+}
+
+// adjustTrim moves the focused offset, keeping at least trimMinDuration seconds of audio.
+func (m *model) adjustTrim(delta float64) {
+	v := &m.trimEdit.Head
+	other := m.trimEdit.Tail
+	if m.trimFocus == 1 {
+		v, other = &m.trimEdit.Tail, m.trimEdit.Head
+	}
+	*v = math.Max(0, *v+delta)
+	if d := videoDuration(m.selectedYT); d > 0 {
+		*v = math.Min(*v, math.Max(0, d-other-trimMinDuration))
+	}
+}
+
+// previewTrimCmd plays a few seconds right after the head cut, or right before the tail cut, straight
+// from the stream so nothing has to be downloaded first.
+func previewTrimCmd(ytDlpPath string, yt item, streamURL string, t trimOffsets, tail bool) tea.Cmd {
+	return func() tea.Msg {
+		ffplay, err := exec.LookPath("ffplay")
+		if err != nil {
+			return trimPreviewMsg{err: errorf("ffplay が見つからないため試聴できません")}
+		}
+		if streamURL == "" {
+			ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+			defer cancel()
+			out, err := ytDlpCommand(ctx, ytDlpPath, "--quiet", "--no-warnings", "-f", audioFormat(yt), "-g", yt.url).Output()
+			if err != nil {
+				return trimPreviewMsg{err: errorf("音声URLの取得に失敗しました: %v", err)}
+			}
+			streamURL = strings.TrimSpace(strings.Split(string(out), "\n")[0])
+		}
+		start := t.Head
+		if tail {
+			start = math.Max(0, videoDuration(yt)-t.Tail-trimPreviewSec)
+		}
+		args := []string{"-nodisp", "-autoexit", "-loglevel", "quiet", "-ss", fmt.Sprintf("%.1f", start), "-t", fmt.Sprintf("%.1f", trimPreviewSec), streamURL}
+		if err := exec.Command(ffplay, args...).Run(); err != nil {
+			return trimPreviewMsg{videoID: yt.id, streamURL: streamURL, err: errorf("試聴に失敗しました: %v", err)}
+		}
+		return trimPreviewMsg{videoID: yt.id, streamURL: streamURL}
+	}
+}
+
+// updateTrim handles keys on the trim screen.
+func (m *model) updateTrim(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "tab", "shift+tab", "up", "down":
+		m.trimFocus = 1 - m.trimFocus
+	case "left", "h":
+		m.adjustTrim(-trimStep)
+	case "right", "l":
+		m.adjustTrim(trimStep)
+	case "shift+left", "H":
+		m.adjustTrim(-trimBigStep)
+	case "shift+right", "L":
+		m.adjustTrim(trimBigStep)
+	case "0":
+		m.trimEdit = trimOffsets{}
+	case "p", " ":
+		if m.trimBusy {
+			return nil
+		}
+		streamURL := ""
+		if m.trimStreamFor == m.selectedYT.id {
+			streamURL = m.trimStream
+		}
+		m.trimBusy, m.trimNote = true, tr("再生中...")
+		return previewTrimCmd(m.ytDlpPath, m.selectedYT, streamURL, m.trimEdit, m.trimFocus == 1)
+	case "enter":
+		m.pendingTags.Trim, m.state = m.trimEdit, stateCompare
+	case "esc":
+		m.state = stateCompare
+	}
+	return nil
+}
+
+func (m model) trimView() string {
+	var b strings.Builder
+	b.WriteString("\n" + listTitleStyle.Render(tr("前後のトリム — %s", m.selectedYT.title)) + "\n\n")
+	labels := []string{tr("先頭から削る"), tr("末尾から削る")}
+	values := []float64{m.trimEdit.Head, m.trimEdit.Tail}
+	for i, label := range labels {
+		cursor := "  "
+		style := lipgloss.NewStyle()
+		if i == m.trimFocus {
+			cursor = lipgloss.NewStyle().Foreground(cyanColor).Render("> ")
+			style = style.Foreground(cyanColor).Bold(true)
+		}
+		b.WriteString(fmt.Sprintf("  %s%s %s\n", cursor, helpStyle.Render(label+":"), style.Render(tr("%6.1f 秒", values[i]))))
+	}
+	if d := videoDuration(m.selectedYT); d > 0 {
+		const width = 40
+		head := int(math.Round(m.trimEdit.Head / d * width))
+		tail := int(math.Round(m.trimEdit.Tail / d * width))
+		keep := max(width-head-tail, 0)
+		bar := lipgloss.NewStyle().Foreground(redColor).Render(strings.Repeat("░", head)) +
+			lipgloss.NewStyle().Foreground(greenColor).Render(strings.Repeat("█", keep)) +
+			lipgloss.NewStyle().Foreground(redColor).Render(strings.Repeat("░", tail))
+		kept := int(math.Round(d - m.trimEdit.Head - m.trimEdit.Tail))
+		b.WriteString(fmt.Sprintf("\n    %s  %s → %s\n", bar, formatDuration(int(math.Round(d))), formatDuration(kept)))
+	}
+	if trackInfo, ok := m.selectedTrack.meta.(MBTrack); ok && trackInfo.Length > 0 {
+		b.WriteString("\n" + helpStyle.Render(tr("    MusicBrainzの長さ: %s", formatDuration(trackInfo.Length/1000))) + "\n")
+	}
+	if m.trimNote != "" {
+		b.WriteString("\n  " + lipgloss.NewStyle().Foreground(yellowColor).Render(m.trimNote) + "\n")
+	}
+	return b.String()
+}
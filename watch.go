@@ -0,0 +1,484 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- アーティストの新譜チェック ---
+// watch add で登録したアーティストのリリースグループを MusicBrainz で定期的に確認し、前回までに無かった
+// ものを新譜として watch.json に残す。TUIでは Ctrl+N で一覧し、選んだリリースの全曲をキューに追加できる。
+// check-new -inbox なら新譜の曲を inbox.txt に書き、daemon に処理させる。
+const (
+	watchFile                 = "watch.json"
+	defaultWatchIntervalHours = 24
+	mbBrowseLimit             = 100
+)
+
+type watchConfig struct {
+	// TUIの起動時に新譜を確認する間隔 (時間)。0 なら起動時には確認しない
+	IntervalHours int `json:"interval_hours"`
+	// 確認するリリースの種類: album / ep / single
+	Types []string `json:"types"`
+}
+
+type watchedArtist struct {
+	ID   string   `json:"id"`
+	Name string   `json:"name"`
+	Seen []string `json:"seen"` // 確認済みのリリースグループのMBID
+}
+
+type newRelease struct {
+	GroupID string    `json:"group_id"`
+	Title   string    `json:"title"`
+	Artist  string    `json:"artist"`
+	Type    string    `json:"type"`
+	Date    string    `json:"date"`
+	Found   time.Time `json:"found"`
+}
+
+type watchList struct {
+	Artists   []watchedArtist `json:"artists"`
+	LastCheck time.Time       `json:"last_check"`
+	// 見つかった新譜。キューに追加するか消すまで残す
+	Pending []newRelease `json:"pending"`
+}
+
+type mbReleaseGroup struct {
+	ID               string     `json:"id"`
+	Title            string     `json:"title"`
+	PrimaryType      string     `json:"primary-type"`
+	FirstReleaseDate string     `json:"first-release-date"`
+	ArtistCredit     []MBArtist `json:"artist-credit"`
+}
+
+type newReleasesMsg struct {
+	pending []newRelease
+	err     error
+}
+
+type newReleaseQueuedMsg struct {
+	group   newRelease
+	release item
+	tracks  []list.Item
+	err     error
+}
+
+var watchMu sync.Mutex
+
+func watchPath() string { return filepath.Join(mainDir, watchFile) }
+
+func loadWatchList() (watchList, error) {
+	var w watchList
+	data, err := os.ReadFile(watchPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return w, err
+	}
+	return w, json.Unmarshal(data, &w)
+}
+
+func saveWatchList(w watchList) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(watchPath(), data, 0o644)
+}
+
+// updateWatchList applies fn to the stored list under the lock and saves it.
+func updateWatchList(fn func(w *watchList) error) (watchList, error) {
+	watchMu.Lock()
+	defer watchMu.Unlock()
+	w, err := loadWatchList()
+	if err != nil {
+		return w, err
+	}
+	if err := fn(&w); err != nil {
+		return w, err
+	}
+	return w, saveWatchList(w)
+}
+
+func (c watchConfig) typeFilter() string {
+	types := c.Types
+	if len(types) == 0 {
+		types = []string{"album", "ep", "single"}
+	}
+	return strings.Join(types, "|")
+}
+
+// findArtist looks the name up on MusicBrainz and takes the best match.
+func findArtist(name string) (watchedArtist, error) {
+	var data struct {
+		Artists []struct {
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			Score int    `json:"score"`
+		} `json:"artists"`
+	}
+	apiURL := "https://musicbrainz.org/ws/2/artist/?fmt=json&limit=5&query=" + url.QueryEscape(name)
+	if err := mbGetJSON(apiURL, &data); err != nil {
+		return watchedArtist{}, err
+	}
+	for _, a := range data.Artists {
+		if strings.EqualFold(a.Name, name) {
+			return watchedArtist{ID: a.ID, Name: a.Name}, nil
+		}
+	}
+	if len(data.Artists) == 0 || data.Artists[0].Score < 90 {
+		return watchedArtist{}, errorf("MusicBrainzで「%s」に一致するアーティストが見つかりませんでした", name)
+	}
+	return watchedArtist{ID: data.Artists[0].ID, Name: data.Artists[0].Name}, nil
+}
+
+// artistReleaseGroups browses all of the artist's release groups of the watched types, bypassing the
+// cache so new ones show up.
+func artistReleaseGroups(artistID string) ([]mbReleaseGroup, error) {
+	var groups []mbReleaseGroup
+	for offset := 0; ; offset += mbBrowseLimit {
+		var page struct {
+			Count  int              `json:"release-group-count"`
+			Groups []mbReleaseGroup `json:"release-groups"`
+		}
+		apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release-group?artist=%s&type=%s&inc=artist-credits&fmt=json&limit=%d&offset=%d",
+			artistID, url.QueryEscape(cfg.Watch.typeFilter()), mbBrowseLimit, offset)
+		if err := mbGetFreshJSON(apiURL, &page); err != nil {
+			return nil, err
+		}
+		groups = append(groups, page.Groups...)
+		if len(page.Groups) == 0 || len(groups) >= page.Count {
+			return groups, nil
+		}
+	}
+}
+
+// checkNewReleases compares every watched artist's release groups with the ones already seen and
+// adds the new ones to the pending list.
+func checkNewReleases() (watchList, error) {
+	watchMu.Lock()
+	w, err := loadWatchList()
+	watchMu.Unlock()
+	if err != nil {
+		return w, err
+	}
+	found := map[string][]mbReleaseGroup{}
+	for _, a := range w.Artists {
+		groups, err := artistReleaseGroups(a.ID)
+		if err != nil {
+			return w, errorf("%s の確認に失敗: %w", a.Name, err)
+		}
+		found[a.ID] = groups
+	}
+	return updateWatchList(func(w *watchList) error {
+		for i, a := range w.Artists {
+			seen := map[string]bool{}
+			for _, id := range a.Seen {
+				seen[id] = true
+			}
+			for _, g := range found[a.ID] {
+				if seen[g.ID] {
+					continue
+				}
+				seen[g.ID] = true
+				w.Artists[i].Seen = append(w.Artists[i].Seen, g.ID)
+				w.Pending = append(w.Pending, newRelease{
+					GroupID: g.ID, Title: g.Title, Artist: firstNonEmpty(joinArtistCredits(g.ArtistCredit), a.Name),
+					Type: g.PrimaryType, Date: g.FirstReleaseDate, Found: time.Now(),
+				})
+			}
+		}
+		w.LastCheck = time.Now()
+		return nil
+	})
+}
+
+// checkNewReleasesCmd runs the check at startup when it is due, and otherwise just reports what is
+// still pending.
+func checkNewReleasesCmd() tea.Msg {
+	w, err := loadWatchList()
+	if err != nil || len(w.Artists) == 0 {
+		return newReleasesMsg{err: err}
+	}
+	interval := time.Duration(cfg.Watch.IntervalHours) * time.Hour
+	if interval > 0 && time.Since(w.LastCheck) >= interval {
+		if w, err = checkNewReleases(); err != nil {
+			return newReleasesMsg{pending: w.Pending, err: err}
+		}
+	}
+	return newReleasesMsg{pending: w.Pending}
+}
+
+func dismissNewRelease(groupID string) error {
+	_, err := updateWatchList(func(w *watchList) error {
+		kept := w.Pending[:0]
+		for _, p := range w.Pending {
+			if p.GroupID != groupID {
+				kept = append(kept, p)
+			}
+		}
+		w.Pending = kept
+		return nil
+	})
+	return err
+}
+
+// groupRelease picks the release of a release group to take the tracklist from: the earliest
+// official one.
+func groupRelease(groupID string) (MBRelease, error) {
+	var data struct {
+		Releases []MBRelease `json:"releases"`
+	}
+	apiURL := fmt.Sprintf("https://musicbrainz.org/ws/2/release?release-group=%s&status=official&inc=artist-credits&fmt=json&limit=%d", groupID, mbBrowseLimit)
+	if err := mbGetFreshJSON(apiURL, &data); err != nil {
+		return MBRelease{}, err
+	}
+	if len(data.Releases) == 0 {
+		return MBRelease{}, errorf("リリースグループにまだ公式のリリースがありません")
+	}
+	sort.SliceStable(data.Releases, func(i, j int) bool {
+		a, b := data.Releases[i].Date, data.Releases[j].Date
+		return a != "" && (b == "" || a < b)
+	})
+	return data.Releases[0], nil
+}
+
+// newReleaseQueueCmd fetches the tracklist of a new release so all of it can be queued.
+func newReleaseQueueCmd(nr newRelease) tea.Cmd {
+	return func() tea.Msg {
+		release, err := groupRelease(nr.GroupID)
+		if err != nil {
+			return newReleaseQueuedMsg{group: nr, err: err}
+		}
+		tracks, releaseData, err := fetchTracklist(release.ID)
+		if err != nil {
+			return newReleaseQueuedMsg{group: nr, err: err}
+		}
+		desc := fmt.Sprintf("%s (%s) [%s]", joinArtistCredits(releaseData.ArtistCredit), releaseData.Date, nr.Type)
+		return newReleaseQueuedMsg{group: nr, release: item{title: releaseData.Title, desc: desc, id: releaseData.ID, meta: releaseData}, tracks: tracks}
+	}
+}
+
+func newReleaseItems(pending []newRelease) []list.Item {
+	items := make([]list.Item, len(pending))
+	for i, p := range pending {
+		items[i] = item{title: p.Title, desc: fmt.Sprintf("%s (%s) [%s]", p.Artist, firstNonEmpty(p.Date, tr("日付不明")), p.Type), id: p.GroupID, meta: p}
+	}
+	return items
+}
+
+func (m *model) openNewReleases() {
+	m.state = stateNewReleases
+	m.releaseList = newList(tr("ウォッチ中のアーティストの新譜 (%d件)", len(m.newReleases)), newReleaseItems(m.newReleases))
+	m.releaseList.SetSize(m.width-4, m.height-8)
+}
+
+// updateNewReleases handles keys on the new release list.
+func (m *model) updateNewReleases(msg tea.KeyMsg) tea.Cmd {
+	if m.releaseList.FilterState() == list.Filtering {
+		return nil
+	}
+	i, ok := m.releaseList.SelectedItem().(item)
+	switch msg.String() {
+	case "enter":
+		if ok {
+			m.state, m.statusMsg = stateSearching, tr("「%s」のトラックリストを取得中です...", i.title)
+			return tea.Batch(m.spinner.Tick, newReleaseQueueCmd(i.meta.(newRelease)))
+		}
+	case "d":
+		if ok {
+			m.removeNewRelease(i.id)
+			m.openNewReleases()
+		}
+	case "esc":
+		m.state = stateInput
+	}
+	return nil
+}
+
+func (m *model) removeNewRelease(groupID string) {
+	kept := m.newReleases[:0]
+	for _, p := range m.newReleases {
+		if p.GroupID != groupID {
+			kept = append(kept, p)
+		}
+	}
+	m.newReleases = kept
+	if err := dismissNewRelease(groupID); err != nil {
+		m.statusMsg = "⚠ " + err.Error()
+	}
+}
+
+func runWatch(args []string) error {
+	if len(args) == 0 {
+		return errorf("使い方: watch add <アーティスト名> | watch remove <アーティスト名> | watch list")
+	}
+	name := strings.TrimSpace(strings.Join(args[1:], " "))
+	switch args[0] {
+	case "add":
+		if name == "" {
+			return errorf("使い方: watch add <アーティスト名>")
+		}
+		artist, err := findArtist(name)
+		if err != nil {
+			return err
+		}
+		// 登録時点のリリースは既知として扱い、これ以降のものだけを新譜にする
+		groups, err := artistReleaseGroups(artist.ID)
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			artist.Seen = append(artist.Seen, g.ID)
+		}
+		_, err = updateWatchList(func(w *watchList) error {
+			for _, a := range w.Artists {
+				if a.ID == artist.ID {
+					return errorf("%s はすでに登録されています", a.Name)
+				}
+			}
+			w.Artists = append(w.Artists, artist)
+			return nil
+		})
+		if err == nil {
+			fmt.Print(tr("%s (https://musicbrainz.org/artist/%s) を登録しました。既存のリリース %d 件は新譜として扱いません。\n", artist.Name, artist.ID, len(groups)))
+		}
+		return err
+	case "remove":
+		_, err := updateWatchList(func(w *watchList) error {
+			for i, a := range w.Artists {
+				if strings.EqualFold(a.Name, name) || a.ID == name {
+					w.Artists = append(w.Artists[:i], w.Artists[i+1:]...)
+					return nil
+				}
+			}
+			return errorf("%s は登録されていません", name)
+		})
+		return err
+	case "list":
+		w, err := loadWatchList()
+		if err != nil {
+			return err
+		}
+		for _, a := range w.Artists {
+			fmt.Printf("%s\t%s\n", a.Name, a.ID)
+		}
+		if !w.LastCheck.IsZero() {
+			fmt.Print(tr("\n最終確認: %s / 未処理の新譜: %d件\n", w.LastCheck.Format("2006-01-02 15:04"), len(w.Pending)))
+		}
+		return nil
+	}
+	return errorf("不明な操作です: %s (add / remove / list)", args[0])
+}
+
+func runCheckNew(args []string) error {
+	fs := flag.NewFlagSet("check-new", flag.ContinueOnError)
+	inbox := fs.Bool("inbox", false, tr("新譜の曲を inbox.txt に書き出して daemon に任せる"))
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	w, err := checkNewReleases()
+	if err != nil {
+		return err
+	}
+	if len(w.Pending) == 0 {
+		fmt.Println(tr("新譜はありません。"))
+		return nil
+	}
+	for _, p := range w.Pending {
+		fmt.Printf("%s - %s (%s) [%s]\n", p.Artist, p.Title, firstNonEmpty(p.Date, tr("日付不明")), p.Type)
+	}
+	if !*inbox {
+		fmt.Println(tr("\nTUIの入力画面で Ctrl+N を押すとキューに追加できます。"))
+		return nil
+	}
+	for _, p := range w.Pending {
+		n, err := inboxNewRelease(p)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", p.Title, err)
+			continue
+		}
+		fmt.Print(tr("%s: %d曲を inbox に追加しました\n", p.Title, n))
+		if err := dismissNewRelease(p.GroupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newReleaseQueries returns an "artist title" search for every track of the release.
+func newReleaseQueries(nr newRelease) ([]string, error) {
+	release, err := groupRelease(nr.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	tracks, _, err := fetchTracklist(release.ID)
+	if err != nil {
+		return nil, err
+	}
+	queries := make([]string, len(tracks))
+	for i, li := range tracks {
+		t := li.(item)
+		queries[i] = t.artist + " " + t.title
+	}
+	return queries, nil
+}
+
+// inboxNewRelease appends the release's track searches to the daemon's inbox.
+func inboxNewRelease(nr newRelease) (int, error) {
+	queries, err := newReleaseQueries(nr)
+	if err != nil {
+		return 0, err
+	}
+	var b strings.Builder
+	for _, q := range queries {
+		b.WriteString(q + "\n")
+	}
+	f, err := os.OpenFile(filepath.Join(mainDir, inboxFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		f.Close()
+		return 0, err
+	}
+	return len(queries), f.Close()
+}
+
+// queueNewReleaseJobs checks the watched artists and adds the tracks of every new release to the
+// daemon's job queue.
+func queueNewReleaseJobs() error {
+	w, err := checkNewReleases()
+	if err != nil {
+		return err
+	}
+	for _, p := range w.Pending {
+		queries, err := newReleaseQueries(p)
+		if err != nil {
+			daemonLog("新譜「%s」の曲を取得できませんでした: %v", p.Title, err)
+			continue
+		}
+		if err := addJobs(queries); err != nil {
+			return err
+		}
+		daemonLog("新譜: %s - %s の %d 曲をジョブキューに追加しました", p.Artist, p.Title, len(queries))
+		if err := dismissNewRelease(p.GroupID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// --- Webhook 通知 ---
+// ダウンロードの完了・失敗を設定したWebhookに送る。daemon で動かしているときの通知向け。
+// Discord と Slack のURLはそれぞれの埋め込み形式で、それ以外のURLには webhookEvent をそのままJSONで送る。
+const (
+	webhookUsername = "GoMusicDownloader"
+	webhookSuccess  = "success"
+	webhookFailure  = "failure"
+	discordGreen    = 0x2ecc71
+	discordRed      = 0xe74c3c
+)
+
+type webhookConfig struct {
+	// 通知を送るWebhookのURL (Discord / Slack / 任意のJSONを受け取るURL)。空なら送らない
+	URLs []string `json:"urls"`
+	// 完了時に送る
+	OnSuccess bool `json:"on_success"`
+	// 失敗時に送る
+	OnFailure bool `json:"on_failure"`
+}
+
+// webhookEvent is the payload for generic webhooks.
+type webhookEvent struct {
+	Status    string    `json:"status"`
+	Title     string    `json:"title"`
+	Artist    string    `json:"artist"`
+	Album     string    `json:"album,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	VideoURL  string    `json:"video_url,omitempty"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// webhookThumbnail prefers the release's cover on the Cover Art Archive and falls back to the video's.
+func webhookThumbnail(selectedYT, selectedMB item) string {
+	if id := mbReleaseID(selectedMB.id); id != "" {
+		return fmt.Sprintf("https://coverartarchive.org/release/%s/front-250", id)
+	}
+	if selectedYT.id != "" {
+		return fmt.Sprintf("https://i.ytimg.com/vi/%s/hqdefault.jpg", selectedYT.id)
+	}
+	return ""
+}
+
+func notifyDownloaded(finalPath string, tags finalTags, selectedYT, selectedMB item) {
+	if !cfg.Webhook.OnSuccess {
+		return
+	}
+	sendWebhooks(webhookEvent{
+		Status: webhookSuccess, Title: tags.Title, Artist: tags.Artist, Album: tags.Album, Path: finalPath,
+		VideoURL: selectedYT.url, Thumbnail: webhookThumbnail(selectedYT, selectedMB), Time: time.Now(),
+	})
+}
+
+func notifyFailed(selectedYT item, tags finalTags, cause error) {
+	if !cfg.Webhook.OnFailure {
+		return
+	}
+	sendWebhooks(webhookEvent{
+		Status: webhookFailure, Title: tags.Title, Artist: tags.Artist, Album: tags.Album, Error: firstLine(cause.Error()),
+		VideoURL: selectedYT.url, Thumbnail: webhookThumbnail(selectedYT, item{}), Time: time.Now(),
+	})
+}
+
+// sendWebhooks posts the event to every configured URL. Failures are logged only.
+func sendWebhooks(e webhookEvent) {
+	for _, u := range cfg.Webhook.URLs {
+		if err := postWebhook(u, webhookPayload(u, e)); err != nil {
+			log.Printf("Webhook: failed to notify %s: %v", webhookHost(u), err)
+		}
+	}
+}
+
+func webhookHost(u string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	return host
+}
+
+func (e webhookEvent) heading() string {
+	if e.Status == webhookFailure {
+		return tr("❌ ダウンロード失敗: %s", e.Title)
+	}
+	return tr("✅ ダウンロード完了: %s", e.Title)
+}
+
+func (e webhookEvent) summary() string {
+	if e.Album == "" {
+		return e.Artist
+	}
+	return e.Artist + " / " + e.Album
+}
+
+// webhookPayload shapes the event for the service behind the URL.
+func webhookPayload(u string, e webhookEvent) interface{} {
+	switch host := webhookHost(u); {
+	case strings.HasSuffix(host, "discord.com") || strings.HasSuffix(host, "discordapp.com"):
+		return discordPayload(e)
+	case host == "hooks.slack.com":
+		return slackPayload(e)
+	}
+	return e
+}
+
+func discordPayload(e webhookEvent) interface{} {
+	embed := map[string]interface{}{
+		"title":       e.heading(),
+		"description": e.summary(),
+		"color":       discordGreen,
+		"timestamp":   e.Time.Format(time.RFC3339),
+	}
+	if e.VideoURL != "" {
+		embed["url"] = e.VideoURL
+	}
+	if e.Thumbnail != "" {
+		embed["thumbnail"] = map[string]string{"url": e.Thumbnail}
+	}
+	var fields []map[string]interface{}
+	if e.Path != "" {
+		fields = append(fields, map[string]interface{}{"name": tr("ファイル"), "value": e.Path})
+	}
+	if e.Error != "" {
+		embed["color"] = discordRed
+		fields = append(fields, map[string]interface{}{"name": tr("エラー"), "value": e.Error})
+	}
+	if fields != nil {
+		embed["fields"] = fields
+	}
+	return map[string]interface{}{"username": webhookUsername, "embeds": []interface{}{embed}}
+}
+
+func slackPayload(e webhookEvent) interface{} {
+	lines := []string{"*" + e.heading() + "*"}
+	if s := e.summary(); s != "" {
+		lines = append(lines, s)
+	}
+	if e.Path != "" {
+		lines = append(lines, "`"+e.Path+"`")
+	}
+	if e.Error != "" {
+		lines = append(lines, e.Error)
+	}
+	if e.VideoURL != "" {
+		lines = append(lines, e.VideoURL)
+	}
+	section := map[string]interface{}{
+		"type": "section",
+		"text": map[string]string{"type": "mrkdwn", "text": strings.Join(lines, "\n")},
+	}
+	if e.Thumbnail != "" {
+		section["accessory"] = map[string]string{"type": "image", "image_url": e.Thumbnail, "alt_text": e.Title}
+	}
+	return map[string]interface{}{"text": e.heading(), "blocks": []interface{}{section}}
+}
+
+func postWebhook(u string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := doWithRetry("webhook", req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
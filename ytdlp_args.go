@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- yt-dlp への追加引数 ---
+// config の yt_dlp.extra_args と起動時の --yt-dlp-args を、すべての yt-dlp 呼び出しの末尾に付ける。
+const ytDlpArgsFlag = "--yt-dlp-args"
+
+type ytDlpConfig struct {
+	// 例: ["--extractor-args", "youtube:player_client=web", "--limit-rate", "2M"]
+	ExtraArgs []string `json:"extra_args"`
+}
+
+// ytDlpFlagArgs holds the arguments given with --yt-dlp-args for this run.
+var ytDlpFlagArgs []string
+
+func ytDlpExtraArgs() []string {
+	return append(append([]string(nil), cfg.YtDlp.ExtraArgs...), ytDlpFlagArgs...)
+}
+
+// takeYtDlpArgsFlag removes --yt-dlp-args "..." (or --yt-dlp-args=...) from the command line, wherever it
+// appears, and stores the split arguments.
+func takeYtDlpArgsFlag(args []string) ([]string, error) {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		value, ok := "", false
+		switch {
+		case args[i] == ytDlpArgsFlag:
+			if i+1 >= len(args) {
+				return nil, errorf("%s に値がありません", ytDlpArgsFlag)
+			}
+			value, ok = args[i+1], true
+			i++
+		case strings.HasPrefix(args[i], ytDlpArgsFlag+"="):
+			value, ok = strings.TrimPrefix(args[i], ytDlpArgsFlag+"="), true
+		}
+		if !ok {
+			rest = append(rest, args[i])
+			continue
+		}
+		split, err := splitShellWords(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ytDlpArgsFlag, err)
+		}
+		ytDlpFlagArgs = append(ytDlpFlagArgs, split...)
+	}
+	return rest, nil
+}
+
+// splitShellWords splits s on whitespace, honouring single quotes, double quotes and backslash escapes.
+func splitShellWords(s string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord, quote, escaped := false, rune(0), false
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errorf("引用符が閉じられていません: %s", s)
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
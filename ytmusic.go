@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- YouTube Music のライブラリ ---
+// ブラウザのCookie (cookies の設定) でログインした状態の yt-dlp で、自分のプレイリストと
+// 「高く評価した曲」を一覧し、選んだプレイリストの曲をまとめてダウンロードキューに追加する。
+// YouTube は yt-dlp の OAuth ログインを受け付けなくなったため、ログインはCookieのみで行う。
+// キューの曲は動画が決まっているので、実行時のYouTube検索は行わずにそのままダウンロードする。
+const (
+	ytmLikedURL     = "https://music.youtube.com/playlist?list=LM"
+	ytmPlaylistsURL = "https://www.youtube.com/feed/playlists"
+	ytmIDPrefix     = "ytmusic:"
+	ytmTopicSuffix  = " - Topic"
+)
+
+type ytmEntry struct {
+	ID         string  `json:"id"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	Channel    string  `json:"channel"`
+	Uploader   string  `json:"uploader"`
+	Duration   float64 `json:"duration"`
+	LiveStatus string  `json:"live_status"`
+}
+
+// isYTMusicURL reports a music.youtube.com link. yt-dlp reads those with the YouTube Music clients,
+// which fill the track, artist and album fields for songs.
+func isYTMusicURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	return err == nil && strings.EqualFold(u.Hostname(), "music.youtube.com")
+}
+
+// unavailable reports entries that can't be downloaded: private or deleted videos and streams that
+// haven't ended yet.
+func (e ytmEntry) unavailable() bool {
+	return e.ID == "" || e.Title == "[Private video]" || e.Title == "[Deleted video]" ||
+		e.LiveStatus == "is_upcoming" || e.LiveStatus == "is_live"
+}
+
+// artist is the channel name, without the " - Topic" YouTube Music adds to auto-generated channels.
+func (e ytmEntry) artist() string {
+	return strings.TrimSuffix(firstNonEmpty(e.Channel, e.Uploader), ytmTopicSuffix)
+}
+
+type ytmPlaylistsMsg struct {
+	items []list.Item
+	err   error
+}
+
+type ytmTracksMsg struct {
+	items []queueItem
+	err   error
+}
+
+// ytmFlat runs yt-dlp --flat-playlist on a page that needs the signed-in cookies.
+func ytmFlat(ytDlpPath, pageURL string) (string, []ytmEntry, error) {
+	if cookieArgs() == nil {
+		// needsCookies がブラウザの選択画面を開くよう "--cookies" を含める
+		return "", nil, errorf("YouTube Musicのライブラリを読むにはログイン (--cookies) が必要です")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 4*cmdTimeout)
+	defer cancel()
+	output, err := ytDlpCommand(ctx, ytDlpPath, "--quiet", "--no-warnings", "--flat-playlist", "-J", pageURL).CombinedOutput()
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", nil, errorf("YouTube Musicのライブラリの取得がタイムアウトしました")
+		}
+		return "", nil, errorf("YouTube Musicのライブラリの取得に失敗:\n%s", string(output))
+	}
+	var page struct {
+		Title   string     `json:"title"`
+		Entries []ytmEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(output, &page); err != nil {
+		return "", nil, errorf("yt-dlpの出力のJSON解析に失敗:\n%v", err)
+	}
+	return page.Title, page.Entries, nil
+}
+
+// ytmPlaylistsCmd lists "liked songs" followed by the account's playlists.
+func ytmPlaylistsCmd(ytDlpPath string) tea.Cmd {
+	return func() tea.Msg {
+		_, entries, err := ytmFlat(ytDlpPath, ytmPlaylistsURL)
+		if err != nil {
+			return ytmPlaylistsMsg{err: err}
+		}
+		items := []list.Item{item{title: tr("高く評価した曲"), desc: "YouTube Music", id: "LM", url: ytmLikedURL}}
+		for _, e := range entries {
+			if e.ID == "" {
+				continue
+			}
+			url := e.URL
+			if url == "" {
+				url = "https://www.youtube.com/playlist?list=" + e.ID
+			}
+			items = append(items, item{title: e.Title, desc: firstNonEmpty(e.Uploader, e.Channel, tr("プレイリスト")), id: e.ID, url: url})
+		}
+		return ytmPlaylistsMsg{items: items}
+	}
+}
+
+// ytmTracksCmd reads the playlist's videos into queue items. Tags start from the video title and the
+// channel; the playlist becomes the queue group, with no album.
+func ytmTracksCmd(ytDlpPath string, playlist item) tea.Cmd {
+	return func() tea.Msg {
+		_, entries, err := ytmFlat(ytDlpPath, playlist.url)
+		if err != nil {
+			return ytmTracksMsg{err: err}
+		}
+		release := item{id: ytmIDPrefix + "playlist:" + playlist.id, title: playlist.title, desc: "YouTube Music", meta: MBRelease{}}
+		var items []queueItem
+		for _, e := range entries {
+			if !e.unavailable() {
+				items = append(items, videoQueueItem(e, e.artist(), release))
+			}
+		}
+		if len(items) == 0 {
+			return ytmTracksMsg{err: errorf("プレイリスト「%s」にダウンロードできる曲がありません", playlist.title)}
+		}
+		return ytmTracksMsg{items: items}
+	}
+}
+
+// videoQueueItem queues a known video. Tags start from the video title and the given artist.
+func videoQueueItem(e ytmEntry, artist string, release item) queueItem {
+	track := item{
+		id: ytmIDPrefix + "video:" + e.ID, title: e.Title, desc: artist, artist: artist,
+		meta: MBTrack{ID: ytmIDPrefix + "video:" + e.ID, Title: e.Title, Length: int(e.Duration * 1000)},
+	}
+	source := item{
+		title: e.Title, desc: artist, id: e.ID, url: "https://www.youtube.com/watch?v=" + e.ID,
+		meta: ytDlpVideoInfo{ID: e.ID, Title: e.Title, Uploader: e.Uploader, Channel: e.Channel, Duration: e.Duration},
+	}
+	return queueItem{track: track, release: release, source: source}
+}
+
+// openYTMusic starts loading the library; the cookie picker opens if yt-dlp isn't signed in.
+func (m *model) openYTMusic() tea.Cmd {
+	m.state, m.statusMsg = stateSearching, tr("YouTube Musicのライブラリを取得中です...")
+	return tea.Batch(m.spinner.Tick, m.retryable(ytmPlaylistsCmd(m.ytDlpPath)))
+}
+
+// updateYTMusic handles keys on the playlist list.
+func (m *model) updateYTMusic(msg tea.KeyMsg) tea.Cmd {
+	if m.ytmList.FilterState() == list.Filtering {
+		return nil
+	}
+	switch msg.String() {
+	case "enter":
+		i, ok := m.ytmList.SelectedItem().(item)
+		if !ok {
+			return nil
+		}
+		m.state, m.statusMsg = stateSearching, tr("「%s」の曲を取得中です...", i.title)
+		return tea.Batch(m.spinner.Tick, m.retryable(ytmTracksCmd(m.ytDlpPath, i)))
+	case "esc":
+		m.state = stateInput
+	}
+	return nil
+}